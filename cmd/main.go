@@ -4,24 +4,31 @@ import (
 	"context"
 	"crypto/tls"
 	"flag"
+	"net/http"
 	"os"
 	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
 	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
+	kubecache "k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/leaderelection"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
 
 	"github.com/mesosphere/dklb/pkg/admission"
+	"github.com/mesosphere/dklb/pkg/autoscaler"
 	"github.com/mesosphere/dklb/pkg/backends"
 	"github.com/mesosphere/dklb/pkg/cache"
 	"github.com/mesosphere/dklb/pkg/constants"
 	"github.com/mesosphere/dklb/pkg/controllers"
+	"github.com/mesosphere/dklb/pkg/dcos/secrets"
 	"github.com/mesosphere/dklb/pkg/edgelb/manager"
 	"github.com/mesosphere/dklb/pkg/features"
+	"github.com/mesosphere/dklb/pkg/garbagecollector"
 	_ "github.com/mesosphere/dklb/pkg/metrics"
 	"github.com/mesosphere/dklb/pkg/signals"
 	kubernetesutil "github.com/mesosphere/dklb/pkg/util/kubernetes"
@@ -48,8 +55,16 @@ var (
 	admissionTLSCertFile string
 	// admissionTLSPrivateKeyFile is the path to the file containing the private key to use for serving the admission webhook.
 	admissionTLSPrivateKeyFile string
+	// autoscalerOptions is the set of options used to configure the EdgeLB pool autoscaler.
+	autoscalerOptions autoscaler.EdgeLBPoolAutoscalerOptions
 	// clusterName is the name of the Mesos framework that corresponds to the current Kubernetes cluster.
 	clusterName string
+	// dcosSecretsOptions is the set of options used to configure the DC/OS secrets manager.
+	dcosSecretsOptions secrets.SecretsManagerOptions
+	// dryRun indicates whether calls to the write APIs of EdgeLB should be recorded instead of executed.
+	dryRun bool
+	// edgelbGarbageCollectorOptions is the set of options used to configure the EdgeLB garbage collector.
+	edgelbGarbageCollectorOptions garbagecollector.EdgeLBGarbageCollectorOptions
 	// edgelbOptions is the set of options used to configure the EdgeLB Manager.
 	edgelbOptions manager.EdgeLBManagerOptions
 	// featureGates is a comma-separated list of "key=value" pairs used to toggle certain features.
@@ -75,10 +90,23 @@ func init() {
 	flag.StringVar(&admissionTLSCaBundle, admissionTLSCaBundleFlagName, "", "the base64-encoded ca bundle to use for registering the admission webhook")
 	flag.StringVar(&admissionTLSCertFile, admissionTLSCertFileFlagName, "", "the path to the file containing the certificate to use for serving the admission webhook")
 	flag.StringVar(&admissionTLSPrivateKeyFile, admissionTLSPrivateKeyFlagName, "", "the path to the file containing the private key to use for serving the admission webhook")
+	flag.StringVar(&dcosSecretsOptions.BearerToken, "dcos-secrets-bearer-token", "", "the (optional) bearer token to use when communicating with the dc/os secrets api")
+	flag.StringVar(&dcosSecretsOptions.Host, "dcos-secrets-host", constants.DefaultDCOSSecretsHost, "the host at which the dc/os secrets api can be reached")
+	flag.BoolVar(&dcosSecretsOptions.InsecureSkipTLSVerify, "dcos-secrets-insecure-skip-tls-verify", false, "whether to skip verification of the tls certificate presented by the dc/os secrets api")
+	flag.StringVar(&dcosSecretsOptions.Path, "dcos-secrets-path", constants.DefaultDCOSSecretsPath, "the path at which the dc/os secrets api can be reached")
+	flag.StringVar(&dcosSecretsOptions.Scheme, "dcos-secrets-scheme", constants.DefaultDCOSSecretsScheme, "the scheme to use when communicating with the dc/os secrets api")
+	flag.StringVar(&dcosSecretsOptions.Store, "dcos-secrets-store", constants.DefaultDCOSSecretsStore, "the dc/os secret store in which to reflect tls secrets referenced by ingress resources")
+	flag.BoolVar(&dryRun, "dry-run", false, "whether to record the changes that would be made to edgelb pools (and expose them via logs, events and the \""+manager.DryRunOperationsPath+"\" endpoint) instead of making them")
 	flag.StringVar(&edgelbOptions.BearerToken, "edgelb-bearer-token", "", "the (optional) bearer token to use when communicating with the edgelb api server")
+	flag.BoolVar(&edgelbGarbageCollectorOptions.DryRun, "edgelb-gc-dry-run", false, "whether the edgelb garbage collector should only report the orphaned edgelb objects it finds instead of removing them")
+	flag.DurationVar(&edgelbGarbageCollectorOptions.GracePeriod, "edgelb-gc-grace-period", constants.DefaultEdgeLBGarbageCollectionGracePeriod, "the minimum amount of time during which an edgelb object must be found to be orphaned before it is removed by the edgelb garbage collector")
+	flag.DurationVar(&edgelbGarbageCollectorOptions.Interval, "edgelb-gc-interval", constants.DefaultEdgeLBGarbageCollectionInterval, "the interval at which edgelb pools are scanned for orphaned edgelb objects")
 	flag.StringVar(&edgelbOptions.Host, "edgelb-host", constants.DefaultEdgeLBHost, "the host at which the edgelb api server can be reached")
 	flag.BoolVar(&edgelbOptions.InsecureSkipTLSVerify, "edgelb-insecure-skip-tls-verify", false, "whether to skip verification of the tls certificate presented by the edgelb api server")
 	flag.StringVar(&edgelbOptions.Path, "edgelb-path", constants.DefaultEdgeLBPath, "the path at which the edgelb api server can be reached")
+	flag.DurationVar(&autoscalerOptions.Interval, "edgelb-pool-autoscaling-interval", constants.DefaultEdgeLBPoolAutoscalingInterval, "the interval at which the load of edgelb pools for which autoscaling is enabled is checked")
+	flag.DurationVar(&autoscalerOptions.ScaleDownCooldown, "edgelb-pool-scale-down-cooldown", constants.DefaultEdgeLBPoolScaleDownCooldown, "the minimum amount of time that must elapse after an edgelb pool has been scaled before it can be scaled down")
+	flag.DurationVar(&autoscalerOptions.ScaleUpCooldown, "edgelb-pool-scale-up-cooldown", constants.DefaultEdgeLBPoolScaleUpCooldown, "the minimum amount of time that must elapse after an edgelb pool has been scaled before it can be scaled up")
 	flag.StringVar(&edgelbOptions.PoolGroup, "edgelb-pool-group", constants.DefaultEdgeLBPoolGroup, "the dc/os service group in which to create edgelb pools")
 	flag.StringVar(&edgelbOptions.Scheme, "edgelb-scheme", constants.DefaultEdgeLBScheme, "the scheme to use when communicating with the edgelb api server")
 	flag.StringVar(&featureGates, "feature-gates", "", "a comma-separated list of \"key=value\" pairs used to toggle certain features")
@@ -135,6 +163,20 @@ func main() {
 	if err != nil {
 		log.Fatalf("failed to build edgelb manager: %v", err)
 	}
+	// Record calls to the write APIs of EdgeLB instead of executing them if dry-run mode is enabled.
+	// The recorded calls are exposed by the same HTTP server that exposes application-level metrics.
+	if dryRun {
+		d := manager.NewDryRunEdgeLBManager(edgelbManager)
+		http.Handle(manager.DryRunOperationsPath, d)
+		edgelbManager = d
+		log.Warnf("dry-run mode is enabled, changes to edgelb pools will be recorded instead of made")
+	}
+
+	// Create a new instance of the DC/OS secrets manager.
+	secretsManager, err := secrets.NewSecretsManager(dcosSecretsOptions)
+	if err != nil {
+		log.Fatalf("failed to build dc/os secrets manager: %v", err)
+	}
 
 	// Check the version of the EdgeLB API server that is currently installed, and issue a warning in case it could not be detected within a couple seconds.
 	ctx, fn := context.WithTimeout(context.Background(), 2*time.Second)
@@ -155,6 +197,17 @@ func main() {
 	if err != nil {
 		log.Fatalf("failed to build kubernetes client: %v", err)
 	}
+	// Create a dynamic client, used to read and update "networking.k8s.io/v1" Ingress and IngressClass resources.
+	dynamicClient, err := dynamic.NewForConfig(kubeConfig)
+	if err != nil {
+		log.Fatalf("failed to build dynamic kubernetes client: %v", err)
+	}
+	// Detect whether the Kubernetes API serves "networking.k8s.io/v1" Ingress resources, in which case these are used instead of "extensions/v1beta1" ones.
+	networkingV1Ingresses, err := kubernetesutil.ServesNetworkingV1Ingresses(kubeClient.Discovery())
+	if err != nil {
+		log.Fatalf("failed to detect whether networking.k8s.io/v1 ingresses are served: %v", err)
+	}
+	log.Infof("serving networking.k8s.io/v1 ingresses: %t", networkingV1Ingresses)
 
 	// Launch the default backend.
 	srvWaitGroup.Add(1)
@@ -183,7 +236,7 @@ func main() {
 				log.Fatalf("failed to read the tls certificate: %v", err)
 			}
 			// Create and start the admission webhook.
-			if err := admission.NewWebhook(clusterName, p).Run(stopCh); err != nil {
+			if err := admission.NewWebhook(clusterName, dynamicClient, p).Run(stopCh); err != nil {
 				log.Fatalf("failed to serve the admission webhook: %v", err)
 			}
 		}()
@@ -229,7 +282,7 @@ func main() {
 					<-stopCh
 					runCancel()
 				}()
-				run(runCtx, kubeClient, edgelbManager)
+				run(runCtx, kubeClient, dynamicClient, networkingV1Ingresses, edgelbManager, secretsManager)
 			},
 			OnStoppedLeading: func() {
 				// We've stopped leading, so we should exit immediately.
@@ -244,21 +297,41 @@ func main() {
 }
 
 // run starts the controllers and blocks until they stop.
-func run(ctx context.Context, kubeClient kubernetes.Interface, edgelbManager manager.EdgeLBManager) {
+// "networkingV1Ingresses" indicates whether Ingress resources must be read using the "networking.k8s.io/v1" API rather than the "extensions/v1beta1" one.
+func run(ctx context.Context, kubeClient kubernetes.Interface, dynamicClient dynamic.Interface, networkingV1Ingresses bool, edgelbManager manager.EdgeLBManager, secretsManager secrets.SecretsManager) {
 	// Create a shared informer factory for the base API types.
 	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeClient, resyncPeriod)
-	// Create a cache for Kubernetes resources based on the shared informer factory.
-	kubeCache := cache.NewKubernetesResourceCache(kubeInformerFactory)
+	// Create a shared informer factory for the API types not supported by the Kubernetes client libraries we depend on (i.e. "networking.k8s.io/v1" Ingress and IngressClass resources).
+	dynamicInformerFactory := dynamicinformer.NewDynamicSharedInformerFactory(dynamicClient, resyncPeriod)
+	// Create a cache for Kubernetes resources based on the shared informer factories, as well as an informer for Ingress resources served by the appropriate API (and, if applicable, an informer for IngressClass resources).
+	var (
+		kubeCache            cache.KubernetesResourceCache
+		ingressInformer      kubecache.SharedIndexInformer
+		ingressClassInformer kubecache.SharedIndexInformer
+	)
+	if networkingV1Ingresses {
+		kubeCache = cache.NewKubernetesResourceCacheWithNetworkingV1Ingresses(kubeInformerFactory, dynamicInformerFactory)
+		ingressInformer = dynamicInformerFactory.ForResource(kubernetesutil.NetworkingV1IngressResource).Informer()
+		ingressClassInformer = dynamicInformerFactory.ForResource(kubernetesutil.NetworkingV1IngressClassResource).Informer()
+	} else {
+		kubeCache = cache.NewKubernetesResourceCache(kubeInformerFactory)
+		ingressInformer = kubeInformerFactory.Extensions().V1beta1().Ingresses().Informer()
+	}
 	// Create an instance of the ingress controller that uses an ingress informer for watching Ingress resources.
-	ingressController := controllers.NewIngressController(clusterName, kubeClient, kubeInformerFactory.Extensions().V1beta1().Ingresses(), kubeInformerFactory.Core().V1().Services(), kubeCache, edgelbManager)
+	ingressController := controllers.NewIngressController(clusterName, kubeClient, dynamicClient, ingressInformer, ingressClassInformer, kubeInformerFactory.Core().V1().Secrets(), kubeInformerFactory.Core().V1().Services(), kubeInformerFactory.Core().V1().Endpoints(), kubeInformerFactory.Core().V1().Nodes(), kubeCache, edgelbManager, secretsManager)
 	// Create an instance of the service controller that uses a service informer for watching Service resources.
-	serviceController := controllers.NewServiceController(clusterName, kubeClient, kubeInformerFactory.Core().V1().Services(), kubeInformerFactory.Core().V1().ConfigMaps(), kubeCache, edgelbManager)
-	// Start the shared informer factory.
+	serviceController := controllers.NewServiceController(clusterName, kubeClient, kubeInformerFactory.Core().V1().Services(), kubeInformerFactory.Core().V1().ConfigMaps(), kubeInformerFactory.Core().V1().Endpoints(), kubeInformerFactory.Core().V1().Nodes(), kubeCache, edgelbManager)
+	// Create an instance of the EdgeLB pool autoscaler.
+	edgelbPoolAutoscaler := autoscaler.NewEdgeLBPoolAutoscaler(clusterName, kubeClient, kubeCache, edgelbManager, autoscalerOptions)
+	// Create an instance of the EdgeLB garbage collector.
+	edgelbGarbageCollector := garbagecollector.NewEdgeLBGarbageCollector(clusterName, kubeCache, edgelbManager, secretsManager, edgelbGarbageCollectorOptions)
+	// Start the shared informer factories.
 	go kubeInformerFactory.Start(ctx.Done())
+	go dynamicInformerFactory.Start(ctx.Done())
 
-	// Start the ingress and service controllers.
+	// Start the ingress and service controllers, as well as the EdgeLB pool autoscaler and the EdgeLB garbage collector.
 	var wg sync.WaitGroup
-	for _, c := range []controllers.Controller{ingressController, serviceController} {
+	for _, c := range []controllers.Controller{ingressController, serviceController, edgelbPoolAutoscaler, edgelbGarbageCollector} {
 		wg.Add(1)
 		go func(c controllers.Controller) {
 			defer wg.Done()
@@ -268,7 +341,7 @@ func run(ctx context.Context, kubeClient kubernetes.Interface, edgelbManager man
 		}(c)
 	}
 
-	// Wait for the controllers to stop.
+	// Wait for the controllers (and the EdgeLB pool autoscaler and garbage collector) to stop.
 	wg.Wait()
 	// Wait for the default backend and admission webhook servers to stop.
 	srvWaitGroup.Wait()
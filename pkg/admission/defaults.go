@@ -18,7 +18,7 @@ func setBaseDefaults(object metav1.Object, options *translator.BaseTranslationOp
 	if annotations == nil {
 		annotations = make(map[string]string)
 	}
-	// If no cloud load-balancer configuration is specified, we explicitly set the values of the "kubernetes.dcos.io/edgelb-pool-*" annotations.
+	// If no cloud load-balancer configuration is specified, we explicitly set the values of the "kubernetes.dcos.io/edgelb-pool-*" and "kubernetes.dcos.io/edgelb-backend-target" annotations.
 	// Otherwise, we explicitly remove them as their values must be controlled by ourselves.
 	if options.CloudLoadBalancerConfigMapName == nil {
 		annotations[constants.CloudLoadBalancerConfigMapNameAnnotationKey] = ""
@@ -28,6 +28,7 @@ func setBaseDefaults(object metav1.Object, options *translator.BaseTranslationOp
 		annotations[constants.EdgeLBPoolCpusAnnotationKey] = options.EdgeLBPoolCpus.String()
 		annotations[constants.EdgeLBPoolMemAnnotationKey] = options.EdgeLBPoolMem.String()
 		annotations[constants.EdgeLBPoolSizeAnnotationKey] = strconv.Itoa(options.EdgeLBPoolSize)
+		annotations[constants.EdgeLBBackendTargetAnnotationKey] = string(options.EdgeLBBackendTarget)
 	} else {
 		annotations[constants.CloudLoadBalancerConfigMapNameAnnotationKey] = *options.CloudLoadBalancerConfigMapName
 		delete(annotations, constants.EdgeLBPoolNameAnnotationKey)
@@ -36,17 +37,51 @@ func setBaseDefaults(object metav1.Object, options *translator.BaseTranslationOp
 		delete(annotations, constants.EdgeLBPoolCpusAnnotationKey)
 		delete(annotations, constants.EdgeLBPoolMemAnnotationKey)
 		delete(annotations, constants.EdgeLBPoolSizeAnnotationKey)
+		delete(annotations, constants.EdgeLBBackendTargetAnnotationKey)
 	}
+	// The load-balancing algorithm is honored even when a cloud load-balancer is configured, so we always explicitly set its value.
+	annotations[constants.EdgeLBBackendBalanceAnnotationKey] = options.EdgeLBBackendBalance
 	object.SetAnnotations(annotations)
 }
 
 // setDefaultsOnIngress sets default values for each missing annotation on the specified "Ingress" resource.
 func setDefaultsOnIngress(ingress *extsv1beta1.Ingress, options *translator.IngressTranslationOptions) {
 	setBaseDefaults(ingress, &options.BaseTranslationOptions)
+	// The (deprecated) "edgelb-pool-port" annotation is always removed, as its value has already been taken into account when computing the bind port for the HTTP frontend.
+	delete(ingress.Annotations, constants.EdgeLBPoolPortAnnotationKey)
 	if options.CloudLoadBalancerConfigMapName == nil {
-		ingress.Annotations[constants.EdgeLBPoolPortAnnotationKey] = strconv.Itoa(int(options.EdgeLBPoolPort))
+		ingress.Annotations[constants.EdgeLBPoolHTTPPortAnnotationKey] = strconv.Itoa(int(options.EdgeLBPoolHTTPPort))
+		ingress.Annotations[constants.EdgeLBPoolHTTPSPortAnnotationKey] = strconv.Itoa(int(options.EdgeLBPoolHTTPSPort))
 	} else {
-		delete(ingress.Annotations, constants.EdgeLBPoolPortAnnotationKey)
+		delete(ingress.Annotations, constants.EdgeLBPoolHTTPPortAnnotationKey)
+		delete(ingress.Annotations, constants.EdgeLBPoolHTTPSPortAnnotationKey)
+	}
+	ingress.Annotations[constants.EdgeLBPathMatchTypeAnnotationKey] = string(options.EdgeLBPathMatchType)
+	// The name and maximum lifetime of the cookie used to implement sticky sessions are only relevant when sticky sessions are enabled.
+	ingress.Annotations[constants.EdgeLBStickySessionsAnnotationKey] = strconv.FormatBool(options.EdgeLBStickySessionsEnabled)
+	if options.EdgeLBStickySessionsEnabled {
+		ingress.Annotations[constants.EdgeLBStickySessionsCookieNameAnnotationKey] = options.EdgeLBStickySessionsCookieName
+		ingress.Annotations[constants.EdgeLBStickySessionsCookieTTLAnnotationKey] = options.EdgeLBStickySessionsCookieTTL.String()
+	} else {
+		delete(ingress.Annotations, constants.EdgeLBStickySessionsCookieNameAnnotationKey)
+		delete(ingress.Annotations, constants.EdgeLBStickySessionsCookieTTLAnnotationKey)
+	}
+	// The status code and exceptions used when redirecting requests to the HTTPS frontend are only relevant when redirects are enabled.
+	ingress.Annotations[constants.EdgeLBSSLRedirectAnnotationKey] = strconv.FormatBool(options.EdgeLBSSLRedirectEnabled)
+	if options.EdgeLBSSLRedirectEnabled {
+		ingress.Annotations[constants.EdgeLBSSLRedirectCodeAnnotationKey] = strconv.Itoa(options.EdgeLBSSLRedirectCode)
+	} else {
+		delete(ingress.Annotations, constants.EdgeLBSSLRedirectCodeAnnotationKey)
+		delete(ingress.Annotations, constants.EdgeLBSSLRedirectExceptionsAnnotationKey)
+	}
+	// The directives of the "Strict-Transport-Security" header are only relevant when HSTS is enabled.
+	ingress.Annotations[constants.EdgeLBHSTSAnnotationKey] = strconv.FormatBool(options.EdgeLBHSTSEnabled)
+	if options.EdgeLBHSTSEnabled {
+		ingress.Annotations[constants.EdgeLBHSTSMaxAgeAnnotationKey] = options.EdgeLBHSTSMaxAge.String()
+		ingress.Annotations[constants.EdgeLBHSTSIncludeSubdomainsAnnotationKey] = strconv.FormatBool(options.EdgeLBHSTSIncludeSubdomains)
+	} else {
+		delete(ingress.Annotations, constants.EdgeLBHSTSMaxAgeAnnotationKey)
+		delete(ingress.Annotations, constants.EdgeLBHSTSIncludeSubdomainsAnnotationKey)
 	}
 }
 
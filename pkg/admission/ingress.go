@@ -1,7 +1,12 @@
 package admission
 
 import (
+	"fmt"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
 	extsv1beta1 "k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	"github.com/mesosphere/dklb/pkg/translator"
 	kubernetesutil "github.com/mesosphere/dklb/pkg/util/kubernetes"
@@ -37,6 +42,19 @@ func (w *Webhook) validateAndMutateIngress(currentIng, previousIng *extsv1beta1.
 		if err := translator.ValidateIngressTranslationOptionsUpdate(previousOptions, currentOptions); err != nil {
 			return nil, err
 		}
+		// Request for the resource to be migrated in case the name of the target EdgeLB pool has changed.
+		if err := startEdgeLBPoolMigration(mutatedIng, previousIng, &previousOptions.BaseTranslationOptions, &currentOptions.BaseTranslationOptions); err != nil {
+			return nil, err
+		}
+	}
+
+	// Make sure that all the paths defined in the current "Ingress" resource can be translated using the requested match type.
+	if err := translator.ValidateIngressPaths(currentIng, currentOptions.EdgeLBPathMatchType); err != nil {
+		return nil, err
+	}
+	// Make sure that all the paths defined in the current "Ingress" resource can be rewritten as requested.
+	if err := translator.ValidateIngressRewriteTargets(currentIng, *currentOptions); err != nil {
+		return nil, err
 	}
 
 	// At this point we know that the current "Ingress" resource is valid.
@@ -50,3 +68,56 @@ func (w *Webhook) validateAndMutateIngress(currentIng, previousIng *extsv1beta1.
 	setDefaultsOnIngress(mutatedIng, currentOptions)
 	return mutatedIng, nil
 }
+
+// validateAndMutateNetworkingV1Ingress validates and mutates the "networking.k8s.io/v1" Ingress resource contained in the specified admission review.
+// The Ingress resource is converted into the representation used internally by dklb so that it can be validated and mutated as any other Ingress resource, and the resulting annotations are then applied to the original object.
+func (w *Webhook) validateAndMutateNetworkingV1Ingress(rev admissionv1beta1.AdmissionReview) *admissionv1beta1.AdmissionResponse {
+	// Deserialize and convert the current object.
+	// "currentObj" MUST NOT be modified, as it is used as the basis for the patch to apply as a result of the current request.
+	currentObj := &unstructured.Unstructured{}
+	if err := currentObj.UnmarshalJSON(rev.Request.Object.Raw); err != nil {
+		return admissionResponseFromError(fmt.Errorf("failed to deserialize the current object: %v", err))
+	}
+	currentIng, err := kubernetesutil.ConvertNetworkingV1Ingress(currentObj, w.getIngressClass)
+	if err != nil {
+		return admissionResponseFromError(err)
+	}
+
+	// If the current request corresponds to an update operation, we also deserialize and convert the previous version of the resource so we can validate the transition.
+	var previousIng *extsv1beta1.Ingress
+	if rev.Request.Operation == admissionv1beta1.Update {
+		previousObj := &unstructured.Unstructured{}
+		if err := previousObj.UnmarshalJSON(rev.Request.OldObject.Raw); err != nil {
+			return admissionResponseFromError(fmt.Errorf("failed to deserialize the previous object: %v", err))
+		}
+		if previousIng, err = kubernetesutil.ConvertNetworkingV1Ingress(previousObj, w.getIngressClass); err != nil {
+			return admissionResponseFromError(err)
+		}
+	}
+
+	// Validate and mutate the Ingress resource.
+	mutatedIng, err := w.validateAndMutateIngress(currentIng, previousIng)
+	if err != nil {
+		return admissionResponseFromError(err)
+	}
+
+	// Apply the resulting annotations to a copy of the current object, and create a patch containing the changes to apply to the resource.
+	mutatedObj := currentObj.DeepCopy()
+	kubernetesutil.SetNetworkingV1IngressAnnotations(mutatedObj, mutatedIng)
+	patch, err := CreateRFC6902Patch(currentObj, mutatedObj)
+	if err != nil {
+		return admissionResponseFromError(fmt.Errorf("failed to create patch: %v", err))
+	}
+
+	// Return an admission response that admits the resource and contains the patch to be applied.
+	return &admissionv1beta1.AdmissionResponse{
+		Allowed:   true,
+		Patch:     patch,
+		PatchType: &patchType,
+	}
+}
+
+// getIngressClass returns the ("networking.k8s.io/v1") IngressClass resource with the specified name.
+func (w *Webhook) getIngressClass(name string) (*unstructured.Unstructured, error) {
+	return w.dynamicClient.Resource(kubernetesutil.NetworkingV1IngressClassResource).Get(name, metav1.GetOptions{})
+}
@@ -0,0 +1,37 @@
+package admission
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/mesosphere/dklb/pkg/constants"
+	"github.com/mesosphere/dklb/pkg/translator"
+)
+
+// startEdgeLBPoolMigration requests for the specified resource to be migrated from the previous target EdgeLB pool to the current one whenever the name of the target EdgeLB pool changes.
+// Changes to the name of the target EdgeLB pool are rejected while a migration is in progress, unless they roll the resource back to the EdgeLB pool it is being migrated from.
+func startEdgeLBPoolMigration(mutatedObj, previousObj metav1.Object, previousOptions, currentOptions *translator.BaseTranslationOptions) error {
+	// The name of the EdgeLB pool used with a cloud load-balancer is controlled by ourselves, so there is nothing to migrate.
+	if previousOptions.CloudLoadBalancerConfigMapName != nil || currentOptions.CloudLoadBalancerConfigMapName != nil {
+		return nil
+	}
+	// If the name of the target EdgeLB pool hasn't changed, there is nothing to do.
+	if previousOptions.EdgeLBPoolName == currentOptions.EdgeLBPoolName {
+		return nil
+	}
+	// Check whether a migration is already in progress.
+	previousMigration, err := translator.GetEdgeLBPoolMigrationStatus(previousObj)
+	if err != nil {
+		return err
+	}
+	if previousMigration != nil && currentOptions.EdgeLBPoolName != previousMigration.SourcePool {
+		return fmt.Errorf("the name of the target edgelb pool cannot be changed while a migration from edgelb pool %q is in progress", previousMigration.SourcePool)
+	}
+	translator.SetEdgeLBPoolMigrationStatus(mutatedObj, &translator.EdgeLBPoolMigrationStatus{
+		Phase:      constants.EdgeLBPoolMigrationPhasePending,
+		SourcePool: previousOptions.EdgeLBPoolName,
+		TargetPool: currentOptions.EdgeLBPoolName,
+	})
+	return nil
+}
@@ -14,6 +14,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/mesosphere/dklb/pkg/constants"
+	kubernetesutil "github.com/mesosphere/dklb/pkg/util/kubernetes"
 )
 
 const (
@@ -87,6 +88,23 @@ func RegisterWebhook(kubeClient kubernetes.Interface, admissionTLSCaBundle, admi
 							},
 						},
 					},
+					{
+						Operations: []admissionregistrationv1beta1.OperationType{
+							admissionregistrationv1beta1.Create,
+							admissionregistrationv1beta1.Update,
+						},
+						Rule: admissionregistrationv1beta1.Rule{
+							APIGroups: []string{
+								kubernetesutil.NetworkingV1IngressResource.Group,
+							},
+							APIVersions: []string{
+								kubernetesutil.NetworkingV1IngressResource.Version,
+							},
+							Resources: []string{
+								kubernetesutil.NetworkingV1IngressResource.Resource,
+							},
+						},
+					},
 				},
 				ClientConfig: admissionregistrationv1beta1.WebhookClientConfig{
 					Service: &admissionregistrationv1beta1.ServiceReference{
@@ -36,6 +36,10 @@ func (w *Webhook) validateAndMutateService(currentSvc, previousSvc *corev1.Servi
 		if err := translator.ValidateServiceTranslationOptionsUpdate(previousOptions, currentOptions); err != nil {
 			return nil, err
 		}
+		// Request for the resource to be migrated in case the name of the target EdgeLB pool has changed.
+		if err := startEdgeLBPoolMigration(mutatedSvc, previousSvc, &previousOptions.BaseTranslationOptions, &currentOptions.BaseTranslationOptions); err != nil {
+			return nil, err
+		}
 	}
 
 	// At this point we know that the current "Service" resource is valid.
@@ -18,6 +18,9 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/client-go/dynamic"
+
+	kubernetesutil "github.com/mesosphere/dklb/pkg/util/kubernetes"
 )
 
 const (
@@ -30,10 +33,12 @@ const (
 var (
 	// admissionPath is the path where the admission endpoint is served.
 	admissionPath = "/admissionrequests"
-	// ingressGvk is the "GroupVersionKind" that corresponds to "Ingress" resources.
+	// ingressGvk is the "GroupVersionKind" that corresponds to "extensions/v1beta1" Ingress resources.
 	ingressGvk = &schema.GroupVersionKind{Group: "extensions", Version: "v1beta1", Kind: "Ingress"}
-	// ingressGvr is the "GroupVersionResource" that corresponds to "Ingress" resources.
+	// ingressGvr is the "GroupVersionResource" that corresponds to "extensions/v1beta1" Ingress resources.
 	ingressGvr = metav1.GroupVersionResource{Group: "extensions", Version: "v1beta1", Resource: "ingresses"}
+	// networkingV1IngressGvr is the "GroupVersionResource" that corresponds to "networking.k8s.io/v1" Ingress resources.
+	networkingV1IngressGvr = metav1.GroupVersionResource{Group: kubernetesutil.NetworkingV1IngressResource.Group, Version: kubernetesutil.NetworkingV1IngressResource.Version, Resource: kubernetesutil.NetworkingV1IngressResource.Resource}
 	// patchType is the type of patch sent in admission responses.
 	patchType = admissionv1beta1.PatchTypeJSONPatch
 	// serviceGvk is the "GroupVersionKind" that corresponds to "Service" resources.
@@ -48,12 +53,14 @@ type Webhook struct {
 	codecs serializer.CodecFactory
 	// clusterName is the name of the Mesos framework that corresponds to the current Kubernetes cluster.
 	clusterName string
+	// dynamicClient is a client to the Kubernetes API used to read the IngressClass resources referenced by "networking.k8s.io/v1" Ingress resources.
+	dynamicClient dynamic.Interface
 	// tlsCertificate is the TLS certificate to use for the server.
 	tlsCertificate tls.Certificate
 }
 
 // NewWebhook creates a new instance of the admission webhook.
-func NewWebhook(clusterName string, tlsCertificate tls.Certificate) *Webhook {
+func NewWebhook(clusterName string, dynamicClient dynamic.Interface, tlsCertificate tls.Certificate) *Webhook {
 	// Create a new scheme and register the "Ingress" and "Service" types so we can serialize/deserialize them.
 	scheme := runtime.NewScheme()
 	scheme.AddKnownTypes(extsv1beta1.SchemeGroupVersion, &extsv1beta1.Ingress{})
@@ -61,6 +68,7 @@ func NewWebhook(clusterName string, tlsCertificate tls.Certificate) *Webhook {
 	return &Webhook{
 		codecs:         serializer.NewCodecFactory(scheme),
 		clusterName:    clusterName,
+		dynamicClient:  dynamicClient,
 		tlsCertificate: tlsCertificate,
 	}
 }
@@ -166,6 +174,10 @@ func (w *Webhook) validateAndMutateResource(rev admissionv1beta1.AdmissionReview
 	case ingressGvr:
 		// We're dealing with an "Ingress" resource.
 		currentGVK = ingressGvk
+	case networkingV1IngressGvr:
+		// We're dealing with a "networking.k8s.io/v1" Ingress resource.
+		// These are not supported by the Kubernetes client libraries we depend on, and are hence handled separately.
+		return w.validateAndMutateNetworkingV1Ingress(rev)
 	case serviceGvr:
 		// We're dealing with a "Service" resource.
 		currentGVK = serviceGvk
@@ -0,0 +1,300 @@
+package autoscaler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+
+	dklbcache "github.com/mesosphere/dklb/pkg/cache"
+	"github.com/mesosphere/dklb/pkg/constants"
+	"github.com/mesosphere/dklb/pkg/edgelb/manager"
+	dklberrors "github.com/mesosphere/dklb/pkg/errors"
+	"github.com/mesosphere/dklb/pkg/translator"
+	kubernetesutil "github.com/mesosphere/dklb/pkg/util/kubernetes"
+	"github.com/mesosphere/dklb/pkg/util/pointers"
+)
+
+const (
+	// autoscalerName is the name of the EdgeLB pool autoscaler.
+	autoscalerName = "edgelb-pool-autoscaler"
+	// defaultEdgeLBManagerTimeout is the default timeout used when interacting with the EdgeLB manager.
+	defaultEdgeLBManagerTimeout = 10 * time.Second
+	// defaultStatsScrapeTimeout is the default timeout used when reading HAProxy statistics from an instance of an EdgeLB pool.
+	defaultStatsScrapeTimeout = 5 * time.Second
+)
+
+// EdgeLBPoolAutoscalerOptions groups options that can be used to configure an instance of the EdgeLB pool autoscaler.
+type EdgeLBPoolAutoscalerOptions struct {
+	// Interval is the interval at which the load of EdgeLB pools for which autoscaling is enabled is checked.
+	Interval time.Duration
+	// ScaleDownCooldown is the minimum amount of time that must elapse after an EdgeLB pool has been scaled before it can be scaled down.
+	ScaleDownCooldown time.Duration
+	// ScaleUpCooldown is the minimum amount of time that must elapse after an EdgeLB pool has been scaled before it can be scaled up.
+	ScaleUpCooldown time.Duration
+}
+
+// EdgeLBPoolAutoscaler periodically adjusts the size of EdgeLB pools for which autoscaling has been requested based on the number of connections they are handling.
+type EdgeLBPoolAutoscaler struct {
+	// clusterName is the name of the Mesos framework that corresponds to the current Kubernetes cluster.
+	clusterName string
+	// kubeCache is the instance of the Kubernetes resource cache to use.
+	kubeCache dklbcache.KubernetesResourceCache
+	// edgelbManager is the instance of the EdgeLB manager to use for reading and updating EdgeLB pools.
+	edgelbManager manager.EdgeLBManager
+	// eventRecorderForNamespace returns an event recorder that can be used to emit Kubernetes events for objects in the specified namespace.
+	eventRecorderForNamespace func(namespace string) record.EventRecorder
+	// scraper is used to read the number of current connections handled by each instance of an EdgeLB pool.
+	scraper statsScraper
+	// options is the set of options used to configure the autoscaler.
+	options EdgeLBPoolAutoscalerOptions
+	// lastScaleTimes holds the time at which each EdgeLB pool was last scaled, and is used to enforce cooldowns.
+	// It is only accessed from the goroutine running the autoscaler, so it doesn't need to be protected by a lock.
+	lastScaleTimes map[string]time.Time
+	// now returns the current time.
+	now func() time.Time
+	// logger is the logger that the autoscaler will use.
+	logger log.FieldLogger
+}
+
+// autoscalingOwner represents a Service/Ingress resource requesting autoscaling of the EdgeLB pool it targets.
+type autoscalingOwner struct {
+	// obj is the Service/Ingress resource.
+	obj runtime.Object
+	// namespace is the namespace of the Service/Ingress resource.
+	namespace string
+}
+
+// autoscalingRequest groups together the autoscaling configuration requested for a given EdgeLB pool and the Service/Ingress resources requesting it.
+type autoscalingRequest struct {
+	// options are the translation options holding the autoscaling configuration requested for the EdgeLB pool.
+	options translator.BaseTranslationOptions
+	// owners is the list of Service/Ingress resources requesting autoscaling of the EdgeLB pool.
+	owners []autoscalingOwner
+	// conflicting indicates whether the Service/Ingress resources targeting the EdgeLB pool request different autoscaling configurations.
+	conflicting bool
+}
+
+// NewEdgeLBPoolAutoscaler creates a new instance of the EdgeLB pool autoscaler.
+func NewEdgeLBPoolAutoscaler(clusterName string, kubeClient kubernetes.Interface, kubeCache dklbcache.KubernetesResourceCache, edgelbManager manager.EdgeLBManager, options EdgeLBPoolAutoscalerOptions) *EdgeLBPoolAutoscaler {
+	return &EdgeLBPoolAutoscaler{
+		clusterName:   clusterName,
+		kubeCache:     kubeCache,
+		edgelbManager: edgelbManager,
+		eventRecorderForNamespace: func(namespace string) record.EventRecorder {
+			return kubernetesutil.NewEventRecorderForNamespace(kubeClient, namespace)
+		},
+		scraper:        newHAProxyStatsScraper(),
+		options:        options,
+		lastScaleTimes: make(map[string]time.Time),
+		now:            time.Now,
+		logger:         log.WithField("controller", autoscalerName),
+	}
+}
+
+// Run starts the autoscaler, blocking until the specified context is canceled.
+func (a *EdgeLBPoolAutoscaler) Run(ctx context.Context) error {
+	a.logger.Debugf("starting %q", autoscalerName)
+
+	// Wait for the cache to be synced before listing Service/Ingress resources.
+	a.logger.Debug("waiting for informer caches to be synced")
+	if ok := cache.WaitForCacheSync(ctx.Done(), a.kubeCache.HasSynced); !ok {
+		return fmt.Errorf("failed to wait for informer caches to be synced")
+	}
+
+	a.logger.Info("started autoscaler")
+
+	// Check the load of EdgeLB pools periodically until the context is canceled.
+	wait.Until(a.autoscale, a.options.Interval, ctx.Done())
+	return nil
+}
+
+// autoscale adjusts the size of every EdgeLB pool for which autoscaling has been requested.
+func (a *EdgeLBPoolAutoscaler) autoscale() {
+	requests, err := a.computeAutoscalingRequests()
+	if err != nil {
+		a.logger.Errorf("failed to compute the set of edgelb pools to autoscale: %v", err)
+		return
+	}
+	// Iterate over EdgeLB pools in a predictable order.
+	poolNames := make([]string, 0, len(requests))
+	for poolName := range requests {
+		poolNames = append(poolNames, poolName)
+	}
+	sort.Strings(poolNames)
+	for _, poolName := range poolNames {
+		if err := a.autoscalePool(poolName, requests[poolName]); err != nil {
+			a.logger.Errorf("failed to autoscale edgelb pool %q: %v", poolName, err)
+		}
+	}
+}
+
+// computeAutoscalingRequests computes the autoscaling configuration requested for each EdgeLB pool, indexed by the name of the EdgeLB pool.
+// Service/Ingress resources with invalid annotations or for which translation is paused are ignored.
+func (a *EdgeLBPoolAutoscaler) computeAutoscalingRequests() (map[string]*autoscalingRequest, error) {
+	res := make(map[string]*autoscalingRequest)
+	// add registers the specified Service/Ingress resource as requesting the specified autoscaling configuration.
+	add := func(obj runtime.Object, namespace string, options translator.BaseTranslationOptions) {
+		if !options.IsEdgeLBPoolAutoscalingEnabled() || options.EdgeLBPoolTranslationPaused {
+			return
+		}
+		req, exists := res[options.EdgeLBPoolName]
+		if !exists {
+			req = &autoscalingRequest{
+				options: options,
+			}
+			res[options.EdgeLBPoolName] = req
+		}
+		if options.EdgeLBPoolMinSize != req.options.EdgeLBPoolMinSize ||
+			options.EdgeLBPoolMaxSize != req.options.EdgeLBPoolMaxSize ||
+			options.EdgeLBPoolTargetConnectionsPerInstance != req.options.EdgeLBPoolTargetConnectionsPerInstance {
+			req.conflicting = true
+		}
+		req.owners = append(req.owners, autoscalingOwner{
+			obj:       obj,
+			namespace: namespace,
+		})
+	}
+	services, err := a.kubeCache.GetServices(metav1.NamespaceAll)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %v", err)
+	}
+	for _, service := range services {
+		if service.Spec.Type != corev1.ServiceTypeLoadBalancer {
+			continue
+		}
+		if options, err := translator.ComputeServiceTranslationOptions(a.clusterName, service); err == nil {
+			add(service, service.Namespace, options.BaseTranslationOptions)
+		}
+	}
+	ingresses, err := a.kubeCache.GetIngresses(metav1.NamespaceAll)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ingresses: %v", err)
+	}
+	for _, ingress := range ingresses {
+		if !kubernetesutil.IsEdgeLBIngress(ingress) {
+			continue
+		}
+		if options, err := translator.ComputeIngressTranslationOptions(a.clusterName, ingress); err == nil {
+			add(ingress, ingress.Namespace, options.BaseTranslationOptions)
+		}
+	}
+	return res, nil
+}
+
+// autoscalePool adjusts the size of the specified EdgeLB pool based on the number of connections its instances are currently handling.
+func (a *EdgeLBPoolAutoscaler) autoscalePool(poolName string, req *autoscalingRequest) error {
+	// Refuse to autoscale the EdgeLB pool in case the Service/Ingress resources targeting it disagree on the autoscaling configuration, as they would otherwise keep overriding each other's changes.
+	if req.conflicting {
+		return fmt.Errorf("the resources targeting the edgelb pool request different autoscaling configurations")
+	}
+
+	// Read the EdgeLB pool from the EdgeLB API server.
+	ctx, fn := context.WithTimeout(context.Background(), defaultEdgeLBManagerTimeout)
+	defer fn()
+	pool, err := a.edgelbManager.GetPool(ctx, poolName)
+	if err != nil {
+		// The EdgeLB pool may not have been created yet, in which case there's nothing to do.
+		if dklberrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read edgelb pool: %v", err)
+	}
+	if pool.Count == nil {
+		return nil
+	}
+	currentSize := int(*pool.Count)
+
+	// Compute the total number of connections currently handled by the instances of the EdgeLB pool.
+	connections, err := a.computeCurrentConnections(poolName)
+	if err != nil {
+		return err
+	}
+
+	// Compute the desired size of the EdgeLB pool, and check whether it can be scaled at this time.
+	desiredSize := computeDesiredEdgeLBPoolSize(connections, req.options)
+	if desiredSize == currentSize {
+		return nil
+	}
+	now := a.now()
+	cooldown := a.options.ScaleUpCooldown
+	if desiredSize < currentSize {
+		cooldown = a.options.ScaleDownCooldown
+	}
+	if lastScaleTime, exists := a.lastScaleTimes[poolName]; exists && now.Sub(lastScaleTime) < cooldown {
+		a.logger.Debugf("not scaling edgelb pool %q from %d to %d instances as it was last scaled at %s", poolName, currentSize, desiredSize, lastScaleTime)
+		return nil
+	}
+
+	// Update the size of the EdgeLB pool.
+	pool.Count = pointers.NewInt32(int32(desiredSize))
+	uctx, ufn := context.WithTimeout(context.Background(), defaultEdgeLBManagerTimeout)
+	defer ufn()
+	if _, err := a.edgelbManager.UpdatePool(uctx, pool); err != nil {
+		return fmt.Errorf("failed to update edgelb pool: %v", err)
+	}
+	a.lastScaleTimes[poolName] = now
+
+	// Report the scaling decision on every Service/Ingress resource targeting the EdgeLB pool.
+	// When dry-run mode is enabled the EdgeLB pool isn't actually scaled, and the message must say so.
+	verb := "scaled"
+	if manager.IsDryRun(a.edgelbManager) {
+		verb = "would have scaled"
+	}
+	msg := fmt.Sprintf("%s edgelb pool %q from %d to %d instances (%d current connections, target of %d connections per instance)", verb, poolName, currentSize, desiredSize, connections, req.options.EdgeLBPoolTargetConnectionsPerInstance)
+	a.logger.Info(msg)
+	for _, owner := range req.owners {
+		a.eventRecorderForNamespace(owner.namespace).Eventf(owner.obj, corev1.EventTypeNormal, constants.ReasonEdgeLBPoolScaled, msg)
+	}
+	return nil
+}
+
+// computeCurrentConnections computes the total number of connections currently handled by the instances of the specified EdgeLB pool.
+// Instances are discovered using the metadata of the EdgeLB pool, and an error is returned in case the statistics of any instance cannot be read, as scaling based on partial data could cause the EdgeLB pool to be scaled down while under load.
+func (a *EdgeLBPoolAutoscaler) computeCurrentConnections(poolName string) (int, error) {
+	ctx, fn := context.WithTimeout(context.Background(), defaultEdgeLBManagerTimeout)
+	defer fn()
+	m, err := a.edgelbManager.GetPoolMetadata(ctx, poolName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read edgelb pool metadata: %v", err)
+	}
+	// Compute the set of IPs at which the instances of the EdgeLB pool can be reached.
+	// Each instance is reported once per frontend, so duplicates must be removed.
+	ips := make(map[string]bool)
+	for _, frontend := range m.Frontends {
+		for _, endpoint := range frontend.Endpoints {
+			if len(endpoint.Private) > 0 {
+				ips[endpoint.Private[0]] = true
+			}
+		}
+	}
+	if len(ips) == 0 {
+		return 0, fmt.Errorf("no instances have been reported")
+	}
+	res := 0
+	for ip := range ips {
+		sctx, sfn := context.WithTimeout(context.Background(), defaultStatsScrapeTimeout)
+		c, err := a.scraper.ScrapeCurrentConnections(sctx, ip)
+		sfn()
+		if err != nil {
+			return 0, fmt.Errorf("failed to read haproxy statistics from instance at %q: %v", ip, err)
+		}
+		res += c
+	}
+	return res, nil
+}
+
+// computeDesiredEdgeLBPoolSize computes the size of an EdgeLB pool handling the specified number of connections so that each instance handles at most the target number of connections, within the bounds specified in the translation options.
+func computeDesiredEdgeLBPoolSize(connections int, options translator.BaseTranslationOptions) int {
+	target := options.EdgeLBPoolTargetConnectionsPerInstance
+	return translator.ClampEdgeLBPoolSize((connections+target-1)/target, options)
+}
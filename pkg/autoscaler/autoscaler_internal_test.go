@@ -0,0 +1,275 @@
+package autoscaler
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mesosphere/dcos-edge-lb/models"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"k8s.io/client-go/tools/record"
+
+	dklberrors "github.com/mesosphere/dklb/pkg/errors"
+	"github.com/mesosphere/dklb/pkg/translator"
+	"github.com/mesosphere/dklb/pkg/util/pointers"
+	edgelbmanagertestutil "github.com/mesosphere/dklb/test/util/edgelb/manager"
+	edgelbpooltestutil "github.com/mesosphere/dklb/test/util/edgelb/pool"
+	servicetestutil "github.com/mesosphere/dklb/test/util/kubernetes/service"
+)
+
+// fakeStatsScraper is a fake implementation of "statsScraper" that returns a fixed number of connections for each IP.
+type fakeStatsScraper map[string]int
+
+// ScrapeCurrentConnections returns the number of connections configured for the specified IP, or an error in case no value has been configured.
+func (s fakeStatsScraper) ScrapeCurrentConnections(ctx context.Context, ip string) (int, error) {
+	if v, exists := s[ip]; exists {
+		return v, nil
+	}
+	return 0, errors.New("connection refused")
+}
+
+// TestParseHAProxyStatsCurrentConnections tests the "parseHAProxyStatsCurrentConnections" function.
+func TestParseHAProxyStatsCurrentConnections(t *testing.T) {
+	tests := []struct {
+		description         string
+		stats               string
+		expectedConnections int
+		expectedError       bool
+	}{
+		{
+			description: "statistics for multiple frontends and backends",
+			stats: strings.Join([]string{
+				"# pxname,svname,qcur,qmax,scur,smax,slim,",
+				"stats,FRONTEND,,,3,5,2000,",
+				"dev.kubernetes01:foo:bar:80,FRONTEND,,,12,40,2000,",
+				"dev.kubernetes01:foo:bar:80,BACKEND,0,0,12,40,200,",
+				"dev.kubernetes01:foo:baz:443,FRONTEND,,,30,41,2000,",
+				"dev.kubernetes01:foo:baz:443,server-1,0,0,30,41,,",
+			}, "\n"),
+			expectedConnections: 42,
+		},
+		{
+			description:         "statistics without any frontend",
+			stats:               "# pxname,svname,qcur,qmax,scur,smax,slim,\n",
+			expectedConnections: 0,
+		},
+		{
+			description:   "statistics missing the number of current sessions",
+			stats:         "# pxname,svname,qcur,qmax\nfoo,FRONTEND,,\n",
+			expectedError: true,
+		},
+		{
+			description:   "statistics with a malformed number of current sessions",
+			stats:         "# pxname,svname,qcur,qmax,scur\nfoo,FRONTEND,,,bar\n",
+			expectedError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		c, err := parseHAProxyStatsCurrentConnections(strings.NewReader(test.stats))
+		if test.expectedError {
+			assert.Error(t, err)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, test.expectedConnections, c)
+	}
+}
+
+// TestComputeDesiredEdgeLBPoolSize tests the "computeDesiredEdgeLBPoolSize" function.
+func TestComputeDesiredEdgeLBPoolSize(t *testing.T) {
+	options := translator.BaseTranslationOptions{
+		EdgeLBPoolMinSize:                      2,
+		EdgeLBPoolMaxSize:                      5,
+		EdgeLBPoolTargetConnectionsPerInstance: 100,
+	}
+	tests := []struct {
+		description  string
+		connections  int
+		expectedSize int
+	}{
+		{
+			description:  "no connections",
+			connections:  0,
+			expectedSize: 2,
+		},
+		{
+			description:  "connections requiring a partially loaded instance",
+			connections:  301,
+			expectedSize: 4,
+		},
+		{
+			description:  "connections exactly matching the target",
+			connections:  300,
+			expectedSize: 3,
+		},
+		{
+			description:  "connections exceeding the maximum size",
+			connections:  10000,
+			expectedSize: 5,
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		assert.Equal(t, test.expectedSize, computeDesiredEdgeLBPoolSize(test.connections, options))
+	}
+}
+
+// TestAutoscalePool tests the "autoscalePool" function.
+func TestAutoscalePool(t *testing.T) {
+	var (
+		now     = time.Date(2019, 5, 1, 10, 0, 0, 0, time.UTC)
+		options = translator.BaseTranslationOptions{
+			EdgeLBPoolName:                         "foo",
+			EdgeLBPoolMinSize:                      1,
+			EdgeLBPoolMaxSize:                      4,
+			EdgeLBPoolTargetConnectionsPerInstance: 100,
+		}
+		metadata = &models.V2PoolMetadata{
+			Name: "foo",
+			Frontends: []*models.V2PoolMetadataFrontend{
+				{
+					Endpoints: []*models.V2PoolMetadataFrontendEndpoint{
+						{Private: []string{"10.0.0.1"}},
+						{Private: []string{"10.0.0.2"}},
+					},
+				},
+				{
+					Endpoints: []*models.V2PoolMetadataFrontendEndpoint{
+						{Private: []string{"10.0.0.1"}},
+						{Private: []string{"10.0.0.2"}},
+					},
+				},
+			},
+		}
+	)
+	tests := []struct {
+		description        string
+		currentSize        int32
+		poolNotFound       bool
+		connections        fakeStatsScraper
+		lastScaledAgo      time.Duration
+		conflicting        bool
+		expectedSize       int32
+		expectedUpdate     bool
+		expectedEventCount int
+		expectedError      bool
+	}{
+		{
+			description:        "pool under load",
+			currentSize:        2,
+			connections:        fakeStatsScraper{"10.0.0.1": 150, "10.0.0.2": 130},
+			expectedSize:       3,
+			expectedUpdate:     true,
+			expectedEventCount: 1,
+		},
+		{
+			description:  "pool whose load matches its size",
+			currentSize:  2,
+			connections:  fakeStatsScraper{"10.0.0.1": 100, "10.0.0.2": 90},
+			expectedSize: 2,
+		},
+		{
+			description:   "pool under load within the scale up cooldown",
+			currentSize:   2,
+			connections:   fakeStatsScraper{"10.0.0.1": 150, "10.0.0.2": 130},
+			lastScaledAgo: time.Minute,
+			expectedSize:  2,
+		},
+		{
+			description:   "idle pool within the scale down cooldown",
+			currentSize:   3,
+			connections:   fakeStatsScraper{"10.0.0.1": 0, "10.0.0.2": 0},
+			lastScaledAgo: 5 * time.Minute,
+			expectedSize:  3,
+		},
+		{
+			description:        "idle pool after the scale down cooldown",
+			currentSize:        3,
+			connections:        fakeStatsScraper{"10.0.0.1": 0, "10.0.0.2": 0},
+			lastScaledAgo:      15 * time.Minute,
+			expectedSize:       1,
+			expectedUpdate:     true,
+			expectedEventCount: 1,
+		},
+		{
+			description:   "pool with an instance whose statistics cannot be read",
+			currentSize:   2,
+			connections:   fakeStatsScraper{"10.0.0.1": 500},
+			expectedSize:  2,
+			expectedError: true,
+		},
+		{
+			description:  "pool that doesn't exist yet",
+			poolNotFound: true,
+		},
+		{
+			description:   "pool targeted by resources requesting different autoscaling configurations",
+			currentSize:   2,
+			conflicting:   true,
+			expectedSize:  2,
+			expectedError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		pool := edgelbpooltestutil.DummyEdgeLBPool("foo", func(p *models.V2Pool) {
+			p.Count = pointers.NewInt32(test.currentSize)
+		})
+		m := new(edgelbmanagertestutil.MockEdgeLBManager)
+		if test.poolNotFound {
+			m.On("GetPool", mock.Anything, "foo").Return(nil, dklberrors.NotFound(errors.New("not found")))
+		} else {
+			m.On("GetPool", mock.Anything, "foo").Return(pool, nil)
+		}
+		m.On("GetPoolMetadata", mock.Anything, "foo").Return(metadata, nil)
+		m.On("UpdatePool", mock.Anything).Return(pool, nil)
+		recorder := record.NewFakeRecorder(test.expectedEventCount + 1)
+		a := &EdgeLBPoolAutoscaler{
+			edgelbManager: m,
+			eventRecorderForNamespace: func(namespace string) record.EventRecorder {
+				return recorder
+			},
+			scraper: test.connections,
+			options: EdgeLBPoolAutoscalerOptions{
+				ScaleDownCooldown: 10 * time.Minute,
+				ScaleUpCooldown:   3 * time.Minute,
+			},
+			lastScaleTimes: make(map[string]time.Time),
+			now: func() time.Time {
+				return now
+			},
+			logger: log.WithField("test", t.Name()),
+		}
+		// A value of zero means that the pool has never been scaled.
+		if test.lastScaledAgo != 0 {
+			a.lastScaleTimes["foo"] = now.Add(-test.lastScaledAgo)
+		}
+		err := a.autoscalePool("foo", &autoscalingRequest{
+			options: options,
+			owners: []autoscalingOwner{
+				{obj: servicetestutil.DummyServiceResource("bar", "baz"), namespace: "bar"},
+			},
+			conflicting: test.conflicting,
+		})
+		if test.expectedError {
+			assert.Error(t, err)
+		} else {
+			assert.NoError(t, err)
+		}
+		if test.expectedUpdate {
+			m.AssertCalled(t, "UpdatePool", mock.Anything)
+			assert.Equal(t, now, a.lastScaleTimes["foo"])
+		} else {
+			m.AssertNotCalled(t, "UpdatePool", mock.Anything)
+		}
+		if !test.poolNotFound {
+			assert.Equal(t, test.expectedSize, *pool.Count)
+		}
+		assert.Len(t, recorder.Events, test.expectedEventCount)
+	}
+}
@@ -0,0 +1,108 @@
+package autoscaler
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/mesosphere/dklb/pkg/constants"
+)
+
+const (
+	// haproxyStatsFrontendServerName is the value of the "svname" column used by HAProxy in rows that report statistics for a frontend as a whole.
+	haproxyStatsFrontendServerName = "FRONTEND"
+	// haproxyStatsProxyNameColumn is the name of the column holding the name of the proxy (i.e. frontend or backend) a row refers to.
+	haproxyStatsProxyNameColumn = "pxname"
+	// haproxyStatsServerNameColumn is the name of the column holding the name of the server a row refers to.
+	haproxyStatsServerNameColumn = "svname"
+	// haproxyStatsCurrentSessionsColumn is the name of the column holding the number of current sessions (i.e. connections).
+	haproxyStatsCurrentSessionsColumn = "scur"
+	// haproxyStatsProxyName is the name of the proxy used by EdgeLB to expose HAProxy statistics, and whose connections must not be accounted for.
+	haproxyStatsProxyName = "stats"
+)
+
+// statsScraper knows how to read the number of current connections handled by an instance of an EdgeLB pool.
+type statsScraper interface {
+	// ScrapeCurrentConnections returns the number of current connections handled by the instance of an EdgeLB pool running at the specified IP.
+	ScrapeCurrentConnections(ctx context.Context, ip string) (int, error)
+}
+
+// haproxyStatsScraper is the main implementation of "statsScraper", which reads the HAProxy statistics exposed by each instance of an EdgeLB pool.
+type haproxyStatsScraper struct {
+	// client is the HTTP client used to read HAProxy statistics.
+	client *http.Client
+}
+
+// newHAProxyStatsScraper returns a new instance of "haproxyStatsScraper".
+func newHAProxyStatsScraper() *haproxyStatsScraper {
+	return &haproxyStatsScraper{
+		client: &http.Client{},
+	}
+}
+
+// ScrapeCurrentConnections returns the number of current connections handled by the instance of an EdgeLB pool running at the specified IP.
+func (s *haproxyStatsScraper) ScrapeCurrentConnections(ctx context.Context, ip string) (int, error) {
+	u := fmt.Sprintf("http://%s%s", net.JoinHostPort(ip, strconv.Itoa(constants.EdgeLBPoolStatsPort)), constants.EdgeLBPoolStatsPath)
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return 0, err
+	}
+	res, err := s.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("unexpected status code %d when reading haproxy statistics from %q", res.StatusCode, u)
+	}
+	return parseHAProxyStatsCurrentConnections(res.Body)
+}
+
+// parseHAProxyStatsCurrentConnections parses the specified HAProxy statistics (in CSV format) and returns the total number of current connections across all frontends.
+// The frontend used by EdgeLB to expose HAProxy statistics is ignored.
+func parseHAProxyStatsCurrentConnections(r io.Reader) (int, error) {
+	cr := csv.NewReader(r)
+	// HAProxy may add new columns in future versions, so we don't enforce a fixed number of fields per row.
+	cr.FieldsPerRecord = -1
+	// The first row is the header, whose first column is prefixed by "# ".
+	header, err := cr.Read()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the header of the haproxy statistics: %v", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimPrefix(strings.TrimSpace(name), "# ")] = i
+	}
+	for _, name := range []string{haproxyStatsProxyNameColumn, haproxyStatsServerNameColumn, haproxyStatsCurrentSessionsColumn} {
+		if _, exists := columns[name]; !exists {
+			return 0, fmt.Errorf("the haproxy statistics don't contain the %q column", name)
+		}
+	}
+	res := 0
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read the haproxy statistics: %v", err)
+		}
+		if len(row) <= columns[haproxyStatsCurrentSessionsColumn] {
+			continue
+		}
+		if row[columns[haproxyStatsServerNameColumn]] != haproxyStatsFrontendServerName || row[columns[haproxyStatsProxyNameColumn]] == haproxyStatsProxyName {
+			continue
+		}
+		v, err := strconv.Atoi(row[columns[haproxyStatsCurrentSessionsColumn]])
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse %q as the number of current sessions of frontend %q: %v", row[columns[haproxyStatsCurrentSessionsColumn]], row[columns[haproxyStatsProxyNameColumn]], err)
+		}
+		res += v
+	}
+	return res, nil
+}
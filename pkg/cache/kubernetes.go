@@ -1,12 +1,20 @@
 package cache
 
 import (
+	"fmt"
+
 	corev1 "k8s.io/api/core/v1"
 	extsv1beta1 "k8s.io/api/extensions/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/dynamicinformer"
 	kubeinformers "k8s.io/client-go/informers"
 	corev1informers "k8s.io/client-go/informers/core/v1"
 	extsv1beta1informers "k8s.io/client-go/informers/extensions/v1beta1"
+
+	kubernetesutil "github.com/mesosphere/dklb/pkg/util/kubernetes"
 )
 
 // kubernetesResourceCache knows how to list Kubernetes resources.
@@ -15,12 +23,22 @@ type KubernetesResourceCache interface {
 	HasSynced() bool
 	// GetConfigMap returns the ConfigMap resource with the specified namespace and name.
 	GetConfigMap(string, string) (*corev1.ConfigMap, error)
+	// GetEndpoints returns the Endpoints resource with the specified namespace and name.
+	GetEndpoints(string, string) (*corev1.Endpoints, error)
 	// GetIngress returns the Ingress resource with the specified namespace and name.
 	GetIngress(string, string) (*extsv1beta1.Ingress, error)
 	// GetIngresses returns a list of all Ingress resources in the specified namespace.
 	GetIngresses(string) ([]*extsv1beta1.Ingress, error)
+	// GetIngressClass returns the ("networking.k8s.io/v1") IngressClass resource with the specified name.
+	GetIngressClass(string) (*unstructured.Unstructured, error)
+	// GetNode returns the Node resource with the specified name.
+	GetNode(string) (*corev1.Node, error)
+	// GetNodes returns a list of all Node resources.
+	GetNodes() ([]*corev1.Node, error)
 	// GetService returns the Service resource with the specified namespace and name.
 	GetService(string, string) (*corev1.Service, error)
+	// GetSecret returns the Secret resource with the specified namespace and name.
+	GetSecret(string, string) (*corev1.Secret, error)
 	// GetServices returns a list of all Service resources in the specified namespace.
 	GetServices(string) ([]*corev1.Service, error)
 }
@@ -30,8 +48,21 @@ type KubernetesResourceCache interface {
 type kubernetesResourceCache struct {
 	// configMapInformer is an informer for ConfigMap resources.
 	configMapInformer corev1informers.ConfigMapInformer
-	// ingressInformer is an informer for Ingress resources.
+	// endpointsInformer is an informer for Endpoints resources.
+	endpointsInformer corev1informers.EndpointsInformer
+	// ingressInformer is an informer for ("extensions/v1beta1") Ingress resources.
+	// It is only used whenever the Kubernetes API doesn't serve "networking.k8s.io/v1" Ingress resources.
 	ingressInformer extsv1beta1informers.IngressInformer
+	// networkingV1IngressInformer is an informer for "networking.k8s.io/v1" Ingress resources.
+	// It is only used whenever the Kubernetes API serves "networking.k8s.io/v1" Ingress resources.
+	networkingV1IngressInformer kubeinformers.GenericInformer
+	// ingressClassInformer is an informer for "networking.k8s.io/v1" IngressClass resources.
+	// It is only used whenever the Kubernetes API serves "networking.k8s.io/v1" Ingress resources.
+	ingressClassInformer kubeinformers.GenericInformer
+	// nodeInformer is an informer for Node resources.
+	nodeInformer corev1informers.NodeInformer
+	// secretInformer is an informer for Secret resources.
+	secretInformer corev1informers.SecretInformer
 	// serviceInformer is an informer for Service resources.
 	serviceInformer corev1informers.ServiceInformer
 }
@@ -40,14 +71,39 @@ type kubernetesResourceCache struct {
 func NewKubernetesResourceCache(factory kubeinformers.SharedInformerFactory) KubernetesResourceCache {
 	return &kubernetesResourceCache{
 		configMapInformer: factory.Core().V1().ConfigMaps(),
+		endpointsInformer: factory.Core().V1().Endpoints(),
 		ingressInformer:   factory.Extensions().V1beta1().Ingresses(),
+		nodeInformer:      factory.Core().V1().Nodes(),
+		secretInformer:    factory.Core().V1().Secrets(),
 		serviceInformer:   factory.Core().V1().Services(),
 	}
 }
 
+// NewKubernetesResourceCacheWithNetworkingV1Ingresses returns a new instance of the Kubernetes resource cache that reads Ingress resources using the "networking.k8s.io/v1" API.
+// It must be used whenever the Kubernetes API serves "networking.k8s.io/v1" Ingress resources, as "extsv1beta1" Ingress resources may not be served at all.
+func NewKubernetesResourceCacheWithNetworkingV1Ingresses(factory kubeinformers.SharedInformerFactory, dynamicFactory dynamicinformer.DynamicSharedInformerFactory) KubernetesResourceCache {
+	return &kubernetesResourceCache{
+		configMapInformer:           factory.Core().V1().ConfigMaps(),
+		endpointsInformer:           factory.Core().V1().Endpoints(),
+		networkingV1IngressInformer: dynamicFactory.ForResource(kubernetesutil.NetworkingV1IngressResource),
+		ingressClassInformer:        dynamicFactory.ForResource(kubernetesutil.NetworkingV1IngressClassResource),
+		nodeInformer:                factory.Core().V1().Nodes(),
+		secretInformer:              factory.Core().V1().Secrets(),
+		serviceInformer:             factory.Core().V1().Services(),
+	}
+}
+
 // HasSynced returns a value indicating whether the cache is synced.
 func (c *kubernetesResourceCache) HasSynced() bool {
-	return c.configMapInformer.Informer().HasSynced() && c.ingressInformer.Informer().HasSynced() && c.serviceInformer.Informer().HasSynced()
+	return c.configMapInformer.Informer().HasSynced() && c.endpointsInformer.Informer().HasSynced() && c.ingressInformersHaveSynced() && c.nodeInformer.Informer().HasSynced() && c.secretInformer.Informer().HasSynced() && c.serviceInformer.Informer().HasSynced()
+}
+
+// ingressInformersHaveSynced returns a value indicating whether the informers for Ingress resources (and IngressClass resources, if applicable) have synced.
+func (c *kubernetesResourceCache) ingressInformersHaveSynced() bool {
+	if c.networkingV1IngressInformer != nil {
+		return c.networkingV1IngressInformer.Informer().HasSynced() && c.ingressClassInformer.Informer().HasSynced()
+	}
+	return c.ingressInformer.Informer().HasSynced()
 }
 
 // GetConfigMap returns the ConfigMap resource with the specified namespace and name.
@@ -55,14 +111,84 @@ func (c *kubernetesResourceCache) GetConfigMap(namespace, name string) (*corev1.
 	return c.configMapInformer.Lister().ConfigMaps(namespace).Get(name)
 }
 
+// GetEndpoints returns the Endpoints resource with the specified namespace and name.
+func (c *kubernetesResourceCache) GetEndpoints(namespace, name string) (*corev1.Endpoints, error) {
+	return c.endpointsInformer.Lister().Endpoints(namespace).Get(name)
+}
+
 // GetIngress returns the Ingress resource with the specified namespace and name.
+// "networking.k8s.io/v1" Ingress resources are converted into the representation used internally by dklb.
 func (c *kubernetesResourceCache) GetIngress(namespace, name string) (*extsv1beta1.Ingress, error) {
-	return c.ingressInformer.Lister().Ingresses(namespace).Get(name)
+	if c.networkingV1IngressInformer == nil {
+		return c.ingressInformer.Lister().Ingresses(namespace).Get(name)
+	}
+	obj, err := c.networkingV1IngressInformer.Lister().ByNamespace(namespace).Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return c.convertNetworkingV1Ingress(obj)
 }
 
 // GetIngresses returns a list of all Ingress resources in the specified namespace.
+// "networking.k8s.io/v1" Ingress resources are converted into the representation used internally by dklb.
 func (c *kubernetesResourceCache) GetIngresses(namespace string) ([]*extsv1beta1.Ingress, error) {
-	return c.ingressInformer.Lister().Ingresses(namespace).List(labels.Everything())
+	if c.networkingV1IngressInformer == nil {
+		return c.ingressInformer.Lister().Ingresses(namespace).List(labels.Everything())
+	}
+	objs, err := c.networkingV1IngressInformer.Lister().ByNamespace(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*extsv1beta1.Ingress, 0, len(objs))
+	for _, obj := range objs {
+		ingress, err := c.convertNetworkingV1Ingress(obj)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, ingress)
+	}
+	return res, nil
+}
+
+// GetIngressClass returns the ("networking.k8s.io/v1") IngressClass resource with the specified name.
+// A "not found" error is returned whenever the Kubernetes API doesn't serve "networking.k8s.io/v1" Ingress resources.
+func (c *kubernetesResourceCache) GetIngressClass(name string) (*unstructured.Unstructured, error) {
+	if c.ingressClassInformer == nil {
+		return nil, apierrors.NewNotFound(kubernetesutil.NetworkingV1IngressClassResource.GroupResource(), name)
+	}
+	obj, err := c.ingressClassInformer.Lister().Get(name)
+	if err != nil {
+		return nil, err
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object of type %T in the ingress class cache", obj)
+	}
+	return u, nil
+}
+
+// convertNetworkingV1Ingress converts the specified "networking.k8s.io/v1" Ingress resource (as read from the dynamic informer) into the representation used internally by dklb.
+func (c *kubernetesResourceCache) convertNetworkingV1Ingress(obj runtime.Object) (*extsv1beta1.Ingress, error) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object of type %T in the ingress cache", obj)
+	}
+	return kubernetesutil.ConvertNetworkingV1Ingress(u, c.GetIngressClass)
+}
+
+// GetNode returns the Node resource with the specified name.
+func (c *kubernetesResourceCache) GetNode(name string) (*corev1.Node, error) {
+	return c.nodeInformer.Lister().Get(name)
+}
+
+// GetNodes returns a list of all Node resources.
+func (c *kubernetesResourceCache) GetNodes() ([]*corev1.Node, error) {
+	return c.nodeInformer.Lister().List(labels.Everything())
+}
+
+// GetSecret returns the Secret resource with the specified namespace and name.
+func (c *kubernetesResourceCache) GetSecret(namespace, name string) (*corev1.Secret, error) {
+	return c.secretInformer.Lister().Secrets(namespace).Get(name)
 }
 
 // GetService returns the Service resource with the specified namespace and name.
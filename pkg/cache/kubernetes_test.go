@@ -35,6 +35,18 @@ var (
 			ServicePort: intstr.FromInt(80),
 		}
 	})
+	// dummySecret1 represents a dummy Secret resource.
+	dummySecret1 = &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "namespace-1",
+			Name:      "name-1",
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       []byte("foo"),
+			corev1.TLSPrivateKeyKey: []byte("bar"),
+		},
+	}
 	// dummyService1 represents a dummy Service resource.
 	dummyService1 = servicetestutil.DummyServiceResource("namespace-1", "name-1", func(service *corev1.Service) {
 		service.Spec.Ports = []corev1.ServicePort{
@@ -47,7 +59,7 @@ var (
 
 // TestHasSynced tests the "HasSynced" function.
 func TestHasSynced(t *testing.T) {
-	cache := dklbcache.NewKubernetesResourceCache(cachetestutil.NewFakeSharedInformerFactory(dummyConfigMap1, dummyIngress1, dummyService1))
+	cache := dklbcache.NewKubernetesResourceCache(cachetestutil.NewFakeSharedInformerFactory(dummyConfigMap1, dummyIngress1, dummySecret1, dummyService1))
 	// "fakeSharedInformerFactory" already waits for caches to be synced, so this should be trivially true.
 	assert.True(t, cache.HasSynced())
 }
@@ -124,6 +136,42 @@ func TestGetIngress(t *testing.T) {
 	}
 }
 
+// TestGetSecret tests the "GetSecret" function.
+func TestGetSecret(t *testing.T) {
+	cache := dklbcache.NewKubernetesResourceCache(cachetestutil.NewFakeSharedInformerFactory(dummySecret1))
+	tests := []struct {
+		description    string
+		namespace      string
+		name           string
+		expectedResult *corev1.Secret
+		expectedError  error
+	}{
+		{
+			description:    "get an existing secret resource",
+			namespace:      dummySecret1.Namespace,
+			name:           dummySecret1.Name,
+			expectedResult: dummySecret1,
+			expectedError:  nil,
+		},
+		{
+			description:    "get an inexistent secret resource",
+			namespace:      "foo",
+			name:           "bar",
+			expectedResult: nil,
+			expectedError:  kubeerrors.NewNotFound(schema.GroupResource{Group: "", Resource: "secret"}, "bar"),
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		res, err := cache.GetSecret(test.namespace, test.name)
+		if test.expectedError != nil {
+			assert.Equal(t, test.expectedError, err)
+		} else {
+			assert.Equal(t, test.expectedResult, res)
+		}
+	}
+}
+
 // TestGetService tests the "GetService" function.
 func TestGetService(t *testing.T) {
 	cache := dklbcache.NewKubernetesResourceCache(cachetestutil.NewFakeSharedInformerFactory(dummyService1))
@@ -12,6 +12,52 @@ const (
 	EdgeLBPoolCreationStrategyOnce = EdgeLBPoolCreationStrategy("Once")
 )
 
+// EdgeLBPoolDriftPolicy represents the way in which changes made out-of-band (e.g. using the EdgeLB CLI) to the EdgeLB objects owned by an Ingress/Service resource are handled.
+type EdgeLBPoolDriftPolicy string
+
+const (
+	// EdgeLBPoolDriftPolicyCorrect denotes that changes made out-of-band are reported and overwritten with the state computed for the Ingress/Service resource.
+	EdgeLBPoolDriftPolicyCorrect = EdgeLBPoolDriftPolicy("Correct")
+	// EdgeLBPoolDriftPolicyReport denotes that changes made out-of-band are reported but left untouched.
+	EdgeLBPoolDriftPolicyReport = EdgeLBPoolDriftPolicy("Report")
+)
+
+// EdgeLBPathMatchType represents the way in which the paths defined in an Ingress resource are matched against the paths of incoming requests.
+type EdgeLBPathMatchType string
+
+const (
+	// EdgeLBPathMatchTypeExact denotes that paths are matched exactly.
+	EdgeLBPathMatchTypeExact = EdgeLBPathMatchType("Exact")
+	// EdgeLBPathMatchTypePrefix denotes that paths are matched as (element-wise) prefixes.
+	EdgeLBPathMatchTypePrefix = EdgeLBPathMatchType("Prefix")
+	// EdgeLBPathMatchTypeRegex denotes that paths are regular expressions following the egrep (IEEE Std 1003.1) syntax.
+	EdgeLBPathMatchTypeRegex = EdgeLBPathMatchType("Regex")
+)
+
+// EdgeLBBackendTarget represents the kind of servers targeted by the EdgeLB backends corresponding to an Ingress/Service resource.
+type EdgeLBBackendTarget string
+
+const (
+	// EdgeLBBackendTargetNodePort denotes that EdgeLB backends target the node port of the Service resource on every Kubernetes node.
+	EdgeLBBackendTargetNodePort = EdgeLBBackendTarget("NodePort")
+	// EdgeLBBackendTargetPodIP denotes that EdgeLB backends target the IPs of the pods backing the Service resource directly, as reported by the corresponding Endpoints resource.
+	// This requires the target EdgeLB pool to join the DC/OS virtual network used by the pods.
+	EdgeLBBackendTargetPodIP = EdgeLBBackendTarget("PodIP")
+)
+
+// EdgeLBPoolMigrationPhase represents a phase of the migration of an Ingress/Service resource from one EdgeLB pool to another.
+type EdgeLBPoolMigrationPhase string
+
+const (
+	// EdgeLBPoolMigrationPhasePending denotes that the migration has been requested (i.e. the name of the target EdgeLB pool has changed) but hasn't been started yet.
+	EdgeLBPoolMigrationPhasePending = EdgeLBPoolMigrationPhase("Pending")
+	// EdgeLBPoolMigrationPhaseProvisioning denotes that the resource has been translated into the new EdgeLB pool, and that dklb is waiting for said EdgeLB pool to report its endpoints.
+	// While in this phase, the status of the resource keeps reporting the addresses of the old EdgeLB pool.
+	EdgeLBPoolMigrationPhaseProvisioning = EdgeLBPoolMigrationPhase("Provisioning")
+	// EdgeLBPoolMigrationPhaseDraining denotes that the status of the resource has been switched to the addresses of the new EdgeLB pool, and that the old EdgeLB pool keeps serving traffic until the grace period expires.
+	EdgeLBPoolMigrationPhaseDraining = EdgeLBPoolMigrationPhase("Draining")
+)
+
 const (
 	// annotationKeyPrefix is the prefix used by annotations that belong to the MKE domain.
 	annotationKeyPrefix = "kubernetes.dcos.io/"
@@ -24,11 +70,23 @@ const (
 	// EdgeLBIngressClassAnnotationValue is the value that must be used for the annotation that selects the ingress controller used to satisfy a given Ingress resource.
 	// Only Ingres resources having this as the value of the aforementioned annotation will be provisioned using EdgeLB.
 	EdgeLBIngressClassAnnotationValue = "edgelb"
+	// EdgeLBIngressClassController is the value that must be used as the ".spec.controller" field of IngressClass resources that select dklb as the ingress controller.
+	// Only "networking.k8s.io/v1" Ingress resources whose ".spec.ingressClassName" field references such an IngressClass resource will be provisioned using EdgeLB.
+	EdgeLBIngressClassController = annotationKeyPrefix + ComponentName
+
+	// IngressClassControllerAnnotationKey is the key of the annotation that holds the controller of the IngressClass resource referenced in the ".spec.ingressClassName" field of a "networking.k8s.io/v1" Ingress resource.
+	// It is only ever set by dklb on its in-memory representation of such Ingress resources, and is never persisted.
+	IngressClassControllerAnnotationKey = annotationKeyPrefix + "ingress-class-controller"
+	// IngressPathTypesAnnotationKey is the key of the annotation that holds a JSON description of the ".pathType" field of the paths defined in a "networking.k8s.io/v1" Ingress resource.
+	// It is only ever set by dklb on its in-memory representation of such Ingress resources, and is never persisted.
+	IngressPathTypesAnnotationKey = annotationKeyPrefix + "ingress-path-types"
 
 	// CloudLoadBalancerConfigMapNameAnnotationKey is the key of the annotation that holds the name of the configmap to be used for configuring a cloud load-balancer on the target EdgeLB pool.
 	CloudLoadBalancerConfigMapNameAnnotationKey = annotationKeyPrefix + "cloud-loadbalancer-configmap"
 	// EdgeLBPoolCreationStrategyAnnotationKey is the key of the annotation that holds the strategy to use for provisioning the target EdgeLB pool.
 	EdgeLBPoolCreationStrategyAnnotationKey = annotationKeyPrefix + "edgelb-pool-creation-strategy"
+	// EdgeLBPoolDriftPolicyAnnotationKey is the key of the annotation that holds the way in which changes made out-of-band to the EdgeLB objects owned by a given Ingress/Service resource are handled.
+	EdgeLBPoolDriftPolicyAnnotationKey = annotationKeyPrefix + "edgelb-pool-drift-policy"
 	// EdgeLBPoolCpusAnnotationKey is the key of the annotation that holds the CPU request for the target EdgeLB pool.
 	EdgeLBPoolCpusAnnotationKey = annotationKeyPrefix + "edgelb-pool-cpus"
 	// EdgeLBPoolMemAnnotationKey is the key of the annotation that holds the memory request for the target EdgeLB pool.
@@ -41,14 +99,129 @@ const (
 	EdgeLBPoolRoleAnnotationKey = annotationKeyPrefix + "edgelb-pool-role"
 	// EdgeLBPoolSizeAnnotationKey is the key of the annotation that holds the size to request for the target EdgeLB pool.
 	EdgeLBPoolSizeAnnotationKey = annotationKeyPrefix + "edgelb-pool-size"
+	// EdgeLBPoolMinSizeAnnotationKey is the key of the annotation that holds the minimum size of the target EdgeLB pool when autoscaling is enabled.
+	EdgeLBPoolMinSizeAnnotationKey = annotationKeyPrefix + "edgelb-pool-min-size"
+	// EdgeLBPoolMaxSizeAnnotationKey is the key of the annotation that holds the maximum size of the target EdgeLB pool.
+	// Specifying this annotation enables autoscaling of the target EdgeLB pool.
+	EdgeLBPoolMaxSizeAnnotationKey = annotationKeyPrefix + "edgelb-pool-max-size"
+	// EdgeLBPoolTargetConnectionsPerInstanceAnnotationKey is the key of the annotation that holds the number of concurrent connections that each instance of the target EdgeLB pool should handle when autoscaling is enabled.
+	EdgeLBPoolTargetConnectionsPerInstanceAnnotationKey = annotationKeyPrefix + "edgelb-pool-target-connections-per-instance"
+
+	// EdgeLBBackendBalanceAnnotationKey is the key of the annotation that holds the load-balancing algorithm to use in the EdgeLB backends corresponding to a given Ingress/Service resource.
+	EdgeLBBackendBalanceAnnotationKey = annotationKeyPrefix + "edgelb-backend-balance"
+	// EdgeLBBackendTargetAnnotationKey is the key of the annotation that holds the kind of servers targeted by the EdgeLB backends corresponding to a given Ingress/Service resource.
+	EdgeLBBackendTargetAnnotationKey = annotationKeyPrefix + "edgelb-backend-target"
+
+	// EdgeLBHealthCheckPathAnnotationKey is the key of the annotation that holds the path to which HTTP health check requests are sent.
+	// If this annotation is not specified, backends are checked by establishing a TCP connection.
+	EdgeLBHealthCheckPathAnnotationKey = annotationKeyPrefix + "edgelb-health-check-path"
+	// EdgeLBHealthCheckExpectedStatusAnnotationKey is the key of the annotation that holds the HTTP status code expected in responses to health check requests.
+	EdgeLBHealthCheckExpectedStatusAnnotationKey = annotationKeyPrefix + "edgelb-health-check-expected-status"
+	// EdgeLBHealthCheckIntervalAnnotationKey is the key of the annotation that holds the interval between two consecutive health checks.
+	EdgeLBHealthCheckIntervalAnnotationKey = annotationKeyPrefix + "edgelb-health-check-interval"
+	// EdgeLBHealthCheckTimeoutAnnotationKey is the key of the annotation that holds the timeout for a health check to succeed.
+	EdgeLBHealthCheckTimeoutAnnotationKey = annotationKeyPrefix + "edgelb-health-check-timeout"
+	// EdgeLBHealthCheckRiseAnnotationKey is the key of the annotation that holds the number of consecutive successful health checks after which a server is considered healthy.
+	EdgeLBHealthCheckRiseAnnotationKey = annotationKeyPrefix + "edgelb-health-check-rise"
+	// EdgeLBHealthCheckFallAnnotationKey is the key of the annotation that holds the number of consecutive failed health checks after which a server is considered unhealthy.
+	EdgeLBHealthCheckFallAnnotationKey = annotationKeyPrefix + "edgelb-health-check-fall"
 
 	// EdgeLBPoolPortAnnotationKey is the key of the annotation that holds the port to use as a frontend bind port by the target EdgeLB pool.
 	// This annotation is specific to Ingress resources.
+	// DEPRECATED: This annotation is only read as a fallback for "EdgeLBPoolHTTPPortAnnotationKey", and will be removed in a future version.
 	EdgeLBPoolPortAnnotationKey = annotationKeyPrefix + "edgelb-pool-port"
+	// EdgeLBPoolHTTPPortAnnotationKey is the key of the annotation that holds the port to use as the bind port for the HTTP frontend of the target EdgeLB pool.
+	// This annotation is specific to Ingress resources.
+	EdgeLBPoolHTTPPortAnnotationKey = annotationKeyPrefix + "edgelb-pool-http-port"
+	// EdgeLBPoolHTTPSPortAnnotationKey is the key of the annotation that holds the port to use as the bind port for the HTTPS frontend of the target EdgeLB pool.
+	// This annotation is specific to Ingress resources.
+	EdgeLBPoolHTTPSPortAnnotationKey = annotationKeyPrefix + "edgelb-pool-https-port"
+
+	// EdgeLBPathMatchTypeAnnotationKey is the key of the annotation that holds the way in which the paths defined in an Ingress resource are matched against the paths of incoming requests.
+	// This annotation is specific to Ingress resources.
+	EdgeLBPathMatchTypeAnnotationKey = annotationKeyPrefix + "edgelb-path-match-type"
+
+	// EdgeLBRewriteTargetAnnotationKey is the key of the annotation that holds the path(s) with which the paths matched by the rules of an Ingress resource are replaced before requests are forwarded.
+	// Its value is either a single path (used for all rules) or a JSON object mapping paths defined in the Ingress resource to the corresponding rewrite target.
+	// This annotation is specific to Ingress resources.
+	EdgeLBRewriteTargetAnnotationKey = annotationKeyPrefix + "edgelb-rewrite-target"
+
+	// EdgeLBStickySessionsAnnotationKey is the key of the annotation that holds whether cookie-based sticky sessions should be enabled for an Ingress resource.
+	// This annotation is specific to Ingress resources.
+	EdgeLBStickySessionsAnnotationKey = annotationKeyPrefix + "edgelb-sticky-sessions"
+	// EdgeLBStickySessionsCookieNameAnnotationKey is the key of the annotation that holds the name of the cookie used to implement sticky sessions.
+	// This annotation is specific to Ingress resources.
+	EdgeLBStickySessionsCookieNameAnnotationKey = annotationKeyPrefix + "edgelb-sticky-sessions-cookie-name"
+	// EdgeLBStickySessionsCookieTTLAnnotationKey is the key of the annotation that holds the maximum lifetime of the cookie used to implement sticky sessions.
+	// This annotation is specific to Ingress resources.
+	EdgeLBStickySessionsCookieTTLAnnotationKey = annotationKeyPrefix + "edgelb-sticky-sessions-cookie-ttl"
+
+	// EdgeLBSSLRedirectAnnotationKey is the key of the annotation that holds whether requests made to the HTTP frontend should be redirected to the HTTPS frontend.
+	// This annotation is specific to Ingress resources.
+	EdgeLBSSLRedirectAnnotationKey = annotationKeyPrefix + "edgelb-ssl-redirect"
+	// EdgeLBSSLRedirectCodeAnnotationKey is the key of the annotation that holds the HTTP status code used when redirecting requests to the HTTPS frontend.
+	// This annotation is specific to Ingress resources.
+	EdgeLBSSLRedirectCodeAnnotationKey = annotationKeyPrefix + "edgelb-ssl-redirect-code"
+	// EdgeLBSSLRedirectExceptionsAnnotationKey is the key of the annotation that holds the comma-separated list of hosts for which requests are not redirected to the HTTPS frontend.
+	// This annotation is specific to Ingress resources.
+	EdgeLBSSLRedirectExceptionsAnnotationKey = annotationKeyPrefix + "edgelb-ssl-redirect-exceptions"
+
+	// EdgeLBHSTSAnnotationKey is the key of the annotation that holds whether the "Strict-Transport-Security" header should be added to responses served over HTTPS.
+	// This annotation is specific to Ingress resources.
+	EdgeLBHSTSAnnotationKey = annotationKeyPrefix + "edgelb-hsts"
+	// EdgeLBHSTSMaxAgeAnnotationKey is the key of the annotation that holds the value of the "max-age" directive of the "Strict-Transport-Security" header.
+	// This annotation is specific to Ingress resources.
+	EdgeLBHSTSMaxAgeAnnotationKey = annotationKeyPrefix + "edgelb-hsts-max-age"
+	// EdgeLBHSTSIncludeSubdomainsAnnotationKey is the key of the annotation that holds whether the "includeSubDomains" directive should be added to the "Strict-Transport-Security" header.
+	// This annotation is specific to Ingress resources.
+	EdgeLBHSTSIncludeSubdomainsAnnotationKey = annotationKeyPrefix + "edgelb-hsts-include-subdomains"
+
+	// EdgeLBWhitelistSourceRangeAnnotationKey is the key of the annotation that holds the comma-separated list of source ranges (in CIDR notation) from which connections are allowed.
+	// This annotation is specific to Ingress resources, as Service resources use ".spec.loadBalancerSourceRanges" instead.
+	EdgeLBWhitelistSourceRangeAnnotationKey = annotationKeyPrefix + "edgelb-whitelist-source-range"
 
 	// EdgeLBPoolPortMapKeyPrefix is the prefix of the key of the annotation that holds the port to use as a frontend bind port by the target EdgeLB pool.
 	// This annotation is specific to Service resources.
 	EdgeLBPoolPortMapKeyPrefix = annotationKeyPrefix + "edgelb-pool-portmap."
+	// EdgeLBPoolSNIHostnamesKeyPrefix is the prefix of the key of the annotation that holds the (comma-separated) list of TLS SNI hostnames for which traffic should be routed to a given service port.
+	// Service ports for which this annotation is specified are exposed via a TCP frontend that is shared by all Service resources targeting the same EdgeLB pool and frontend bind port.
+	// This annotation is specific to Service resources.
+	EdgeLBPoolSNIHostnamesKeyPrefix = annotationKeyPrefix + "edgelb-pool-sni-hostnames."
+
+	// EdgeLBPoolPatchAnnotationKey is the key of the annotation that holds a JSON merge patch (RFC 7386) to apply to the target EdgeLB pool after translation.
+	// It allows for configuring features of EdgeLB pools for which no dedicated annotation exists.
+	EdgeLBPoolPatchAnnotationKey = annotationKeyPrefix + "edgelb-pool-patch"
+	// EdgeLBBackendPatchAnnotationKey is the key of the annotation that holds a JSON merge patch (RFC 7386) to apply to each EdgeLB backend corresponding to a given Ingress/Service resource after translation.
+	EdgeLBBackendPatchAnnotationKey = annotationKeyPrefix + "edgelb-backend-patch"
+	// EdgeLBFrontendPatchAnnotationKey is the key of the annotation that holds a JSON merge patch (RFC 7386) to apply to each EdgeLB frontend corresponding to a given Ingress/Service resource after translation.
+	// Frontends shared by Service resources using TLS SNI are not patched.
+	EdgeLBFrontendPatchAnnotationKey = annotationKeyPrefix + "edgelb-frontend-patch"
+
+	// EdgeLBPoolMigrationStatusAnnotationKey is the key of the annotation that holds the status of the migration of a given Ingress/Service resource from one EdgeLB pool to another.
+	// This annotation is set by the admission webhook whenever the name of the target EdgeLB pool changes, is updated by dklb as the migration progresses, and is removed once the migration is complete.
+	// It MUST NOT be set or changed manually.
+	EdgeLBPoolMigrationStatusAnnotationKey = annotationKeyPrefix + "edgelb-pool-migration-status"
+
+	// EdgeLBPoolAppliedStateHashAnnotationKey is the key of the annotation that holds a hash of the EdgeLB objects last applied by dklb to the target EdgeLB pool for a given Ingress/Service resource.
+	// It is set by dklb after every successful translation, and is used to tell changes made out-of-band to the target EdgeLB pool apart from changes to the Ingress/Service resource.
+	// It MUST NOT be set or changed manually.
+	EdgeLBPoolAppliedStateHashAnnotationKey = annotationKeyPrefix + "edgelb-pool-applied-state-hash"
+	// EdgeLBPoolLastAppliedDiffAnnotationKey is the key of the annotation that holds a JSON description of the changes last made by dklb to the target EdgeLB pool for a given Ingress/Service resource.
+	// It is set by dklb whenever the target EdgeLB pool is created or updated, and is meant to help answering the question of why the EdgeLB pool changed.
+	// It MUST NOT be set or changed manually.
+	EdgeLBPoolLastAppliedDiffAnnotationKey = annotationKeyPrefix + "last-applied-diff"
+	// EdgeLBPoolAppliedResourcesAnnotationKey is the key of the annotation that holds a JSON description of the CPU and memory requests and of the size last applied by dklb to the target EdgeLB pool for a given Ingress/Service resource.
+	// It is set by dklb after every successful translation, and is used to only change these fields of the target EdgeLB pool when the corresponding annotations of the Ingress/Service resource change.
+	// It MUST NOT be set or changed manually.
+	EdgeLBPoolAppliedResourcesAnnotationKey = annotationKeyPrefix + "edgelb-pool-applied-resources"
+	// EdgeLBPoolAppliedPatchAnnotationKey is the key of the annotation that holds the JSON merge patch last applied by dklb to the target EdgeLB pool for a given Ingress/Service resource.
+	// It is set by dklb after every successful translation, and is used to reset the fields of the target EdgeLB pool that are no longer set by the "kubernetes.dcos.io/edgelb-pool-patch" annotation.
+	// It MUST NOT be set or changed manually.
+	EdgeLBPoolAppliedPatchAnnotationKey = annotationKeyPrefix + "edgelb-pool-applied-patch"
+	// EdgeLBPoolReportedDriftHashAnnotationKey is the key of the annotation that holds a hash of the changes made out-of-band to the target EdgeLB pool that have last been reported for a given Ingress/Service resource.
+	// It is set by dklb whenever such changes are reported, and is used to avoid reporting the same changes on every resync.
+	// It MUST NOT be set or changed manually.
+	EdgeLBPoolReportedDriftHashAnnotationKey = annotationKeyPrefix + "edgelb-pool-reported-drift-hash"
 
 	// EdgeLBPoolTranslationPaused is the key of the annotation that holds whether a given resource is currently paused.
 	// While this annotation is set to "true" on a given Ingress/Service resource, dklb will not perform any calls to the EdgeLB API server regarding said resource.
@@ -4,3 +4,14 @@ const (
 	// DefaultDCOSVirtualNetworkName is the name of the virtual network that exists by default in DC/OS.
 	DefaultDCOSVirtualNetworkName = "dcos"
 )
+
+const (
+	// DefaultDCOSSecretsHost is the default host at which the DC/OS secrets API can be reached.
+	DefaultDCOSSecretsHost = "master.mesos"
+	// DefaultDCOSSecretsPath is the default path at which the DC/OS secrets API can be reached.
+	DefaultDCOSSecretsPath = "/secrets/v1"
+	// DefaultDCOSSecretsScheme is the default scheme used to communicate with the DC/OS secrets API.
+	DefaultDCOSSecretsScheme = "https"
+	// DefaultDCOSSecretsStore is the name of the DC/OS secret store in which secrets are created by default.
+	DefaultDCOSSecretsStore = "default"
+)
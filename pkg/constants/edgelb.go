@@ -1,12 +1,25 @@
 package constants
 
 const (
+	// EdgeLBBackendBalanceHeaderHashFormatString is the format string used to compute the value used to request the "hdr(<name>)" mode (i.e. hashing of the specified HTTP header) for a backend.
+	EdgeLBBackendBalanceHeaderHashFormatString = "hdr(%s)"
 	// EdgeLBBackendBalanceLeastConnections holds the value used to request the "leastconn" mode for a backend.
 	EdgeLBBackendBalanceLeastConnections = "leastconn"
+	// EdgeLBBackendBalanceRoundRobin holds the value used to request the "roundrobin" mode for a backend.
+	EdgeLBBackendBalanceRoundRobin = "roundrobin"
+	// EdgeLBBackendBalanceSourceHash holds the value used to request the "source" mode (i.e. hashing of the client's IP address) for a backend.
+	EdgeLBBackendBalanceSourceHash = "source"
+	// EdgeLBBackendBalanceURIHash holds the value used to request the "uri" mode (i.e. hashing of the request's URI) for a backend.
+	EdgeLBBackendBalanceURIHash = "uri"
 	// EdgeLBCloudLoadBalancerPoolNamePrefix is the prefix used in the names of EdgeLB pools requesting a cloud load-balancer to be configured.
 	EdgeLBCloudLoadBalancerPoolNamePrefix = "ext"
 	// EdgeLBFrontendBindAddress holds the bind address to use in EdgeLB frontends.
 	EdgeLBFrontendBindAddress = "0.0.0.0"
+	// EdgeLBPoolStatsPath is the path (including the query string) at which each instance of an EdgeLB pool reports HAProxy statistics in CSV format.
+	EdgeLBPoolStatsPath = "/haproxy?stats;csv"
+	// EdgeLBPoolStatsPort is the port at which each instance of an EdgeLB pool exposes HAProxy statistics.
+	// This is the default value used by EdgeLB, which dklb never overrides when creating EdgeLB pools.
+	EdgeLBPoolStatsPort = 9090
 	// EdgeLBRolePublic is the role used to schedule an EdgeLB pool onto a public DC/OS agent.
 	EdgeLBRolePublic = "slave_public"
 	// EdgeLBRolePrivate is the value used to schedule an EdgeLB pool onto a private DC/OS agent.
@@ -5,8 +5,32 @@ const (
 	ReasonNoDefaultBackendSpecified = "NoDefaultBackendSpecified"
 	// ReasonInvalidBackendService is the reason used in Kubernetes events emitted due to a missing or otherwise invalid Service resource referenced by an Ingress resource.
 	ReasonInvalidBackendService = "InvalidBackendService"
+	// ReasonInvalidTLSSecret is the reason used in Kubernetes events emitted due to a missing or otherwise invalid Secret resource referenced by the ".spec.tls" field of an Ingress resource.
+	ReasonInvalidTLSSecret = "InvalidTLSSecret"
+	// ReasonInvalidPath is the reason used in Kubernetes events emitted due to a path defined in an Ingress resource that cannot be translated into a regular expression understood by EdgeLB.
+	ReasonInvalidPath = "InvalidPath"
+	// ReasonTLSSecretChanged is the reason used in Kubernetes events emitted when a change to a Secret resource referenced by the ".spec.tls" field of an Ingress resource has been reflected on the target EdgeLB pool.
+	ReasonTLSSecretChanged = "TLSSecretChanged"
 	// ReasonInvalidAnnotations is the reason used in Kubernetes events emitted due to missing/invalid annotations on a Service/Ingress resource.
 	ReasonInvalidAnnotations = "InvalidAnnotations"
+	// ReasonDCOSSecretCleanupFailed is the reason used in Kubernetes events emitted due to a failure to delete a DC/OS secret reflected from a Secret resource that is not referenced by the target EdgeLB pool anymore.
+	ReasonDCOSSecretCleanupFailed = "DCOSSecretCleanupFailed"
+	// ReasonSNIHostnameConflict is the reason used in Kubernetes events emitted due to a TLS SNI hostname requested by a Service resource being already in use by a different Service resource in the same EdgeLB pool and frontend bind port.
+	ReasonSNIHostnameConflict = "SNIHostnameConflict"
+	// ReasonEdgeLBPoolMigrationStarted is the reason used in Kubernetes events emitted when the migration of a Service/Ingress resource from one EdgeLB pool to another starts.
+	ReasonEdgeLBPoolMigrationStarted = "EdgeLBPoolMigrationStarted"
+	// ReasonEdgeLBPoolSwitchedOver is the reason used in Kubernetes events emitted when the status of a Service/Ingress resource being migrated is switched to the addresses of the new EdgeLB pool.
+	ReasonEdgeLBPoolSwitchedOver = "EdgeLBPoolSwitchedOver"
+	// ReasonEdgeLBPoolMigrationCompleted is the reason used in Kubernetes events emitted when a Service/Ingress resource has been removed from the old EdgeLB pool after being migrated to a new one.
+	ReasonEdgeLBPoolMigrationCompleted = "EdgeLBPoolMigrationCompleted"
+	// ReasonEdgeLBPoolScaled is the reason used in Kubernetes events emitted when the size of the EdgeLB pool targeted by a Service/Ingress resource is changed by the autoscaler.
+	ReasonEdgeLBPoolScaled = "EdgeLBPoolScaled"
+	// ReasonEdgeLBPoolDryRun is the reason used in Kubernetes events emitted when a change to the EdgeLB pool targeted by a Service/Ingress resource is recorded instead of being executed as dry-run mode is enabled.
+	ReasonEdgeLBPoolDryRun = "EdgeLBPoolDryRun"
+	// ReasonEdgeLBPoolChanged is the reason used in Kubernetes events emitted when the EdgeLB pool targeted by a Service/Ingress resource is created, updated or deleted as a result of translation.
+	ReasonEdgeLBPoolChanged = "EdgeLBPoolChanged"
+	// ReasonPoolDriftDetected is the reason used in Kubernetes events emitted when the EdgeLB objects owned by a Service/Ingress resource are found to have been changed out-of-band in the target EdgeLB pool.
+	ReasonPoolDriftDetected = "PoolDriftDetected"
 	// ReasonTranslationError is the reason used in Kubernetes events emitted due to failed translation of a Service/Ingress resource into an EdgeLB pool.
 	// TODO (@bcustodio) Understand if we should break this down into more fine-grained reasons (e.g. "InvalidSpec", "NetworkingError", ...).
 	ReasonTranslationError = "TranslationError"
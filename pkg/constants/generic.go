@@ -11,6 +11,10 @@ const (
 	DefaultBackendServiceName = "dklb"
 	// DefaultBackendServicePort is the service port defined in the Service resource that exposes dklb as a default backend for Ingress resources.
 	DefaultBackendServicePort = 80
+	// DefaultEdgeLBGarbageCollectionGracePeriod is the (default) minimum amount of time during which an EdgeLB object must be found to be orphaned before it is removed by the EdgeLB garbage collector.
+	DefaultEdgeLBGarbageCollectionGracePeriod = 15 * time.Minute
+	// DefaultEdgeLBGarbageCollectionInterval is the (default) interval at which EdgeLB pools are scanned for orphaned EdgeLB objects.
+	DefaultEdgeLBGarbageCollectionInterval = 5 * time.Minute
 	// DefaultEdgeLBHost is the default host at which the EdgeLB API server can be reached.
 	DefaultEdgeLBHost = "api.edgelb.marathon.l4lb.thisdcos.directory"
 	// DefaultEdgeLBPath is the default path at which the EdgeLB API server can be reached.
@@ -19,8 +23,17 @@ const (
 	DefaultEdgeLBPoolGroup = "dcos-edgelb/pools"
 	// DefaultEdgeLBScheme is the default scheme to use when communicating with the EdgeLB API server.
 	DefaultEdgeLBScheme = "http"
+	// DefaultEdgeLBPoolAutoscalingInterval is the (default) interval at which the load of EdgeLB pools for which autoscaling is enabled is checked.
+	DefaultEdgeLBPoolAutoscalingInterval = 30 * time.Second
+	// DefaultEdgeLBPoolScaleDownCooldown is the (default) minimum amount of time that must elapse after an EdgeLB pool has been scaled before it can be scaled down.
+	DefaultEdgeLBPoolScaleDownCooldown = 10 * time.Minute
+	// DefaultEdgeLBPoolScaleUpCooldown is the (default) minimum amount of time that must elapse after an EdgeLB pool has been scaled before it can be scaled up.
+	DefaultEdgeLBPoolScaleUpCooldown = 3 * time.Minute
 	// DefaultResyncPeriod is the (default) maximum amount of time that may elapse between two consecutive synchronizations of Ingress/Service resources and the status of EdgeLB pools.
 	DefaultResyncPeriod = 2 * time.Minute
+	// EdgeLBCleanupFinalizer is the finalizer added to Ingress/Service resources managed by dklb.
+	// It prevents said resources from being removed from the Kubernetes API before the EdgeLB objects they own have been removed from the target EdgeLB pool.
+	EdgeLBCleanupFinalizer = "kubernetes.dcos.io/edgelb-cleanup"
 	// KubeNodeTaskPattern is the pattern used to match Mesos tasks that correspond to Kubernetes nodes (either private or public).
 	KubeNodeTaskPattern = "^kube-node-.*$"
 	// KubeSystemNamespaceName holds the name of the "kube-system" namespace.
@@ -6,10 +6,18 @@ import (
 	"time"
 
 	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
+
+	kubernetesutil "github.com/mesosphere/dklb/pkg/util/kubernetes"
+)
+
+const (
+	// defaultEdgeLBManagerTimeout is the default timeout used when interacting with the EdgeLB manager.
+	defaultEdgeLBManagerTimeout = 10 * time.Second
 )
 
 // Controller represents a controller that handles Kubernetes resources.
@@ -151,6 +159,17 @@ func (c *genericController) enqueue(obj interface{}) {
 	}
 }
 
+// enqueueAfter takes a Kubernetes resource, computes its resource key and puts it as a work item onto the work queue after the specified delay.
+func (c *genericController) enqueueAfter(obj interface{}, delay time.Duration) {
+	if key, err := cache.MetaNamespaceKeyFunc(obj); err != nil {
+		runtime.HandleError(err)
+	} else {
+		c.workqueue.AddAfter(WorkItem{
+			Key: key,
+		}, delay)
+	}
+}
+
 // enqueueTombstone takes the tombstone of a Kubernetes resource that has been deleted, computes its resource key and puts it as a work item onto the work queue.
 // Must only be used to handle cleanup in scenarios where the Kubernetes resource has been deleted.
 // For all other usage scenarios, "enqueue" should be used instead.
@@ -164,3 +183,25 @@ func (c *genericController) enqueueTombstone(obj interface{}) {
 		})
 	}
 }
+
+// nodeEventHandler returns an event handler that calls the specified function whenever a change to a Node resource may require EdgeLB backends to be updated.
+// This happens whenever a Kubernetes node is added or removed, whenever it becomes (or stops being) Ready and schedulable, and whenever its internal IP changes.
+// Periodic resyncs and changes to node status that don't affect the abovementioned properties (e.g. heartbeats) are ignored.
+func nodeEventHandler(f func()) cache.ResourceEventHandlerFuncs {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(_ interface{}) {
+			f()
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldNode := oldObj.(*corev1.Node)
+			newNode := newObj.(*corev1.Node)
+			if kubernetesutil.IsReadyAndSchedulableNode(oldNode) == kubernetesutil.IsReadyAndSchedulableNode(newNode) && kubernetesutil.NodeInternalIP(oldNode) == kubernetesutil.NodeInternalIP(newNode) {
+				return
+			}
+			f()
+		},
+		DeleteFunc: func(_ interface{}) {
+			f()
+		},
+	}
+}
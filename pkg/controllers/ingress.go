@@ -1,7 +1,10 @@
 package controllers
 
 import (
+	"context"
 	"fmt"
+	"reflect"
+	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -9,15 +12,19 @@ import (
 	extsv1beta1 "k8s.io/api/extensions/v1beta1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/dynamic"
 	corev1informers "k8s.io/client-go/informers/core/v1"
-	extsv1beta1informers "k8s.io/client-go/informers/extensions/v1beta1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 
 	dklbcache "github.com/mesosphere/dklb/pkg/cache"
 	"github.com/mesosphere/dklb/pkg/constants"
+	"github.com/mesosphere/dklb/pkg/dcos/secrets"
 	"github.com/mesosphere/dklb/pkg/edgelb/manager"
+	dklberrors "github.com/mesosphere/dklb/pkg/errors"
 	"github.com/mesosphere/dklb/pkg/metrics"
 	"github.com/mesosphere/dklb/pkg/translator"
 	kubernetesutil "github.com/mesosphere/dklb/pkg/util/kubernetes"
@@ -29,6 +36,10 @@ const (
 	ingressControllerName = "ingress-controller"
 	// ingressControllerThreadiness is the number of workers the ingress controller will use to process items from its work queue.
 	ingressControllerThreadiness = 1
+	// ingressTLSSecretIndex is the name of the index used to lookup Ingress resources referencing a given Secret resource in their ".spec.tls" field.
+	ingressTLSSecretIndex = "tlsSecret"
+	// ingressClassNameIndex is the name of the index used to lookup "networking.k8s.io/v1" Ingress resources referencing a given IngressClass resource in their ".spec.ingressClassName" field.
+	ingressClassNameIndex = "ingressClassName"
 )
 
 // IngressController is the controller for Ingress resources.
@@ -37,24 +48,53 @@ type IngressController struct {
 	*genericController
 	// kubeClient is a client to the Kubernetes core APIs.
 	kubeClient kubernetes.Interface
+	// dynamicClient is a client to the Kubernetes API used to update "networking.k8s.io/v1" Ingress resources.
+	dynamicClient dynamic.Interface
 	// dklbCache is the instance of the Kubernetes resource cache to use.
 	kubeCache dklbcache.KubernetesResourceCache
 	// edgelbManager is the instance of the EdgeLB manager to use for materializing EdgeLB pools for Ingress resources.
 	edgelbManager manager.EdgeLBManager
+	// secretsManager is the instance of the DC/OS secrets manager to use for reflecting TLS secrets referenced by Ingress resources.
+	secretsManager secrets.SecretsManager
+	// ingressIndexer is the indexer used to lookup Ingress resources referencing a given Secret resource.
+	ingressIndexer cache.Indexer
+	// changedSecrets holds, for each Ingress resource (indexed by its "namespace/name" key), the names of the Secret resources referenced by said Ingress resource that have changed since it was last successfully translated.
+	changedSecrets map[string]map[string]time.Time
+	// changedSecretsLock is used to synchronize access to "changedSecrets".
+	changedSecretsLock sync.Mutex
 }
 
 // NewIngressController creates a new instance of the EdgeLB ingress controller.
-func NewIngressController(clusterName string, kubeClient kubernetes.Interface, ingressInformer extsv1beta1informers.IngressInformer, serviceInformer corev1informers.ServiceInformer, kubeCache dklbcache.KubernetesResourceCache, edgelbManager manager.EdgeLBManager) *IngressController {
+// "ingressInformer" must be an informer for either "extensions/v1beta1" or "networking.k8s.io/v1" Ingress resources, depending on which API is served by the Kubernetes API.
+// "dynamicClient" is only used to update "networking.k8s.io/v1" Ingress resources.
+// "ingressClassInformer" must be an informer for "networking.k8s.io/v1" IngressClass resources, or nil in case the Kubernetes API doesn't serve "networking.k8s.io/v1" Ingress resources.
+func NewIngressController(clusterName string, kubeClient kubernetes.Interface, dynamicClient dynamic.Interface, ingressInformer cache.SharedIndexInformer, ingressClassInformer cache.SharedIndexInformer, secretInformer corev1informers.SecretInformer, serviceInformer corev1informers.ServiceInformer, endpointsInformer corev1informers.EndpointsInformer, nodeInformer corev1informers.NodeInformer, kubeCache dklbcache.KubernetesResourceCache, edgelbManager manager.EdgeLBManager, secretsManager secrets.SecretsManager) *IngressController {
 	// Create a new instance of the ingress controller with the specified name and threadiness.
 	c := &IngressController{
 		genericController: newGenericController(clusterName, ingressControllerName, ingressControllerThreadiness),
 		kubeClient:        kubeClient,
+		dynamicClient:     dynamicClient,
 		kubeCache:         kubeCache,
 		edgelbManager:     edgelbManager,
+		secretsManager:    secretsManager,
+		ingressIndexer:    ingressInformer.GetIndexer(),
+		changedSecrets:    make(map[string]map[string]time.Time),
+	}
+	// Index Ingress resources by the Secret resources they reference in their ".spec.tls" field.
+	// This allows us to efficiently lookup the Ingress resources that must be enqueued whenever a Secret resource changes.
+	if err := ingressInformer.AddIndexers(cache.Indexers{ingressTLSSecretIndex: c.indexIngress}); err != nil {
+		c.logger.Errorf("failed to add the %q index to the ingress informer: %v", ingressTLSSecretIndex, err)
+	}
+	// Index "networking.k8s.io/v1" Ingress resources by the IngressClass resource they reference in their ".spec.ingressClassName" field.
+	// This allows us to efficiently lookup the Ingress resources that must be enqueued whenever an IngressClass resource changes.
+	if ingressClassInformer != nil {
+		if err := ingressInformer.AddIndexers(cache.Indexers{ingressClassNameIndex: indexIngressByIngressClassName}); err != nil {
+			c.logger.Errorf("failed to add the %q index to the ingress informer: %v", ingressClassNameIndex, err)
+		}
 	}
 	// Make the controller wait for the caches to sync.
 	c.hasSyncedFuncs = []cache.InformerSynced{
-		ingressInformer.Informer().HasSynced,
+		ingressInformer.HasSynced,
 		kubeCache.HasSynced,
 	}
 	// Make processQueueItem the handler for items popped out of the work queue.
@@ -62,34 +102,60 @@ func NewIngressController(clusterName string, kubeClient kubernetes.Interface, i
 
 	// Setup an event handler to inform us when Ingress resources change.
 	// An Ingress resource is enqueued in the following scenarios:
-	// * It was listed ("ADDED") and has the required "kubernetes.io/ingress.class" annotation set to "edgelb".
-	// * It was updated ("MODIFIED") and either the old or the new types - or both - have this same annotation.
-	//   * This allows for handling the cases in which the annotation is changed/removed.
-	// * It was deleted ("DELETED") and has this same annotation.
-	ingressInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+	// * It was listed ("ADDED") and its ingress class selects EdgeLB (i.e. it references an IngressClass resource whose controller is "kubernetes.dcos.io/dklb", or it has the "kubernetes.io/ingress.class" annotation set to "edgelb").
+	// * It was updated ("MODIFIED") and the ingress class of either the old or the new types - or both - selects EdgeLB.
+	//   * This allows for handling the cases in which the ingress class is changed/removed.
+	// * It was deleted ("DELETED") and its ingress class selects EdgeLB.
+	// Ingress resources holding the "kubernetes.dcos.io/edgelb-cleanup" finalizer are always enqueued, so that the finalizer can be removed once the EdgeLB objects they own have been removed.
+	// "networking.k8s.io/v1" Ingress resources are converted into the representation used internally by dklb before being inspected.
+	ingressInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
-			ingress := obj.(*extsv1beta1.Ingress)
-			if !kubernetesutil.IsEdgeLBIngress(ingress) {
+			ingress, ok := c.toIngress(obj)
+			if !ok || (!kubernetesutil.IsEdgeLBIngress(ingress) && !kubernetesutil.HasFinalizer(ingress, constants.EdgeLBCleanupFinalizer)) {
 				return
 			}
 			c.enqueue(ingress)
 		},
 		UpdateFunc: func(oldObj, newObj interface{}) {
-			oldIngress := oldObj.(*extsv1beta1.Ingress)
-			newIngress := newObj.(*extsv1beta1.Ingress)
-			if !kubernetesutil.IsEdgeLBIngress(oldIngress) && !kubernetesutil.IsEdgeLBIngress(newIngress) {
+			oldIngress, oldOk := c.toIngress(oldObj)
+			newIngress, newOk := c.toIngress(newObj)
+			if !oldOk || !newOk {
+				return
+			}
+			if !kubernetesutil.IsEdgeLBIngress(oldIngress) && !kubernetesutil.IsEdgeLBIngress(newIngress) && !kubernetesutil.HasFinalizer(newIngress, constants.EdgeLBCleanupFinalizer) {
 				return
 			}
 			c.enqueue(newIngress)
 		},
 		DeleteFunc: func(obj interface{}) {
-			ingress := obj.(*extsv1beta1.Ingress)
-			if !kubernetesutil.IsEdgeLBIngress(ingress) {
+			ingress, ok := c.toIngress(obj)
+			if !ok || !kubernetesutil.IsEdgeLBIngress(ingress) {
 				return
 			}
 			c.enqueueTombstone(ingress)
 		},
 	})
+	// Setup an event handler to inform us when IngressClass resources change, in case the Kubernetes API serves "networking.k8s.io/v1" Ingress resources.
+	// This allows us to enqueue all Ingress resources that reference said IngressClass resource whenever it is created, updated or deleted, as this may change whether they are meant to be provisioned by EdgeLB.
+	// Periodic resyncs are ignored, as they don't change the controller of the IngressClass resource.
+	if ingressClassInformer != nil {
+		ingressClassInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				c.enqueueIngressesForIngressClass(obj)
+			},
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				oldIngressClass, oldOk := oldObj.(*unstructured.Unstructured)
+				newIngressClass, newOk := newObj.(*unstructured.Unstructured)
+				if oldOk && newOk && oldIngressClass.GetResourceVersion() == newIngressClass.GetResourceVersion() {
+					return
+				}
+				c.enqueueIngressesForIngressClass(newObj)
+			},
+			DeleteFunc: func(obj interface{}) {
+				c.enqueueIngressesForIngressClass(obj)
+			},
+		})
+	}
 	// Setup an event handler to inform us when Service resources change.
 	// This allows us to enqueue all ingresses that reference said Service resource.
 	serviceInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -104,6 +170,60 @@ func NewIngressController(clusterName string, kubeClient kubernetes.Interface, i
 		},
 	})
 
+	// Setup an event handler to inform us when Endpoints resources change.
+	// This allows us to enqueue all Ingress resources that target the pods backing the corresponding Service resource (or the Kubernetes nodes hosting them) whenever a pod is created, deleted or becomes ready.
+	// Periodic resyncs are ignored, as they don't require the target EdgeLB pools to be updated.
+	endpointsInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.enqueueIngressesTargetingEndpoints(obj.(*corev1.Endpoints))
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldEndpoints := oldObj.(*corev1.Endpoints)
+			newEndpoints := newObj.(*corev1.Endpoints)
+			if oldEndpoints.ResourceVersion == newEndpoints.ResourceVersion {
+				return
+			}
+			c.enqueueIngressesTargetingEndpoints(newEndpoints)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if endpoints, ok := obj.(*corev1.Endpoints); ok {
+				c.enqueueIngressesTargetingEndpoints(endpoints)
+			}
+		},
+	})
+
+	// Setup an event handler to inform us when Node resources change.
+	// This allows us to enqueue all Ingress resources that should be satisfied by EdgeLB whenever a Kubernetes node is added, removed, cordoned, uncordoned or changes readiness, so that the set of targeted Kubernetes nodes is kept up-to-date.
+	nodeInformer.Informer().AddEventHandler(nodeEventHandler(c.enqueueAllEdgeLBIngresses))
+
+	// Setup an event handler to inform us when Secret resources change.
+	// This allows us to enqueue all Ingress resources that reference said Secret resource (e.g. whenever a certificate is rotated).
+	// Periodic resyncs and updates that don't change the contents of the Secret resource are ignored, as they don't require the target EdgeLB pools to be updated.
+	secretInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.enqueueReferencingIngressesForSecret(obj.(*corev1.Secret), false)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldSecret := oldObj.(*corev1.Secret)
+			newSecret := newObj.(*corev1.Secret)
+			if !isSecretContentChanged(oldSecret, newSecret) {
+				return
+			}
+			c.enqueueReferencingIngressesForSecret(newSecret, true)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if secret, ok := obj.(*corev1.Secret); ok {
+				c.enqueueReferencingIngressesForSecret(secret, false)
+			}
+		},
+	})
+
 	// Return the instance created above.
 	return c
 }
@@ -122,6 +242,9 @@ func (c *IngressController) processQueueItem(workItem WorkItem) error {
 		return nil
 	}
 
+	// isTombstone holds whether the Ingress resource has already been removed from the Kubernetes API.
+	isTombstone := false
+
 	// Get the Ingress resource with the specified namespace and name.
 	ingress, err := c.kubeCache.GetIngress(namespace, name)
 	if err == nil {
@@ -139,6 +262,7 @@ func (c *IngressController) processQueueItem(workItem WorkItem) error {
 		}
 		// Create a deep copy of the tombstone in order to avoid mutating the cache.
 		ingress = workItem.Tombstone.(*extsv1beta1.Ingress).DeepCopy()
+		isTombstone = true
 		// Set the current timestamp as the value of ".metadata.deletionTimestamp" so the translator can understand that the resource has been deleted.
 		deletionTimestamp := metav1.NewTime(startTime)
 		ingress.ObjectMeta.DeletionTimestamp = &deletionTimestamp
@@ -153,31 +277,193 @@ func (c *IngressController) processQueueItem(workItem WorkItem) error {
 		// Emit an event and log an error, but do not re-enqueue as the resource's spec was found to be invalid.
 		er.Eventf(ingress, corev1.EventTypeWarning, constants.ReasonInvalidAnnotations, "the resource's annotations are not valid: %v", err)
 		c.logger.Errorf("failed to compute translation options for ingress %q: %v", workItem.Key, err)
+		// In case the Ingress resource has been deleted, the EdgeLB pool it targets cannot be determined from its annotations.
+		// Hence, remove the EdgeLB objects it owns from every EdgeLB pool instead, so that the finalizer can still be removed.
+		if ingress.ObjectMeta.DeletionTimestamp != nil && kubernetesutil.HasFinalizer(ingress, constants.EdgeLBCleanupFinalizer) {
+			return c.cleanupAllEdgeLBPools(ingress, isTombstone)
+		}
 		return nil
 	}
 
 	// Output some tracing information about the computed set of options.
 	prettyprint.LogfSpew(log.Tracef, options, "computed ingress translation options for %q", workItem.Key)
 
+	// isManaged holds whether the Ingress resource must be provisioned by EdgeLB (i.e. whether it has the required "kubernetes.io/ingress.class" annotation and hasn't been deleted).
+	isManaged := ingress.ObjectMeta.DeletionTimestamp == nil && kubernetesutil.IsEdgeLBIngress(ingress)
+	// Add the "kubernetes.dcos.io/edgelb-cleanup" finalizer to the Ingress resource before translating it, so that it cannot be removed from the Kubernetes API before the EdgeLB objects it owns are removed.
+	if isManaged && kubernetesutil.AddFinalizer(ingress, constants.EdgeLBCleanupFinalizer) {
+		if ingress, err = c.updateIngress(ingress); err != nil {
+			c.logger.Errorf("failed to add finalizer to ingress %q: %v", workItem.Key, err)
+			return err
+		}
+	}
+
 	// Perform translation of the Ingress resource into an EdgeLB pool.
-	status, err := translator.NewIngressTranslator(c.clusterName, ingress, *options, c.kubeCache, c.edgelbManager, er).Translate()
+	t := translator.NewIngressTranslator(c.clusterName, ingress, *options, c.kubeCache, c.edgelbManager, c.secretsManager, er)
+	status, err := t.Translate()
 	if err != nil {
 		er.Eventf(ingress, corev1.EventTypeWarning, constants.ReasonTranslationError, "failed to translate ingress: %v", err)
 		c.logger.Errorf("failed to translate ingress %q: %v", workItem.Key, err)
 		return err
 	}
 
+	// Report any changes to referenced Secret resources that have been taken into account by the current translation.
+	// If translation is paused, changes are kept so they can be reported once translation resumes.
+	if !options.EdgeLBPoolTranslationPaused {
+		c.reportChangedSecrets(workItem.Key, ingress, options.EdgeLBPoolName, t.EdgeLBPoolChanged(), er)
+	}
+
+	// Advance the migration of the Ingress resource between EdgeLB pools, if one is in progress.
+	migration, err := translator.GetEdgeLBPoolMigrationStatus(ingress)
+	if err != nil {
+		er.Eventf(ingress, corev1.EventTypeWarning, constants.ReasonInvalidAnnotations, "the resource's annotations are not valid: %v", err)
+		c.logger.Errorf("failed to read the edgelb pool migration status for ingress %q: %v", workItem.Key, err)
+		migration = nil
+	}
+	if migration != nil && !options.EdgeLBPoolTranslationPaused {
+		// cleanup removes the EdgeLB objects owned by the Ingress resource from the EdgeLB pool it is being migrated from.
+		sourcePool := migration.SourcePool
+		cleanup := func() error {
+			return c.cleanupEdgeLBPool(ingress, *options, sourcePool, er)
+		}
+		// If the Ingress resource has been deleted, the EdgeLB pool it was being migrated from must be cleaned up as well.
+		if ingress.ObjectMeta.DeletionTimestamp != nil {
+			if err := cleanup(); err != nil {
+				return err
+			}
+			return c.removeFinalizer(ingress, isTombstone, []string{options.EdgeLBPoolName, sourcePool})
+		}
+		res, err := translator.AdvanceEdgeLBPoolMigration(ingress, *migration, &ingress.Status.LoadBalancer, status, time.Now(), cleanup, er)
+		if err != nil {
+			er.Eventf(ingress, corev1.EventTypeWarning, constants.ReasonTranslationError, "failed to migrate ingress: %v", err)
+			c.logger.Errorf("failed to migrate ingress %q: %v", workItem.Key, err)
+			return err
+		}
+		if res.RequeueAfter > 0 {
+			defer c.enqueueAfter(ingress, res.RequeueAfter)
+		}
+		status = res.Status
+		migration = res.Migration
+	}
+
 	// Update the status of the Ingress resource if it hasn't been deleted.
 	if ingress.ObjectMeta.DeletionTimestamp == nil && status != nil {
 		ingress.Status = extsv1beta1.IngressStatus{LoadBalancer: *status}
-		if _, err := c.kubeClient.ExtensionsV1beta1().Ingresses(ingress.Namespace).UpdateStatus(ingress); err != nil {
+		if ingress, err = c.updateIngressStatus(ingress); err != nil {
 			c.logger.Errorf("failed to update status for ingress %q: %v", workItem.Key, err)
 			return err
 		}
 	}
+
+	// Persist the updated status of the migration (if any).
+	if ingress.ObjectMeta.DeletionTimestamp == nil && ingress.Annotations[constants.EdgeLBPoolMigrationStatusAnnotationKey] != "" {
+		previous := ingress.Annotations[constants.EdgeLBPoolMigrationStatusAnnotationKey]
+		translator.SetEdgeLBPoolMigrationStatus(ingress, migration)
+		if ingress.Annotations[constants.EdgeLBPoolMigrationStatusAnnotationKey] != previous {
+			if ingress, err = c.updateIngress(ingress); err != nil {
+				c.logger.Errorf("failed to update the edgelb pool migration status for ingress %q: %v", workItem.Key, err)
+				return err
+			}
+		}
+	}
+
+	// Record the hash of the EdgeLB objects applied for the Ingress resource, so that changes made out-of-band to these EdgeLB objects can be detected.
+	// Record the changes made to the target EdgeLB pool as well, so that the reason why the EdgeLB pool changed can be inspected later on.
+	// Record the changes made out-of-band to the target EdgeLB pool that have been reported (if any) too, so that they are not reported again on every resync.
+	// Record the CPU and memory requests and the size applied to the target EdgeLB pool too, so that these are only applied again in case they change.
+	// Record the JSON merge patch applied to the target EdgeLB pool too, so that the fields it no longer sets can be reset.
+	// The Ingress resource is only updated in case any of these annotations has actually changed.
+	if ingress.ObjectMeta.DeletionTimestamp == nil {
+		hashChanged := translator.SetEdgeLBPoolAppliedStateHash(ingress, t.AppliedStateHash())
+		diffChanged := translator.SetEdgeLBPoolLastAppliedDiff(ingress, t.LastAppliedDiff())
+		driftChanged := translator.SetEdgeLBPoolReportedDriftHash(ingress, t.ReportedDriftHash())
+		resourcesChanged := translator.SetEdgeLBPoolAppliedResources(ingress, t.AppliedResources())
+		patchChanged := translator.SetEdgeLBPoolAppliedPatch(ingress, t.AppliedEdgeLBPoolPatch())
+		if hashChanged || diffChanged || driftChanged || resourcesChanged || patchChanged {
+			if ingress, err = c.updateIngress(ingress); err != nil {
+				c.logger.Errorf("failed to record the state applied to the edgelb pool for ingress %q: %v", workItem.Key, err)
+				return err
+			}
+		}
+	}
+
+	// Remove the "kubernetes.dcos.io/edgelb-cleanup" finalizer in case the Ingress resource has been deleted or is not meant to be provisioned by EdgeLB anymore.
+	if !isManaged {
+		return c.removeFinalizer(ingress, isTombstone, []string{options.EdgeLBPoolName})
+	}
 	return nil
 }
 
+// removeFinalizer removes the "kubernetes.dcos.io/edgelb-cleanup" finalizer from the specified Ingress resource, allowing for it to be removed from the Kubernetes API.
+// The finalizer is only removed after confirming (by reading them from the EdgeLB API server) that none of the specified EdgeLB pools contains EdgeLB objects owned by the Ingress resource.
+// Otherwise, the finalizer is kept and the Ingress resource is re-enqueued after the default resync period.
+// In dry-run mode, the finalizer is removed right away.
+func (c *IngressController) removeFinalizer(ingress *extsv1beta1.Ingress, isTombstone bool, poolNames []string) error {
+	// There is nothing to do in case the Ingress resource has already been removed from the Kubernetes API or doesn't hold the finalizer.
+	if isTombstone || !kubernetesutil.HasFinalizer(ingress, constants.EdgeLBCleanupFinalizer) {
+		return nil
+	}
+	// The EdgeLB objects owned by the Ingress resource are never actually removed in dry-run mode, so the EdgeLB pools are not checked as otherwise the finalizer would never be removed.
+	if manager.IsDryRun(c.edgelbManager) {
+		poolNames = nil
+	}
+	for _, poolName := range poolNames {
+		cleanedUp, err := translator.IsEdgeLBPoolCleanedUpForIngress(c.edgelbManager, poolName, c.clusterName, ingress)
+		if err != nil {
+			return err
+		}
+		if !cleanedUp {
+			c.logger.Warnf("edgelb pool %q still contains edgelb objects owned by ingress %q, keeping the %q finalizer", poolName, kubernetesutil.Key(ingress), constants.EdgeLBCleanupFinalizer)
+			c.enqueueAfter(ingress, constants.DefaultResyncPeriod)
+			return nil
+		}
+	}
+	kubernetesutil.RemoveFinalizer(ingress, constants.EdgeLBCleanupFinalizer)
+	if _, err := c.updateIngress(ingress); err != nil {
+		c.logger.Errorf("failed to remove finalizer from ingress %q: %v", kubernetesutil.Key(ingress), err)
+		return err
+	}
+	c.logger.Debugf("removed the %q finalizer from ingress %q", constants.EdgeLBCleanupFinalizer, kubernetesutil.Key(ingress))
+	return nil
+}
+
+// cleanupAllEdgeLBPools removes the EdgeLB objects owned by the specified Ingress resource from every EdgeLB pool in the EdgeLB pool group, and then removes the "kubernetes.dcos.io/edgelb-cleanup" finalizer from it.
+// It is used whenever the Ingress resource has been deleted but its translation options cannot be computed.
+func (c *IngressController) cleanupAllEdgeLBPools(ingress *extsv1beta1.Ingress, isTombstone bool) error {
+	owner := translator.EdgeLBObjectOwner{
+		Kind:      translator.EdgeLBObjectOwnerKindIngress,
+		Namespace: ingress.Namespace,
+		Name:      ingress.Name,
+	}
+	poolNames, err := translator.RemoveEdgeLBObjectsOwnedBy(c.edgelbManager, c.secretsManager, c.clusterName, owner)
+	if err != nil {
+		c.logger.Errorf("failed to clean up edgelb pools for ingress %q: %v", kubernetesutil.Key(ingress), err)
+		return err
+	}
+	return c.removeFinalizer(ingress, isTombstone, poolNames)
+}
+
+// cleanupEdgeLBPool removes the EdgeLB objects owned by the specified Ingress resource from the EdgeLB pool with the specified name, deleting said EdgeLB pool in case it becomes empty.
+// It is used to clean up the EdgeLB pool an Ingress resource is being migrated from.
+func (c *IngressController) cleanupEdgeLBPool(ingress *extsv1beta1.Ingress, options translator.IngressTranslationOptions, poolName string, er record.EventRecorder) error {
+	// Check whether the EdgeLB pool still exists, as there is nothing to clean up otherwise.
+	ctx, fn := context.WithTimeout(context.Background(), defaultEdgeLBManagerTimeout)
+	defer fn()
+	if _, err := c.edgelbManager.GetPool(ctx, poolName); err != nil {
+		if dklberrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	// Translate a copy of the Ingress resource marked for deletion into the EdgeLB pool, so that the EdgeLB objects it owns are removed.
+	i := ingress.DeepCopy()
+	deletionTimestamp := metav1.Now()
+	i.ObjectMeta.DeletionTimestamp = &deletionTimestamp
+	options.EdgeLBPoolName = poolName
+	_, err := translator.NewIngressTranslator(c.clusterName, i, options, c.kubeCache, c.edgelbManager, c.secretsManager, er).Translate()
+	return err
+}
+
 // enqueueReferencingIngresses enqueues Ingress resources that reference the provided Service resource.
 func (c *IngressController) enqueueReferencingIngresses(service *corev1.Service) {
 	// Grab a list of all Ingress resources in the same namespace as the Service resource.
@@ -196,3 +482,245 @@ func (c *IngressController) enqueueReferencingIngresses(service *corev1.Service)
 		})
 	}
 }
+
+// enqueueIngressesTargetingEndpoints enqueues Ingress resources that reference the Service resource corresponding to the provided Endpoints resource and whose EdgeLB backends target the addresses in said Endpoints resource.
+// This happens whenever the EdgeLB backends of an Ingress resource target pod IPs, or whenever the Service resource has "externalTrafficPolicy: Local".
+// Ingress resources whose EdgeLB backends target node ports on every Ready and schedulable Kubernetes node are not affected by changes to Endpoints resources, and hence are not enqueued.
+func (c *IngressController) enqueueIngressesTargetingEndpoints(endpoints *corev1.Endpoints) {
+	// Check whether the corresponding Service resource has "externalTrafficPolicy: Local".
+	local := false
+	if service, err := c.kubeCache.GetService(endpoints.Namespace, endpoints.Name); err == nil {
+		local = service.Spec.ExternalTrafficPolicy == corev1.ServiceExternalTrafficPolicyTypeLocal
+	}
+	// Grab a list of all Ingress resources in the same namespace as the Endpoints resource.
+	ingresses, err := c.kubeCache.GetIngresses(endpoints.Namespace)
+	if err != nil {
+		c.logger.Errorf("failed to list all ingresses in namespace %q: %v", endpoints.Namespace, err)
+		return
+	}
+	// Iterate over all Ingress resources in the same namespace, checking whether each one targets the addresses in the Endpoints resource and references the corresponding Service resource, and enqueueing it if it does.
+	for _, ingress := range ingresses {
+		obj := ingress
+		if !kubernetesutil.IsEdgeLBIngress(obj) {
+			continue
+		}
+		if !local && obj.Annotations[constants.EdgeLBBackendTargetAnnotationKey] != string(constants.EdgeLBBackendTargetPodIP) {
+			continue
+		}
+		kubernetesutil.ForEachIngresBackend(obj, func(_, _ *string, backend extsv1beta1.IngressBackend) {
+			if backend.ServiceName == endpoints.Name {
+				c.enqueue(obj)
+			}
+		})
+	}
+}
+
+// enqueueAllEdgeLBIngresses enqueues all Ingress resources that should be satisfied by EdgeLB.
+func (c *IngressController) enqueueAllEdgeLBIngresses() {
+	ingresses, err := c.kubeCache.GetIngresses(metav1.NamespaceAll)
+	if err != nil {
+		c.logger.Errorf("failed to list all ingresses: %v", err)
+		return
+	}
+	for _, ingress := range ingresses {
+		if kubernetesutil.IsEdgeLBIngress(ingress) {
+			c.enqueue(ingress)
+		}
+	}
+}
+
+// enqueueIngressesForIngressClass enqueues the "networking.k8s.io/v1" Ingress resources that reference the provided IngressClass resource in their ".spec.ingressClassName" field.
+// Only Ingress resources whose ingress class selects EdgeLB or that hold the "kubernetes.dcos.io/edgelb-cleanup" finalizer are enqueued, so that Ingress resources are also enqueued whenever their IngressClass resource stops selecting EdgeLB.
+func (c *IngressController) enqueueIngressesForIngressClass(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	ingressClass, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+	// Lookup all Ingress resources that reference this IngressClass resource.
+	objs, err := c.ingressIndexer.ByIndex(ingressClassNameIndex, ingressClass.GetName())
+	if err != nil {
+		c.logger.Errorf("failed to list ingresses referencing ingress class %q: %v", ingressClass.GetName(), err)
+		return
+	}
+	for _, obj := range objs {
+		ingress, ok := c.toIngress(obj)
+		if !ok || (!kubernetesutil.IsEdgeLBIngress(ingress) && !kubernetesutil.HasFinalizer(ingress, constants.EdgeLBCleanupFinalizer)) {
+			continue
+		}
+		c.enqueue(ingress)
+	}
+}
+
+// enqueueReferencingIngressesForSecret enqueues Ingress resources that reference the provided Secret resource in their ".spec.tls" field.
+// If "changed" is true, the change is recorded so that it can be reported as an event after the Ingress resources are successfully translated.
+func (c *IngressController) enqueueReferencingIngressesForSecret(secret *corev1.Secret, changed bool) {
+	// Lookup all Ingress resources that reference this Secret resource.
+	objs, err := c.ingressIndexer.ByIndex(ingressTLSSecretIndex, kubernetesutil.Key(secret))
+	if err != nil {
+		c.logger.Errorf("failed to list ingresses referencing secret %q: %v", kubernetesutil.Key(secret), err)
+		return
+	}
+	// Iterate over all referencing Ingress resources, recording the change (if requested) and enqueueing them.
+	// The ingress class of "networking.k8s.io/v1" Ingress resources is checked here rather than when indexing them, as it may change independently of the Ingress resources themselves.
+	for _, obj := range objs {
+		ingress, ok := c.toIngress(obj)
+		if !ok || !kubernetesutil.IsEdgeLBIngress(ingress) {
+			continue
+		}
+		if changed {
+			c.changedSecretsLock.Lock()
+			if _, exists := c.changedSecrets[kubernetesutil.Key(ingress)]; !exists {
+				c.changedSecrets[kubernetesutil.Key(ingress)] = make(map[string]time.Time)
+			}
+			c.changedSecrets[kubernetesutil.Key(ingress)][secret.Name] = time.Now()
+			c.changedSecretsLock.Unlock()
+		}
+		c.enqueue(ingress)
+	}
+}
+
+// reportChangedSecrets emits an event on the specified Ingress resource for each referenced Secret resource whose change has been taken into account by the latest translation.
+// "poolChanged" indicates whether the latest translation actually updated the target EdgeLB pool.
+// If it didn't, the recorded changes didn't require the target EdgeLB pool to be updated (e.g. because they had already been reflected by a previous translation), and are discarded without being reported.
+func (c *IngressController) reportChangedSecrets(key string, ingress *extsv1beta1.Ingress, poolName string, poolChanged bool, er record.EventRecorder) {
+	c.changedSecretsLock.Lock()
+	changed := c.changedSecrets[key]
+	delete(c.changedSecrets, key)
+	c.changedSecretsLock.Unlock()
+	// There's nothing to report if the Ingress resource has been deleted.
+	if ingress.ObjectMeta.DeletionTimestamp != nil {
+		return
+	}
+	// There's nothing to report either if the target EdgeLB pool hasn't been updated.
+	if !poolChanged {
+		if len(changed) > 0 {
+			c.logger.Debugf("edgelb pool %q was not updated for ingress %q, not reporting changes to referenced secrets", poolName, key)
+		}
+		return
+	}
+	for name, changedAt := range changed {
+		er.Eventf(ingress, corev1.EventTypeNormal, constants.ReasonTLSSecretChanged, "secret %q changed at %s, and edgelb pool %q was updated at %s", name, changedAt.Format(time.RFC3339), poolName, time.Now().Format(time.RFC3339))
+	}
+}
+
+// isSecretContentChanged indicates whether the contents of the specified Secret resource changed between the two specified versions.
+// Periodic resyncs (which don't change the resource version) and updates that only change the Secret resource's metadata are not considered to be changes.
+func isSecretContentChanged(oldSecret, newSecret *corev1.Secret) bool {
+	if oldSecret.ResourceVersion == newSecret.ResourceVersion {
+		return false
+	}
+	return oldSecret.Type != newSecret.Type || !reflect.DeepEqual(oldSecret.Data, newSecret.Data)
+}
+
+// toIngress returns the Ingress resource represented by the specified object, as delivered by the ingress informer.
+// "networking.k8s.io/v1" Ingress resources are converted into the representation used internally by dklb, and tombstones are unwrapped.
+// It returns false whenever the object is not an Ingress resource or cannot be converted.
+func (c *IngressController) toIngress(obj interface{}) (*extsv1beta1.Ingress, bool) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	switch o := obj.(type) {
+	case *extsv1beta1.Ingress:
+		return o, true
+	case *unstructured.Unstructured:
+		ingress, err := kubernetesutil.ConvertNetworkingV1Ingress(o, c.kubeCache.GetIngressClass)
+		if err != nil {
+			c.logger.Errorf("failed to convert ingress %q: %v", kubernetesutil.Key(o), err)
+			return nil, false
+		}
+		return ingress, true
+	default:
+		return nil, false
+	}
+}
+
+// updateIngress updates the metadata of the specified Ingress resource (i.e. its annotations and finalizers) using the API it was read from.
+// It returns the updated Ingress resource, in the representation used internally by dklb.
+func (c *IngressController) updateIngress(ingress *extsv1beta1.Ingress) (*extsv1beta1.Ingress, error) {
+	if !kubernetesutil.IsNetworkingV1Ingress(ingress) {
+		return c.kubeClient.ExtensionsV1beta1().Ingresses(ingress.Namespace).Update(ingress)
+	}
+	client := c.dynamicClient.Resource(kubernetesutil.NetworkingV1IngressResource).Namespace(ingress.Namespace)
+	obj, err := client.Get(ingress.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	// Copy the resource version as well, so that the update fails in case the Ingress resource has changed since it was read.
+	kubernetesutil.SetNetworkingV1IngressMetadata(obj, ingress)
+	if obj, err = client.Update(obj, metav1.UpdateOptions{}); err != nil {
+		return nil, err
+	}
+	return kubernetesutil.ConvertNetworkingV1Ingress(obj, c.kubeCache.GetIngressClass)
+}
+
+// updateIngressStatus updates the status of the specified Ingress resource using the API it was read from.
+// It returns the updated Ingress resource, in the representation used internally by dklb.
+func (c *IngressController) updateIngressStatus(ingress *extsv1beta1.Ingress) (*extsv1beta1.Ingress, error) {
+	if !kubernetesutil.IsNetworkingV1Ingress(ingress) {
+		return c.kubeClient.ExtensionsV1beta1().Ingresses(ingress.Namespace).UpdateStatus(ingress)
+	}
+	client := c.dynamicClient.Resource(kubernetesutil.NetworkingV1IngressResource).Namespace(ingress.Namespace)
+	obj, err := client.Get(ingress.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	obj.SetResourceVersion(ingress.ResourceVersion)
+	if err := kubernetesutil.SetNetworkingV1IngressStatus(obj, ingress); err != nil {
+		return nil, err
+	}
+	if obj, err = client.UpdateStatus(obj, metav1.UpdateOptions{}); err != nil {
+		return nil, err
+	}
+	return kubernetesutil.ConvertNetworkingV1Ingress(obj, c.kubeCache.GetIngressClass)
+}
+
+// indexIngress is the index function used to lookup Ingress resources referencing a given Secret resource.
+// "networking.k8s.io/v1" Ingress resources are indexed regardless of their ingress class, as the IngressClass resource they reference may change independently of them.
+func (c *IngressController) indexIngress(obj interface{}) ([]string, error) {
+	if _, ok := obj.(*extsv1beta1.Ingress); ok {
+		return indexIngressByTLSSecret(obj)
+	}
+	ingress, ok := c.toIngress(obj)
+	if !ok {
+		return []string{}, nil
+	}
+	return computeIngressTLSSecretKeys(ingress), nil
+}
+
+// indexIngressByTLSSecret returns the keys of the Secret resources referenced in the ".spec.tls" field of the specified Ingress resource.
+// Ingress resources that are not meant to be provisioned by EdgeLB are not indexed.
+func indexIngressByTLSSecret(obj interface{}) ([]string, error) {
+	ingress, ok := obj.(*extsv1beta1.Ingress)
+	if !ok || !kubernetesutil.IsEdgeLBIngress(ingress) {
+		return []string{}, nil
+	}
+	return computeIngressTLSSecretKeys(ingress), nil
+}
+
+// indexIngressByIngressClassName returns the name of the IngressClass resource referenced in the ".spec.ingressClassName" field of the specified "networking.k8s.io/v1" Ingress resource.
+// "extensions/v1beta1" Ingress resources, as well as "networking.k8s.io/v1" Ingress resources that don't reference an IngressClass resource, are not indexed.
+func indexIngressByIngressClassName(obj interface{}) ([]string, error) {
+	ingress, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return []string{}, nil
+	}
+	name, found, err := unstructured.NestedString(ingress.Object, "spec", "ingressClassName")
+	if err != nil || !found || name == "" {
+		return []string{}, nil
+	}
+	return []string{name}, nil
+}
+
+// computeIngressTLSSecretKeys returns the keys of the Secret resources referenced in the ".spec.tls" field of the specified Ingress resource.
+func computeIngressTLSSecretKeys(ingress *extsv1beta1.Ingress) []string {
+	res := make([]string, 0, len(ingress.Spec.TLS))
+	for _, tls := range ingress.Spec.TLS {
+		if tls.SecretName != "" {
+			res = append(res, fmt.Sprintf("%s/%s", ingress.Namespace, tls.SecretName))
+		}
+	}
+	return res
+}
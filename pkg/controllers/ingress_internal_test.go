@@ -0,0 +1,576 @@
+package controllers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mesosphere/dcos-edge-lb/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	corev1 "k8s.io/api/core/v1"
+	extsv1beta1 "k8s.io/api/extensions/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+
+	dklbcache "github.com/mesosphere/dklb/pkg/cache"
+	"github.com/mesosphere/dklb/pkg/constants"
+	"github.com/mesosphere/dklb/pkg/edgelb/manager"
+	kubernetesutil "github.com/mesosphere/dklb/pkg/util/kubernetes"
+	cachetestutil "github.com/mesosphere/dklb/test/util/cache"
+	secretstestutil "github.com/mesosphere/dklb/test/util/dcos/secrets"
+	edgelbmanagertestutil "github.com/mesosphere/dklb/test/util/edgelb/manager"
+	edgelbpooltestutil "github.com/mesosphere/dklb/test/util/edgelb/pool"
+	ingresstestutil "github.com/mesosphere/dklb/test/util/kubernetes/ingress"
+)
+
+const (
+	// testClusterName is the name of the Kubernetes cluster used in tests.
+	testClusterName = "dev/kubernetes01"
+	// testEdgeLBPoolGroup is the DC/OS service group in which EdgeLB pools used in tests are created.
+	testEdgeLBPoolGroup = "dcos-edgelb/pools"
+)
+
+// TestIngressControllerCleanupAllEdgeLBPools tests that the finalizer is removed from a deleted Ingress resource whose translation options cannot be computed once the EdgeLB objects it owns have been removed from every EdgeLB pool.
+func TestIngressControllerCleanupAllEdgeLBPools(t *testing.T) {
+	tests := []struct {
+		description       string
+		updateError       error
+		expectedError     bool
+		expectedFinalizer bool
+	}{
+		{
+			description:       "edgelb pool successfully updated",
+			expectedFinalizer: false,
+		},
+		{
+			description:       "edgelb pool cannot be updated",
+			updateError:       errors.New("connection refused"),
+			expectedError:     true,
+			expectedFinalizer: true,
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		// Create a deleted Ingress resource holding the finalizer and having an invalid value for the name of the target EdgeLB pool.
+		ingress := ingresstestutil.DummyIngressResource("foo", "bar", func(ingress *extsv1beta1.Ingress) {
+			deletionTimestamp := metav1.Now()
+			ingress.ObjectMeta.DeletionTimestamp = &deletionTimestamp
+			ingress.Annotations = map[string]string{
+				constants.EdgeLBIngressClassAnnotationKey: constants.EdgeLBIngressClassAnnotationValue,
+				constants.EdgeLBPoolNameAnnotationKey:     "_invalid_",
+			}
+			kubernetesutil.AddFinalizer(ingress, constants.EdgeLBCleanupFinalizer)
+		})
+		kubeClient := fake.NewSimpleClientset(ingress)
+		poolGroup := testEdgeLBPoolGroup
+		pool := edgelbpooltestutil.DummyEdgeLBPool("baz", func(p *models.V2Pool) {
+			p.Namespace = &poolGroup
+			p.Haproxy.Backends = []*models.V2Backend{
+				{Name: "dev.kubernetes01:foo:bar:qux:80"},
+				{Name: "dev.kubernetes01:foo:other:qux:80"},
+			}
+		})
+		m := new(edgelbmanagertestutil.MockEdgeLBManager)
+		m.On("GetPools", mock.Anything).Return([]*models.V2Pool{pool}, nil)
+		m.On("GetPool", mock.Anything, "baz").Return(pool, nil)
+		m.On("PoolGroup").Return(testEdgeLBPoolGroup)
+		m.On("UpdatePool", mock.Anything).Return(pool, test.updateError)
+		c := &IngressController{
+			genericController: newGenericController(testClusterName, ingressControllerName, ingressControllerThreadiness),
+			kubeClient:        kubeClient,
+			edgelbManager:     m,
+			secretsManager:    new(secretstestutil.MockSecretsManager),
+		}
+		err := c.cleanupAllEdgeLBPools(ingress, false)
+		if test.expectedError {
+			assert.Error(t, err)
+		} else {
+			assert.NoError(t, err)
+		}
+		res, err := kubeClient.ExtensionsV1beta1().Ingresses("foo").Get("bar", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, test.expectedFinalizer, kubernetesutil.HasFinalizer(res, constants.EdgeLBCleanupFinalizer))
+	}
+}
+
+// TestIngressControllerCleanupAllEdgeLBPoolsInDryRunMode tests that the finalizer is removed from a deleted Ingress resource in dry-run mode, even though the EdgeLB objects it owns are never actually removed from the EdgeLB pool.
+func TestIngressControllerCleanupAllEdgeLBPoolsInDryRunMode(t *testing.T) {
+	// Create a deleted Ingress resource holding the finalizer and having an invalid value for the name of the target EdgeLB pool.
+	ingress := ingresstestutil.DummyIngressResource("foo", "bar", func(ingress *extsv1beta1.Ingress) {
+		deletionTimestamp := metav1.Now()
+		ingress.ObjectMeta.DeletionTimestamp = &deletionTimestamp
+		ingress.Annotations = map[string]string{
+			constants.EdgeLBIngressClassAnnotationKey: constants.EdgeLBIngressClassAnnotationValue,
+			constants.EdgeLBPoolNameAnnotationKey:     "_invalid_",
+		}
+		kubernetesutil.AddFinalizer(ingress, constants.EdgeLBCleanupFinalizer)
+	})
+	kubeClient := fake.NewSimpleClientset(ingress)
+	// Use a different instance of the EdgeLB pool for every call, so that the EdgeLB objects owned by the Ingress resource are still reported as existing after the (recorded) update.
+	newPool := func() *models.V2Pool {
+		poolGroup := testEdgeLBPoolGroup
+		return edgelbpooltestutil.DummyEdgeLBPool("baz", func(p *models.V2Pool) {
+			p.Namespace = &poolGroup
+			p.Haproxy.Backends = []*models.V2Backend{
+				{Name: "dev.kubernetes01:foo:bar:qux:80"},
+				{Name: "dev.kubernetes01:foo:other:qux:80"},
+			}
+		})
+	}
+	m := new(edgelbmanagertestutil.MockEdgeLBManager)
+	m.On("GetPools", mock.Anything).Return([]*models.V2Pool{newPool()}, nil)
+	m.On("GetPool", mock.Anything, "baz").Return(newPool(), nil)
+	m.On("PoolGroup").Return(testEdgeLBPoolGroup)
+	d := manager.NewDryRunEdgeLBManager(m)
+	c := &IngressController{
+		genericController: newGenericController(testClusterName, ingressControllerName, ingressControllerThreadiness),
+		kubeClient:        kubeClient,
+		edgelbManager:     d,
+		secretsManager:    new(secretstestutil.MockSecretsManager),
+	}
+	assert.NoError(t, c.cleanupAllEdgeLBPools(ingress, false))
+	res, err := kubeClient.ExtensionsV1beta1().Ingresses("foo").Get("bar", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.False(t, kubernetesutil.HasFinalizer(res, constants.EdgeLBCleanupFinalizer))
+	// Make sure that the update of the EdgeLB pool was recorded instead of being made.
+	assert.Len(t, d.Operations(), 1)
+	m.AssertNotCalled(t, "UpdatePool", mock.Anything)
+}
+
+// edgeLBIngressWithTLSSecrets returns an Ingress resource that is meant to be provisioned by EdgeLB and that references the Secret resources with the specified names in its ".spec.tls" field.
+func edgeLBIngressWithTLSSecrets(namespace, name string, secretNames ...string) *extsv1beta1.Ingress {
+	return ingresstestutil.DummyIngressResource(namespace, name, func(ingress *extsv1beta1.Ingress) {
+		ingress.Annotations = map[string]string{
+			constants.EdgeLBIngressClassAnnotationKey: constants.EdgeLBIngressClassAnnotationValue,
+		}
+		for _, secretName := range secretNames {
+			ingress.Spec.TLS = append(ingress.Spec.TLS, extsv1beta1.IngressTLS{SecretName: secretName})
+		}
+	})
+}
+
+// networkingV1Ingress returns a "networking.k8s.io/v1" Ingress resource referencing the IngressClass resource with the specified name (if any) and the Secret resources with the specified names in its ".spec.tls" field.
+// If "withFinalizer" is true, the Ingress resource holds the "kubernetes.dcos.io/edgelb-cleanup" finalizer.
+func networkingV1Ingress(namespace, name, ingressClassName string, withFinalizer bool, secretNames ...string) *unstructured.Unstructured {
+	spec := map[string]interface{}{}
+	if ingressClassName != "" {
+		spec["ingressClassName"] = ingressClassName
+	}
+	if len(secretNames) > 0 {
+		tls := make([]interface{}, 0, len(secretNames))
+		for _, secretName := range secretNames {
+			tls = append(tls, map[string]interface{}{"secretName": secretName})
+		}
+		spec["tls"] = tls
+	}
+	res := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "networking.k8s.io/v1",
+			"kind":       "Ingress",
+			"metadata": map[string]interface{}{
+				"namespace": namespace,
+				"name":      name,
+			},
+			"spec": spec,
+		},
+	}
+	if withFinalizer {
+		res.SetFinalizers([]string{constants.EdgeLBCleanupFinalizer})
+	}
+	return res
+}
+
+// ingressClass returns a "networking.k8s.io/v1" IngressClass resource with the specified name and controller.
+func ingressClass(name, controller string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "networking.k8s.io/v1",
+			"kind":       "IngressClass",
+			"metadata": map[string]interface{}{
+				"name": name,
+			},
+			"spec": map[string]interface{}{
+				"controller": controller,
+			},
+		},
+	}
+}
+
+// fakeIngressClassCache is a Kubernetes resource cache that serves a fixed set of IngressClass resources.
+type fakeIngressClassCache struct {
+	dklbcache.KubernetesResourceCache
+	// ingressClasses holds the IngressClass resources served by the cache, indexed by name.
+	ingressClasses map[string]*unstructured.Unstructured
+}
+
+// newFakeIngressClassCache returns a Kubernetes resource cache that serves the specified IngressClass resources.
+func newFakeIngressClassCache(ingressClasses ...*unstructured.Unstructured) *fakeIngressClassCache {
+	res := &fakeIngressClassCache{
+		KubernetesResourceCache: cachetestutil.NewFakeKubernetesResourceCache(),
+		ingressClasses:          make(map[string]*unstructured.Unstructured, len(ingressClasses)),
+	}
+	for _, obj := range ingressClasses {
+		res.ingressClasses[obj.GetName()] = obj
+	}
+	return res
+}
+
+// GetIngressClass returns the IngressClass resource with the specified name.
+func (c *fakeIngressClassCache) GetIngressClass(name string) (*unstructured.Unstructured, error) {
+	if obj, exists := c.ingressClasses[name]; exists {
+		return obj, nil
+	}
+	return nil, apierrors.NewNotFound(kubernetesutil.NetworkingV1IngressClassResource.GroupResource(), name)
+}
+
+// newTestIngressController returns an Ingress controller whose indexer holds the specified Ingress resources, and whose work queue makes enqueued items immediately available.
+func newTestIngressController(ingresses ...*extsv1beta1.Ingress) *IngressController {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{ingressTLSSecretIndex: indexIngressByTLSSecret, ingressClassNameIndex: indexIngressByIngressClassName})
+	for _, ingress := range ingresses {
+		_ = indexer.Add(ingress)
+	}
+	c := &IngressController{
+		genericController: newGenericController(testClusterName, ingressControllerName, ingressControllerThreadiness),
+		ingressIndexer:    indexer,
+		changedSecrets:    make(map[string]map[string]time.Time),
+	}
+	c.workqueue = workqueue.NewRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(0, 0))
+	return c
+}
+
+// TestIndexIngressByTLSSecret tests the "indexIngressByTLSSecret" function.
+func TestIndexIngressByTLSSecret(t *testing.T) {
+	tests := []struct {
+		description  string
+		obj          interface{}
+		expectedKeys []string
+	}{
+		{
+			description:  "object that is not an ingress",
+			obj:          &corev1.Secret{},
+			expectedKeys: []string{},
+		},
+		{
+			description:  "ingress not meant to be provisioned by edgelb",
+			obj:          ingresstestutil.DummyIngressResource("foo", "bar", ingresstestutil.WithAnnotations(nil)),
+			expectedKeys: []string{},
+		},
+		{
+			description:  "edgelb ingress without tls",
+			obj:          edgeLBIngressWithTLSSecrets("foo", "bar"),
+			expectedKeys: []string{},
+		},
+		{
+			description:  "edgelb ingress referencing two secrets",
+			obj:          edgeLBIngressWithTLSSecrets("foo", "bar", "tls-1", "", "tls-2"),
+			expectedKeys: []string{"foo/tls-1", "foo/tls-2"},
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		keys, err := indexIngressByTLSSecret(test.obj)
+		assert.NoError(t, err)
+		assert.Equal(t, test.expectedKeys, keys)
+	}
+}
+
+// TestIngressControllerIndexIngress tests that both "extensions/v1beta1" and "networking.k8s.io/v1" Ingress resources are indexed by the Secret resources they reference.
+func TestIngressControllerIndexIngress(t *testing.T) {
+	tests := []struct {
+		description  string
+		obj          interface{}
+		expectedKeys []string
+	}{
+		{
+			description:  "extensions/v1beta1 ingress not meant to be provisioned by edgelb",
+			obj:          ingresstestutil.DummyIngressResource("foo", "bar", ingresstestutil.WithAnnotations(nil)),
+			expectedKeys: []string{},
+		},
+		{
+			description:  "extensions/v1beta1 edgelb ingress referencing a secret",
+			obj:          edgeLBIngressWithTLSSecrets("foo", "bar", "tls-1"),
+			expectedKeys: []string{"foo/tls-1"},
+		},
+		{
+			description:  "networking.k8s.io/v1 ingress referencing a secret and an ingress class that does not exist",
+			obj:          networkingV1Ingress("foo", "bar", "edgelb", false, "tls-1"),
+			expectedKeys: []string{"foo/tls-1"},
+		},
+	}
+	c := newTestIngressController()
+	c.kubeCache = cachetestutil.NewFakeKubernetesResourceCache()
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		keys, err := c.indexIngress(test.obj)
+		assert.NoError(t, err)
+		assert.Equal(t, test.expectedKeys, keys)
+	}
+}
+
+// TestIndexIngressByIngressClassName tests the "indexIngressByIngressClassName" function.
+func TestIndexIngressByIngressClassName(t *testing.T) {
+	tests := []struct {
+		description  string
+		obj          interface{}
+		expectedKeys []string
+	}{
+		{
+			description:  "extensions/v1beta1 ingress",
+			obj:          edgeLBIngressWithTLSSecrets("foo", "bar"),
+			expectedKeys: []string{},
+		},
+		{
+			description:  "networking.k8s.io/v1 ingress not referencing an ingress class",
+			obj:          networkingV1Ingress("foo", "bar", "", false),
+			expectedKeys: []string{},
+		},
+		{
+			description:  "networking.k8s.io/v1 ingress referencing an ingress class",
+			obj:          networkingV1Ingress("foo", "bar", "edgelb", false),
+			expectedKeys: []string{"edgelb"},
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		keys, err := indexIngressByIngressClassName(test.obj)
+		assert.NoError(t, err)
+		assert.Equal(t, test.expectedKeys, keys)
+	}
+}
+
+// TestEnqueueIngressesForIngressClass tests that the "networking.k8s.io/v1" Ingress resources referencing an IngressClass resource are enqueued whenever said IngressClass resource changes.
+func TestEnqueueIngressesForIngressClass(t *testing.T) {
+	tests := []struct {
+		description    string
+		ingressClasses []*unstructured.Unstructured
+		obj            interface{}
+		expectedKeys   []string
+	}{
+		{
+			description:    "ingress class selecting edgelb",
+			ingressClasses: []*unstructured.Unstructured{ingressClass("edgelb", constants.EdgeLBIngressClassController)},
+			obj:            ingressClass("edgelb", constants.EdgeLBIngressClassController),
+			expectedKeys:   []string{"foo/ingress-1", "foo/ingress-2"},
+		},
+		{
+			description:    "ingress class not selecting edgelb anymore",
+			ingressClasses: []*unstructured.Unstructured{ingressClass("edgelb", "example.com/other")},
+			obj:            ingressClass("edgelb", "example.com/other"),
+			expectedKeys:   []string{"foo/ingress-2"},
+		},
+		{
+			description:    "deleted ingress class selecting edgelb",
+			ingressClasses: []*unstructured.Unstructured{},
+			obj:            cache.DeletedFinalStateUnknown{Key: "edgelb", Obj: ingressClass("edgelb", constants.EdgeLBIngressClassController)},
+			expectedKeys:   []string{"foo/ingress-2"},
+		},
+		{
+			description:    "ingress class not referenced by any edgelb ingress",
+			ingressClasses: []*unstructured.Unstructured{ingressClass("edgelb", constants.EdgeLBIngressClassController), ingressClass("nginx", "k8s.io/ingress-nginx")},
+			obj:            ingressClass("nginx", "k8s.io/ingress-nginx"),
+			expectedKeys:   []string{},
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		c := newTestIngressController()
+		c.kubeCache = newFakeIngressClassCache(test.ingressClasses...)
+		for _, obj := range []*unstructured.Unstructured{
+			networkingV1Ingress("foo", "ingress-1", "edgelb", false),
+			// An Ingress resource holding the finalizer, which must be enqueued even if the IngressClass resource doesn't select EdgeLB anymore.
+			networkingV1Ingress("foo", "ingress-2", "edgelb", true),
+			networkingV1Ingress("foo", "ingress-3", "nginx", false),
+			networkingV1Ingress("foo", "ingress-4", "", false),
+		} {
+			_ = c.ingressIndexer.Add(obj)
+		}
+		c.enqueueIngressesForIngressClass(test.obj)
+		// Make sure that all the expected Ingress resources (and only these) have been enqueued.
+		keys := make([]string, 0, c.workqueue.Len())
+		for c.workqueue.Len() > 0 {
+			item, _ := c.workqueue.Get()
+			keys = append(keys, item.(WorkItem).Key)
+			c.workqueue.Done(item)
+		}
+		assert.ElementsMatch(t, test.expectedKeys, keys)
+	}
+}
+
+// TestIsSecretContentChanged tests the "isSecretContentChanged" function.
+func TestIsSecretContentChanged(t *testing.T) {
+	secret := func(resourceVersion string, secretType corev1.SecretType, crt string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "tls", ResourceVersion: resourceVersion},
+			Type:       secretType,
+			Data:       map[string][]byte{corev1.TLSCertKey: []byte(crt)},
+		}
+	}
+	tests := []struct {
+		description     string
+		oldSecret       *corev1.Secret
+		newSecret       *corev1.Secret
+		expectedChanged bool
+	}{
+		{
+			description:     "periodic resync",
+			oldSecret:       secret("1", corev1.SecretTypeTLS, "crt"),
+			newSecret:       secret("1", corev1.SecretTypeTLS, "crt"),
+			expectedChanged: false,
+		},
+		{
+			description:     "metadata-only update",
+			oldSecret:       secret("1", corev1.SecretTypeTLS, "crt"),
+			newSecret:       secret("2", corev1.SecretTypeTLS, "crt"),
+			expectedChanged: false,
+		},
+		{
+			description:     "rotated certificate",
+			oldSecret:       secret("1", corev1.SecretTypeTLS, "crt"),
+			newSecret:       secret("2", corev1.SecretTypeTLS, "new-crt"),
+			expectedChanged: true,
+		},
+		{
+			description:     "changed type",
+			oldSecret:       secret("1", corev1.SecretTypeOpaque, "crt"),
+			newSecret:       secret("2", corev1.SecretTypeTLS, "crt"),
+			expectedChanged: true,
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		assert.Equal(t, test.expectedChanged, isSecretContentChanged(test.oldSecret, test.newSecret))
+	}
+}
+
+// TestEnqueueReferencingIngressesForSecret tests the "enqueueReferencingIngressesForSecret" function.
+func TestEnqueueReferencingIngressesForSecret(t *testing.T) {
+	tests := []struct {
+		description            string
+		secret                 *corev1.Secret
+		changed                bool
+		expectedKeys           []string
+		expectedChangedSecrets map[string][]string
+	}{
+		{
+			description:            "secret not referenced by any ingress",
+			secret:                 &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "unreferenced"}},
+			changed:                true,
+			expectedKeys:           []string{},
+			expectedChangedSecrets: map[string][]string{},
+		},
+		{
+			description:            "secret with the same name in a different namespace",
+			secret:                 &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "bar", Name: "shared"}},
+			changed:                true,
+			expectedKeys:           []string{},
+			expectedChangedSecrets: map[string][]string{},
+		},
+		{
+			description:            "added secret referenced by two ingresses",
+			secret:                 &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "shared"}},
+			changed:                false,
+			expectedKeys:           []string{"foo/ingress-1", "foo/ingress-2"},
+			expectedChangedSecrets: map[string][]string{},
+		},
+		{
+			description:  "changed secret referenced by two ingresses",
+			secret:       &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "shared"}},
+			changed:      true,
+			expectedKeys: []string{"foo/ingress-1", "foo/ingress-2"},
+			expectedChangedSecrets: map[string][]string{
+				"foo/ingress-1": {"shared"},
+				"foo/ingress-2": {"shared"},
+			},
+		},
+		{
+			description:  "changed secret referenced by a single ingress",
+			secret:       &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "dedicated"}},
+			changed:      true,
+			expectedKeys: []string{"foo/ingress-2"},
+			expectedChangedSecrets: map[string][]string{
+				"foo/ingress-2": {"dedicated"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		c := newTestIngressController(
+			edgeLBIngressWithTLSSecrets("foo", "ingress-1", "shared"),
+			edgeLBIngressWithTLSSecrets("foo", "ingress-2", "shared", "dedicated"),
+			// An Ingress resource not meant to be provisioned by EdgeLB, which must never be enqueued.
+			ingresstestutil.DummyIngressResource("foo", "ingress-3", func(ingress *extsv1beta1.Ingress) {
+				ingress.Spec.TLS = []extsv1beta1.IngressTLS{{SecretName: "shared"}}
+			}),
+		)
+		c.enqueueReferencingIngressesForSecret(test.secret, test.changed)
+		// Make sure that all the expected Ingress resources (and only these) have been enqueued.
+		keys := make([]string, 0, c.workqueue.Len())
+		for c.workqueue.Len() > 0 {
+			item, _ := c.workqueue.Get()
+			keys = append(keys, item.(WorkItem).Key)
+			c.workqueue.Done(item)
+		}
+		assert.ElementsMatch(t, test.expectedKeys, keys)
+		// Make sure that the change has been recorded for every enqueued Ingress resource (if requested).
+		changedSecrets := make(map[string][]string, len(c.changedSecrets))
+		for key, secrets := range c.changedSecrets {
+			for name := range secrets {
+				changedSecrets[key] = append(changedSecrets[key], name)
+			}
+		}
+		assert.Equal(t, test.expectedChangedSecrets, changedSecrets)
+	}
+}
+
+// TestReportChangedSecrets tests the "reportChangedSecrets" function.
+func TestReportChangedSecrets(t *testing.T) {
+	tests := []struct {
+		description        string
+		deleted            bool
+		poolChanged        bool
+		expectedEventCount int
+	}{
+		{
+			description:        "edgelb pool updated",
+			poolChanged:        true,
+			expectedEventCount: 2,
+		},
+		{
+			description:        "edgelb pool not updated",
+			poolChanged:        false,
+			expectedEventCount: 0,
+		},
+		{
+			description:        "ingress deleted",
+			deleted:            true,
+			poolChanged:        true,
+			expectedEventCount: 0,
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		ingress := edgeLBIngressWithTLSSecrets("foo", "bar", "tls-1", "tls-2")
+		if test.deleted {
+			deletionTimestamp := metav1.Now()
+			ingress.ObjectMeta.DeletionTimestamp = &deletionTimestamp
+		}
+		c := newTestIngressController(ingress)
+		c.enqueueReferencingIngressesForSecret(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "tls-1"}}, true)
+		c.enqueueReferencingIngressesForSecret(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "tls-2"}}, true)
+		recorder := record.NewFakeRecorder(test.expectedEventCount + 1)
+		c.reportChangedSecrets("foo/bar", ingress, "baz", test.poolChanged, recorder)
+		// Make sure that the expected number of events has been emitted.
+		assert.Len(t, recorder.Events, test.expectedEventCount)
+		for idx := 0; idx < test.expectedEventCount; idx++ {
+			assert.Contains(t, <-recorder.Events, constants.ReasonTLSSecretChanged)
+		}
+		// Make sure that recorded changes are always discarded, so that they are not reported by a later translation.
+		assert.Empty(t, c.changedSecrets)
+	}
+}
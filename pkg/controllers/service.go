@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -12,10 +13,12 @@ import (
 	corev1informers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 
 	dklbcache "github.com/mesosphere/dklb/pkg/cache"
 	"github.com/mesosphere/dklb/pkg/constants"
 	"github.com/mesosphere/dklb/pkg/edgelb/manager"
+	dklberrors "github.com/mesosphere/dklb/pkg/errors"
 	"github.com/mesosphere/dklb/pkg/metrics"
 	"github.com/mesosphere/dklb/pkg/translator"
 	kubernetesutil "github.com/mesosphere/dklb/pkg/util/kubernetes"
@@ -42,7 +45,7 @@ type ServiceController struct {
 }
 
 // NewServiceController creates a new instance of the EdgeLB service controller.
-func NewServiceController(clusterName string, kubeClient kubernetes.Interface, serviceInformer corev1informers.ServiceInformer, configMapInformer corev1informers.ConfigMapInformer, kubeCache dklbcache.KubernetesResourceCache, edgelbManager manager.EdgeLBManager) *ServiceController {
+func NewServiceController(clusterName string, kubeClient kubernetes.Interface, serviceInformer corev1informers.ServiceInformer, configMapInformer corev1informers.ConfigMapInformer, endpointsInformer corev1informers.EndpointsInformer, nodeInformer corev1informers.NodeInformer, kubeCache dklbcache.KubernetesResourceCache, edgelbManager manager.EdgeLBManager) *ServiceController {
 	// Create a new instance of the service controller with the specified name and threadiness.
 	c := &ServiceController{
 		genericController: newGenericController(clusterName, serviceControllerName, serviceControllerThreadiness),
@@ -65,10 +68,11 @@ func NewServiceController(clusterName string, kubeClient kubernetes.Interface, s
 	// * It was updated ("MODIFIED") and either the old or the new types (or both) are of type "LoadBalancer".
 	//   * This allows for handling the cases in which the type of a service changes.
 	// * It was deleted ("DELETED") and was of type "LoadBalancer".
+	// Service resources holding the "kubernetes.dcos.io/edgelb-cleanup" finalizer are always enqueued, so that the finalizer can be removed once the EdgeLB objects they own have been removed.
 	serviceInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
 			svc := obj.(*corev1.Service)
-			if svc.Spec.Type != corev1.ServiceTypeLoadBalancer {
+			if svc.Spec.Type != corev1.ServiceTypeLoadBalancer && !kubernetesutil.HasFinalizer(svc, constants.EdgeLBCleanupFinalizer) {
 				return
 			}
 			c.enqueue(svc)
@@ -76,7 +80,7 @@ func NewServiceController(clusterName string, kubeClient kubernetes.Interface, s
 		UpdateFunc: func(oldObj, newObj interface{}) {
 			oldSvc := oldObj.(*corev1.Service)
 			newSvc := newObj.(*corev1.Service)
-			if oldSvc.Spec.Type != corev1.ServiceTypeLoadBalancer && newSvc.Spec.Type != corev1.ServiceTypeLoadBalancer {
+			if oldSvc.Spec.Type != corev1.ServiceTypeLoadBalancer && newSvc.Spec.Type != corev1.ServiceTypeLoadBalancer && !kubernetesutil.HasFinalizer(newSvc, constants.EdgeLBCleanupFinalizer) {
 				return
 			}
 			c.enqueue(newSvc)
@@ -102,6 +106,33 @@ func NewServiceController(clusterName string, kubeClient kubernetes.Interface, s
 			c.enqueueReferencingServices(obj.(*corev1.ConfigMap))
 		},
 	})
+	// Setup an event handler to inform us when Endpoints resources change.
+	// This allows us to enqueue the corresponding Service resource whenever its EdgeLB backends target the pods backing it (or the Kubernetes nodes hosting them) and a pod is created, deleted or becomes ready.
+	// Periodic resyncs are ignored, as they don't require the target EdgeLB pool to be updated.
+	endpointsInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.enqueueServiceTargetingEndpoints(obj.(*corev1.Endpoints))
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldEndpoints := oldObj.(*corev1.Endpoints)
+			newEndpoints := newObj.(*corev1.Endpoints)
+			if oldEndpoints.ResourceVersion == newEndpoints.ResourceVersion {
+				return
+			}
+			c.enqueueServiceTargetingEndpoints(newEndpoints)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if endpoints, ok := obj.(*corev1.Endpoints); ok {
+				c.enqueueServiceTargetingEndpoints(endpoints)
+			}
+		},
+	})
+	// Setup an event handler to inform us when Node resources change.
+	// This allows us to enqueue all Service resources of type "LoadBalancer" whenever a Kubernetes node is added, removed, cordoned, uncordoned or changes readiness, so that the set of targeted Kubernetes nodes is kept up-to-date.
+	nodeInformer.Informer().AddEventHandler(nodeEventHandler(c.enqueueAllLoadBalancerServices))
 
 	// Return the instance created above.
 	return c
@@ -121,6 +152,9 @@ func (c *ServiceController) processQueueItem(workItem WorkItem) error {
 		return nil
 	}
 
+	// isTombstone holds whether the Service resource has already been removed from the Kubernetes API.
+	isTombstone := false
+
 	// Get the Service resource with the specified namespace and name.
 	service, err := c.kubeCache.GetService(namespace, name)
 	if err == nil {
@@ -138,6 +172,7 @@ func (c *ServiceController) processQueueItem(workItem WorkItem) error {
 		}
 		// Create a deep copy of the tombstone in order to avoid mutating the cache.
 		service = workItem.Tombstone.(*corev1.Service).DeepCopy()
+		isTombstone = true
 		// Set the current timestamp as the value of ".metadata.deletionTimestamp" so the translator can understand that the resource has been deleted.
 		deletionTimestamp := metav1.NewTime(startTime)
 		service.ObjectMeta.DeletionTimestamp = &deletionTimestamp
@@ -152,31 +187,188 @@ func (c *ServiceController) processQueueItem(workItem WorkItem) error {
 		// Emit an event and log an error, but do not re-enqueue as the resource's spec was found to be invalid.
 		er.Eventf(service, corev1.EventTypeWarning, constants.ReasonInvalidAnnotations, "the resource's annotations are not valid: %v", err)
 		c.logger.Errorf("failed to compute translation options for service %q: %v", workItem.Key, err)
+		// In case the Service resource has been deleted, the EdgeLB pool it targets cannot be determined from its annotations.
+		// Hence, remove the EdgeLB objects it owns from every EdgeLB pool instead, so that the finalizer can still be removed.
+		if service.ObjectMeta.DeletionTimestamp != nil && kubernetesutil.HasFinalizer(service, constants.EdgeLBCleanupFinalizer) {
+			return c.cleanupAllEdgeLBPools(service, isTombstone)
+		}
 		return nil
 	}
 
 	// Output some tracing information about the computed set of options.
 	prettyprint.LogfSpew(log.Tracef, options, "computed service translation options for %q", workItem.Key)
 
+	// isManaged holds whether the Service resource must be provisioned by EdgeLB (i.e. whether it is of type "LoadBalancer" and hasn't been deleted).
+	isManaged := service.ObjectMeta.DeletionTimestamp == nil && service.Spec.Type == corev1.ServiceTypeLoadBalancer
+	// Add the "kubernetes.dcos.io/edgelb-cleanup" finalizer to the Service resource before translating it, so that it cannot be removed from the Kubernetes API before the EdgeLB objects it owns are removed.
+	if isManaged && kubernetesutil.AddFinalizer(service, constants.EdgeLBCleanupFinalizer) {
+		if service, err = c.kubeClient.CoreV1().Services(service.Namespace).Update(service); err != nil {
+			c.logger.Errorf("failed to add finalizer to service %q: %v", workItem.Key, err)
+			return err
+		}
+	}
+
 	// Perform translation of the Service resource into an EdgeLB pool.
-	status, err := translator.NewServiceTranslator(c.clusterName, service, *options, c.kubeCache, c.edgelbManager).Translate()
+	t := translator.NewServiceTranslator(c.clusterName, service, *options, c.kubeCache, c.edgelbManager, er)
+	status, err := t.Translate()
 	if err != nil {
 		er.Eventf(service, corev1.EventTypeWarning, constants.ReasonTranslationError, "failed to translate service: %v", err)
 		c.logger.Errorf("failed to translate service %q: %v", workItem.Key, err)
 		return err
 	}
 
+	// Advance the migration of the Service resource between EdgeLB pools, if one is in progress.
+	migration, err := translator.GetEdgeLBPoolMigrationStatus(service)
+	if err != nil {
+		er.Eventf(service, corev1.EventTypeWarning, constants.ReasonInvalidAnnotations, "the resource's annotations are not valid: %v", err)
+		c.logger.Errorf("failed to read the edgelb pool migration status for service %q: %v", workItem.Key, err)
+		migration = nil
+	}
+	if migration != nil && !options.EdgeLBPoolTranslationPaused {
+		// cleanup removes the EdgeLB objects owned by the Service resource from the EdgeLB pool it is being migrated from.
+		sourcePool := migration.SourcePool
+		cleanup := func() error {
+			return c.cleanupEdgeLBPool(service, *options, sourcePool, er)
+		}
+		// If the Service resource has been deleted, the EdgeLB pool it was being migrated from must be cleaned up as well.
+		if service.ObjectMeta.DeletionTimestamp != nil {
+			if err := cleanup(); err != nil {
+				return err
+			}
+			return c.removeFinalizer(service, isTombstone, []string{options.EdgeLBPoolName, sourcePool})
+		}
+		res, err := translator.AdvanceEdgeLBPoolMigration(service, *migration, &service.Status.LoadBalancer, status, time.Now(), cleanup, er)
+		if err != nil {
+			er.Eventf(service, corev1.EventTypeWarning, constants.ReasonTranslationError, "failed to migrate service: %v", err)
+			c.logger.Errorf("failed to migrate service %q: %v", workItem.Key, err)
+			return err
+		}
+		if res.RequeueAfter > 0 {
+			defer c.enqueueAfter(service, res.RequeueAfter)
+		}
+		status = res.Status
+		migration = res.Migration
+	}
+
 	// Update the status of the Service resource if it hasn't been deleted.
 	if service.ObjectMeta.DeletionTimestamp == nil && status != nil {
 		service.Status = corev1.ServiceStatus{LoadBalancer: *status}
-		if _, err := c.kubeClient.CoreV1().Services(service.Namespace).UpdateStatus(service); err != nil {
+		if service, err = c.kubeClient.CoreV1().Services(service.Namespace).UpdateStatus(service); err != nil {
 			c.logger.Errorf("failed to update status for service %q: %v", workItem.Key, err)
 			return err
 		}
 	}
+
+	// Persist the updated status of the migration (if any).
+	if service.ObjectMeta.DeletionTimestamp == nil && service.Annotations[constants.EdgeLBPoolMigrationStatusAnnotationKey] != "" {
+		previous := service.Annotations[constants.EdgeLBPoolMigrationStatusAnnotationKey]
+		translator.SetEdgeLBPoolMigrationStatus(service, migration)
+		if service.Annotations[constants.EdgeLBPoolMigrationStatusAnnotationKey] != previous {
+			if service, err = c.kubeClient.CoreV1().Services(service.Namespace).Update(service); err != nil {
+				c.logger.Errorf("failed to update the edgelb pool migration status for service %q: %v", workItem.Key, err)
+				return err
+			}
+		}
+	}
+
+	// Record the hash of the EdgeLB objects applied for the Service resource, so that changes made out-of-band to these EdgeLB objects can be detected.
+	// Record the changes made to the target EdgeLB pool as well, so that the reason why the EdgeLB pool changed can be inspected later on.
+	// Record the changes made out-of-band to the target EdgeLB pool that have been reported (if any) too, so that they are not reported again on every resync.
+	// Record the CPU and memory requests and the size applied to the target EdgeLB pool too, so that these are only applied again in case they change.
+	// Record the JSON merge patch applied to the target EdgeLB pool too, so that the fields it no longer sets can be reset.
+	// The Service resource is only updated in case any of these annotations has actually changed.
+	if service.ObjectMeta.DeletionTimestamp == nil {
+		hashChanged := translator.SetEdgeLBPoolAppliedStateHash(service, t.AppliedStateHash())
+		diffChanged := translator.SetEdgeLBPoolLastAppliedDiff(service, t.LastAppliedDiff())
+		driftChanged := translator.SetEdgeLBPoolReportedDriftHash(service, t.ReportedDriftHash())
+		resourcesChanged := translator.SetEdgeLBPoolAppliedResources(service, t.AppliedResources())
+		patchChanged := translator.SetEdgeLBPoolAppliedPatch(service, t.AppliedEdgeLBPoolPatch())
+		if hashChanged || diffChanged || driftChanged || resourcesChanged || patchChanged {
+			if service, err = c.kubeClient.CoreV1().Services(service.Namespace).Update(service); err != nil {
+				c.logger.Errorf("failed to record the state applied to the edgelb pool for service %q: %v", workItem.Key, err)
+				return err
+			}
+		}
+	}
+
+	// Remove the "kubernetes.dcos.io/edgelb-cleanup" finalizer in case the Service resource has been deleted or is not of type "LoadBalancer" anymore.
+	if !isManaged {
+		return c.removeFinalizer(service, isTombstone, []string{options.EdgeLBPoolName})
+	}
 	return nil
 }
 
+// removeFinalizer removes the "kubernetes.dcos.io/edgelb-cleanup" finalizer from the specified Service resource, allowing for it to be removed from the Kubernetes API.
+// The finalizer is only removed after confirming (by reading them from the EdgeLB API server) that none of the specified EdgeLB pools contains EdgeLB objects owned by the Service resource.
+// Otherwise, the finalizer is kept and the Service resource is re-enqueued after the default resync period.
+// In dry-run mode, the finalizer is removed right away.
+func (c *ServiceController) removeFinalizer(service *corev1.Service, isTombstone bool, poolNames []string) error {
+	// There is nothing to do in case the Service resource has already been removed from the Kubernetes API or doesn't hold the finalizer.
+	if isTombstone || !kubernetesutil.HasFinalizer(service, constants.EdgeLBCleanupFinalizer) {
+		return nil
+	}
+	// The EdgeLB objects owned by the Service resource are never actually removed in dry-run mode, so the EdgeLB pools are not checked as otherwise the finalizer would never be removed.
+	if manager.IsDryRun(c.edgelbManager) {
+		poolNames = nil
+	}
+	for _, poolName := range poolNames {
+		cleanedUp, err := translator.IsEdgeLBPoolCleanedUpForService(c.edgelbManager, poolName, c.clusterName, service)
+		if err != nil {
+			return err
+		}
+		if !cleanedUp {
+			c.logger.Warnf("edgelb pool %q still contains edgelb objects owned by service %q, keeping the %q finalizer", poolName, kubernetesutil.Key(service), constants.EdgeLBCleanupFinalizer)
+			c.enqueueAfter(service, constants.DefaultResyncPeriod)
+			return nil
+		}
+	}
+	kubernetesutil.RemoveFinalizer(service, constants.EdgeLBCleanupFinalizer)
+	if _, err := c.kubeClient.CoreV1().Services(service.Namespace).Update(service); err != nil {
+		c.logger.Errorf("failed to remove finalizer from service %q: %v", kubernetesutil.Key(service), err)
+		return err
+	}
+	c.logger.Debugf("removed the %q finalizer from service %q", constants.EdgeLBCleanupFinalizer, kubernetesutil.Key(service))
+	return nil
+}
+
+// cleanupAllEdgeLBPools removes the EdgeLB objects owned by the specified Service resource from every EdgeLB pool in the EdgeLB pool group, and then removes the "kubernetes.dcos.io/edgelb-cleanup" finalizer from it.
+// It is used whenever the Service resource has been deleted but its translation options cannot be computed.
+// Service resources don't own any DC/OS secrets, so no secrets manager is used.
+func (c *ServiceController) cleanupAllEdgeLBPools(service *corev1.Service, isTombstone bool) error {
+	owner := translator.EdgeLBObjectOwner{
+		Kind:      translator.EdgeLBObjectOwnerKindService,
+		Namespace: service.Namespace,
+		Name:      service.Name,
+	}
+	poolNames, err := translator.RemoveEdgeLBObjectsOwnedBy(c.edgelbManager, nil, c.clusterName, owner)
+	if err != nil {
+		c.logger.Errorf("failed to clean up edgelb pools for service %q: %v", kubernetesutil.Key(service), err)
+		return err
+	}
+	return c.removeFinalizer(service, isTombstone, poolNames)
+}
+
+// cleanupEdgeLBPool removes the EdgeLB objects owned by the specified Service resource from the EdgeLB pool with the specified name, deleting said EdgeLB pool in case it becomes empty.
+// It is used to clean up the EdgeLB pool a Service resource is being migrated from.
+func (c *ServiceController) cleanupEdgeLBPool(service *corev1.Service, options translator.ServiceTranslationOptions, poolName string, er record.EventRecorder) error {
+	// Check whether the EdgeLB pool still exists, as there is nothing to clean up otherwise.
+	ctx, fn := context.WithTimeout(context.Background(), defaultEdgeLBManagerTimeout)
+	defer fn()
+	if _, err := c.edgelbManager.GetPool(ctx, poolName); err != nil {
+		if dklberrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	// Translate a copy of the Service resource marked for deletion into the EdgeLB pool, so that the EdgeLB objects it owns are removed.
+	s := service.DeepCopy()
+	deletionTimestamp := metav1.Now()
+	s.ObjectMeta.DeletionTimestamp = &deletionTimestamp
+	options.EdgeLBPoolName = poolName
+	_, err := translator.NewServiceTranslator(c.clusterName, s, options, c.kubeCache, c.edgelbManager, er).Translate()
+	return err
+}
+
 // enqueueReferencingServices enqueues Service resources that reference the provided ConfigMap resource.
 func (c *ServiceController) enqueueReferencingServices(configMap *corev1.ConfigMap) {
 	// Grab a list of all Service resources in the same namespace as the ConfigMap resource.
@@ -195,3 +387,35 @@ func (c *ServiceController) enqueueReferencingServices(configMap *corev1.ConfigM
 		}
 	}
 }
+
+// enqueueServiceTargetingEndpoints enqueues the Service resource corresponding to the provided Endpoints resource in case it is of type "LoadBalancer" and its EdgeLB backends target the addresses in said Endpoints resource.
+// This happens whenever the EdgeLB backends of the Service resource target pod IPs, or whenever it has "externalTrafficPolicy: Local".
+// Service resources whose EdgeLB backends target node ports on every Ready and schedulable Kubernetes node are not affected by changes to Endpoints resources, and hence are not enqueued.
+func (c *ServiceController) enqueueServiceTargetingEndpoints(endpoints *corev1.Endpoints) {
+	service, err := c.kubeCache.GetService(endpoints.Namespace, endpoints.Name)
+	if err != nil {
+		// The Endpoints resource may not correspond to any Service resource (e.g. it may be used for leader election).
+		return
+	}
+	if service.Spec.Type != corev1.ServiceTypeLoadBalancer {
+		return
+	}
+	if service.Spec.ExternalTrafficPolicy != corev1.ServiceExternalTrafficPolicyTypeLocal && service.Annotations[constants.EdgeLBBackendTargetAnnotationKey] != string(constants.EdgeLBBackendTargetPodIP) {
+		return
+	}
+	c.enqueue(service)
+}
+
+// enqueueAllLoadBalancerServices enqueues all Service resources of type "LoadBalancer".
+func (c *ServiceController) enqueueAllLoadBalancerServices() {
+	services, err := c.kubeCache.GetServices(metav1.NamespaceAll)
+	if err != nil {
+		c.logger.Errorf("failed to list all services: %v", err)
+		return
+	}
+	for _, service := range services {
+		if service.Spec.Type == corev1.ServiceTypeLoadBalancer {
+			c.enqueue(service)
+		}
+	}
+}
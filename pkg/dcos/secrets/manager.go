@@ -0,0 +1,164 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/mesosphere/dklb/pkg/errors"
+)
+
+// SecretsManagerOptions groups options that can be used to configure an instance of the DC/OS secrets manager.
+type SecretsManagerOptions struct {
+	// BearerToken is the (optional) bearer token to use when communicating with the DC/OS secrets API.
+	BearerToken string
+	// Host is the host at which the DC/OS secrets API can be reached.
+	Host string
+	// InsecureSkipTLSVerify indicates whether to skip verification of the TLS certificate presented by the DC/OS secrets API.
+	InsecureSkipTLSVerify bool
+	// Path is the path at which the DC/OS secrets API can be reached.
+	Path string
+	// Scheme is the scheme to use when communicating with the DC/OS secrets API.
+	Scheme string
+	// Store is the name of the DC/OS secret store in which to manage secrets.
+	Store string
+}
+
+// SecretsManager knows how to manage secrets in the DC/OS secret store.
+type SecretsManager interface {
+	// DeleteSecret deletes the DC/OS secret at the specified path.
+	DeleteSecret(context.Context, string) error
+	// PutSecret creates or updates the DC/OS secret at the specified path so that it holds the specified value.
+	PutSecret(context.Context, string, string) error
+}
+
+// secretsManager is the main implementation of the DC/OS secrets manager.
+type secretsManager struct {
+	// baseURL is the URL at which the DC/OS secret store can be reached.
+	baseURL string
+	// bearerToken is the (optional) bearer token to use when communicating with the DC/OS secrets API.
+	bearerToken string
+	// client is the HTTP client used to communicate with the DC/OS secrets API.
+	client *http.Client
+}
+
+// secret represents the body of requests made to the DC/OS secrets API.
+type secret struct {
+	// Value is the value of the secret.
+	Value string `json:"value"`
+}
+
+// NewSecretsManager creates a new instance of SecretsManager configured according to the provided options.
+func NewSecretsManager(opts SecretsManagerOptions) (SecretsManager, error) {
+	// Trim the "http://" and/or "https://" prefixes from the host if they exist.
+	opts.Host = strings.TrimPrefix(opts.Host, "http://")
+	opts.Host = strings.TrimPrefix(opts.Host, "https://")
+
+	// Build the URL at which the DC/OS secret store can be reached, making sure it is valid.
+	u := &url.URL{
+		Scheme: opts.Scheme,
+		Host:   opts.Host,
+		Path:   fmt.Sprintf("%s/secret/%s", strings.TrimSuffix(opts.Path, "/"), opts.Store),
+	}
+	if _, err := url.Parse(u.String()); err != nil {
+		return nil, fmt.Errorf("failed to build the url for the dc/os secrets api: %v", err)
+	}
+
+	// Configure the HTTP client, skipping TLS verification if requested.
+	c := &http.Client{}
+	if opts.InsecureSkipTLSVerify {
+		c.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		}
+	}
+
+	// Return a new instance of "secretsManager" that uses the specified client.
+	return &secretsManager{
+		baseURL:     u.String(),
+		bearerToken: opts.BearerToken,
+		client:      c,
+	}, nil
+}
+
+// DeleteSecret deletes the DC/OS secret at the specified path.
+func (m *secretsManager) DeleteSecret(ctx context.Context, path string) error {
+	code, err := m.do(ctx, http.MethodDelete, path, nil)
+	if err != nil {
+		return errors.Unknown(err)
+	}
+	switch code {
+	case http.StatusNoContent, http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		return errors.NotFound(fmt.Errorf("secret %q not found", path))
+	default:
+		return errors.Unknown(fmt.Errorf("failed to delete secret %q: unexpected status code %d", path, code))
+	}
+}
+
+// PutSecret creates or updates the DC/OS secret at the specified path so that it holds the specified value.
+// The DC/OS secrets API requires existing secrets to be updated using a different method than the one used to create them.
+// Hence, we start by trying to create the secret, and fallback to updating it in case it already exists.
+func (m *secretsManager) PutSecret(ctx context.Context, path, value string) error {
+	code, err := m.do(ctx, http.MethodPut, path, &secret{Value: value})
+	if err != nil {
+		return errors.Unknown(err)
+	}
+	switch code {
+	case http.StatusCreated, http.StatusOK:
+		return nil
+	case http.StatusConflict:
+		// The secret already exists, so we must update it instead.
+	default:
+		return errors.Unknown(fmt.Errorf("failed to create secret %q: unexpected status code %d", path, code))
+	}
+	code, err = m.do(ctx, http.MethodPatch, path, &secret{Value: value})
+	if err != nil {
+		return errors.Unknown(err)
+	}
+	switch code {
+	case http.StatusNoContent, http.StatusOK:
+		return nil
+	default:
+		return errors.Unknown(fmt.Errorf("failed to update secret %q: unexpected status code %d", path, code))
+	}
+}
+
+// do performs a request to the DC/OS secrets API using the specified method, secret path and body, and returns the resulting status code.
+func (m *secretsManager) do(ctx context.Context, method, path string, body *secret) (int, error) {
+	// Serialize the body of the request if one has been provided.
+	var b []byte
+	if body != nil {
+		var err error
+		if b, err = json.Marshal(body); err != nil {
+			return 0, err
+		}
+	}
+	// Build the request.
+	req, err := http.NewRequest(method, fmt.Sprintf("%s/%s", m.baseURL, strings.TrimPrefix(path, "/")), bytes.NewReader(b))
+	if err != nil {
+		return 0, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	if m.bearerToken != "" {
+		// Use the specified bearer token for authentication.
+		req.Header.Set("Authorization", fmt.Sprintf("token=%s", m.bearerToken))
+	}
+	// Perform the request and make sure we read and close the body so that the underlying connection can be reused.
+	res, err := m.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	_, _ = ioutil.ReadAll(res.Body)
+	return res.StatusCode, nil
+}
@@ -0,0 +1,87 @@
+package secrets_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mesosphere/dklb/pkg/dcos/secrets"
+	dklberrors "github.com/mesosphere/dklb/pkg/errors"
+)
+
+// TestSecretsManager_PutSecret tests the "PutSecret" function.
+func TestSecretsManager_PutSecret(t *testing.T) {
+	tests := []struct {
+		description     string
+		exists          bool
+		expectedMethods []string
+	}{
+		{
+			description:     "secret is created when it doesn't exist",
+			exists:          false,
+			expectedMethods: []string{http.MethodPut},
+		},
+		{
+			description:     "secret is updated when it already exists",
+			exists:          true,
+			expectedMethods: []string{http.MethodPut, http.MethodPatch},
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		// Create a test server that mimics the DC/OS secrets API.
+		methods := make([]string, 0)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			methods = append(methods, r.Method)
+			assert.Equal(t, "/secrets/v1/secret/default/foo/bar", r.URL.Path)
+			assert.Equal(t, "token=baz", r.Header.Get("Authorization"))
+			var body map[string]string
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "value", body["value"])
+			switch {
+			case r.Method == http.MethodPut && test.exists:
+				w.WriteHeader(http.StatusConflict)
+			case r.Method == http.MethodPut:
+				w.WriteHeader(http.StatusCreated)
+			default:
+				w.WriteHeader(http.StatusNoContent)
+			}
+		}))
+		u, _ := url.Parse(srv.URL)
+		m, err := secrets.NewSecretsManager(secrets.SecretsManagerOptions{
+			BearerToken: "baz",
+			Host:        u.Host,
+			Path:        "/secrets/v1",
+			Scheme:      u.Scheme,
+			Store:       "default",
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, m.PutSecret(context.Background(), "foo/bar", "value"))
+		assert.Equal(t, test.expectedMethods, methods)
+		srv.Close()
+	}
+}
+
+// TestSecretsManager_DeleteSecret tests the "DeleteSecret" function.
+func TestSecretsManager_DeleteSecret(t *testing.T) {
+	// Create a test server that mimics the DC/OS secrets API and on which no secrets exist.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+	m, err := secrets.NewSecretsManager(secrets.SecretsManagerOptions{
+		Host:   u.Host,
+		Path:   "/secrets/v1",
+		Scheme: u.Scheme,
+		Store:  "default",
+	})
+	assert.NoError(t, err)
+	assert.True(t, dklberrors.IsNotFound(m.DeleteSecret(context.Background(), "foo/bar")))
+}
@@ -0,0 +1,177 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	edgelbmodels "github.com/mesosphere/dcos-edge-lb/models"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/mesosphere/dklb/pkg/errors"
+)
+
+const (
+	// DryRunOperationsPath is the path at which the operations recorded by "DryRunEdgeLBManager" are exposed.
+	DryRunOperationsPath = "/debug/dry-run"
+	// maxDryRunOperations is the maximum number of operations kept in memory by "DryRunEdgeLBManager".
+	// Older operations are discarded first.
+	maxDryRunOperations = 100
+)
+
+// DryRunOperationType is the type of a call to one of the write APIs of EdgeLB.
+type DryRunOperationType string
+
+const (
+	// DryRunOperationTypeCreate is the type of a call to "CreatePool".
+	DryRunOperationTypeCreate = DryRunOperationType("create")
+	// DryRunOperationTypeDelete is the type of a call to "DeletePool".
+	DryRunOperationTypeDelete = DryRunOperationType("delete")
+	// DryRunOperationTypeUpdate is the type of a call to "UpdatePool".
+	DryRunOperationTypeUpdate = DryRunOperationType("update")
+)
+
+// DryRunOperation describes a call to one of the write APIs of EdgeLB that was recorded instead of being executed.
+type DryRunOperation struct {
+	// Timestamp is the time at which the operation was recorded.
+	Timestamp time.Time `json:"timestamp"`
+	// Type is the type of the operation.
+	Type DryRunOperationType `json:"type"`
+	// PoolName is the name of the target EdgeLB pool.
+	PoolName string `json:"poolName"`
+	// Diff is the JSON merge patch (RFC 7386) that would turn the EdgeLB pool currently reported by the EdgeLB API server into the one that was requested.
+	// It contains the whole EdgeLB pool when the EdgeLB pool would be created, and is "null" when the EdgeLB pool would be deleted.
+	Diff json.RawMessage `json:"diff"`
+}
+
+// DryRunEdgeLBManager is an implementation of "EdgeLBManager" that records calls to "CreatePool", "DeletePool" and "UpdatePool" instead of executing them.
+// Calls to the remaining methods are delegated to the wrapped EdgeLB manager.
+// It is used to understand what would change in EdgeLB (e.g. after upgrading dklb or changing its defaults) without actually changing anything.
+type DryRunEdgeLBManager struct {
+	// EdgeLBManager is the EdgeLB manager to which read-only calls are delegated.
+	EdgeLBManager
+	// logger is the logger to use when recording operations.
+	logger log.FieldLogger
+	// now returns the current time.
+	now func() time.Time
+	// operations holds the operations recorded so far, from oldest to newest.
+	operations []DryRunOperation
+	// operationsLock is used to synchronize access to "operations".
+	operationsLock sync.RWMutex
+}
+
+// NewDryRunEdgeLBManager returns an EdgeLB manager that records calls to the write APIs of EdgeLB instead of executing them, delegating all other calls to the specified EdgeLB manager.
+func NewDryRunEdgeLBManager(manager EdgeLBManager) *DryRunEdgeLBManager {
+	return &DryRunEdgeLBManager{
+		EdgeLBManager: manager,
+		logger:        log.WithField("dry-run", true),
+		now:           time.Now,
+		operations:    make([]DryRunOperation, 0, maxDryRunOperations),
+	}
+}
+
+// IsDryRun returns a value indicating whether the specified EdgeLB manager only records calls to the write APIs of EdgeLB.
+func IsDryRun(manager EdgeLBManager) bool {
+	_, ok := manager.(*DryRunEdgeLBManager)
+	return ok
+}
+
+// CreatePool records the creation of the specified EdgeLB pool.
+func (m *DryRunEdgeLBManager) CreatePool(ctx context.Context, pool *edgelbmodels.V2Pool) (*edgelbmodels.V2Pool, error) {
+	diff, err := json.Marshal(pool)
+	if err != nil {
+		return nil, errors.Unknown(err)
+	}
+	m.record(DryRunOperationTypeCreate, pool.Name, diff)
+	return pool, nil
+}
+
+// DeletePool records the deletion of the EdgeLB pool with the specified name.
+func (m *DryRunEdgeLBManager) DeletePool(ctx context.Context, name string) error {
+	m.record(DryRunOperationTypeDelete, name, json.RawMessage("null"))
+	return nil
+}
+
+// UpdatePool records the update of the specified EdgeLB pool.
+// The EdgeLB pool is read from the EdgeLB API server in order to compute the changes that would be made to it.
+func (m *DryRunEdgeLBManager) UpdatePool(ctx context.Context, pool *edgelbmodels.V2Pool) (*edgelbmodels.V2Pool, error) {
+	current, err := m.EdgeLBManager.GetPool(ctx, pool.Name)
+	if err != nil {
+		return nil, err
+	}
+	diff, err := computeEdgeLBPoolDiff(current, pool)
+	if err != nil {
+		return nil, errors.Unknown(err)
+	}
+	m.record(DryRunOperationTypeUpdate, pool.Name, diff)
+	return pool, nil
+}
+
+// Operations returns the operations recorded so far, from oldest to newest.
+func (m *DryRunEdgeLBManager) Operations() []DryRunOperation {
+	m.operationsLock.RLock()
+	defer m.operationsLock.RUnlock()
+	res := make([]DryRunOperation, len(m.operations))
+	copy(res, m.operations)
+	return res
+}
+
+// LastOperation returns the last operation recorded for the EdgeLB pool with the specified name, or nil in case no operation has been recorded for said EdgeLB pool.
+func (m *DryRunEdgeLBManager) LastOperation(poolName string) *DryRunOperation {
+	m.operationsLock.RLock()
+	defer m.operationsLock.RUnlock()
+	for idx := len(m.operations) - 1; idx >= 0; idx-- {
+		if m.operations[idx].PoolName == poolName {
+			op := m.operations[idx]
+			return &op
+		}
+	}
+	return nil
+}
+
+// ServeHTTP responds with the operations recorded so far (from oldest to newest) in JSON format.
+// If the "pool" query parameter is specified, only the operations targeting the EdgeLB pool with said name are included.
+func (m *DryRunEdgeLBManager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	res := make([]DryRunOperation, 0)
+	for _, op := range m.Operations() {
+		if poolName := r.URL.Query().Get("pool"); poolName == "" || op.PoolName == poolName {
+			res = append(res, op)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		m.logger.Errorf("failed to serve the recorded operations: %v", err)
+	}
+}
+
+// record records an operation of the specified type targeting the EdgeLB pool with the specified name.
+func (m *DryRunEdgeLBManager) record(operationType DryRunOperationType, poolName string, diff json.RawMessage) {
+	m.logger.Infof("would %s edgelb pool %q: %s", operationType, poolName, string(diff))
+	m.operationsLock.Lock()
+	defer m.operationsLock.Unlock()
+	if len(m.operations) == maxDryRunOperations {
+		m.operations = append(m.operations[:0], m.operations[1:]...)
+	}
+	m.operations = append(m.operations, DryRunOperation{
+		Timestamp: m.now(),
+		Type:      operationType,
+		PoolName:  poolName,
+		Diff:      diff,
+	})
+}
+
+// computeEdgeLBPoolDiff computes the JSON merge patch that turns the "current" EdgeLB pool into the "desired" one.
+func computeEdgeLBPoolDiff(current, desired *edgelbmodels.V2Pool) (json.RawMessage, error) {
+	c, err := json.Marshal(current)
+	if err != nil {
+		return nil, err
+	}
+	d, err := json.Marshal(desired)
+	if err != nil {
+		return nil, err
+	}
+	return jsonpatch.CreateMergePatch(c, d)
+}
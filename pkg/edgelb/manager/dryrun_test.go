@@ -0,0 +1,62 @@
+package manager_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mesosphere/dcos-edge-lb/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mesosphere/dklb/pkg/edgelb/manager"
+	"github.com/mesosphere/dklb/pkg/util/pointers"
+	edgelbmanagertestutil "github.com/mesosphere/dklb/test/util/edgelb/manager"
+	edgelbpooltestutil "github.com/mesosphere/dklb/test/util/edgelb/pool"
+)
+
+// TestDryRunEdgeLBManager tests that calls to the write APIs of EdgeLB are recorded instead of being executed.
+func TestDryRunEdgeLBManager(t *testing.T) {
+	current := edgelbpooltestutil.DummyEdgeLBPool("foo", func(p *models.V2Pool) {
+		p.Count = pointers.NewInt32(1)
+	})
+	desired := edgelbpooltestutil.DummyEdgeLBPool("foo", func(p *models.V2Pool) {
+		p.Count = pointers.NewInt32(2)
+	})
+	m := new(edgelbmanagertestutil.MockEdgeLBManager)
+	m.On("GetPool", mock.Anything, "foo").Return(current, nil)
+	d := manager.NewDryRunEdgeLBManager(m)
+	assert.True(t, manager.IsDryRun(d))
+	assert.False(t, manager.IsDryRun(m))
+
+	_, err := d.CreatePool(context.Background(), desired)
+	assert.NoError(t, err)
+	_, err = d.UpdatePool(context.Background(), desired)
+	assert.NoError(t, err)
+	assert.NoError(t, d.DeletePool(context.Background(), "bar"))
+
+	// Make sure that none of the calls was delegated to the wrapped EdgeLB manager.
+	m.AssertNotCalled(t, "CreatePool", mock.Anything, mock.Anything)
+	m.AssertNotCalled(t, "UpdatePool", mock.Anything)
+	m.AssertNotCalled(t, "DeletePool", mock.Anything, mock.Anything)
+
+	// Make sure that the calls were recorded in order, and that the diffs are the expected ones.
+	ops := d.Operations()
+	assert.Len(t, ops, 3)
+	assert.Equal(t, manager.DryRunOperationTypeCreate, ops[0].Type)
+	assert.Equal(t, manager.DryRunOperationTypeUpdate, ops[1].Type)
+	assert.JSONEq(t, `{"count":2}`, string(ops[1].Diff))
+	assert.Equal(t, manager.DryRunOperationTypeDelete, ops[2].Type)
+	assert.Equal(t, "null", string(ops[2].Diff))
+	assert.Equal(t, manager.DryRunOperationTypeUpdate, d.LastOperation("foo").Type)
+	assert.Nil(t, d.LastOperation("baz"))
+
+	// Make sure that the recorded calls are exposed over HTTP, and that they can be filtered by the name of the EdgeLB pool.
+	w := httptest.NewRecorder()
+	d.ServeHTTP(w, httptest.NewRequest("GET", manager.DryRunOperationsPath+"?pool=bar", nil))
+	var res []manager.DryRunOperation
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	assert.Len(t, res, 1)
+	assert.Equal(t, "bar", res[0].PoolName)
+}
@@ -0,0 +1,198 @@
+package garbagecollector
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mesosphere/dcos-edge-lb/models"
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+
+	dklbcache "github.com/mesosphere/dklb/pkg/cache"
+	"github.com/mesosphere/dklb/pkg/dcos/secrets"
+	"github.com/mesosphere/dklb/pkg/edgelb/manager"
+	"github.com/mesosphere/dklb/pkg/metrics"
+	"github.com/mesosphere/dklb/pkg/translator"
+)
+
+const (
+	// garbageCollectorName is the name of the EdgeLB garbage collector.
+	garbageCollectorName = "edgelb-garbage-collector"
+	// defaultEdgeLBManagerTimeout is the default timeout used when interacting with the EdgeLB manager.
+	defaultEdgeLBManagerTimeout = 10 * time.Second
+)
+
+// EdgeLBGarbageCollectorOptions groups options that can be used to configure an instance of the EdgeLB garbage collector.
+type EdgeLBGarbageCollectorOptions struct {
+	// DryRun indicates whether the garbage collector should only report the orphaned EdgeLB objects it finds instead of removing them.
+	DryRun bool
+	// GracePeriod is the minimum amount of time during which an EdgeLB object must be found to be orphaned before it is removed.
+	GracePeriod time.Duration
+	// Interval is the interval at which EdgeLB pools are scanned for orphaned EdgeLB objects.
+	Interval time.Duration
+}
+
+// EdgeLBGarbageCollector periodically removes EdgeLB objects owned by Ingress/Service resources that no longer exist from every EdgeLB pool in the EdgeLB pool group.
+// EdgeLB objects may be left behind in case an Ingress/Service resource is removed while dklb is not able to clean up after it (e.g. because the finalizer was manually removed, or because the EdgeLB API server was unavailable).
+type EdgeLBGarbageCollector struct {
+	// clusterName is the name of the Mesos framework that corresponds to the current Kubernetes cluster.
+	clusterName string
+	// kubeCache is the instance of the Kubernetes resource cache to use.
+	kubeCache dklbcache.KubernetesResourceCache
+	// edgelbManager is the instance of the EdgeLB manager to use for reading and updating EdgeLB pools.
+	edgelbManager manager.EdgeLBManager
+	// secretsManager is the instance of the DC/OS secrets manager to use for deleting DC/OS secrets that are not referenced by EdgeLB pools anymore.
+	secretsManager secrets.SecretsManager
+	// options is the set of options used to configure the garbage collector.
+	options EdgeLBGarbageCollectorOptions
+	// firstSeenOrphaned holds the time at which each Ingress/Service resource was first found to be missing, and is used to enforce the grace period.
+	// It is only accessed from the goroutine running the garbage collector, so it doesn't need to be protected by a lock.
+	firstSeenOrphaned map[translator.EdgeLBObjectOwner]time.Time
+	// now returns the current time.
+	now func() time.Time
+	// logger is the logger that the garbage collector will use.
+	logger log.FieldLogger
+}
+
+// NewEdgeLBGarbageCollector creates a new instance of the EdgeLB garbage collector.
+func NewEdgeLBGarbageCollector(clusterName string, kubeCache dklbcache.KubernetesResourceCache, edgelbManager manager.EdgeLBManager, secretsManager secrets.SecretsManager, options EdgeLBGarbageCollectorOptions) *EdgeLBGarbageCollector {
+	return &EdgeLBGarbageCollector{
+		clusterName:       clusterName,
+		kubeCache:         kubeCache,
+		edgelbManager:     edgelbManager,
+		secretsManager:    secretsManager,
+		options:           options,
+		firstSeenOrphaned: make(map[translator.EdgeLBObjectOwner]time.Time),
+		now:               time.Now,
+		logger:            log.WithField("controller", garbageCollectorName),
+	}
+}
+
+// Run starts the garbage collector, blocking until the specified context is canceled.
+func (g *EdgeLBGarbageCollector) Run(ctx context.Context) error {
+	g.logger.Debugf("starting %q", garbageCollectorName)
+
+	// Wait for the cache to be synced before checking whether Service/Ingress resources exist, as otherwise every EdgeLB object would be considered orphaned.
+	g.logger.Debug("waiting for informer caches to be synced")
+	if ok := cache.WaitForCacheSync(ctx.Done(), g.kubeCache.HasSynced); !ok {
+		return fmt.Errorf("failed to wait for informer caches to be synced")
+	}
+
+	g.logger.WithField("dry_run", g.options.DryRun).Info("started garbage collector")
+
+	// Scan EdgeLB pools periodically until the context is canceled.
+	wait.Until(g.collect, g.options.Interval, ctx.Done())
+	return nil
+}
+
+// collect removes orphaned EdgeLB objects from every EdgeLB pool in the EdgeLB pool group.
+func (g *EdgeLBGarbageCollector) collect() {
+	defer metrics.RecordGarbageCollection()
+
+	ctx, fn := context.WithTimeout(context.Background(), defaultEdgeLBManagerTimeout)
+	defer fn()
+	pools, err := g.edgelbManager.GetPools(ctx)
+	if err != nil {
+		g.logger.Errorf("failed to list edgelb pools: %v", err)
+		return
+	}
+
+	// seen holds the Ingress/Service resources found to be missing during the current run.
+	// It is used to forget about resources that have since been recreated, or whose EdgeLB objects have been removed.
+	now := g.now()
+	seen := make(map[translator.EdgeLBObjectOwner]bool)
+	for _, pool := range pools {
+		// Skip EdgeLB pools that dklb doesn't manage.
+		if pool.Namespace == nil || *pool.Namespace != g.edgelbManager.PoolGroup() {
+			continue
+		}
+		if err := g.collectPool(pool, now, seen); err != nil {
+			g.logger.Errorf("failed to garbage collect edgelb pool %q: %v", pool.Name, err)
+		}
+	}
+	for owner := range g.firstSeenOrphaned {
+		if !seen[owner] {
+			delete(g.firstSeenOrphaned, owner)
+		}
+	}
+}
+
+// collectPool removes the EdgeLB objects whose owner has been missing for longer than the grace period from the specified EdgeLB pool.
+// The EdgeLB pool is deleted in case it is left without any EdgeLB backends or frontends.
+// DC/OS secrets that are not referenced by the EdgeLB pool anymore are deleted after the EdgeLB pool has been updated/deleted.
+func (g *EdgeLBGarbageCollector) collectPool(pool *models.V2Pool, now time.Time, seen map[translator.EdgeLBObjectOwner]bool) error {
+	// Keep track of the EdgeLB pool's secrets before removing orphaned EdgeLB objects, so that the DC/OS secrets that are not referenced anymore can be deleted.
+	previousSecrets := pool.Secrets
+	removed := translator.RemoveOrphanedEdgeLBObjects(pool, g.clusterName, func(owner translator.EdgeLBObjectOwner) bool {
+		if !g.isMissing(owner) {
+			return false
+		}
+		seen[owner] = true
+		firstSeen, exists := g.firstSeenOrphaned[owner]
+		if !exists {
+			g.firstSeenOrphaned[owner] = now
+			firstSeen = now
+		}
+		return now.Sub(firstSeen) >= g.options.GracePeriod
+	})
+	if len(removed) == 0 {
+		return nil
+	}
+
+	verb := "removing"
+	if g.options.DryRun {
+		verb = "would remove"
+	}
+	for _, obj := range removed {
+		g.logger.Infof("%s %s %q from edgelb pool %q as %s no longer exists", verb, obj.Type, obj.Name, pool.Name, obj.Owner)
+		metrics.RecordGarbageCollectedEdgeLBObject(pool.Name, obj.Type, g.options.DryRun)
+	}
+	if g.options.DryRun {
+		return nil
+	}
+
+	ctx, fn := context.WithTimeout(context.Background(), defaultEdgeLBManagerTimeout)
+	defer fn()
+	if len(pool.Haproxy.Frontends) == 0 && len(pool.Haproxy.Backends) == 0 {
+		g.logger.Infof("deleting edgelb pool %q as it is empty", pool.Name)
+		if err := g.edgelbManager.DeletePool(ctx, pool.Name); err != nil {
+			return err
+		}
+		return g.deleteOrphanedDCOSSecrets(pool.Name, previousSecrets, nil)
+	}
+	if _, err := g.edgelbManager.UpdatePool(ctx, pool); err != nil {
+		return err
+	}
+	return g.deleteOrphanedDCOSSecrets(pool.Name, previousSecrets, pool.Secrets)
+}
+
+// deleteOrphanedDCOSSecrets deletes the DC/OS secrets that were referenced by the "previous" set of secrets of the EdgeLB pool with the specified name but that are not referenced by the "current" one.
+func (g *EdgeLBGarbageCollector) deleteOrphanedDCOSSecrets(poolName string, previous, current []*models.V2PoolSecretsItems0) error {
+	// DC/OS secrets are never actually deleted in dry-run mode, as the EdgeLB pool still references them.
+	if manager.IsDryRun(g.edgelbManager) {
+		return nil
+	}
+	paths := translator.ComputeOrphanedDCOSSecretPaths(g.clusterName, previous, current)
+	for _, path := range paths {
+		g.logger.Infof("deleting dc/os secret %q as it is not referenced by edgelb pool %q anymore", path, poolName)
+	}
+	return translator.DeleteDCOSSecrets(g.secretsManager, paths)
+}
+
+// isMissing indicates whether the specified Ingress/Service resource is known not to exist.
+// Errors other than "not found" are treated as the resource existing so that EdgeLB objects are never removed by mistake.
+func (g *EdgeLBGarbageCollector) isMissing(owner translator.EdgeLBObjectOwner) bool {
+	var err error
+	switch owner.Kind {
+	case translator.EdgeLBObjectOwnerKindIngress:
+		_, err = g.kubeCache.GetIngress(owner.Namespace, owner.Name)
+	case translator.EdgeLBObjectOwnerKindService:
+		_, err = g.kubeCache.GetService(owner.Namespace, owner.Name)
+	default:
+		return false
+	}
+	return apierrors.IsNotFound(err)
+}
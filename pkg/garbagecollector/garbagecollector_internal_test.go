@@ -0,0 +1,183 @@
+package garbagecollector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mesosphere/dcos-edge-lb/models"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mesosphere/dklb/pkg/translator"
+	cachetestutil "github.com/mesosphere/dklb/test/util/cache"
+	secretstestutil "github.com/mesosphere/dklb/test/util/dcos/secrets"
+	edgelbmanagertestutil "github.com/mesosphere/dklb/test/util/edgelb/manager"
+	edgelbpooltestutil "github.com/mesosphere/dklb/test/util/edgelb/pool"
+	ingresstestutil "github.com/mesosphere/dklb/test/util/kubernetes/ingress"
+	servicetestutil "github.com/mesosphere/dklb/test/util/kubernetes/service"
+)
+
+const (
+	// testClusterName is the name of the Kubernetes cluster used in tests.
+	testClusterName = "dev/kubernetes01"
+	// testPoolGroup is the DC/OS service group in which EdgeLB pools used in tests are created.
+	testPoolGroup = "dcos-edgelb/pools"
+)
+
+// TestCollect tests the "collect" function.
+func TestCollect(t *testing.T) {
+	var (
+		now           = time.Date(2019, 5, 1, 10, 0, 0, 0, time.UTC)
+		orphanedOwner = translator.EdgeLBObjectOwner{Kind: translator.EdgeLBObjectOwnerKindService, Namespace: "foo", Name: "orphaned"}
+	)
+	tests := []struct {
+		description            string
+		firstSeenOrphanedAgo   time.Duration
+		dryRun                 bool
+		onlyOrphanedObjects    bool
+		otherPoolGroup         bool
+		expectedUpdate         bool
+		expectedDelete         bool
+		expectedBackends       int
+		expectedFirstSeenAtNow bool
+	}{
+		{
+			description:            "orphaned objects seen for the first time",
+			expectedBackends:       2,
+			expectedFirstSeenAtNow: true,
+		},
+		{
+			description:          "orphaned objects seen within the grace period",
+			firstSeenOrphanedAgo: 5 * time.Minute,
+			expectedBackends:     2,
+		},
+		{
+			description:          "orphaned objects seen after the grace period",
+			firstSeenOrphanedAgo: 15 * time.Minute,
+			expectedUpdate:       true,
+			expectedBackends:     1,
+		},
+		{
+			description:          "orphaned objects seen after the grace period in dry-run mode",
+			firstSeenOrphanedAgo: 15 * time.Minute,
+			dryRun:               true,
+			expectedBackends:     1,
+		},
+		{
+			description:          "pool containing only orphaned objects seen after the grace period",
+			firstSeenOrphanedAgo: 15 * time.Minute,
+			onlyOrphanedObjects:  true,
+			expectedDelete:       true,
+			expectedBackends:     0,
+		},
+		{
+			description:          "pool in a different pool group",
+			firstSeenOrphanedAgo: 15 * time.Minute,
+			otherPoolGroup:       true,
+			expectedBackends:     2,
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		poolGroup := testPoolGroup
+		if test.otherPoolGroup {
+			poolGroup = "other/pools"
+		}
+		pool := edgelbpooltestutil.DummyEdgeLBPool("foo", func(p *models.V2Pool) {
+			p.Namespace = &poolGroup
+			p.Haproxy.Backends = []*models.V2Backend{
+				{Name: "dev.kubernetes01:foo:orphaned:80"},
+			}
+			if !test.onlyOrphanedObjects {
+				p.Haproxy.Backends = append(p.Haproxy.Backends, &models.V2Backend{Name: "dev.kubernetes01:foo:existing:80"})
+			}
+		})
+		m := new(edgelbmanagertestutil.MockEdgeLBManager)
+		m.On("GetPools", mock.Anything).Return([]*models.V2Pool{pool}, nil)
+		m.On("PoolGroup").Return(testPoolGroup)
+		m.On("UpdatePool", mock.Anything).Return(pool, nil)
+		m.On("DeletePool", mock.Anything, "foo").Return(nil)
+		g := &EdgeLBGarbageCollector{
+			clusterName:    testClusterName,
+			kubeCache:      cachetestutil.NewFakeKubernetesResourceCache(servicetestutil.DummyServiceResource("foo", "existing")),
+			edgelbManager:  m,
+			secretsManager: new(secretstestutil.MockSecretsManager),
+			options: EdgeLBGarbageCollectorOptions{
+				DryRun:      test.dryRun,
+				GracePeriod: 10 * time.Minute,
+			},
+			firstSeenOrphaned: make(map[translator.EdgeLBObjectOwner]time.Time),
+			now: func() time.Time {
+				return now
+			},
+			logger: log.WithField("test", t.Name()),
+		}
+		// A value of zero means that the orphaned service has never been seen before.
+		if test.firstSeenOrphanedAgo != 0 {
+			g.firstSeenOrphaned[orphanedOwner] = now.Add(-test.firstSeenOrphanedAgo)
+		}
+		g.collect()
+		if test.expectedUpdate {
+			m.AssertCalled(t, "UpdatePool", mock.Anything)
+		} else {
+			m.AssertNotCalled(t, "UpdatePool", mock.Anything)
+		}
+		if test.expectedDelete {
+			m.AssertCalled(t, "DeletePool", mock.Anything, "foo")
+		} else {
+			m.AssertNotCalled(t, "DeletePool", mock.Anything, "foo")
+		}
+		assert.Len(t, pool.Haproxy.Backends, test.expectedBackends)
+		if test.expectedFirstSeenAtNow {
+			assert.Equal(t, now, g.firstSeenOrphaned[orphanedOwner])
+		}
+		// Owners that were not found to be orphaned during the current run must be forgotten.
+		if test.otherPoolGroup {
+			assert.NotContains(t, g.firstSeenOrphaned, orphanedOwner)
+		}
+	}
+}
+
+// TestCollectDeletesOrphanedDCOSSecrets tests that the "collect" function deletes the DC/OS secrets that are not referenced by an EdgeLB pool anymore after removing the EdgeLB pool secrets owned by a missing Ingress resource.
+func TestCollectDeletesOrphanedDCOSSecrets(t *testing.T) {
+	now := time.Date(2019, 5, 1, 10, 0, 0, 0, time.UTC)
+	poolGroup := testPoolGroup
+	pool := edgelbpooltestutil.DummyEdgeLBPool("foo", func(p *models.V2Pool) {
+		p.Namespace = &poolGroup
+		p.Haproxy.Backends = []*models.V2Backend{
+			{Name: "dev.kubernetes01:foo:orphaned:bar:80"},
+			{Name: "dev.kubernetes01:foo:existing:bar:80"},
+		}
+		p.Secrets = []*models.V2PoolSecretsItems0{
+			// An EdgeLB pool secret owned by the missing Ingress resource, referencing a DC/OS secret that is not referenced by any other EdgeLB pool secret.
+			{File: "dev.kubernetes01__foo__orphaned__tls-1__0123456789", Secret: testPoolGroup + "/foo/dev.kubernetes01__foo__tls-1"},
+			// An EdgeLB pool secret owned by the missing Ingress resource, referencing a DC/OS secret that is still referenced by an existing Ingress resource.
+			{File: "dev.kubernetes01__foo__orphaned__tls-2__0123456789", Secret: testPoolGroup + "/foo/dev.kubernetes01__foo__tls-2"},
+			{File: "dev.kubernetes01__foo__existing__tls-2__0123456789", Secret: testPoolGroup + "/foo/dev.kubernetes01__foo__tls-2"},
+		}
+	})
+	m := new(edgelbmanagertestutil.MockEdgeLBManager)
+	m.On("GetPools", mock.Anything).Return([]*models.V2Pool{pool}, nil)
+	m.On("PoolGroup").Return(testPoolGroup)
+	m.On("UpdatePool", mock.Anything).Return(pool, nil)
+	s := new(secretstestutil.MockSecretsManager)
+	s.On("DeleteSecret", mock.Anything, testPoolGroup+"/foo/dev.kubernetes01__foo__tls-1").Return(nil)
+	g := &EdgeLBGarbageCollector{
+		clusterName:       testClusterName,
+		kubeCache:         cachetestutil.NewFakeKubernetesResourceCache(ingresstestutil.DummyIngressResource("foo", "existing")),
+		edgelbManager:     m,
+		secretsManager:    s,
+		options:           EdgeLBGarbageCollectorOptions{},
+		firstSeenOrphaned: make(map[translator.EdgeLBObjectOwner]time.Time),
+		now: func() time.Time {
+			return now
+		},
+		logger: log.WithField("test", t.Name()),
+	}
+	g.collect()
+	m.AssertCalled(t, "UpdatePool", mock.Anything)
+	s.AssertExpectations(t)
+	s.AssertNumberOfCalls(t, "DeleteSecret", 1)
+	assert.Len(t, pool.Secrets, 1)
+}
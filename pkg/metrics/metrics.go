@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -16,17 +17,33 @@ const (
 	bindAddr = "0.0.0.0:10250"
 	// controllerNameLabel is the name of the label used to hold the name of a controller.
 	controllerNameLabel = "controller_name"
+	// dryRunLabel is the name of the label used to indicate whether an action was only simulated.
+	dryRunLabel = "dry_run"
+	// edgeLBObjectTypeLabel is the name of the label used to hold the type of an EdgeLB object (e.g. "backend").
+	edgeLBObjectTypeLabel = "object_type"
+	// edgeLBPoolNameLabel is the name of the label used to hold the name of an EdgeLB pool.
+	edgeLBPoolNameLabel = "pool_name"
+	// lastGarbageCollectionTimestampKey is the name of the metric used to hold the timestamp at which the EdgeLB garbage collector last ran.
+	lastGarbageCollectionTimestampKey = "last_garbage_collection_timestamp"
 	// lastSyncTimestampKey is the name of the metric used to hold the timestamp at which a controller last synced a resource.
 	lastSyncTimestampKey = "last_sync_timestamp"
 	// resourceKeyLabel is the name of the label used to hold the key of a resource.
 	resourceKeyLabel = "resource_key"
 	// syncDurationSecondsKey is the name of the metric used to hold the time taken to sync resources,
 	syncDurationSecondsKey = "sync_duration_seconds"
+	// totalGarbageCollectedEdgeLBObjectsKey is the name of the metric used to hold the total number of orphaned EdgeLB objects removed by the EdgeLB garbage collector.
+	totalGarbageCollectedEdgeLBObjectsKey = "total_garbage_collected_edgelb_objects"
 	// totalSyncsKey is the name of the metric used to hold the total number of times a controller synced a resource.
 	totalSyncsKey = "total_syncs"
 )
 
 var (
+	// lastGarbageCollectionTimestamp holds the timestamp at which the EdgeLB garbage collector last ran.
+	lastGarbageCollectionTimestamp = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: constants.ComponentName,
+		Name:      lastGarbageCollectionTimestampKey,
+		Help:      "The timestamp at which the edgelb garbage collector last ran",
+	})
 	// lastSyncTimestamp holds the timestamp at which a controller last synced a resource.
 	lastSyncTimestamp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: constants.ComponentName,
@@ -39,6 +56,12 @@ var (
 		Name:      syncDurationSecondsKey,
 		Help:      "The time taken to sync resources",
 	}, []string{controllerNameLabel})
+	// totalGarbageCollectedEdgeLBObjects holds the total number of orphaned EdgeLB objects removed by the EdgeLB garbage collector.
+	totalGarbageCollectedEdgeLBObjects = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: constants.ComponentName,
+		Name:      totalGarbageCollectedEdgeLBObjectsKey,
+		Help:      "The total number of orphaned edgelb objects removed (or, in dry-run mode, that would have been removed) by the edgelb garbage collector",
+	}, []string{edgeLBPoolNameLabel, edgeLBObjectTypeLabel, dryRunLabel})
 	// totalSyncs holds the total number of times a controller synced a resource.
 	totalSyncs = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Namespace: constants.ComponentName,
@@ -49,8 +72,10 @@ var (
 
 func init() {
 	// Register metrics.
+	prometheus.MustRegister(lastGarbageCollectionTimestamp)
 	prometheus.MustRegister(lastSyncTimestamp)
 	prometheus.MustRegister(syncDuration)
+	prometheus.MustRegister(totalGarbageCollectedEdgeLBObjects)
 	prometheus.MustRegister(totalSyncs)
 	// Start the HTTP server that will expose application-level metrics.
 	go func() {
@@ -76,3 +101,16 @@ func RecordSync(controllerName, resourceKey string) {
 		controllerName,
 		resourceKey).Inc()
 }
+
+// RecordGarbageCollection records a run of the EdgeLB garbage collector.
+func RecordGarbageCollection() {
+	lastGarbageCollectionTimestamp.Set(float64(time.Now().UTC().UnixNano()))
+}
+
+// RecordGarbageCollectedEdgeLBObject records the removal of an orphaned EdgeLB object of the specified type from the specified EdgeLB pool by the EdgeLB garbage collector.
+func RecordGarbageCollectedEdgeLBObject(poolName, objectType string, dryRun bool) {
+	totalGarbageCollectedEdgeLBObjects.WithLabelValues(
+		poolName,
+		objectType,
+		strconv.FormatBool(dryRun)).Inc()
+}
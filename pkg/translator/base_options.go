@@ -11,6 +11,12 @@ import (
 	"github.com/mesosphere/dklb/pkg/constants"
 )
 
+var (
+	// edgeLBBackendBalanceHeaderHashRegex is the regular expression used to parse a request for hashing of an HTTP header (e.g. "hdr(X-User-ID)").
+	// Header names are restricted to alphanumeric characters, dashes and underscores so that they can be safely included in the HAProxy configuration.
+	edgeLBBackendBalanceHeaderHashRegex = regexp.MustCompile(`^hdr\(([A-Za-z0-9_-]+)\)$`)
+)
+
 // BaseTranslationOptions groups together options used to "translate" an Ingress/Service resource into an EdgeLB pool.
 type BaseTranslationOptions struct {
 	// CloudLoadBalancerConfigMapName is the name of the configmap specifying cloud load-balancer configuration.
@@ -28,12 +34,41 @@ type BaseTranslationOptions struct {
 	// EdgeLBPoolMem is the amount of memory to request for the target EdgeLB pool.
 	EdgeLBPoolMem resource.Quantity
 	// EdgeLBPoolSize is the size to request for the target EdgeLB pool.
+	// When autoscaling is enabled, this is the initial size of the target EdgeLB pool.
 	EdgeLBPoolSize int
+	// EdgeLBPoolMinSize is the minimum size of the target EdgeLB pool when autoscaling is enabled.
+	EdgeLBPoolMinSize int
+	// EdgeLBPoolMaxSize is the maximum size of the target EdgeLB pool when autoscaling is enabled.
+	// A value of zero means that autoscaling is disabled.
+	EdgeLBPoolMaxSize int
+	// EdgeLBPoolTargetConnectionsPerInstance is the number of concurrent connections that each instance of the target EdgeLB pool should handle when autoscaling is enabled.
+	EdgeLBPoolTargetConnectionsPerInstance int
 
 	// EdgeLBPoolCreationStrategy is the strategy to use for provisioning an EdgeLB pool for the Ingress/Service resource.
 	EdgeLBPoolCreationStrategy constants.EdgeLBPoolCreationStrategy
 	// EdgeLBPoolTranslationPaused indicates whether translation is currently paused for the Ingress/Service resource.
 	EdgeLBPoolTranslationPaused bool
+	// EdgeLBPoolDriftPolicy is the way in which changes made out-of-band to the EdgeLB objects owned by the Ingress/Service resource are handled.
+	EdgeLBPoolDriftPolicy constants.EdgeLBPoolDriftPolicy
+
+	// EdgeLBBackendBalance is the load-balancing algorithm to use in the EdgeLB backends corresponding to the Ingress/Service resource.
+	EdgeLBBackendBalance string
+	// EdgeLBBackendTarget is the kind of servers targeted by the EdgeLB backends corresponding to the Ingress/Service resource.
+	EdgeLBBackendTarget constants.EdgeLBBackendTarget
+	// EdgeLBBackendHealthCheck is the configuration of the health checks performed by the EdgeLB backends corresponding to the Ingress/Service resource.
+	EdgeLBBackendHealthCheck HealthCheckOptions
+
+	// EdgeLBPoolPatch is the JSON merge patch to apply to the target EdgeLB pool after translation.
+	EdgeLBPoolPatch []byte
+	// EdgeLBBackendPatch is the JSON merge patch to apply to each EdgeLB backend corresponding to the Ingress/Service resource after translation.
+	EdgeLBBackendPatch []byte
+	// EdgeLBFrontendPatch is the JSON merge patch to apply to each EdgeLB frontend corresponding to the Ingress/Service resource after translation.
+	EdgeLBFrontendPatch []byte
+}
+
+// IsEdgeLBPoolAutoscalingEnabled returns a value indicating whether autoscaling has been requested for the target EdgeLB pool.
+func (o BaseTranslationOptions) IsEdgeLBPoolAutoscalingEnabled() bool {
+	return o.EdgeLBPoolMaxSize > 0
 }
 
 // ValidateBaseTranslationOptionsUpdate validates the transition between "previousOptions" and "currentOptions".
@@ -47,29 +82,18 @@ func ValidateBaseTranslationOptionsUpdate(previousOptions, currentOptions *BaseT
 		return errors.New("the name of the configmap used for configuring the cloud load-balancer cannot be removed")
 	}
 
-	// Prevent the name of the EdgeLB pool from changing.
+	// Changing the name of the target EdgeLB pool is allowed, and causes the resource to be migrated to the new EdgeLB pool.
+	// As EdgeLB pools cannot be moved to a different role or virtual network without downtime, these can only be changed together with the name of the target EdgeLB pool (i.e. as part of a migration).
 	if currentOptions.EdgeLBPoolName != previousOptions.EdgeLBPoolName {
-		return errors.New("the name of the target edgelb pool cannot be changed")
+		return nil
 	}
-	// Prevent the role of the EdgeLB pool from changing.
+	// Prevent the role of the EdgeLB pool from changing in-place.
 	if currentOptions.EdgeLBPoolRole != previousOptions.EdgeLBPoolRole {
-		return errors.New("the role of the target edgelb pool cannot be changed")
+		return errors.New("the role of the target edgelb pool can only be changed together with its name")
 	}
-	// Prevent the CPU request for the EdgeLB pool from changing.
-	if currentOptions.EdgeLBPoolCpus != previousOptions.EdgeLBPoolCpus {
-		return errors.New("the cpu request for the target edgelb pool cannot be changed")
-	}
-	// Prevent the memory request for the EdgeLB pool from changing.
-	if currentOptions.EdgeLBPoolMem != previousOptions.EdgeLBPoolMem {
-		return errors.New("the memory request for the target edgelb pool cannot be changed")
-	}
-	// Prevent the size of the EdgeLB pool from changing.
-	if currentOptions.EdgeLBPoolSize != previousOptions.EdgeLBPoolSize {
-		return errors.New("the size of the target edgelb pool cannot be changed")
-	}
-	// Prevent the virtual network of the target EdgeLB pool from changing.
+	// Prevent the virtual network of the target EdgeLB pool from changing in-place.
 	if currentOptions.EdgeLBPoolNetwork != previousOptions.EdgeLBPoolNetwork {
-		return errors.New("the virtual network of the target edgelb pool cannot be changed")
+		return errors.New("the virtual network of the target edgelb pool can only be changed together with its name")
 	}
 	return nil
 }
@@ -80,6 +104,22 @@ func parseBaseTranslationOptions(clusterName, namespace, name string, annotation
 	// Check whether we've been asked to configure a cloud load-balancer for the current resource.
 	// In such a scenario, we override the values of the remaining options with our own defaults in order to ensure the requirements of the cloud load-balancer.
 	if v, exists := annotations[constants.CloudLoadBalancerConfigMapNameAnnotationKey]; exists && v != "" {
+		// The load-balancing algorithm doesn't affect the requirements of the cloud load-balancer, so we still honor its value.
+		balance, err := parseEdgeLBBackendBalance(annotations[constants.EdgeLBBackendBalanceAnnotationKey])
+		if err != nil {
+			return nil, err
+		}
+		// The same goes for health checks.
+		healthCheck, err := parseHealthCheckOptions(annotations)
+		if err != nil {
+			return nil, err
+		}
+		// The same goes for the way in which changes made out-of-band are handled.
+		driftPolicy, err := parseEdgeLBPoolDriftPolicy(annotations[constants.EdgeLBPoolDriftPolicyAnnotationKey])
+		if err != nil {
+			return nil, err
+		}
+		// Pod IPs are not reachable from the host network, so EdgeLB backends must always target node ports.
 		return &BaseTranslationOptions{
 			CloudLoadBalancerConfigMapName: &v,
 			EdgeLBPoolName:                 ComputeEdgeLBPoolName(constants.EdgeLBCloudLoadBalancerPoolNamePrefix, clusterName, namespace, name),
@@ -89,6 +129,10 @@ func parseBaseTranslationOptions(clusterName, namespace, name string, annotation
 			EdgeLBPoolSize:                 DefaultEdgeLBPoolSize,
 			EdgeLBPoolRole:                 constants.EdgeLBRolePrivate,
 			EdgeLBPoolCreationStrategy:     constants.EdgeLBPoolCreationStrategyIfNotPresent,
+			EdgeLBPoolDriftPolicy:          driftPolicy,
+			EdgeLBBackendBalance:           balance,
+			EdgeLBBackendTarget:            constants.EdgeLBBackendTargetNodePort,
+			EdgeLBBackendHealthCheck:       *healthCheck,
 		}, nil
 	}
 
@@ -164,6 +208,11 @@ func parseBaseTranslationOptions(clusterName, namespace, name string, annotation
 		res.EdgeLBPoolSize = r
 	}
 
+	// Parse the autoscaling configuration for the target EdgeLB pool.
+	if err := parseEdgeLBPoolAutoscalingOptions(annotations, res); err != nil {
+		return nil, err
+	}
+
 	// Parse the creation strategy to use for creating the target EdgeLB pool.
 	if v, exists := annotations[constants.EdgeLBPoolCreationStrategyAnnotationKey]; !exists || v == "" {
 		res.EdgeLBPoolCreationStrategy = DefaultEdgeLBPoolCreationStrategy
@@ -191,6 +240,137 @@ func parseBaseTranslationOptions(clusterName, namespace, name string, annotation
 		res.EdgeLBPoolTranslationPaused = p
 	}
 
+	// Parse the way in which changes made out-of-band to the EdgeLB objects owned by the resource are handled.
+	driftPolicy, err := parseEdgeLBPoolDriftPolicy(annotations[constants.EdgeLBPoolDriftPolicyAnnotationKey])
+	if err != nil {
+		return nil, err
+	}
+	res.EdgeLBPoolDriftPolicy = driftPolicy
+
+	// Parse the load-balancing algorithm to use in the EdgeLB backends.
+	balance, err := parseEdgeLBBackendBalance(annotations[constants.EdgeLBBackendBalanceAnnotationKey])
+	if err != nil {
+		return nil, err
+	}
+	res.EdgeLBBackendBalance = balance
+
+	// Parse the kind of servers targeted by the EdgeLB backends.
+	switch v := annotations[constants.EdgeLBBackendTargetAnnotationKey]; v {
+	case "":
+		res.EdgeLBBackendTarget = DefaultEdgeLBBackendTarget
+	case string(constants.EdgeLBBackendTargetNodePort):
+		res.EdgeLBBackendTarget = constants.EdgeLBBackendTargetNodePort
+	case string(constants.EdgeLBBackendTargetPodIP):
+		// Pod IPs are only reachable from the target EdgeLB pool if it joins a DC/OS virtual network.
+		if res.EdgeLBPoolNetwork == constants.EdgeLBHostNetwork {
+			return nil, fmt.Errorf("cannot target pod ips when the pool doesn't join a dcos virtual network")
+		}
+		res.EdgeLBBackendTarget = constants.EdgeLBBackendTargetPodIP
+	default:
+		return nil, fmt.Errorf("failed to parse %q as a backend target", v)
+	}
+
+	// Parse the configuration of the health checks performed by the EdgeLB backends.
+	healthCheck, err := parseHealthCheckOptions(annotations)
+	if err != nil {
+		return nil, err
+	}
+	res.EdgeLBBackendHealthCheck = *healthCheck
+
+	// Parse the JSON merge patches to apply to the target EdgeLB pool and to the EdgeLB backends and frontends.
+	if res.EdgeLBPoolPatch, err = parseEdgeLBPoolPatch(annotations[constants.EdgeLBPoolPatchAnnotationKey]); err != nil {
+		return nil, err
+	}
+	if res.EdgeLBBackendPatch, err = parseEdgeLBBackendPatch(annotations[constants.EdgeLBBackendPatchAnnotationKey]); err != nil {
+		return nil, err
+	}
+	if res.EdgeLBFrontendPatch, err = parseEdgeLBFrontendPatch(annotations[constants.EdgeLBFrontendPatchAnnotationKey]); err != nil {
+		return nil, err
+	}
+
 	// Return the computed set of options.
 	return res, nil
 }
+
+// parseEdgeLBPoolAutoscalingOptions parses the autoscaling configuration for the target EdgeLB pool from the specified set of annotations, storing it in "res".
+// Autoscaling is enabled only when the maximum size of the EdgeLB pool is specified, in which case the requested size of the EdgeLB pool is used as its initial size (adjusted to the specified bounds).
+func parseEdgeLBPoolAutoscalingOptions(annotations map[string]string, res *BaseTranslationOptions) error {
+	var (
+		minSize = annotations[constants.EdgeLBPoolMinSizeAnnotationKey]
+		maxSize = annotations[constants.EdgeLBPoolMaxSizeAnnotationKey]
+		target  = annotations[constants.EdgeLBPoolTargetConnectionsPerInstanceAnnotationKey]
+		err     error
+	)
+	if maxSize == "" {
+		if minSize != "" || target != "" {
+			return errors.New("the maximum size of the edgelb pool must be specified in order to enable autoscaling")
+		}
+		return nil
+	}
+	if res.EdgeLBPoolMaxSize, err = parseEdgeLBPoolAutoscalingValue(maxSize, 0, "maximum size"); err != nil {
+		return err
+	}
+	if res.EdgeLBPoolMinSize, err = parseEdgeLBPoolAutoscalingValue(minSize, DefaultEdgeLBPoolMinSize, "minimum size"); err != nil {
+		return err
+	}
+	if res.EdgeLBPoolTargetConnectionsPerInstance, err = parseEdgeLBPoolAutoscalingValue(target, DefaultEdgeLBPoolTargetConnectionsPerInstance, "target number of connections per instance"); err != nil {
+		return err
+	}
+	if res.EdgeLBPoolMinSize > res.EdgeLBPoolMaxSize {
+		return fmt.Errorf("the minimum size of the edgelb pool (%d) cannot be greater than its maximum size (%d)", res.EdgeLBPoolMinSize, res.EdgeLBPoolMaxSize)
+	}
+	res.EdgeLBPoolSize = ClampEdgeLBPoolSize(res.EdgeLBPoolSize, *res)
+	return nil
+}
+
+// parseEdgeLBPoolAutoscalingValue parses the specified value as a positive integer used to configure autoscaling of the target EdgeLB pool, returning the specified default value in case said value is empty.
+func parseEdgeLBPoolAutoscalingValue(v string, defaultValue int, name string) (int, error) {
+	if v == "" {
+		return defaultValue, nil
+	}
+	r, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse %q as the %s of the edgelb pool: %v", v, name, err)
+	}
+	if r <= 0 {
+		return 0, fmt.Errorf("%d is not a valid %s", r, name)
+	}
+	return r, nil
+}
+
+// parseEdgeLBPoolDriftPolicy parses the specified value as the way in which changes made out-of-band to EdgeLB objects are handled, returning the default policy in case said value is empty.
+func parseEdgeLBPoolDriftPolicy(v string) (constants.EdgeLBPoolDriftPolicy, error) {
+	switch v {
+	case "":
+		return DefaultEdgeLBPoolDriftPolicy, nil
+	case string(constants.EdgeLBPoolDriftPolicyCorrect):
+		return constants.EdgeLBPoolDriftPolicyCorrect, nil
+	case string(constants.EdgeLBPoolDriftPolicyReport):
+		return constants.EdgeLBPoolDriftPolicyReport, nil
+	default:
+		return "", fmt.Errorf("failed to parse %q as a drift policy", v)
+	}
+}
+
+// parseEdgeLBBackendBalance parses the specified value as the load-balancing algorithm to use in EdgeLB backends, returning the default algorithm in case said value is empty.
+// Besides "roundrobin", "leastconn", "source" and "uri", hashing of an HTTP header can be requested by using "hdr(<header-name>)".
+func parseEdgeLBBackendBalance(v string) (string, error) {
+	switch v {
+	case "":
+		return DefaultEdgeLBBackendBalance, nil
+	case constants.EdgeLBBackendBalanceLeastConnections,
+		constants.EdgeLBBackendBalanceRoundRobin,
+		constants.EdgeLBBackendBalanceSourceHash,
+		constants.EdgeLBBackendBalanceURIHash:
+		return v, nil
+	}
+	if m := edgeLBBackendBalanceHeaderHashRegex.FindStringSubmatch(v); m != nil {
+		return fmt.Sprintf(constants.EdgeLBBackendBalanceHeaderHashFormatString, m[1]), nil
+	}
+	return "", fmt.Errorf("failed to parse %q as a load-balancing algorithm", v)
+}
+
+// isHTTPOnlyEdgeLBBackendBalance indicates whether the specified load-balancing algorithm can only be used by EdgeLB backends operating in HTTP mode.
+func isHTTPOnlyEdgeLBBackendBalance(balance string) bool {
+	return balance == constants.EdgeLBBackendBalanceURIHash || edgeLBBackendBalanceHeaderHashRegex.MatchString(balance)
+}
@@ -1,8 +1,14 @@
 package translator
 
 import (
+	"encoding/json"
 	"fmt"
 
+	"github.com/mesosphere/dcos-edge-lb/models"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/mesosphere/dklb/pkg/constants"
+	"github.com/mesosphere/dklb/pkg/util/pointers"
 	"github.com/mesosphere/dklb/pkg/util/strings"
 )
 
@@ -23,3 +29,143 @@ func ComputeEdgeLBPoolName(prefix, clusterName, namespace, name string) string {
 	}
 	return fmt.Sprintf(edgeLBPoolNameFormatString, prefix, strings.ReplaceForwardSlashes(clusterName, edgeLBPoolNameComponentSeparator), namespace, name)
 }
+
+// computeEdgeLBPoolCpus computes the number of CPUs to request for each instance of the EdgeLB pool based on the specified translation options.
+func computeEdgeLBPoolCpus(options BaseTranslationOptions) float64 {
+	return float64(options.EdgeLBPoolCpus.MilliValue()) / 1000
+}
+
+// computeEdgeLBPoolMem computes the amount of memory (in MiB) to request for each instance of the EdgeLB pool based on the specified translation options.
+func computeEdgeLBPoolMem(options BaseTranslationOptions) int32 {
+	return int32(options.EdgeLBPoolMem.Value() / (1024 * 1024))
+}
+
+// ClampEdgeLBPoolSize adjusts the specified size of the EdgeLB pool to the bounds specified in the translation options in case autoscaling is enabled.
+// In case autoscaling is disabled, the specified size is returned unchanged.
+func ClampEdgeLBPoolSize(size int, options BaseTranslationOptions) int {
+	if !options.IsEdgeLBPoolAutoscalingEnabled() {
+		return size
+	}
+	if size < options.EdgeLBPoolMinSize {
+		return options.EdgeLBPoolMinSize
+	}
+	if size > options.EdgeLBPoolMaxSize {
+		return options.EdgeLBPoolMaxSize
+	}
+	return size
+}
+
+// computeEdgeLBPoolSize computes the size of the specified EdgeLB pool based on the specified translation options.
+// In case autoscaling is enabled, the current size of the EdgeLB pool (as set by the autoscaler) is kept as long as it is within the requested bounds.
+func computeEdgeLBPoolSize(pool *models.V2Pool, options BaseTranslationOptions) int32 {
+	if options.IsEdgeLBPoolAutoscalingEnabled() && pool.Count != nil {
+		return int32(ClampEdgeLBPoolSize(int(*pool.Count), options))
+	}
+	return int32(options.EdgeLBPoolSize)
+}
+
+// edgeLBPoolResources holds the CPU and memory requests and the size requested for the target EdgeLB pool by a given Ingress/Service resource.
+type edgeLBPoolResources struct {
+	// Cpus is the number of CPUs requested for each instance of the EdgeLB pool.
+	Cpus float64 `json:"cpus"`
+	// Mem is the amount of memory (in MiB) requested for each instance of the EdgeLB pool.
+	Mem int32 `json:"mem"`
+	// Size is the requested size of the EdgeLB pool.
+	Size int `json:"size"`
+	// MinSize is the minimum size of the EdgeLB pool when autoscaling is enabled.
+	MinSize int `json:"minSize,omitempty"`
+	// MaxSize is the maximum size of the EdgeLB pool when autoscaling is enabled.
+	MaxSize int `json:"maxSize,omitempty"`
+}
+
+// computeEdgeLBPoolResources computes the CPU and memory requests and the size requested for the target EdgeLB pool based on the specified translation options.
+func computeEdgeLBPoolResources(options BaseTranslationOptions) edgeLBPoolResources {
+	return edgeLBPoolResources{
+		Cpus:    computeEdgeLBPoolCpus(options),
+		Mem:     computeEdgeLBPoolMem(options),
+		Size:    options.EdgeLBPoolSize,
+		MinSize: options.EdgeLBPoolMinSize,
+		MaxSize: options.EdgeLBPoolMaxSize,
+	}
+}
+
+// currentEdgeLBPoolResources returns the CPU and memory requests and the size currently set on the specified EdgeLB pool.
+func currentEdgeLBPoolResources(pool *models.V2Pool) edgeLBPoolResources {
+	res := edgeLBPoolResources{
+		Cpus: pool.Cpus,
+		Mem:  pool.Mem,
+	}
+	if pool.Count != nil {
+		res.Size = int(*pool.Count)
+	}
+	return res
+}
+
+// String returns the JSON representation of the requested resources, as recorded in the "kubernetes.dcos.io/edgelb-pool-applied-resources" annotation.
+func (r edgeLBPoolResources) String() string {
+	// Marshaling the requested resources never fails.
+	v, _ := json.Marshal(r)
+	return string(v)
+}
+
+// GetEdgeLBPoolAppliedResources returns the JSON description of the CPU and memory requests and of the size last applied to the target EdgeLB pool for the specified resource.
+// An empty string is returned in case these have never been applied.
+func GetEdgeLBPoolAppliedResources(obj metav1.Object) string {
+	return obj.GetAnnotations()[constants.EdgeLBPoolAppliedResourcesAnnotationKey]
+}
+
+// SetEdgeLBPoolAppliedResources sets the JSON description of the CPU and memory requests and of the size last applied to the target EdgeLB pool for the specified resource.
+// It returns a value indicating whether the value of the corresponding annotation was changed.
+func SetEdgeLBPoolAppliedResources(obj metav1.Object, resources string) bool {
+	if resources == "" || GetEdgeLBPoolAppliedResources(obj) == resources {
+		return false
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[constants.EdgeLBPoolAppliedResourcesAnnotationKey] = resources
+	obj.SetAnnotations(annotations)
+	return true
+}
+
+// updateEdgeLBPoolResources updates the CPU and memory requests and the size of the specified EdgeLB pool in-place in order to reflect the specified translation options.
+// As an EdgeLB pool may be shared by several Ingress/Service resources requesting different resources, a field is only updated in case the value requested for it has changed since it was last applied for the current resource (as described by "lastApplied").
+// In case nothing has been applied yet for the current resource (e.g. because it has just started using an existing EdgeLB pool), the requested values are compared against the EdgeLB pool's current values instead.
+// Nothing is changed in case the EdgeLB pool creation strategy is "Never", as the EdgeLB pool is not managed by the current resource.
+// In case autoscaling is enabled, the size of the EdgeLB pool is only changed if it falls outside the requested bounds.
+// It returns a value indicating whether the EdgeLB pool was changed.
+// EdgeLB performs a rolling update of the EdgeLB pool's instances whenever any of these fields changes, so these changes don't cause downtime as long as the EdgeLB pool has more than one instance.
+func updateEdgeLBPoolResources(pool *models.V2Pool, options BaseTranslationOptions, lastApplied string, report *poolInspectionReport) bool {
+	if options.EdgeLBPoolCreationStrategy == constants.EdgeLBPoolCreationStrategyNever {
+		return false
+	}
+	var previous edgeLBPoolResources
+	if lastApplied == "" {
+		previous = currentEdgeLBPoolResources(pool)
+	} else if err := json.Unmarshal([]byte(lastApplied), &previous); err != nil {
+		return false
+	}
+	var (
+		desired    = computeEdgeLBPoolResources(options)
+		wasChanged = false
+	)
+	if desired.Cpus != previous.Cpus && pool.Cpus != desired.Cpus {
+		report.ModifyPool(newFieldDiff("cpus", pool.Cpus, desired.Cpus))
+		pool.Cpus = desired.Cpus
+		wasChanged = true
+	}
+	if desired.Mem != previous.Mem && pool.Mem != desired.Mem {
+		report.ModifyPool(newFieldDiff("mem", pool.Mem, desired.Mem))
+		pool.Mem = desired.Mem
+		wasChanged = true
+	}
+	if desired.Size != previous.Size || desired.MinSize != previous.MinSize || desired.MaxSize != previous.MaxSize {
+		if size := computeEdgeLBPoolSize(pool, options); pool.Count == nil || *pool.Count != size {
+			report.ModifyPool(newFieldDiff("count", pool.Count, size))
+			pool.Count = pointers.NewInt32(size)
+			wasChanged = true
+		}
+	}
+	return wasChanged
+}
@@ -0,0 +1,228 @@
+package translator
+
+import (
+	"testing"
+
+	"github.com/mesosphere/dcos-edge-lb/models"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/mesosphere/dklb/pkg/constants"
+	"github.com/mesosphere/dklb/pkg/util/pointers"
+	edgelbpooltestutil "github.com/mesosphere/dklb/test/util/edgelb/pool"
+	servicetestutil "github.com/mesosphere/dklb/test/util/kubernetes/service"
+)
+
+// TestUpdateEdgeLBPoolResources tests the "updateEdgeLBPoolResources" function.
+func TestUpdateEdgeLBPoolResources(t *testing.T) {
+	options := BaseTranslationOptions{
+		EdgeLBPoolCpus:             resource.MustParse("250m"),
+		EdgeLBPoolMem:              resource.MustParse("256Mi"),
+		EdgeLBPoolSize:             2,
+		EdgeLBPoolCreationStrategy: constants.EdgeLBPoolCreationStrategyIfNotPresent,
+	}
+	// sharedPool returns an EdgeLB pool whose resources have been set by another Ingress/Service resource sharing it.
+	sharedPool := func() *models.V2Pool {
+		return edgelbpooltestutil.DummyEdgeLBPool("foo", func(p *models.V2Pool) {
+			p.Cpus = 0.5
+			p.Mem = 512
+			p.Count = pointers.NewInt32(3)
+		})
+	}
+	tests := []struct {
+		description        string
+		pool               *models.V2Pool
+		options            BaseTranslationOptions
+		lastApplied        string
+		expectedWasChanged bool
+		expectedCpus       float64
+		expectedMem        int32
+		expectedCount      *int32
+	}{
+		{
+			description: "pool matching the requested resources",
+			pool: edgelbpooltestutil.DummyEdgeLBPool("foo", func(p *models.V2Pool) {
+				p.Cpus = 0.25
+				p.Mem = 256
+				p.Count = pointers.NewInt32(2)
+			}),
+			options:            options,
+			lastApplied:        `{"cpus":0.1,"mem":128,"size":1}`,
+			expectedWasChanged: false,
+			expectedCpus:       0.25,
+			expectedMem:        256,
+			expectedCount:      pointers.NewInt32(2),
+		},
+		{
+			description:        "shared pool and requested resources changed since last applied",
+			pool:               sharedPool(),
+			options:            options,
+			lastApplied:        `{"cpus":0.1,"mem":128,"size":1}`,
+			expectedWasChanged: true,
+			expectedCpus:       0.25,
+			expectedMem:        256,
+			expectedCount:      pointers.NewInt32(2),
+		},
+		{
+			description:        "shared pool and only the cpu request changed since last applied",
+			pool:               sharedPool(),
+			options:            options,
+			lastApplied:        `{"cpus":0.1,"mem":256,"size":2}`,
+			expectedWasChanged: true,
+			expectedCpus:       0.25,
+			expectedMem:        512,
+			expectedCount:      pointers.NewInt32(3),
+		},
+		{
+			description:        "shared pool and requested resources unchanged since last applied",
+			pool:               sharedPool(),
+			options:            options,
+			lastApplied:        computeEdgeLBPoolResources(options).String(),
+			expectedWasChanged: false,
+			expectedCpus:       0.5,
+			expectedMem:        512,
+			expectedCount:      pointers.NewInt32(3),
+		},
+		{
+			description:        "shared pool and requested resources never applied",
+			pool:               sharedPool(),
+			options:            options,
+			lastApplied:        "",
+			expectedWasChanged: true,
+			expectedCpus:       0.25,
+			expectedMem:        256,
+			expectedCount:      pointers.NewInt32(2),
+		},
+		{
+			description: "pool matching the requested resources and requested resources never applied",
+			pool: edgelbpooltestutil.DummyEdgeLBPool("foo", func(p *models.V2Pool) {
+				p.Cpus = 0.25
+				p.Mem = 256
+				p.Count = pointers.NewInt32(2)
+			}),
+			options:            options,
+			lastApplied:        "",
+			expectedWasChanged: false,
+			expectedCpus:       0.25,
+			expectedMem:        256,
+			expectedCount:      pointers.NewInt32(2),
+		},
+		{
+			description: "pool within the autoscaling bounds and requested resources never applied",
+			pool:        sharedPool(),
+			options: func() BaseTranslationOptions {
+				o := options
+				o.EdgeLBPoolMinSize = 1
+				o.EdgeLBPoolMaxSize = 4
+				return o
+			}(),
+			lastApplied:        "",
+			expectedWasChanged: true,
+			expectedCpus:       0.25,
+			expectedMem:        256,
+			expectedCount:      pointers.NewInt32(3),
+		},
+		{
+			description:        "shared pool and invalid description of the last applied resources",
+			pool:               sharedPool(),
+			options:            options,
+			lastApplied:        "{",
+			expectedWasChanged: false,
+			expectedCpus:       0.5,
+			expectedMem:        512,
+			expectedCount:      pointers.NewInt32(3),
+		},
+		{
+			description: "pool not managed by the resource",
+			pool:        sharedPool(),
+			options: func() BaseTranslationOptions {
+				o := options
+				o.EdgeLBPoolCreationStrategy = constants.EdgeLBPoolCreationStrategyNever
+				return o
+			}(),
+			lastApplied:        `{"cpus":0.1,"mem":128,"size":1}`,
+			expectedWasChanged: false,
+			expectedCpus:       0.5,
+			expectedMem:        512,
+			expectedCount:      pointers.NewInt32(3),
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		var report poolInspectionReport
+		assert.Equal(t, test.expectedWasChanged, updateEdgeLBPoolResources(test.pool, test.options, test.lastApplied, &report))
+		assert.Equal(t, test.expectedCpus, test.pool.Cpus)
+		assert.Equal(t, test.expectedMem, test.pool.Mem)
+		assert.Equal(t, test.expectedCount, test.pool.Count)
+	}
+}
+
+// TestSetEdgeLBPoolAppliedResources tests the "SetEdgeLBPoolAppliedResources" function.
+func TestSetEdgeLBPoolAppliedResources(t *testing.T) {
+	service := servicetestutil.DummyServiceResource("foo", "bar")
+	// Make sure that an empty value is never recorded.
+	assert.False(t, SetEdgeLBPoolAppliedResources(service, ""))
+	assert.Equal(t, "", GetEdgeLBPoolAppliedResources(service))
+	// Make sure that a new value is recorded, and that recording it again is reported as a no-op.
+	assert.True(t, SetEdgeLBPoolAppliedResources(service, `{"cpus":0.1,"mem":128,"size":1}`))
+	assert.False(t, SetEdgeLBPoolAppliedResources(service, `{"cpus":0.1,"mem":128,"size":1}`))
+	assert.Equal(t, `{"cpus":0.1,"mem":128,"size":1}`, GetEdgeLBPoolAppliedResources(service))
+}
+
+// TestComputeEdgeLBPoolSize tests the "computeEdgeLBPoolSize" function.
+func TestComputeEdgeLBPoolSize(t *testing.T) {
+	autoscaling := BaseTranslationOptions{
+		EdgeLBPoolSize:    2,
+		EdgeLBPoolMinSize: 2,
+		EdgeLBPoolMaxSize: 5,
+	}
+	tests := []struct {
+		description  string
+		pool         *models.V2Pool
+		options      BaseTranslationOptions
+		expectedSize int32
+	}{
+		{
+			description: "autoscaling disabled",
+			pool: edgelbpooltestutil.DummyEdgeLBPool("foo", func(p *models.V2Pool) {
+				p.Count = pointers.NewInt32(4)
+			}),
+			options:      BaseTranslationOptions{EdgeLBPoolSize: 3},
+			expectedSize: 3,
+		},
+		{
+			description: "autoscaling enabled and pool within bounds",
+			pool: edgelbpooltestutil.DummyEdgeLBPool("foo", func(p *models.V2Pool) {
+				p.Count = pointers.NewInt32(4)
+			}),
+			options:      autoscaling,
+			expectedSize: 4,
+		},
+		{
+			description: "autoscaling enabled and pool above the maximum size",
+			pool: edgelbpooltestutil.DummyEdgeLBPool("foo", func(p *models.V2Pool) {
+				p.Count = pointers.NewInt32(7)
+			}),
+			options:      autoscaling,
+			expectedSize: 5,
+		},
+		{
+			description: "autoscaling enabled and pool below the minimum size",
+			pool: edgelbpooltestutil.DummyEdgeLBPool("foo", func(p *models.V2Pool) {
+				p.Count = pointers.NewInt32(1)
+			}),
+			options:      autoscaling,
+			expectedSize: 2,
+		},
+		{
+			description:  "autoscaling enabled and pool without a size",
+			pool:         edgelbpooltestutil.DummyEdgeLBPool("foo"),
+			options:      autoscaling,
+			expectedSize: 2,
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		assert.Equal(t, test.expectedSize, computeEdgeLBPoolSize(test.pool, test.options))
+	}
+}
@@ -0,0 +1,155 @@
+package translator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mesosphere/dcos-edge-lb/models"
+	corev1 "k8s.io/api/core/v1"
+	extsv1beta1 "k8s.io/api/extensions/v1beta1"
+
+	"github.com/mesosphere/dklb/pkg/dcos/secrets"
+	"github.com/mesosphere/dklb/pkg/edgelb/manager"
+	dklberrors "github.com/mesosphere/dklb/pkg/errors"
+)
+
+// IsEdgeLBPoolCleanedUpForService reads the EdgeLB pool with the specified name from the EdgeLB API server and returns a value indicating whether it doesn't contain any EdgeLB objects owned by the specified Service resource.
+// EdgeLB pools that don't exist are considered to be cleaned up.
+// It is used to confirm that the EdgeLB objects owned by a Service resource have been removed before allowing it to be deleted.
+func IsEdgeLBPoolCleanedUpForService(manager manager.EdgeLBManager, poolName, clusterName string, service *corev1.Service) (bool, error) {
+	pool, err := getEdgeLBPoolForCleanup(manager, poolName)
+	if err != nil || pool == nil {
+		return err == nil, err
+	}
+	isOwned := func(name string) bool {
+		metadata, err := computeServiceOwnedEdgeLBObjectMetadata(name)
+		return err == nil && metadata.IsOwnedBy(clusterName, service)
+	}
+	return !containsOwnedEdgeLBObjects(pool, isOwned), nil
+}
+
+// IsEdgeLBPoolCleanedUpForIngress reads the EdgeLB pool with the specified name from the EdgeLB API server and returns a value indicating whether it doesn't contain any EdgeLB objects or secrets owned by the specified Ingress resource.
+// EdgeLB pools that don't exist are considered to be cleaned up.
+// It is used to confirm that the EdgeLB objects owned by an Ingress resource have been removed before allowing it to be deleted.
+func IsEdgeLBPoolCleanedUpForIngress(manager manager.EdgeLBManager, poolName, clusterName string, ingress *extsv1beta1.Ingress) (bool, error) {
+	pool, err := getEdgeLBPoolForCleanup(manager, poolName)
+	if err != nil || pool == nil {
+		return err == nil, err
+	}
+	isOwned := func(name string) bool {
+		metadata, err := computeIngressOwnedEdgeLBObjectMetadata(name)
+		return err == nil && metadata.IsOwnedBy(clusterName, ingress)
+	}
+	if containsOwnedEdgeLBObjects(pool, isOwned) {
+		return false, nil
+	}
+	for _, secret := range pool.Secrets {
+		if isEdgeLBSecretOwnedByIngress(clusterName, ingress, secret) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// RemoveEdgeLBObjectsOwnedBy removes the EdgeLB objects (and EdgeLB pool secrets) owned by the specified Ingress/Service resource from every EdgeLB pool in the EdgeLB pool group, deleting the EdgeLB pools that are left empty as a result.
+// It is used to clean up after an Ingress/Service resource that has been deleted but whose translation options cannot be computed (e.g. because its annotations are not valid), in which case the name of its target EdgeLB pool cannot be determined.
+// DC/OS secrets that are not referenced by an updated/deleted EdgeLB pool anymore are deleted as well, unless "secretsManager" is nil.
+// It returns the names of all the EdgeLB pools in the EdgeLB pool group, so that the caller can confirm that none of them contains EdgeLB objects owned by the resource anymore.
+func RemoveEdgeLBObjectsOwnedBy(edgelbManager manager.EdgeLBManager, secretsManager secrets.SecretsManager, clusterName string, owner EdgeLBObjectOwner) ([]string, error) {
+	ctx, fn := context.WithTimeout(context.Background(), defaultEdgeLBManagerTimeout)
+	defer fn()
+	pools, err := edgelbManager.GetPools(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list edgelb pools: %v", err)
+	}
+	res := make([]string, 0, len(pools))
+	for _, pool := range pools {
+		// Skip EdgeLB pools that dklb doesn't manage.
+		if pool.Namespace == nil || *pool.Namespace != edgelbManager.PoolGroup() {
+			continue
+		}
+		res = append(res, pool.Name)
+		// Keep track of the EdgeLB pool's secrets before removing the EdgeLB objects owned by the resource, so that the DC/OS secrets that are not referenced anymore can be deleted.
+		previousSecrets := pool.Secrets
+		removed := RemoveOrphanedEdgeLBObjects(pool, clusterName, func(o EdgeLBObjectOwner) bool {
+			return o == owner
+		})
+		if len(removed) == 0 {
+			continue
+		}
+		deleted, err := removeEdgeLBObjectsFromPool(edgelbManager, pool)
+		if err != nil {
+			return nil, err
+		}
+		currentSecrets := pool.Secrets
+		if deleted {
+			currentSecrets = nil
+		}
+		// DC/OS secrets are never actually deleted in dry-run mode, as the EdgeLB pool still references them.
+		if secretsManager == nil || manager.IsDryRun(edgelbManager) {
+			continue
+		}
+		if err := DeleteDCOSSecrets(secretsManager, ComputeOrphanedDCOSSecretPaths(clusterName, previousSecrets, currentSecrets)); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// removeEdgeLBObjectsFromPool updates the specified EdgeLB pool after EdgeLB objects have been removed from it, or deletes it in case it has been left without any EdgeLB backends or frontends.
+// It returns a value indicating whether the EdgeLB pool was deleted.
+func removeEdgeLBObjectsFromPool(edgelbManager manager.EdgeLBManager, pool *models.V2Pool) (bool, error) {
+	ctx, fn := context.WithTimeout(context.Background(), defaultEdgeLBManagerTimeout)
+	defer fn()
+	if len(pool.Haproxy.Frontends) == 0 && len(pool.Haproxy.Backends) == 0 {
+		if err := edgelbManager.DeletePool(ctx, pool.Name); err != nil && !dklberrors.IsNotFound(err) {
+			return false, fmt.Errorf("failed to delete edgelb pool %q: %v", pool.Name, err)
+		}
+		return true, nil
+	}
+	if _, err := edgelbManager.UpdatePool(ctx, pool); err != nil {
+		return false, fmt.Errorf("failed to update edgelb pool %q: %v", pool.Name, err)
+	}
+	return false, nil
+}
+
+// getEdgeLBPoolForCleanup reads the EdgeLB pool with the specified name from the EdgeLB API server.
+// nil is returned in case the EdgeLB pool doesn't exist.
+func getEdgeLBPoolForCleanup(manager manager.EdgeLBManager, poolName string) (*models.V2Pool, error) {
+	ctx, fn := context.WithTimeout(context.Background(), defaultEdgeLBManagerTimeout)
+	defer fn()
+	pool, err := manager.GetPool(ctx, poolName)
+	if err != nil {
+		if dklberrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read edgelb pool %q: %v", poolName, err)
+	}
+	return pool, nil
+}
+
+// containsOwnedEdgeLBObjects returns a value indicating whether the specified EdgeLB pool contains EdgeLB backends or frontends (or items of frontends shared via TLS SNI) for which "isOwned" returns true.
+func containsOwnedEdgeLBObjects(pool *models.V2Pool, isOwned func(name string) bool) bool {
+	if pool.Haproxy == nil {
+		return false
+	}
+	for _, backend := range pool.Haproxy.Backends {
+		if isOwned(backend.Name) {
+			return true
+		}
+	}
+	for _, frontend := range pool.Haproxy.Frontends {
+		if isOwned(frontend.Name) {
+			return true
+		}
+		if frontend.LinkBackend == nil {
+			continue
+		}
+		for _, item := range frontend.LinkBackend.Map {
+			if isOwned(item.Backend) {
+				return true
+			}
+		}
+	}
+	return false
+}
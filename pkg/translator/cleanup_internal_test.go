@@ -0,0 +1,158 @@
+package translator
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mesosphere/dcos-edge-lb/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	dklberrors "github.com/mesosphere/dklb/pkg/errors"
+	secretstestutil "github.com/mesosphere/dklb/test/util/dcos/secrets"
+	edgelbmanagertestutil "github.com/mesosphere/dklb/test/util/edgelb/manager"
+	edgelbpooltestutil "github.com/mesosphere/dklb/test/util/edgelb/pool"
+	servicetestutil "github.com/mesosphere/dklb/test/util/kubernetes/service"
+)
+
+// TestIsEdgeLBPoolCleanedUpForService tests the "IsEdgeLBPoolCleanedUpForService" function.
+func TestIsEdgeLBPoolCleanedUpForService(t *testing.T) {
+	tests := []struct {
+		description       string
+		pool              *models.V2Pool
+		err               error
+		expectedCleanedUp bool
+		expectedError     bool
+	}{
+		{
+			description:       "pool that doesn't exist",
+			err:               dklberrors.NotFound(errors.New("not found")),
+			expectedCleanedUp: true,
+		},
+		{
+			description:   "pool that cannot be read",
+			err:           errors.New("connection refused"),
+			expectedError: true,
+		},
+		{
+			description: "pool containing only objects owned by other resources",
+			pool: edgelbpooltestutil.DummyEdgeLBPool("foo", func(p *models.V2Pool) {
+				p.Haproxy.Backends = []*models.V2Backend{
+					{Name: "dev.kubernetes01:foo:baz:80"},
+				}
+				p.Haproxy.Frontends = []*models.V2Frontend{
+					{Name: "dev.kubernetes01:foo:baz:80"},
+				}
+			}),
+			expectedCleanedUp: true,
+		},
+		{
+			description: "pool containing a backend owned by the service",
+			pool: edgelbpooltestutil.DummyEdgeLBPool("foo", func(p *models.V2Pool) {
+				p.Haproxy.Backends = []*models.V2Backend{
+					{Name: "dev.kubernetes01:foo:bar:80"},
+				}
+			}),
+			expectedCleanedUp: false,
+		},
+		{
+			description: "pool containing a tls sni frontend item pointing at a backend owned by the service",
+			pool: edgelbpooltestutil.DummyEdgeLBPool("foo", func(p *models.V2Pool) {
+				p.Haproxy.Frontends = []*models.V2Frontend{
+					{
+						Name: "sni:443",
+						LinkBackend: &models.V2FrontendLinkBackend{
+							Map: []*models.V2FrontendLinkBackendMapItems0{
+								{Backend: "dev.kubernetes01:foo:bar:443", HostEq: "foo.example.com"},
+							},
+						},
+					},
+				}
+			}),
+			expectedCleanedUp: false,
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		m := new(edgelbmanagertestutil.MockEdgeLBManager)
+		m.On("GetPool", mock.Anything, "foo").Return(test.pool, test.err)
+		cleanedUp, err := IsEdgeLBPoolCleanedUpForService(m, "foo", testClusterName, servicetestutil.DummyServiceResource("foo", "bar"))
+		if test.expectedError {
+			assert.Error(t, err)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, test.expectedCleanedUp, cleanedUp)
+	}
+}
+
+// TestRemoveEdgeLBObjectsOwnedBy tests the "RemoveEdgeLBObjectsOwnedBy" function.
+func TestRemoveEdgeLBObjectsOwnedBy(t *testing.T) {
+	var (
+		owner       = EdgeLBObjectOwner{Kind: EdgeLBObjectOwnerKindIngress, Namespace: "foo", Name: "bar"}
+		poolGroup   = testEdgeLBPoolGroup
+		otherGroup  = "other/pools"
+		ownedSecret = testEdgeLBPoolGroup + "/shared/dev.kubernetes01__foo__tls-1"
+	)
+	// shared is an EdgeLB pool shared with another Ingress resource, and hence must be updated.
+	shared := edgelbpooltestutil.DummyEdgeLBPool("shared", func(p *models.V2Pool) {
+		p.Namespace = &poolGroup
+		p.Haproxy.Backends = []*models.V2Backend{
+			{Name: "dev.kubernetes01:foo:bar:baz:80"},
+			{Name: "dev.kubernetes01:foo:qux:baz:80"},
+		}
+		p.Haproxy.Frontends = []*models.V2Frontend{
+			{Name: "dev.kubernetes01:foo:bar"},
+			{Name: "dev.kubernetes01:foo:qux"},
+		}
+		p.Secrets = []*models.V2PoolSecretsItems0{
+			{File: "dev.kubernetes01__foo__bar__tls-1__0123456789", Secret: ownedSecret},
+		}
+	})
+	// dedicated is an EdgeLB pool used only by the Ingress resource, and hence must be deleted.
+	dedicated := edgelbpooltestutil.DummyEdgeLBPool("dedicated", func(p *models.V2Pool) {
+		p.Namespace = &poolGroup
+		p.Haproxy.Backends = []*models.V2Backend{
+			{Name: "dev.kubernetes01:foo:bar:baz:80"},
+		}
+		p.Haproxy.Frontends = []*models.V2Frontend{
+			{Name: "dev.kubernetes01:foo:bar"},
+		}
+	})
+	// unrelated is an EdgeLB pool that doesn't contain any EdgeLB objects owned by the Ingress resource, and hence must not be touched.
+	unrelated := edgelbpooltestutil.DummyEdgeLBPool("unrelated", func(p *models.V2Pool) {
+		p.Namespace = &poolGroup
+		p.Haproxy.Backends = []*models.V2Backend{
+			{Name: "dev.kubernetes01:foo:qux:baz:80"},
+		}
+	})
+	// external is an EdgeLB pool in a different EdgeLB pool group, and hence must not be touched.
+	external := edgelbpooltestutil.DummyEdgeLBPool("external", func(p *models.V2Pool) {
+		p.Namespace = &otherGroup
+		p.Haproxy.Backends = []*models.V2Backend{
+			{Name: "dev.kubernetes01:foo:bar:baz:80"},
+		}
+	})
+
+	m := new(edgelbmanagertestutil.MockEdgeLBManager)
+	m.On("GetPools", mock.Anything).Return([]*models.V2Pool{shared, dedicated, unrelated, external}, nil)
+	m.On("PoolGroup").Return(testEdgeLBPoolGroup)
+	m.On("UpdatePool", mock.Anything).Return(shared, nil)
+	m.On("DeletePool", mock.Anything, "dedicated").Return(nil)
+	s := new(secretstestutil.MockSecretsManager)
+	s.On("DeleteSecret", mock.Anything, ownedSecret).Return(nil)
+
+	poolNames, err := RemoveEdgeLBObjectsOwnedBy(m, s, testClusterName, owner)
+	assert.NoError(t, err)
+	// Make sure that all EdgeLB pools in the EdgeLB pool group are returned, so that they can all be confirmed to have been cleaned up.
+	assert.Equal(t, []string{"shared", "dedicated", "unrelated"}, poolNames)
+	// Make sure that only the EdgeLB objects owned by the Ingress resource have been removed from the shared EdgeLB pool.
+	assert.Equal(t, []*models.V2Backend{{Name: "dev.kubernetes01:foo:qux:baz:80"}}, shared.Haproxy.Backends)
+	assert.Equal(t, []*models.V2Frontend{{Name: "dev.kubernetes01:foo:qux"}}, shared.Haproxy.Frontends)
+	assert.Empty(t, shared.Secrets)
+	m.AssertNumberOfCalls(t, "UpdatePool", 1)
+	m.AssertNumberOfCalls(t, "DeletePool", 1)
+	s.AssertExpectations(t)
+	// Make sure that EdgeLB pools in a different EdgeLB pool group have not been touched.
+	assert.Len(t, external.Haproxy.Backends, 1)
+}
@@ -9,22 +9,51 @@ import (
 )
 
 const (
+	// DefaultEdgeLBBackendBalance is the load-balancing algorithm to use in EdgeLB backends when a value is not provided.
+	DefaultEdgeLBBackendBalance = constants.EdgeLBBackendBalanceLeastConnections
+	// DefaultEdgeLBBackendTarget is the kind of servers targeted by EdgeLB backends when a value is not provided.
+	DefaultEdgeLBBackendTarget = constants.EdgeLBBackendTargetNodePort
 	// DefaultEdgeLBPoolCreationStrategy is the strategy to use for creating an EdgeLB pool when a value is not provided.
 	DefaultEdgeLBPoolCreationStrategy = constants.EdgeLBPoolCreationStrategyIfNotPresent
-	// DefaultEdgeLBPoolPort is the port to use as the frontend bind port for an EdgeLB pool used to provision an Ingress resource when a value is not provided.
-	// TODO (@bcustodio) Split into HTTP/HTTPS port when TLS support is introduced.
-	DefaultEdgeLBPoolPort = 80
+	// DefaultEdgeLBPoolDriftPolicy is the way in which changes made out-of-band to the EdgeLB objects owned by an Ingress/Service resource are handled when a value is not provided.
+	DefaultEdgeLBPoolDriftPolicy = constants.EdgeLBPoolDriftPolicyCorrect
+	// DefaultEdgeLBPoolHTTPPort is the port to use as the bind port for the HTTP frontend of an EdgeLB pool used to provision an Ingress resource when a value is not provided.
+	DefaultEdgeLBPoolHTTPPort = 80
+	// DefaultEdgeLBPoolHTTPSPort is the port to use as the bind port for the HTTPS frontend of an EdgeLB pool used to provision an Ingress resource when a value is not provided.
+	DefaultEdgeLBPoolHTTPSPort = 443
+	// DefaultEdgeLBPathMatchType is the default way in which paths defined in Ingress resources are matched against the paths of incoming requests.
+	DefaultEdgeLBPathMatchType = constants.EdgeLBPathMatchTypeRegex
+	// DefaultEdgeLBStickySessionsCookieName is the name of the cookie used to implement sticky sessions when a value is not provided.
+	DefaultEdgeLBStickySessionsCookieName = "SERVERID"
+	// DefaultEdgeLBStickySessionsCookieTTL is the maximum lifetime of the cookie used to implement sticky sessions when a value is not provided.
+	// A value of zero means that the cookie has no maximum lifetime.
+	DefaultEdgeLBStickySessionsCookieTTL = 0 * time.Second
+	// DefaultEdgeLBSSLRedirectCode is the HTTP status code used when redirecting requests to the HTTPS frontend when a value is not provided.
+	DefaultEdgeLBSSLRedirectCode = 301
+	// DefaultEdgeLBHSTSMaxAge is the value of the "max-age" directive of the "Strict-Transport-Security" header when a value is not provided.
+	DefaultEdgeLBHSTSMaxAge = 365 * 24 * time.Hour
 	// DefaultEdgeLBPoolRole is the role to use for an EdgeLB pool when a value is not provided.
 	DefaultEdgeLBPoolRole = constants.EdgeLBRolePublic
 	// DefaultEdgeLBPoolNetwork is the name of the DC/OS virtual network to use when creating an EdgeLB pool for which no custom value was specified.
 	DefaultEdgeLBPoolNetwork = constants.DefaultDCOSVirtualNetworkName
 	// DefaultEdgeLBPoolSize is the size to use for an EdgeLB pool when a value is not provided.
 	DefaultEdgeLBPoolSize = 1
+	// DefaultEdgeLBPoolMinSize is the minimum size of an EdgeLB pool for which autoscaling is enabled when a value is not provided.
+	DefaultEdgeLBPoolMinSize = 1
+	// DefaultEdgeLBPoolTargetConnectionsPerInstance is the number of concurrent connections that each instance of an EdgeLB pool for which autoscaling is enabled should handle when a value is not provided.
+	DefaultEdgeLBPoolTargetConnectionsPerInstance = 1000
+	// DefaultEdgeLBPoolMigrationGracePeriod is the amount of time during which the old EdgeLB pool keeps serving traffic after the status of a resource being migrated has been switched to the new EdgeLB pool.
+	// It allows for clients (and DNS records) to pick up the new addresses before the old EdgeLB pool stops serving the resource.
+	DefaultEdgeLBPoolMigrationGracePeriod = 5 * time.Minute
+	// DefaultEdgeLBPoolMigrationPollInterval is the interval at which the new EdgeLB pool is checked for reported endpoints while a resource is being migrated.
+	DefaultEdgeLBPoolMigrationPollInterval = 15 * time.Second
 )
 
 const (
 	// defaultEdgeLBManagerTimeout is the default timeout used when interacting with the EdgeLB manager.
 	defaultEdgeLBManagerTimeout = 10 * time.Second
+	// defaultSecretsManagerTimeout is the default timeout used when interacting with the DC/OS secrets manager.
+	defaultSecretsManagerTimeout = 10 * time.Second
 )
 
 var (
@@ -0,0 +1,359 @@
+package translator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/mesosphere/dcos-edge-lb/models"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/mesosphere/dklb/pkg/constants"
+)
+
+const (
+	// appliedStateHashLength is the number of (hexadecimal) characters of the hash of the EdgeLB objects applied for an Ingress/Service resource that are stored in the "kubernetes.dcos.io/edgelb-pool-applied-state-hash" annotation.
+	appliedStateHashLength = 16
+	// maxDriftEventLines is the maximum number of differences included in the message of a "PoolDriftDetected" event.
+	// The full list of differences is always logged.
+	maxDriftEventLines = 10
+)
+
+// kubernetesObject represents an Ingress/Service resource, whose metadata can be read and changed and for which events can be emitted.
+type kubernetesObject interface {
+	metav1.Object
+	runtime.Object
+}
+
+// ownedEdgeLBObjects holds the EdgeLB objects in an EdgeLB pool that are owned by a given Ingress/Service resource.
+type ownedEdgeLBObjects struct {
+	// Backends holds the EdgeLB backends owned by the resource, indexed by name.
+	Backends map[string]*models.V2Backend `json:"backends"`
+	// Frontends holds the EdgeLB frontends owned by the resource, indexed by name.
+	Frontends map[string]*models.V2Frontend `json:"frontends"`
+	// SNIHostnames holds the items of EdgeLB frontends shared via TLS SNI that point at EdgeLB backends owned by the resource, indexed by "<frontend-name>/<hostname>".
+	SNIHostnames map[string]*models.V2FrontendLinkBackendMapItems0 `json:"sniHostnames"`
+}
+
+// computeOwnedEdgeLBObjects returns the EdgeLB backends and frontends (and items of frontends shared via TLS SNI) in the specified EdgeLB pool for which "isOwned" returns true.
+func computeOwnedEdgeLBObjects(pool *models.V2Pool, isOwned func(name string) bool) ownedEdgeLBObjects {
+	res := ownedEdgeLBObjects{
+		Backends:     make(map[string]*models.V2Backend),
+		Frontends:    make(map[string]*models.V2Frontend),
+		SNIHostnames: make(map[string]*models.V2FrontendLinkBackendMapItems0),
+	}
+	if pool.Haproxy == nil {
+		return res
+	}
+	for _, backend := range pool.Haproxy.Backends {
+		if isOwned(backend.Name) {
+			res.Backends[backend.Name] = backend
+		}
+	}
+	for _, frontend := range pool.Haproxy.Frontends {
+		if isOwned(frontend.Name) {
+			res.Frontends[frontend.Name] = computeEdgeLBFrontendWithOwnedLinkBackendMapItems(frontend, isOwned)
+			continue
+		}
+		// Items of EdgeLB frontends owned by other Ingress resources that point at EdgeLB backends owned by the resource are rules merged from the resource's own EdgeLB frontends, and are hence not considered.
+		if _, err := computeSNIFrontendBindPort(frontend.Name); err != nil || frontend.LinkBackend == nil {
+			continue
+		}
+		for _, item := range frontend.LinkBackend.Map {
+			if isOwned(item.Backend) {
+				res.SNIHostnames[frontend.Name+"/"+item.HostEq] = item
+			}
+		}
+	}
+	return res
+}
+
+// computeEdgeLBFrontendWithOwnedLinkBackendMapItems returns a copy of the specified EdgeLB frontend whose mapping between rules and EdgeLB backends only includes the items pointing at EdgeLB backends for which "isOwned" returns true.
+// This prevents rules merged from other Ingress resources sharing the EdgeLB frontend's bind port from being mistaken for drift whenever said Ingress resources change.
+// In case all the items point at such EdgeLB backends, the EdgeLB frontend is returned as is.
+func computeEdgeLBFrontendWithOwnedLinkBackendMapItems(frontend *models.V2Frontend, isOwned func(name string) bool) *models.V2Frontend {
+	if frontend.LinkBackend == nil {
+		return frontend
+	}
+	items := make([]*models.V2FrontendLinkBackendMapItems0, 0, len(frontend.LinkBackend.Map))
+	for _, item := range frontend.LinkBackend.Map {
+		if isOwned(item.Backend) {
+			items = append(items, item)
+		}
+	}
+	if len(items) == len(frontend.LinkBackend.Map) {
+		return frontend
+	}
+	res := *frontend
+	linkBackend := *frontend.LinkBackend
+	linkBackend.Map = items
+	res.LinkBackend = &linkBackend
+	return &res
+}
+
+// computeAppliedStateHash computes a hash of the specified EdgeLB objects as they are applied to the EdgeLB pool with the specified name.
+// The name of the EdgeLB pool is included so that moving an Ingress/Service resource to a different EdgeLB pool is not mistaken for drift.
+func computeAppliedStateHash(poolName string, objects ownedEdgeLBObjects) string {
+	// Marshaling EdgeLB objects never fails, and map keys are sorted by "encoding/json", so the result is stable.
+	v, _ := json.Marshal(objects)
+	hash := sha256.Sum256(append([]byte(poolName+"\x00"), v...))
+	return hex.EncodeToString(hash[:])[:appliedStateHashLength]
+}
+
+// GetEdgeLBPoolAppliedStateHash returns the hash of the EdgeLB objects last applied to the target EdgeLB pool for the specified resource.
+// An empty string is returned in case no EdgeLB objects have been applied yet.
+func GetEdgeLBPoolAppliedStateHash(obj metav1.Object) string {
+	return obj.GetAnnotations()[constants.EdgeLBPoolAppliedStateHashAnnotationKey]
+}
+
+// SetEdgeLBPoolAppliedStateHash sets the hash of the EdgeLB objects last applied to the target EdgeLB pool for the specified resource.
+// It returns a value indicating whether the value of the corresponding annotation was changed.
+func SetEdgeLBPoolAppliedStateHash(obj metav1.Object, hash string) bool {
+	if hash == "" || GetEdgeLBPoolAppliedStateHash(obj) == hash {
+		return false
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[constants.EdgeLBPoolAppliedStateHashAnnotationKey] = hash
+	obj.SetAnnotations(annotations)
+	return true
+}
+
+// computeDriftHash computes a hash of the specified differences between the EdgeLB objects applied to the EdgeLB pool with the specified name and the ones found in said EdgeLB pool.
+func computeDriftHash(poolName string, diff []string) string {
+	hash := sha256.Sum256([]byte(poolName + "\x00" + strings.Join(diff, "\x00")))
+	return hex.EncodeToString(hash[:])[:appliedStateHashLength]
+}
+
+// GetEdgeLBPoolReportedDriftHash returns the hash of the changes made out-of-band to the target EdgeLB pool that have last been reported for the specified resource.
+// An empty string is returned in case no such changes are currently being reported.
+func GetEdgeLBPoolReportedDriftHash(obj metav1.Object) string {
+	return obj.GetAnnotations()[constants.EdgeLBPoolReportedDriftHashAnnotationKey]
+}
+
+// SetEdgeLBPoolReportedDriftHash sets the hash of the changes made out-of-band to the target EdgeLB pool that have last been reported for the specified resource.
+// An empty hash indicates that no such changes are currently being reported, in which case the corresponding annotation is removed.
+// It returns a value indicating whether the value of the corresponding annotation was changed.
+func SetEdgeLBPoolReportedDriftHash(obj metav1.Object, hash string) bool {
+	if GetEdgeLBPoolReportedDriftHash(obj) == hash {
+		return false
+	}
+	annotations := obj.GetAnnotations()
+	if hash == "" {
+		delete(annotations, constants.EdgeLBPoolReportedDriftHashAnnotationKey)
+		obj.SetAnnotations(annotations)
+		return true
+	}
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[constants.EdgeLBPoolReportedDriftHashAnnotationKey] = hash
+	obj.SetAnnotations(annotations)
+	return true
+}
+
+// diffOwnedEdgeLBObjects returns a human-readable description of the differences between the "actual" and "expected" sets of EdgeLB objects.
+func diffOwnedEdgeLBObjects(actual, expected ownedEdgeLBObjects) []string {
+	res := make([]string, 0)
+	for _, name := range unionOfKeys(actual.Backends, expected.Backends) {
+		res = append(res, diffEdgeLBObject(fmt.Sprintf("backend %q", name), actual.Backends[name], expected.Backends[name])...)
+	}
+	for _, name := range unionOfKeys(actual.Frontends, expected.Frontends) {
+		res = append(res, diffEdgeLBObject(fmt.Sprintf("frontend %q", name), actual.Frontends[name], expected.Frontends[name])...)
+	}
+	for _, key := range unionOfKeys(actual.SNIHostnames, expected.SNIHostnames) {
+		parts := strings.SplitN(key, "/", 2)
+		res = append(res, diffEdgeLBObject(fmt.Sprintf("tls sni hostname %q of frontend %q", parts[1], parts[0]), actual.SNIHostnames[key], expected.SNIHostnames[key])...)
+	}
+	return res
+}
+
+// diffEdgeLBObject returns a human-readable description of the differences between the "actual" and "expected" versions of the EdgeLB object with the specified description.
+// Either version may be nil, in which case the EdgeLB object is reported as having been added or removed.
+func diffEdgeLBObject(description string, actual, expected interface{}) []string {
+	switch {
+	case isNil(actual) && isNil(expected):
+		return nil
+	case isNil(actual):
+		return []string{fmt.Sprintf("%s was removed", description)}
+	case isNil(expected):
+		return []string{fmt.Sprintf("%s was added", description)}
+	}
+	res := make([]string, 0)
+	for _, d := range computeFieldDiffs(expected, actual) {
+		res = append(res, fmt.Sprintf("%s: %q is %s instead of %s", description, d.Field, d.formatTo(), d.formatFrom()))
+	}
+	return res
+}
+
+// fieldDiff describes the difference in the value of a single (top-level) field between two versions of an EdgeLB object.
+type fieldDiff struct {
+	// Field is the name of the field, as it appears in the JSON representation of the EdgeLB object.
+	Field string `json:"field"`
+	// From is the JSON representation of the value of the field in the old version of the EdgeLB object.
+	// It is empty in case the field is not set in said version.
+	From json.RawMessage `json:"from,omitempty"`
+	// To is the JSON representation of the value of the field in the new version of the EdgeLB object.
+	// It is empty in case the field is not set in said version.
+	To json.RawMessage `json:"to,omitempty"`
+}
+
+// formatFrom returns a human-readable representation of the value of the field in the old version of the EdgeLB object.
+func (d fieldDiff) formatFrom() string {
+	return formatJSONField(d.From)
+}
+
+// formatTo returns a human-readable representation of the value of the field in the new version of the EdgeLB object.
+func (d fieldDiff) formatTo() string {
+	return formatJSONField(d.To)
+}
+
+// computeFieldDiffs returns the differences between the JSON representations of the "from" and "to" versions of an EdgeLB object, sorted by field name.
+func computeFieldDiffs(from, to interface{}) []fieldDiff {
+	f, t := toJSONFields(from), toJSONFields(to)
+	res := make([]fieldDiff, 0)
+	for _, field := range unionOfKeys(f, t) {
+		if string(f[field]) == string(t[field]) {
+			continue
+		}
+		res = append(res, fieldDiff{Field: field, From: f[field], To: t[field]})
+	}
+	return res
+}
+
+// toJSONFields returns the JSON representation of each field of the specified EdgeLB object, indexed by name.
+// An empty map is returned in case the specified value is nil.
+func toJSONFields(obj interface{}) map[string]json.RawMessage {
+	res := make(map[string]json.RawMessage)
+	if isNil(obj) {
+		return res
+	}
+	// EdgeLB objects are always represented as JSON objects, so we can safely ignore errors.
+	// Re-marshaling each field makes sure that equal values have equal representations.
+	v, _ := json.Marshal(obj)
+	fields := make(map[string]interface{})
+	_ = json.Unmarshal(v, &fields)
+	for name, value := range fields {
+		res[name], _ = json.Marshal(value)
+	}
+	return res
+}
+
+// formatJSONField returns a human-readable representation of the specified JSON representation of the value of a field.
+func formatJSONField(v json.RawMessage) string {
+	if len(v) == 0 {
+		return "unset"
+	}
+	return string(v)
+}
+
+// isNil returns a value indicating whether the specified value is nil or a nil pointer.
+func isNil(v interface{}) bool {
+	return v == nil || reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()
+}
+
+// unionOfKeys returns the sorted union of the keys of the specified maps, which must be indexed by strings.
+func unionOfKeys(maps ...interface{}) []string {
+	keys := make(map[string]bool)
+	for _, m := range maps {
+		for _, k := range reflect.ValueOf(m).MapKeys() {
+			keys[k.String()] = true
+		}
+	}
+	res := make([]string, 0, len(keys))
+	for k := range keys {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// copyEdgeLBPool returns a deep copy of the specified EdgeLB pool.
+func copyEdgeLBPool(pool *models.V2Pool) (*models.V2Pool, error) {
+	v, err := json.Marshal(pool)
+	if err != nil {
+		return nil, err
+	}
+	res := &models.V2Pool{}
+	if err := json.Unmarshal(v, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// driftReconciliation holds the result of checking whether the EdgeLB objects owned by an Ingress/Service resource have been changed out-of-band.
+type driftReconciliation struct {
+	// MustUpdate indicates whether the desired EdgeLB pool must still be applied.
+	MustUpdate bool
+	// AppliedStateHash is the hash of the EdgeLB objects computed for the resource.
+	AppliedStateHash string
+	// ReportedDriftHash is the hash of the changes made out-of-band to the EdgeLB objects owned by the resource, or an empty string in case no such changes were found.
+	ReportedDriftHash string
+}
+
+// reconcileEdgeLBPoolDrift checks whether the EdgeLB objects owned by the specified Ingress/Service resource have been changed out-of-band (e.g. using the EdgeLB CLI).
+// "live" is the EdgeLB pool as reported by the EdgeLB API server, and "desired" is the same EdgeLB pool after translation.
+// Drift is only reported when the EdgeLB objects computed for the resource are the same that were last applied to the EdgeLB pool, as otherwise the differences are caused by changes to the resource itself.
+// In case drift is detected, a "PoolDriftDetected" event is emitted for the resource unless the very same drift has already been reported (according to the "kubernetes.dcos.io/edgelb-pool-reported-drift-hash" annotation).
+// If the drift policy is "Report", the EdgeLB backends and frontends in "desired" are reset to the ones in "live" (and marked as kept in the specified pool inspection report).
+func reconcileEdgeLBPoolDrift(obj kubernetesObject, options BaseTranslationOptions, live, desired *models.V2Pool, wasChanged bool, report *poolInspectionReport, isOwned func(name string) bool, recorder record.EventRecorder, logger log.FieldLogger) (driftReconciliation, error) {
+	var (
+		actual   = computeOwnedEdgeLBObjects(live, isOwned)
+		expected = computeOwnedEdgeLBObjects(desired, isOwned)
+		res      = driftReconciliation{MustUpdate: wasChanged, AppliedStateHash: computeAppliedStateHash(desired.Name, expected)}
+	)
+	// If the desired state has changed since it was last applied (or has never been applied), any differences are caused by changes to the resource.
+	if GetEdgeLBPoolAppliedStateHash(obj) != res.AppliedStateHash {
+		return res, nil
+	}
+	diff := diffOwnedEdgeLBObjects(actual, expected)
+	if len(diff) == 0 {
+		return res, nil
+	}
+
+	// At this point we know that the EdgeLB objects owned by the resource have been changed out-of-band.
+	// Only report these changes in case they haven't been reported yet, as otherwise the same event would be emitted on every resync.
+	res.ReportedDriftHash = computeDriftHash(desired.Name, diff)
+	if GetEdgeLBPoolReportedDriftHash(obj) == res.ReportedDriftHash {
+		logger.Debugf("edgelb pool %q has drifted from the state applied by dklb, but the differences have already been reported", desired.Name)
+	} else {
+		summary := diff
+		if len(summary) > maxDriftEventLines {
+			summary = append(summary[:maxDriftEventLines:maxDriftEventLines], fmt.Sprintf("and %d more", len(diff)-maxDriftEventLines))
+		}
+		logger.Warnf("edgelb pool %q has drifted from the state applied by dklb: %s", desired.Name, strings.Join(diff, "; "))
+		if options.EdgeLBPoolDriftPolicy != constants.EdgeLBPoolDriftPolicyReport {
+			recorder.Eventf(obj, corev1.EventTypeWarning, constants.ReasonPoolDriftDetected, "edgelb pool %q has drifted from the state applied by dklb and will be corrected: %s", desired.Name, strings.Join(summary, "; "))
+		} else {
+			recorder.Eventf(obj, corev1.EventTypeWarning, constants.ReasonPoolDriftDetected, "edgelb pool %q has drifted from the state applied by dklb and will not be corrected as the drift policy is %q: %s", desired.Name, options.EdgeLBPoolDriftPolicy, strings.Join(summary, "; "))
+		}
+	}
+	if options.EdgeLBPoolDriftPolicy != constants.EdgeLBPoolDriftPolicyReport {
+		return res, nil
+	}
+
+	// Keep the EdgeLB backends and frontends as they are, and check whether the EdgeLB pool must still be updated (e.g. because its size has changed).
+	desired.Haproxy.Backends, desired.Haproxy.Frontends = live.Haproxy.Backends, live.Haproxy.Frontends
+	report.KeepAll(EdgeLBObjectTypeBackend, EdgeLBObjectTypeFrontend)
+	report.Report("changes to edgelb backends and frontends were not applied as the drift policy is %q", options.EdgeLBPoolDriftPolicy)
+	l, err := json.Marshal(live)
+	if err != nil {
+		return driftReconciliation{}, err
+	}
+	d, err := json.Marshal(desired)
+	if err != nil {
+		return driftReconciliation{}, err
+	}
+	res.MustUpdate = string(l) != string(d)
+	return res, nil
+}
@@ -0,0 +1,268 @@
+package translator
+
+import (
+	"testing"
+
+	"github.com/mesosphere/dcos-edge-lb/models"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/mesosphere/dklb/pkg/constants"
+	"github.com/mesosphere/dklb/pkg/util/pointers"
+	edgelbpooltestutil "github.com/mesosphere/dklb/test/util/edgelb/pool"
+	servicetestutil "github.com/mesosphere/dklb/test/util/kubernetes/service"
+)
+
+// TestDiffOwnedEdgeLBObjects tests the "diffOwnedEdgeLBObjects" function.
+func TestDiffOwnedEdgeLBObjects(t *testing.T) {
+	tests := []struct {
+		description  string
+		actual       ownedEdgeLBObjects
+		expected     ownedEdgeLBObjects
+		expectedDiff []string
+	}{
+		{
+			description: "no differences",
+			actual: ownedEdgeLBObjects{
+				Backends: map[string]*models.V2Backend{"foo": {Name: "foo", Balance: "roundrobin"}},
+			},
+			expected: ownedEdgeLBObjects{
+				Backends: map[string]*models.V2Backend{"foo": {Name: "foo", Balance: "roundrobin"}},
+			},
+			expectedDiff: []string{},
+		},
+		{
+			description: "modified backend",
+			actual: ownedEdgeLBObjects{
+				Backends: map[string]*models.V2Backend{"foo": {Name: "foo", Balance: "leastconn"}},
+			},
+			expected: ownedEdgeLBObjects{
+				Backends: map[string]*models.V2Backend{"foo": {Name: "foo", Balance: "roundrobin"}},
+			},
+			expectedDiff: []string{
+				`backend "foo": "balance" is "leastconn" instead of "roundrobin"`,
+			},
+		},
+		{
+			description: "added backend and removed frontend",
+			actual: ownedEdgeLBObjects{
+				Backends: map[string]*models.V2Backend{"foo": {Name: "foo"}, "bar": {Name: "bar"}},
+			},
+			expected: ownedEdgeLBObjects{
+				Backends:  map[string]*models.V2Backend{"foo": {Name: "foo"}},
+				Frontends: map[string]*models.V2Frontend{"foo": {Name: "foo"}},
+			},
+			expectedDiff: []string{
+				`backend "bar" was added`,
+				`frontend "foo" was removed`,
+			},
+		},
+		{
+			description: "modified tls sni hostname",
+			actual: ownedEdgeLBObjects{
+				SNIHostnames: map[string]*models.V2FrontendLinkBackendMapItems0{"sni:443/foo.com": {Backend: "bar", HostEq: "foo.com"}},
+			},
+			expected: ownedEdgeLBObjects{
+				SNIHostnames: map[string]*models.V2FrontendLinkBackendMapItems0{"sni:443/foo.com": {Backend: "foo", HostEq: "foo.com"}},
+			},
+			expectedDiff: []string{
+				`tls sni hostname "foo.com" of frontend "sni:443": "backend" is "bar" instead of "foo"`,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		assert.Equal(t, test.expectedDiff, diffOwnedEdgeLBObjects(test.actual, test.expected))
+	}
+}
+
+// TestComputeOwnedEdgeLBObjects tests the "computeOwnedEdgeLBObjects" function.
+func TestComputeOwnedEdgeLBObjects(t *testing.T) {
+	var (
+		// bar and qux are items pointing at EdgeLB backends owned by the "foo/bar" and "foo/qux" Ingress resources, respectively.
+		bar = &models.V2FrontendLinkBackendMapItems0{Backend: "dev.kubernetes01:foo:bar:svc:80", HostEq: "bar.com"}
+		qux = &models.V2FrontendLinkBackendMapItems0{Backend: "dev.kubernetes01:foo:qux:svc:80", HostEq: "qux.com"}
+		// sni is an item pointing at an EdgeLB backend owned by the "foo/bar" Ingress resource in an EdgeLB frontend shared via TLS SNI.
+		sni = &models.V2FrontendLinkBackendMapItems0{Backend: "dev.kubernetes01:foo:bar:svc:80", HostEq: "sni.com"}
+	)
+	pool := edgelbpooltestutil.DummyEdgeLBPool("baz", func(p *models.V2Pool) {
+		p.Haproxy.Backends = []*models.V2Backend{
+			{Name: "dev.kubernetes01:foo:bar:svc:80"},
+			{Name: "dev.kubernetes01:foo:qux:svc:80"},
+		}
+		// The EdgeLB frontends owned by both Ingress resources share a bind port, and hence serve the rules of both Ingress resources.
+		p.Haproxy.Frontends = []*models.V2Frontend{
+			{Name: "dev.kubernetes01:foo:bar", LinkBackend: &models.V2FrontendLinkBackend{Map: []*models.V2FrontendLinkBackendMapItems0{bar, qux}}},
+			{Name: "dev.kubernetes01:foo:qux", LinkBackend: &models.V2FrontendLinkBackend{Map: []*models.V2FrontendLinkBackendMapItems0{bar, qux}}},
+			{Name: "sni:443", LinkBackend: &models.V2FrontendLinkBackend{Map: []*models.V2FrontendLinkBackendMapItems0{sni}}},
+		}
+	})
+	isOwned := func(name string) bool {
+		m, err := computeIngressOwnedEdgeLBObjectMetadata(name)
+		return err == nil && m.Namespace == "foo" && m.Name == "bar"
+	}
+
+	// Make sure that the rules merged from "foo/qux" are not considered, and that rules of "foo/bar" merged into the EdgeLB frontend owned by "foo/qux" are not mistaken for TLS SNI hostnames.
+	objects := computeOwnedEdgeLBObjects(pool, isOwned)
+	assert.Equal(t, map[string]*models.V2Backend{"dev.kubernetes01:foo:bar:svc:80": pool.Haproxy.Backends[0]}, objects.Backends)
+	assert.Equal(t, map[string]*models.V2Frontend{
+		"dev.kubernetes01:foo:bar": {Name: "dev.kubernetes01:foo:bar", LinkBackend: &models.V2FrontendLinkBackend{Map: []*models.V2FrontendLinkBackendMapItems0{bar}}},
+	}, objects.Frontends)
+	assert.Equal(t, map[string]*models.V2FrontendLinkBackendMapItems0{"sni:443/sni.com": sni}, objects.SNIHostnames)
+}
+
+// TestReconcileEdgeLBPoolDrift tests the "reconcileEdgeLBPoolDrift" function.
+func TestReconcileEdgeLBPoolDrift(t *testing.T) {
+	var (
+		owned   = "dev.kubernetes01:foo:bar:80"
+		isOwned = func(name string) bool {
+			return name == owned
+		}
+	)
+	// desiredPool returns the EdgeLB pool containing the EdgeLB objects computed for the Service resource.
+	desiredPool := func(size int32) *models.V2Pool {
+		return edgelbpooltestutil.DummyEdgeLBPool("baz", func(p *models.V2Pool) {
+			p.Count = pointers.NewInt32(size)
+			p.Haproxy.Backends = []*models.V2Backend{
+				{Name: owned, Balance: "roundrobin"},
+				{Name: "other", Balance: "leastconn"},
+			}
+		})
+	}
+	// appliedStateHash is the hash of the EdgeLB objects computed for the Service resource.
+	appliedStateHash := computeAppliedStateHash("baz", computeOwnedEdgeLBObjects(desiredPool(1), isOwned))
+	// driftHash is the hash of the changes made out-of-band to the EdgeLB backend owned by the Service resource in drifted EdgeLB pools.
+	driftedPool := desiredPool(1)
+	driftedPool.Haproxy.Backends[0].Balance = "source"
+	driftHash := computeDriftHash("baz", diffOwnedEdgeLBObjects(computeOwnedEdgeLBObjects(driftedPool, isOwned), computeOwnedEdgeLBObjects(desiredPool(1), isOwned)))
+
+	tests := []struct {
+		description        string
+		driftPolicy        constants.EdgeLBPoolDriftPolicy
+		previousHash       string
+		reportedDriftHash  string
+		drifted            bool
+		desiredSize        int32
+		expectedMustUpdate bool
+		expectedEvent      bool
+		expectedBalance    string
+		expectedDriftHash  string
+	}{
+		{
+			description:        "first synchronization of a resource",
+			previousHash:       "",
+			drifted:            true,
+			desiredSize:        1,
+			expectedMustUpdate: true,
+			expectedBalance:    "roundrobin",
+		},
+		{
+			description:        "synchronized pool",
+			previousHash:       appliedStateHash,
+			desiredSize:        1,
+			expectedMustUpdate: false,
+			expectedBalance:    "roundrobin",
+		},
+		{
+			description:        "drifted pool with the default drift policy",
+			previousHash:       appliedStateHash,
+			drifted:            true,
+			desiredSize:        1,
+			expectedMustUpdate: true,
+			expectedEvent:      true,
+			expectedBalance:    "roundrobin",
+			expectedDriftHash:  driftHash,
+		},
+		{
+			description:        "drifted pool with the \"Report\" drift policy",
+			driftPolicy:        constants.EdgeLBPoolDriftPolicyReport,
+			previousHash:       appliedStateHash,
+			drifted:            true,
+			desiredSize:        1,
+			expectedMustUpdate: false,
+			expectedEvent:      true,
+			expectedBalance:    "source",
+			expectedDriftHash:  driftHash,
+		},
+		{
+			description:        "drifted and resized pool with the \"Report\" drift policy",
+			driftPolicy:        constants.EdgeLBPoolDriftPolicyReport,
+			previousHash:       appliedStateHash,
+			drifted:            true,
+			desiredSize:        2,
+			expectedMustUpdate: true,
+			expectedEvent:      true,
+			expectedBalance:    "source",
+			expectedDriftHash:  driftHash,
+		},
+		{
+			description:        "already reported drift with the \"Report\" drift policy",
+			driftPolicy:        constants.EdgeLBPoolDriftPolicyReport,
+			previousHash:       appliedStateHash,
+			reportedDriftHash:  driftHash,
+			drifted:            true,
+			desiredSize:        1,
+			expectedMustUpdate: false,
+			expectedEvent:      false,
+			expectedBalance:    "source",
+			expectedDriftHash:  driftHash,
+		},
+		{
+			description:        "different drift than the one already reported with the \"Report\" drift policy",
+			driftPolicy:        constants.EdgeLBPoolDriftPolicyReport,
+			previousHash:       appliedStateHash,
+			reportedDriftHash:  "0123456789abcdef",
+			drifted:            true,
+			desiredSize:        1,
+			expectedMustUpdate: false,
+			expectedEvent:      true,
+			expectedBalance:    "source",
+			expectedDriftHash:  driftHash,
+		},
+		{
+			description:        "resolved drift",
+			driftPolicy:        constants.EdgeLBPoolDriftPolicyReport,
+			previousHash:       appliedStateHash,
+			reportedDriftHash:  driftHash,
+			desiredSize:        1,
+			expectedMustUpdate: false,
+			expectedBalance:    "roundrobin",
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		service := servicetestutil.DummyServiceResource("foo", "bar", servicetestutil.WithAnnotations(map[string]string{
+			constants.EdgeLBPoolAppliedStateHashAnnotationKey:  test.previousHash,
+			constants.EdgeLBPoolReportedDriftHashAnnotationKey: test.reportedDriftHash,
+		}))
+		live := desiredPool(1)
+		if test.drifted {
+			live.Haproxy.Backends[0].Balance = "source"
+		}
+		desired := desiredPool(test.desiredSize)
+		recorder := record.NewFakeRecorder(1)
+		options := BaseTranslationOptions{
+			EdgeLBPoolDriftPolicy: test.driftPolicy,
+		}
+		// Whether the pool contains changes is computed by the translator, and is always true when the pool has drifted or has been resized.
+		wasChanged := test.drifted || test.desiredSize != 1
+		report := poolInspectionReport{Pool: "baz"}
+		report.Modify(EdgeLBObjectTypeBackend, owned, nil, live.Haproxy.Backends[0], desired.Haproxy.Backends[0])
+		res, err := reconcileEdgeLBPoolDrift(service, options, live, desired, wasChanged, &report, isOwned, recorder, log.WithField("test", t.Name()))
+		assert.NoError(t, err)
+		assert.Equal(t, test.expectedMustUpdate, res.MustUpdate)
+		assert.Equal(t, appliedStateHash, res.AppliedStateHash)
+		assert.Equal(t, test.expectedDriftHash, res.ReportedDriftHash)
+		assert.Equal(t, test.expectedBalance, desired.Haproxy.Backends[0].Balance)
+		// Make sure that changes to EdgeLB backends that were not applied are not reported either.
+		if test.expectedBalance == "source" {
+			assert.Len(t, report.Changes(), 0)
+		}
+		if test.expectedEvent {
+			assert.Len(t, recorder.Events, 1)
+		} else {
+			assert.Len(t, recorder.Events, 0)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package translator
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/mesosphere/dklb/pkg/constants"
+	"github.com/mesosphere/dklb/pkg/edgelb/manager"
+)
+
+const (
+	// maxDryRunEventDiffLength is the maximum number of characters of the diff included in the message of an "EdgeLBPoolDryRun" event.
+	// The full diff is always logged and exposed by the dry-run EdgeLB manager.
+	maxDryRunEventDiffLength = 512
+)
+
+// reportDryRunOperation emits an event associated with the specified Ingress/Service resource describing the last operation recorded for the EdgeLB pool with the specified name.
+// It is a no-op unless the specified EdgeLB manager only records calls to the write APIs of EdgeLB.
+func reportDryRunOperation(m manager.EdgeLBManager, recorder record.EventRecorder, obj runtime.Object, poolName string) {
+	d, ok := m.(*manager.DryRunEdgeLBManager)
+	if !ok {
+		return
+	}
+	op := d.LastOperation(poolName)
+	if op == nil {
+		return
+	}
+	diff := string(op.Diff)
+	if len(diff) > maxDryRunEventDiffLength {
+		diff = fmt.Sprintf("%s... (%d more characters)", diff[:maxDryRunEventDiffLength], len(diff)-maxDryRunEventDiffLength)
+	}
+	recorder.Eventf(obj, corev1.EventTypeNormal, constants.ReasonEdgeLBPoolDryRun, "dry-run mode is enabled, so edgelb pool %q was not %sd: %s", poolName, op.Type, diff)
+}
@@ -0,0 +1,227 @@
+package translator
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/mesosphere/dcos-edge-lb/models"
+	corev1 "k8s.io/api/core/v1"
+
+	dklbcache "github.com/mesosphere/dklb/pkg/cache"
+	"github.com/mesosphere/dklb/pkg/constants"
+	kubernetesutil "github.com/mesosphere/dklb/pkg/util/kubernetes"
+)
+
+const (
+	// healthCheckNodePortPath is the path at which kube-proxy reports whether a node hosts ready endpoints for a Service resource with "externalTrafficPolicy: Local".
+	healthCheckNodePortPath = "/healthz"
+	// checkPortFormatString is the format string used to compute the server option that sets the port to which health checks are sent.
+	checkPortFormatString = "port %d"
+)
+
+// endpointAddress represents an address (i.e. a pod or a Kubernetes node) at which a service port can be reached.
+type endpointAddress struct {
+	// IP is the IP of the pod or Kubernetes node.
+	IP string
+	// Port is the port of the pod targeted by the service port, or the service port's node port.
+	Port int32
+}
+
+// backendTarget groups together the addresses targeted by an EdgeLB backend in place of the node port of a Service resource on every Kubernetes node.
+type backendTarget struct {
+	// Addresses is the list of addresses targeted by the EdgeLB backend.
+	Addresses []endpointAddress
+	// HealthCheckNodePort is the node port at which kube-proxy reports whether each targeted node hosts ready endpoints.
+	// A value of zero means that the health of each address should be checked as usual.
+	HealthCheckNodePort int32
+}
+
+// computeBackendTargetForServicePort computes the addresses that must be targeted by the EdgeLB backend corresponding to the specified service port.
+// In case EdgeLB backends have been requested to target pod IPs, the addresses of the ready pods backing the service port are returned.
+// Otherwise, in case the Service resource has "externalTrafficPolicy: Local", the addresses of the Kubernetes nodes hosting said pods are returned (together with the service port's node port).
+// In any other case, the addresses of all Ready and schedulable Kubernetes nodes are returned (together with the service port's node port).
+// nil is returned in case no Kubernetes node is Ready and schedulable, meaning that the node port of the Service resource should be targeted on every Kubernetes node.
+func computeBackendTargetForServicePort(kubeCache dklbcache.KubernetesResourceCache, service *corev1.Service, servicePort corev1.ServicePort, options BaseTranslationOptions) (*backendTarget, error) {
+	podIP := options.EdgeLBBackendTarget == constants.EdgeLBBackendTargetPodIP
+	local := service.Spec.ExternalTrafficPolicy == corev1.ServiceExternalTrafficPolicyTypeLocal
+	if !podIP && !local {
+		return computeBackendTargetForNodePort(kubeCache, servicePort.NodePort)
+	}
+	e, err := kubeCache.GetEndpoints(service.Namespace, service.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read endpoints for service %q: %v", kubernetesutil.Key(service), err)
+	}
+	if podIP {
+		return &backendTarget{
+			Addresses: computeEndpointAddressesForServicePort(e, servicePort),
+		}, nil
+	}
+	return &backendTarget{
+		Addresses:           computeNodeAddressesForServicePort(kubeCache, e, servicePort),
+		HealthCheckNodePort: service.Spec.HealthCheckNodePort,
+	}, nil
+}
+
+// computeBackendTargetForNodePort computes the addresses at which the specified node port can be reached on every Ready and schedulable Kubernetes node.
+// Cordoned, draining and NotReady Kubernetes nodes are excluded so that they stop receiving traffic before their health checks start failing.
+// nil is returned in case no Kubernetes node is Ready and schedulable (e.g. because the Kubernetes nodes cannot currently report their status), in which case every Kubernetes node should be targeted and left to health checks.
+func computeBackendTargetForNodePort(kubeCache dklbcache.KubernetesResourceCache, nodePort int32) (*backendTarget, error) {
+	nodes, err := kubeCache.GetNodes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %v", err)
+	}
+	res := make([]endpointAddress, 0, len(nodes))
+	for _, node := range nodes {
+		if !kubernetesutil.IsReadyAndSchedulableNode(node) {
+			continue
+		}
+		if ip := kubernetesutil.NodeInternalIP(node); ip != "" {
+			res = append(res, endpointAddress{
+				IP:   ip,
+				Port: nodePort,
+			})
+		}
+	}
+	if len(res) == 0 {
+		return nil, nil
+	}
+	sortEndpointAddresses(res)
+	return &backendTarget{
+		Addresses: res,
+	}, nil
+}
+
+// computeEndpointAddressesForServicePort computes the list of addresses of the ready pods backing the specified service port, as reported by the specified Endpoints resource.
+// Endpoint ports are matched against the service port by name (and protocol), as this is how the Kubernetes endpoints controller names them.
+// Addresses are sorted in order to get a predictable output.
+func computeEndpointAddressesForServicePort(endpoints *corev1.Endpoints, servicePort corev1.ServicePort) []endpointAddress {
+	res := make([]endpointAddress, 0)
+	for _, subset := range endpoints.Subsets {
+		for _, port := range subset.Ports {
+			if port.Name != servicePort.Name || port.Protocol != servicePort.Protocol {
+				continue
+			}
+			// Only ready addresses are considered, as pods that are not ready must not receive traffic.
+			for _, address := range subset.Addresses {
+				res = append(res, endpointAddress{
+					IP:   address.IP,
+					Port: port.Port,
+				})
+			}
+		}
+	}
+	sortEndpointAddresses(res)
+	return res
+}
+
+// computeNodeAddressesForServicePort computes the list of addresses at which the service port is exposed on the Kubernetes nodes hosting the ready pods backing it, as reported by the specified Endpoints resource.
+// Each Kubernetes node is targeted at its internal IP and at the service port's node port.
+// Pods whose Kubernetes node is unknown or has no internal IP are skipped.
+// Kubernetes nodes that are not Ready and schedulable are skipped as well, unless this would cause every Kubernetes node to be skipped.
+// Addresses are sorted in order to get a predictable output.
+func computeNodeAddressesForServicePort(kubeCache dklbcache.KubernetesResourceCache, endpoints *corev1.Endpoints, servicePort corev1.ServicePort) []endpointAddress {
+	// visited holds the names of the Kubernetes nodes that have already been visited, and is used to remove duplicates.
+	visited := make(map[string]bool)
+	all := make([]endpointAddress, 0)
+	eligible := make([]endpointAddress, 0)
+	for _, subset := range endpoints.Subsets {
+		for _, address := range subset.Addresses {
+			if address.NodeName == nil || visited[*address.NodeName] {
+				continue
+			}
+			visited[*address.NodeName] = true
+			node, err := kubeCache.GetNode(*address.NodeName)
+			if err != nil {
+				continue
+			}
+			ip := kubernetesutil.NodeInternalIP(node)
+			if ip == "" {
+				continue
+			}
+			a := endpointAddress{
+				IP:   ip,
+				Port: servicePort.NodePort,
+			}
+			all = append(all, a)
+			if kubernetesutil.IsReadyAndSchedulableNode(node) {
+				eligible = append(eligible, a)
+			}
+		}
+	}
+	res := eligible
+	if len(res) == 0 {
+		res = all
+	}
+	sortEndpointAddresses(res)
+	return res
+}
+
+// sortEndpointAddresses sorts the specified addresses by IP and port in order to get a predictable output.
+func sortEndpointAddresses(addresses []endpointAddress) {
+	sort.SliceStable(addresses, func(i, j int) bool {
+		if addresses[i].IP != addresses[j].IP {
+			return addresses[i].IP < addresses[j].IP
+		}
+		return addresses[i].Port < addresses[j].Port
+	})
+}
+
+// applyBackendTarget makes the specified EdgeLB backend target the specified addresses, checking their health via the health check node port if applicable.
+func applyBackendTarget(backend *models.V2Backend, target backendTarget) {
+	applyEndpointAddresses(backend, target.Addresses)
+	if target.HealthCheckNodePort != 0 {
+		applyHealthCheckNodePort(backend, target.HealthCheckNodePort)
+	}
+}
+
+// applyHealthCheckNodePort makes the specified EdgeLB backend check the health of its servers by sending HTTP requests to the specified health check node port.
+// kube-proxy only responds successfully to these requests in Kubernetes nodes hosting ready endpoints, so this takes precedence over any custom health check path.
+// Custom health check timings are preserved.
+func applyHealthCheckNodePort(backend *models.V2Backend, port int32) {
+	backend.CustomCheck = &models.V2BackendCustomCheck{
+		Httpchk:        true,
+		HttpchkMiscStr: fmt.Sprintf(httpCheckRequestFormatString, healthCheckNodePortPath),
+	}
+	for _, service := range backend.Services {
+		if service.Endpoint == nil || service.Endpoint.Check == nil {
+			continue
+		}
+		opt := fmt.Sprintf(checkPortFormatString, port)
+		if service.Endpoint.Check.CustomStr != "" {
+			opt = service.Endpoint.Check.CustomStr + " " + opt
+		}
+		service.Endpoint.Check.CustomStr = opt
+	}
+}
+
+// applyEndpointAddresses makes the specified EdgeLB backend target the specified addresses directly, instead of the node port of the Service resource on every Kubernetes node.
+// The health check configuration of the original EdgeLB service is preserved for every address.
+func applyEndpointAddresses(backend *models.V2Backend, addresses []endpointAddress) {
+	// Grab the health check configuration computed for the original EdgeLB service (if any).
+	var check *models.V2EndpointCheck
+	if len(backend.Services) > 0 && backend.Services[0].Endpoint != nil {
+		check = backend.Services[0].Endpoint.Check
+	}
+	services := make([]*models.V2Service, 0, len(addresses))
+	for _, address := range addresses {
+		service := &models.V2Service{
+			Endpoint: &models.V2Endpoint{
+				Address: address.IP,
+				Port:    address.Port,
+				Type:    models.V2EndpointTypeADDRESS,
+			},
+			Marathon: &models.V2ServiceMarathon{
+				// We don't want to use any Marathon service as the backend.
+			},
+			Mesos: &models.V2ServiceMesos{
+				// We don't want to use any Mesos task as the backend.
+			},
+		}
+		if check != nil {
+			c := *check
+			service.Endpoint.Check = &c
+		}
+		services = append(services, service)
+	}
+	backend.Services = services
+}
@@ -0,0 +1,306 @@
+package translator
+
+import (
+	"testing"
+
+	"github.com/mesosphere/dcos-edge-lb/models"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/mesosphere/dklb/pkg/constants"
+	"github.com/mesosphere/dklb/pkg/util/pointers"
+	cachetestutil "github.com/mesosphere/dklb/test/util/cache"
+	servicetestutil "github.com/mesosphere/dklb/test/util/kubernetes/service"
+)
+
+// TestComputeEndpointAddressesForServicePort tests the "computeEndpointAddressesForServicePort" function.
+func TestComputeEndpointAddressesForServicePort(t *testing.T) {
+	endpoints := &corev1.Endpoints{
+		Subsets: []corev1.EndpointSubset{
+			{
+				Addresses: []corev1.EndpointAddress{
+					{IP: "9.0.1.5"},
+					{IP: "9.0.0.3"},
+				},
+				NotReadyAddresses: []corev1.EndpointAddress{
+					{IP: "9.0.2.7"},
+				},
+				Ports: []corev1.EndpointPort{
+					{Name: "http", Port: 8080, Protocol: corev1.ProtocolTCP},
+					{Name: "metrics", Port: 9090, Protocol: corev1.ProtocolTCP},
+				},
+			},
+			{
+				Addresses: []corev1.EndpointAddress{
+					{IP: "9.0.0.4"},
+				},
+				Ports: []corev1.EndpointPort{
+					{Name: "http", Port: 8081, Protocol: corev1.ProtocolTCP},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		description string
+		servicePort corev1.ServicePort
+		addresses   []endpointAddress
+	}{
+		{
+			description: "service port backed by ready pods in multiple subsets",
+			servicePort: corev1.ServicePort{Name: "http", Port: 80, Protocol: corev1.ProtocolTCP},
+			addresses: []endpointAddress{
+				{IP: "9.0.0.3", Port: 8080},
+				{IP: "9.0.0.4", Port: 8081},
+				{IP: "9.0.1.5", Port: 8080},
+			},
+		},
+		{
+			description: "service port without any matching endpoint port",
+			servicePort: corev1.ServicePort{Name: "admin", Port: 81, Protocol: corev1.ProtocolTCP},
+			addresses:   []endpointAddress{},
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		assert.Equal(t, test.addresses, computeEndpointAddressesForServicePort(endpoints, test.servicePort))
+	}
+}
+
+// TestApplyEndpointAddresses tests the "applyEndpointAddresses" function.
+func TestApplyEndpointAddresses(t *testing.T) {
+	backend := &models.V2Backend{
+		Services: []*models.V2Service{
+			{
+				Endpoint: &models.V2Endpoint{
+					Check: &models.V2EndpointCheck{
+						Enabled:   pointers.NewBool(true),
+						CustomStr: "inter 2000ms",
+					},
+					Port: 32000,
+					Type: models.V2EndpointTypeCONTAINERIP,
+				},
+			},
+		},
+	}
+	applyEndpointAddresses(backend, []endpointAddress{
+		{IP: "9.0.0.3", Port: 8080},
+		{IP: "9.0.0.4", Port: 8081},
+	})
+	assert.Len(t, backend.Services, 2)
+	for i, address := range []string{"9.0.0.3", "9.0.0.4"} {
+		assert.Equal(t, models.V2EndpointTypeADDRESS, backend.Services[i].Endpoint.Type)
+		assert.Equal(t, address, backend.Services[i].Endpoint.Address)
+		assert.Equal(t, int32(8080+i), backend.Services[i].Endpoint.Port)
+		assert.Equal(t, "inter 2000ms", backend.Services[i].Endpoint.Check.CustomStr)
+	}
+}
+
+// TestComputeBackendTargetForServicePort tests the "computeBackendTargetForServicePort" function.
+func TestComputeBackendTargetForServicePort(t *testing.T) {
+	// node is a helper function that returns a Node resource with the specified name and internal IP.
+	node := func(name, ip string) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Status: corev1.NodeStatus{
+				Addresses: []corev1.NodeAddress{
+					{Type: corev1.NodeHostName, Address: name},
+					{Type: corev1.NodeInternalIP, Address: ip},
+				},
+			},
+		}
+	}
+	endpoints := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "foo",
+			Name:      "bar",
+		},
+		Subsets: []corev1.EndpointSubset{
+			{
+				Addresses: []corev1.EndpointAddress{
+					{IP: "9.0.0.3", NodeName: pointers.NewString("kube-node-1")},
+					{IP: "9.0.0.4", NodeName: pointers.NewString("kube-node-0")},
+					{IP: "9.0.0.5", NodeName: pointers.NewString("kube-node-1")},
+					{IP: "9.0.0.6", NodeName: pointers.NewString("kube-node-2")},
+				},
+				Ports: []corev1.EndpointPort{
+					{Port: 8080},
+				},
+			},
+		},
+	}
+	// "kube-node-2" is missing, so the pod running on it is expected to be skipped.
+	kubeCache := cachetestutil.NewFakeKubernetesResourceCache(endpoints, node("kube-node-0", "10.0.0.10"), node("kube-node-1", "10.0.0.11"))
+	servicePort := corev1.ServicePort{Port: 80, NodePort: 30080}
+	tests := []struct {
+		description string
+		service     *corev1.Service
+		options     BaseTranslationOptions
+		target      *backendTarget
+	}{
+		{
+			description: "cluster external traffic policy without any ready node",
+			service:     servicetestutil.DummyServiceResource("foo", "bar"),
+			options:     BaseTranslationOptions{},
+			target:      nil,
+		},
+		{
+			description: "pod ips requested",
+			service:     servicetestutil.DummyServiceResource("foo", "bar"),
+			options: BaseTranslationOptions{
+				EdgeLBBackendTarget: constants.EdgeLBBackendTargetPodIP,
+			},
+			target: &backendTarget{
+				Addresses: []endpointAddress{
+					{IP: "9.0.0.3", Port: 8080},
+					{IP: "9.0.0.4", Port: 8080},
+					{IP: "9.0.0.5", Port: 8080},
+					{IP: "9.0.0.6", Port: 8080},
+				},
+			},
+		},
+		{
+			description: "local external traffic policy with a health check node port",
+			service: servicetestutil.DummyServiceResource("foo", "bar", func(service *corev1.Service) {
+				service.Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyTypeLocal
+				service.Spec.HealthCheckNodePort = 31000
+			}),
+			options: BaseTranslationOptions{},
+			target: &backendTarget{
+				Addresses: []endpointAddress{
+					{IP: "10.0.0.10", Port: 30080},
+					{IP: "10.0.0.11", Port: 30080},
+				},
+				HealthCheckNodePort: 31000,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		target, err := computeBackendTargetForServicePort(kubeCache, test.service, servicePort, test.options)
+		assert.NoError(t, err)
+		assert.Equal(t, test.target, target)
+	}
+}
+
+// TestComputeBackendTargetForServicePortWithNodeConditions tests the "computeBackendTargetForServicePort" function when the Kubernetes nodes report their conditions.
+func TestComputeBackendTargetForServicePortWithNodeConditions(t *testing.T) {
+	// node is a helper function that returns a Node resource with the specified name, internal IP, readiness and schedulability.
+	node := func(name, ip string, ready corev1.ConditionStatus, unschedulable bool) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Spec: corev1.NodeSpec{
+				Unschedulable: unschedulable,
+			},
+			Status: corev1.NodeStatus{
+				Addresses: []corev1.NodeAddress{
+					{Type: corev1.NodeInternalIP, Address: ip},
+				},
+				Conditions: []corev1.NodeCondition{
+					{Type: corev1.NodeReady, Status: ready},
+				},
+			},
+		}
+	}
+	endpoints := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "foo",
+			Name:      "bar",
+		},
+		Subsets: []corev1.EndpointSubset{
+			{
+				Addresses: []corev1.EndpointAddress{
+					{IP: "9.0.0.3", NodeName: pointers.NewString("kube-node-0")},
+					{IP: "9.0.0.4", NodeName: pointers.NewString("kube-node-1")},
+					{IP: "9.0.0.5", NodeName: pointers.NewString("kube-node-2")},
+				},
+				Ports: []corev1.EndpointPort{
+					{Port: 8080},
+				},
+			},
+		},
+	}
+	// "kube-node-1" has been cordoned, "kube-node-2" is NotReady and "kube-node-3" doesn't host any pod.
+	kubeCache := cachetestutil.NewFakeKubernetesResourceCache(
+		endpoints,
+		node("kube-node-0", "10.0.0.10", corev1.ConditionTrue, false),
+		node("kube-node-1", "10.0.0.11", corev1.ConditionTrue, true),
+		node("kube-node-2", "10.0.0.12", corev1.ConditionFalse, false),
+		node("kube-node-3", "10.0.0.13", corev1.ConditionTrue, false),
+	)
+	servicePort := corev1.ServicePort{Port: 80, NodePort: 30080}
+	tests := []struct {
+		description string
+		service     *corev1.Service
+		target      *backendTarget
+	}{
+		{
+			description: "cluster external traffic policy",
+			service:     servicetestutil.DummyServiceResource("foo", "bar"),
+			target: &backendTarget{
+				Addresses: []endpointAddress{
+					{IP: "10.0.0.10", Port: 30080},
+					{IP: "10.0.0.13", Port: 30080},
+				},
+			},
+		},
+		{
+			description: "local external traffic policy",
+			service: servicetestutil.DummyServiceResource("foo", "bar", func(service *corev1.Service) {
+				service.Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyTypeLocal
+				service.Spec.HealthCheckNodePort = 31000
+			}),
+			target: &backendTarget{
+				Addresses: []endpointAddress{
+					{IP: "10.0.0.10", Port: 30080},
+				},
+				HealthCheckNodePort: 31000,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		target, err := computeBackendTargetForServicePort(kubeCache, test.service, servicePort, BaseTranslationOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, test.target, target)
+	}
+}
+
+// TestApplyHealthCheckNodePort tests the "applyHealthCheckNodePort" function.
+func TestApplyHealthCheckNodePort(t *testing.T) {
+	backend := &models.V2Backend{
+		CustomCheck: &models.V2BackendCustomCheck{
+			Httpchk:        true,
+			HttpchkMiscStr: "GET /ready",
+			MiscStr:        "http-check expect status 204",
+		},
+		Services: []*models.V2Service{
+			{
+				Endpoint: &models.V2Endpoint{
+					Check: &models.V2EndpointCheck{
+						Enabled:   pointers.NewBool(true),
+						CustomStr: "inter 2000ms",
+					},
+				},
+			},
+			{
+				Endpoint: &models.V2Endpoint{
+					Check: &models.V2EndpointCheck{
+						Enabled: pointers.NewBool(true),
+					},
+				},
+			},
+		},
+	}
+	applyHealthCheckNodePort(backend, 31000)
+	assert.Equal(t, &models.V2BackendCustomCheck{
+		Httpchk:        true,
+		HttpchkMiscStr: "GET /healthz",
+	}, backend.CustomCheck)
+	assert.Equal(t, "inter 2000ms port 31000", backend.Services[0].Endpoint.Check.CustomStr)
+	assert.Equal(t, "port 31000", backend.Services[1].Endpoint.Check.CustomStr)
+}
@@ -0,0 +1,165 @@
+package translator
+
+import (
+	"fmt"
+
+	"github.com/mesosphere/dcos-edge-lb/models"
+	extsv1beta1 "k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// EdgeLBObjectOwnerKindIngress is the kind of EdgeLB object owners that are Ingress resources.
+	EdgeLBObjectOwnerKindIngress = "Ingress"
+	// EdgeLBObjectOwnerKindService is the kind of EdgeLB object owners that are Service resources.
+	EdgeLBObjectOwnerKindService = "Service"
+
+	// EdgeLBObjectTypeBackend is the type of EdgeLB backends.
+	EdgeLBObjectTypeBackend = "backend"
+	// EdgeLBObjectTypeFrontend is the type of EdgeLB frontends.
+	EdgeLBObjectTypeFrontend = "frontend"
+	// EdgeLBObjectTypePool is the type of EdgeLB pools.
+	EdgeLBObjectTypePool = "pool"
+	// EdgeLBObjectTypeSecret is the type of EdgeLB pool secrets.
+	EdgeLBObjectTypeSecret = "secret"
+	// EdgeLBObjectTypeSNIHostname is the type of TLS SNI hostnames in an EdgeLB frontend shared by Service resources.
+	EdgeLBObjectTypeSNIHostname = "sni-hostname"
+)
+
+// EdgeLBObjectOwner identifies the Ingress/Service resource that owns a given EdgeLB object.
+type EdgeLBObjectOwner struct {
+	// Kind is the kind of the resource (i.e. "Ingress" or "Service").
+	Kind string `json:"kind"`
+	// Namespace is the namespace to which the resource belongs.
+	Namespace string `json:"namespace"`
+	// Name is the name of the resource.
+	Name string `json:"name"`
+}
+
+// String returns a human-readable representation of the owner.
+func (o EdgeLBObjectOwner) String() string {
+	return fmt.Sprintf("%s %s/%s", o.Kind, o.Namespace, o.Name)
+}
+
+// RemovedEdgeLBObject represents an EdgeLB object that has been removed from an EdgeLB pool because its owner no longer exists.
+type RemovedEdgeLBObject struct {
+	// Type is the type of the EdgeLB object (e.g. "backend").
+	Type string
+	// Name is the name of the EdgeLB object.
+	Name string
+	// Owner is the Ingress/Service resource that owned the EdgeLB object.
+	Owner EdgeLBObjectOwner
+}
+
+// ComputeEdgeLBObjectOwner parses the specified EdgeLB backend/frontend name and returns the Ingress/Service resource that owns it.
+// nil is returned in case the name doesn't correspond to an EdgeLB object owned by an Ingress/Service resource in the specified Kubernetes cluster.
+func ComputeEdgeLBObjectOwner(clusterName, name string) *EdgeLBObjectOwner {
+	if m, err := computeServiceOwnedEdgeLBObjectMetadata(name); err == nil {
+		if m.ClusterName != clusterName {
+			return nil
+		}
+		return &EdgeLBObjectOwner{
+			Kind:      EdgeLBObjectOwnerKindService,
+			Namespace: m.Namespace,
+			Name:      m.Name,
+		}
+	}
+	if m, err := computeIngressOwnedEdgeLBObjectMetadata(name); err == nil {
+		if m.ClusterName != clusterName {
+			return nil
+		}
+		return &EdgeLBObjectOwner{
+			Kind:      EdgeLBObjectOwnerKindIngress,
+			Namespace: m.Namespace,
+			Name:      m.Name,
+		}
+	}
+	return nil
+}
+
+// RemoveOrphanedEdgeLBObjects removes the EdgeLB objects owned by Ingress/Service resources in the specified Kubernetes cluster for which "isOrphaned" returns true from the specified EdgeLB pool.
+// It modifies the specified EdgeLB pool in-place and returns the list of removed EdgeLB objects.
+// Besides EdgeLB backends and frontends, TLS SNI hostnames pointing at removed EdgeLB backends and EdgeLB pool secrets owned by removed Ingress resources are removed as well.
+// EdgeLB frontends shared via TLS SNI are removed in case they are left without any TLS SNI hostnames.
+func RemoveOrphanedEdgeLBObjects(pool *models.V2Pool, clusterName string, isOrphaned func(owner EdgeLBObjectOwner) bool) []RemovedEdgeLBObject {
+	res := make([]RemovedEdgeLBObject, 0)
+	if pool.Haproxy == nil {
+		return res
+	}
+
+	// orphanedOwner returns the owner of the EdgeLB object with the specified name in case said owner is orphaned.
+	// orphanedIngresses holds the Ingress resources found to be orphaned, so that the EdgeLB pool secrets they own can be removed.
+	orphanedIngresses := make(map[EdgeLBObjectOwner]bool)
+	orphanedOwner := func(name string) *EdgeLBObjectOwner {
+		owner := ComputeEdgeLBObjectOwner(clusterName, name)
+		if owner == nil || !isOrphaned(*owner) {
+			return nil
+		}
+		if owner.Kind == EdgeLBObjectOwnerKindIngress {
+			orphanedIngresses[*owner] = true
+		}
+		return owner
+	}
+
+	// Remove EdgeLB backends whose owner is orphaned.
+	backends := make([]*models.V2Backend, 0, len(pool.Haproxy.Backends))
+	for _, backend := range pool.Haproxy.Backends {
+		if owner := orphanedOwner(backend.Name); owner != nil {
+			res = append(res, RemovedEdgeLBObject{Type: EdgeLBObjectTypeBackend, Name: backend.Name, Owner: *owner})
+			continue
+		}
+		backends = append(backends, backend)
+	}
+
+	// Remove EdgeLB frontends whose owner is orphaned, as well as TLS SNI hostnames pointing at EdgeLB backends whose owner is orphaned.
+	frontends := make([]*models.V2Frontend, 0, len(pool.Haproxy.Frontends))
+	for _, frontend := range pool.Haproxy.Frontends {
+		if owner := orphanedOwner(frontend.Name); owner != nil {
+			res = append(res, RemovedEdgeLBObject{Type: EdgeLBObjectTypeFrontend, Name: frontend.Name, Owner: *owner})
+			continue
+		}
+		if frontend.LinkBackend == nil || len(frontend.LinkBackend.Map) == 0 {
+			frontends = append(frontends, frontend)
+			continue
+		}
+		items := make([]*models.V2FrontendLinkBackendMapItems0, 0, len(frontend.LinkBackend.Map))
+		for _, item := range frontend.LinkBackend.Map {
+			if owner := orphanedOwner(item.Backend); owner != nil {
+				res = append(res, RemovedEdgeLBObject{Type: EdgeLBObjectTypeSNIHostname, Name: item.HostEq, Owner: *owner})
+				continue
+			}
+			items = append(items, item)
+		}
+		frontend.LinkBackend.Map = items
+		// Remove EdgeLB frontends shared via TLS SNI that are not used by any Service resource anymore.
+		if _, err := computeSNIFrontendBindPort(frontend.Name); err == nil && len(items) == 0 {
+			continue
+		}
+		frontends = append(frontends, frontend)
+	}
+
+	// Remove EdgeLB pool secrets owned by Ingress resources that are orphaned.
+	var secrets []*models.V2PoolSecretsItems0
+	for _, secret := range pool.Secrets {
+		removed := false
+		for owner := range orphanedIngresses {
+			ingress := &extsv1beta1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: owner.Namespace,
+					Name:      owner.Name,
+				},
+			}
+			if isEdgeLBSecretOwnedByIngress(clusterName, ingress, secret) {
+				res = append(res, RemovedEdgeLBObject{Type: EdgeLBObjectTypeSecret, Name: secret.File, Owner: owner})
+				removed = true
+				break
+			}
+		}
+		if !removed {
+			secrets = append(secrets, secret)
+		}
+	}
+
+	pool.Haproxy.Backends, pool.Haproxy.Frontends, pool.Secrets = backends, frontends, secrets
+	return res
+}
@@ -0,0 +1,117 @@
+package translator
+
+import (
+	"testing"
+
+	"github.com/mesosphere/dcos-edge-lb/models"
+	"github.com/stretchr/testify/assert"
+
+	edgelbpooltestutil "github.com/mesosphere/dklb/test/util/edgelb/pool"
+)
+
+// TestComputeEdgeLBObjectOwner tests the "ComputeEdgeLBObjectOwner" function.
+func TestComputeEdgeLBObjectOwner(t *testing.T) {
+	tests := []struct {
+		description   string
+		name          string
+		expectedOwner *EdgeLBObjectOwner
+	}{
+		{
+			description:   "backend owned by a service",
+			name:          "dev.kubernetes01:foo:bar:80",
+			expectedOwner: &EdgeLBObjectOwner{Kind: EdgeLBObjectOwnerKindService, Namespace: "foo", Name: "bar"},
+		},
+		{
+			description:   "frontend owned by an ingress",
+			name:          "dev.kubernetes01:foo:bar",
+			expectedOwner: &EdgeLBObjectOwner{Kind: EdgeLBObjectOwnerKindIngress, Namespace: "foo", Name: "bar"},
+		},
+		{
+			description:   "https frontend owned by an ingress",
+			name:          "dev.kubernetes01:foo:bar:https",
+			expectedOwner: &EdgeLBObjectOwner{Kind: EdgeLBObjectOwnerKindIngress, Namespace: "foo", Name: "bar"},
+		},
+		{
+			description:   "backend owned by an ingress",
+			name:          "dev.kubernetes01:foo:bar:baz:80",
+			expectedOwner: &EdgeLBObjectOwner{Kind: EdgeLBObjectOwnerKindIngress, Namespace: "foo", Name: "bar"},
+		},
+		{
+			description:   "backend owned by a service in a different cluster",
+			name:          "dev.kubernetes02:foo:bar:80",
+			expectedOwner: nil,
+		},
+		{
+			description:   "frontend shared via tls sni",
+			name:          "sni:443",
+			expectedOwner: nil,
+		},
+		{
+			description:   "backend not created by dklb",
+			name:          "my-backend",
+			expectedOwner: nil,
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		assert.Equal(t, test.expectedOwner, ComputeEdgeLBObjectOwner(testClusterName, test.name))
+	}
+}
+
+// TestRemoveOrphanedEdgeLBObjects tests the "RemoveOrphanedEdgeLBObjects" function.
+func TestRemoveOrphanedEdgeLBObjects(t *testing.T) {
+	var (
+		orphanedIngress = EdgeLBObjectOwner{Kind: EdgeLBObjectOwnerKindIngress, Namespace: "foo", Name: "orphaned"}
+		orphanedService = EdgeLBObjectOwner{Kind: EdgeLBObjectOwnerKindService, Namespace: "foo", Name: "orphaned"}
+	)
+	pool := edgelbpooltestutil.DummyEdgeLBPool("foo", func(p *models.V2Pool) {
+		p.Haproxy.Backends = []*models.V2Backend{
+			{Name: "dev.kubernetes01:foo:orphaned:80"},
+			{Name: "dev.kubernetes01:foo:orphaned:443"},
+			{Name: "dev.kubernetes01:foo:orphaned:bar:80"},
+			{Name: "dev.kubernetes01:foo:existing:80"},
+			{Name: "dev.kubernetes02:foo:orphaned:80"},
+		}
+		p.Haproxy.Frontends = []*models.V2Frontend{
+			{Name: "dev.kubernetes01:foo:orphaned:80"},
+			{Name: "dev.kubernetes01:foo:orphaned"},
+			{Name: "dev.kubernetes01:foo:existing:80"},
+			{Name: "dev.kubernetes02:foo:orphaned:80"},
+			{
+				Name: "sni:443",
+				LinkBackend: &models.V2FrontendLinkBackend{
+					Map: []*models.V2FrontendLinkBackendMapItems0{
+						{Backend: "dev.kubernetes01:foo:orphaned:443", HostEq: "foo.example.com"},
+					},
+				},
+			},
+		}
+		p.Secrets = []*models.V2PoolSecretsItems0{
+			{File: "dev.kubernetes01__foo__orphaned__bar__0123"},
+			{File: "dev.kubernetes01__foo__existing__bar__0123"},
+		}
+	})
+	removed := RemoveOrphanedEdgeLBObjects(pool, testClusterName, func(owner EdgeLBObjectOwner) bool {
+		return owner.Name == "orphaned"
+	})
+	assert.Equal(t, []RemovedEdgeLBObject{
+		{Type: EdgeLBObjectTypeBackend, Name: "dev.kubernetes01:foo:orphaned:80", Owner: orphanedService},
+		{Type: EdgeLBObjectTypeBackend, Name: "dev.kubernetes01:foo:orphaned:443", Owner: orphanedService},
+		{Type: EdgeLBObjectTypeBackend, Name: "dev.kubernetes01:foo:orphaned:bar:80", Owner: orphanedIngress},
+		{Type: EdgeLBObjectTypeFrontend, Name: "dev.kubernetes01:foo:orphaned:80", Owner: orphanedService},
+		{Type: EdgeLBObjectTypeFrontend, Name: "dev.kubernetes01:foo:orphaned", Owner: orphanedIngress},
+		{Type: EdgeLBObjectTypeSNIHostname, Name: "foo.example.com", Owner: orphanedService},
+		{Type: EdgeLBObjectTypeSecret, Name: "dev.kubernetes01__foo__orphaned__bar__0123", Owner: orphanedIngress},
+	}, removed)
+	assert.Equal(t, []*models.V2Backend{
+		{Name: "dev.kubernetes01:foo:existing:80"},
+		{Name: "dev.kubernetes02:foo:orphaned:80"},
+	}, pool.Haproxy.Backends)
+	assert.Equal(t, []*models.V2Frontend{
+		{Name: "dev.kubernetes01:foo:existing:80"},
+		{Name: "dev.kubernetes02:foo:orphaned:80"},
+	}, pool.Haproxy.Frontends)
+	assert.Equal(t, []*models.V2PoolSecretsItems0{
+		{File: "dev.kubernetes01__foo__existing__bar__0123"},
+	}, pool.Secrets)
+}
@@ -0,0 +1,182 @@
+package translator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mesosphere/dcos-edge-lb/models"
+
+	"github.com/mesosphere/dklb/pkg/constants"
+)
+
+const (
+	// httpCheckRequestFormatString is the format string used to compute the HTTP request sent to servers when performing HTTP health checks.
+	httpCheckRequestFormatString = "GET %s"
+	// httpCheckExpectStatusFormatString is the format string used to compute the HAProxy directive that sets the HTTP status code expected in responses to health check requests.
+	httpCheckExpectStatusFormatString = "http-check expect status %d"
+	// checkTimeoutFormatString is the format string used to compute the HAProxy directive that sets the timeout for a health check to succeed (in milliseconds).
+	checkTimeoutFormatString = "timeout check %dms"
+	// checkIntervalFormatString is the format string used to compute the server option that sets the interval between two consecutive health checks (in milliseconds).
+	checkIntervalFormatString = "inter %dms"
+	// checkRiseFormatString is the format string used to compute the server option that sets the number of consecutive successful health checks after which a server is considered healthy.
+	checkRiseFormatString = "rise %d"
+	// checkFallFormatString is the format string used to compute the server option that sets the number of consecutive failed health checks after which a server is considered unhealthy.
+	checkFallFormatString = "fall %d"
+)
+
+// HealthCheckOptions groups together options used to configure the health checks performed by EdgeLB backends.
+// Zero values denote that the corresponding HAProxy defaults should be used.
+type HealthCheckOptions struct {
+	// HTTPPath is the path to which HTTP health check requests are sent.
+	// If empty, servers are checked by establishing a TCP connection.
+	HTTPPath string
+	// ExpectedStatus is the HTTP status code expected in responses to health check requests.
+	// It can only be specified together with "HTTPPath".
+	ExpectedStatus int
+	// Interval is the interval between two consecutive health checks.
+	Interval time.Duration
+	// Timeout is the timeout for a health check to succeed.
+	Timeout time.Duration
+	// Rise is the number of consecutive successful health checks after which a server is considered healthy.
+	Rise int
+	// Fall is the number of consecutive failed health checks after which a server is considered unhealthy.
+	Fall int
+}
+
+// parseHealthCheckOptions attempts to compute the configuration of health checks from the specified set of annotations.
+// In case options cannot be computed or are invalid, the error message MUST be suitable to be used as the message for a Kubernetes event associated with the resource.
+func parseHealthCheckOptions(annotations map[string]string) (*HealthCheckOptions, error) {
+	res := &HealthCheckOptions{}
+
+	// Parse the path to which HTTP health check requests are sent.
+	if v := annotations[constants.EdgeLBHealthCheckPathAnnotationKey]; v != "" {
+		if err := validateHealthCheckPath(v); err != nil {
+			return nil, fmt.Errorf("%q is not a valid health check path: %v", v, err)
+		}
+		res.HTTPPath = v
+	}
+
+	// Parse the HTTP status code expected in responses to health check requests.
+	if v := annotations[constants.EdgeLBHealthCheckExpectedStatusAnnotationKey]; v != "" {
+		r, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %q as the expected health check status: %v", v, err)
+		}
+		if r < 100 || r > 599 {
+			return nil, fmt.Errorf("%d is not a valid http status code", r)
+		}
+		if res.HTTPPath == "" {
+			return nil, fmt.Errorf("an expected health check status can only be specified together with a health check path")
+		}
+		res.ExpectedStatus = r
+	}
+
+	// Parse the interval between two consecutive health checks, and the timeout for a health check to succeed.
+	var err error
+	if res.Interval, err = parseHealthCheckDuration(annotations[constants.EdgeLBHealthCheckIntervalAnnotationKey], "interval"); err != nil {
+		return nil, err
+	}
+	if res.Timeout, err = parseHealthCheckDuration(annotations[constants.EdgeLBHealthCheckTimeoutAnnotationKey], "timeout"); err != nil {
+		return nil, err
+	}
+
+	// Parse the number of consecutive successful and failed health checks after which the state of a server changes.
+	if res.Rise, err = parseHealthCheckCount(annotations[constants.EdgeLBHealthCheckRiseAnnotationKey], "rise"); err != nil {
+		return nil, err
+	}
+	if res.Fall, err = parseHealthCheckCount(annotations[constants.EdgeLBHealthCheckFallAnnotationKey], "fall"); err != nil {
+		return nil, err
+	}
+
+	// Return the computed set of options.
+	return res, nil
+}
+
+// parseHealthCheckDuration parses the specified value as a health check duration, returning zero in case said value is empty.
+// "name" is used only to make returned error messages clearer.
+func parseHealthCheckDuration(v, name string) (time.Duration, error) {
+	if v == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse %q as the health check %s: %v", v, name, err)
+	}
+	// HAProxy expresses durations in milliseconds.
+	if d <= 0 || d%time.Millisecond != 0 {
+		return 0, fmt.Errorf("%q is not a valid health check %s as it is not a positive whole number of milliseconds", v, name)
+	}
+	return d, nil
+}
+
+// parseHealthCheckCount parses the specified value as a health check count, returning zero in case said value is empty.
+// "name" is used only to make returned error messages clearer.
+func parseHealthCheckCount(v, name string) (int, error) {
+	if v == "" {
+		return 0, nil
+	}
+	r, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse %q as the health check %s count: %v", v, name, err)
+	}
+	if r <= 0 {
+		return 0, fmt.Errorf("%d is not a valid health check %s count", r, name)
+	}
+	return r, nil
+}
+
+// validateHealthCheckPath checks whether the specified path can be used in HTTP health check requests.
+func validateHealthCheckPath(path string) error {
+	if !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("path must start with %q", "/")
+	}
+	for _, c := range path {
+		if err := validatePathCharacter(c); err != nil {
+			return err
+		}
+		// Characters with special meaning in the HAProxy configuration cannot be escaped in the HTTP health check request line.
+		if isHAProxyConfigCharacter(c) || c == '\\' {
+			return fmt.Errorf("character %q is not supported", c)
+		}
+	}
+	return nil
+}
+
+// applyHealthCheckOptions configures the health checks performed by the specified EdgeLB backend according to the specified options.
+func applyHealthCheckOptions(backend *models.V2Backend, options HealthCheckOptions) {
+	// Use HTTP health checks if a path has been specified.
+	if options.HTTPPath != "" {
+		backend.CustomCheck = &models.V2BackendCustomCheck{
+			Httpchk:        true,
+			HttpchkMiscStr: fmt.Sprintf(httpCheckRequestFormatString, options.HTTPPath),
+		}
+		if options.ExpectedStatus != 0 {
+			backend.CustomCheck.MiscStr = fmt.Sprintf(httpCheckExpectStatusFormatString, options.ExpectedStatus)
+		}
+	}
+	// Set the timeout for health checks to succeed, if specified.
+	if options.Timeout != 0 {
+		backend.MiscStrs = append(backend.MiscStrs, fmt.Sprintf(checkTimeoutFormatString, options.Timeout/time.Millisecond))
+	}
+	// Compute the server options that control the frequency of health checks and the transitions between states, and set them on each service's health check.
+	serverOpts := make([]string, 0, 3)
+	if options.Interval != 0 {
+		serverOpts = append(serverOpts, fmt.Sprintf(checkIntervalFormatString, options.Interval/time.Millisecond))
+	}
+	if options.Rise != 0 {
+		serverOpts = append(serverOpts, fmt.Sprintf(checkRiseFormatString, options.Rise))
+	}
+	if options.Fall != 0 {
+		serverOpts = append(serverOpts, fmt.Sprintf(checkFallFormatString, options.Fall))
+	}
+	if len(serverOpts) == 0 {
+		return
+	}
+	for _, service := range backend.Services {
+		if service.Endpoint != nil && service.Endpoint.Check != nil {
+			service.Endpoint.Check.CustomStr = strings.Join(serverOpts, " ")
+		}
+	}
+}
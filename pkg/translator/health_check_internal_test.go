@@ -0,0 +1,157 @@
+package translator
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mesosphere/dcos-edge-lb/models"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mesosphere/dklb/pkg/constants"
+	"github.com/mesosphere/dklb/pkg/util/pointers"
+)
+
+// TestParseHealthCheckOptions tests the "parseHealthCheckOptions" function.
+func TestParseHealthCheckOptions(t *testing.T) {
+	tests := []struct {
+		description string
+		annotations map[string]string
+		options     *HealthCheckOptions
+		err         error
+	}{
+		{
+			description: "no annotations",
+			annotations: map[string]string{},
+			options:     &HealthCheckOptions{},
+		},
+		{
+			description: "all annotations",
+			annotations: map[string]string{
+				constants.EdgeLBHealthCheckPathAnnotationKey:           "/healthz",
+				constants.EdgeLBHealthCheckExpectedStatusAnnotationKey: "204",
+				constants.EdgeLBHealthCheckIntervalAnnotationKey:       "5s",
+				constants.EdgeLBHealthCheckTimeoutAnnotationKey:        "1500ms",
+				constants.EdgeLBHealthCheckRiseAnnotationKey:           "2",
+				constants.EdgeLBHealthCheckFallAnnotationKey:           "3",
+			},
+			options: &HealthCheckOptions{
+				HTTPPath:       "/healthz",
+				ExpectedStatus: 204,
+				Interval:       5 * time.Second,
+				Timeout:        1500 * time.Millisecond,
+				Rise:           2,
+				Fall:           3,
+			},
+		},
+		{
+			description: "invalid path",
+			annotations: map[string]string{
+				constants.EdgeLBHealthCheckPathAnnotationKey: "healthz",
+			},
+			err: fmt.Errorf("%q is not a valid health check path: %v", "healthz", fmt.Errorf("path must start with %q", "/")),
+		},
+		{
+			description: "expected status without a path",
+			annotations: map[string]string{
+				constants.EdgeLBHealthCheckExpectedStatusAnnotationKey: "200",
+			},
+			err: errors.New("an expected health check status can only be specified together with a health check path"),
+		},
+		{
+			description: "invalid expected status",
+			annotations: map[string]string{
+				constants.EdgeLBHealthCheckPathAnnotationKey:           "/healthz",
+				constants.EdgeLBHealthCheckExpectedStatusAnnotationKey: "999",
+			},
+			err: fmt.Errorf("%d is not a valid http status code", 999),
+		},
+		{
+			description: "negative interval",
+			annotations: map[string]string{
+				constants.EdgeLBHealthCheckIntervalAnnotationKey: "-1s",
+			},
+			err: fmt.Errorf("%q is not a valid health check %s as it is not a positive whole number of milliseconds", "-1s", "interval"),
+		},
+		{
+			description: "invalid fall count",
+			annotations: map[string]string{
+				constants.EdgeLBHealthCheckFallAnnotationKey: "0",
+			},
+			err: fmt.Errorf("%d is not a valid health check %s count", 0, "fall"),
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		options, err := parseHealthCheckOptions(test.annotations)
+		if test.err != nil {
+			assert.Equal(t, test.err, err)
+		} else {
+			assert.NoError(t, err)
+			assert.Equal(t, test.options, options)
+		}
+	}
+}
+
+// TestApplyHealthCheckOptions tests the "applyHealthCheckOptions" function.
+func TestApplyHealthCheckOptions(t *testing.T) {
+	tests := []struct {
+		description         string
+		options             HealthCheckOptions
+		expectedCustomCheck *models.V2BackendCustomCheck
+		expectedMiscStrs    []string
+		expectedCheck       *models.V2EndpointCheck
+	}{
+		{
+			description:         "default options",
+			options:             HealthCheckOptions{},
+			expectedCustomCheck: nil,
+			expectedMiscStrs:    nil,
+			expectedCheck: &models.V2EndpointCheck{
+				Enabled: pointers.NewBool(true),
+			},
+		},
+		{
+			description: "http health checks with custom timings",
+			options: HealthCheckOptions{
+				HTTPPath:       "/healthz",
+				ExpectedStatus: 200,
+				Interval:       2 * time.Second,
+				Timeout:        time.Second,
+				Rise:           2,
+				Fall:           5,
+			},
+			expectedCustomCheck: &models.V2BackendCustomCheck{
+				Httpchk:        true,
+				HttpchkMiscStr: "GET /healthz",
+				MiscStr:        "http-check expect status 200",
+			},
+			expectedMiscStrs: []string{
+				"timeout check 1000ms",
+			},
+			expectedCheck: &models.V2EndpointCheck{
+				Enabled:   pointers.NewBool(true),
+				CustomStr: "inter 2000ms rise 2 fall 5",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		backend := &models.V2Backend{
+			Services: []*models.V2Service{
+				{
+					Endpoint: &models.V2Endpoint{
+						Check: &models.V2EndpointCheck{
+							Enabled: pointers.NewBool(true),
+						},
+					},
+				},
+			},
+		}
+		applyHealthCheckOptions(backend, test.options)
+		assert.Equal(t, test.expectedCustomCheck, backend.CustomCheck)
+		assert.Equal(t, test.expectedMiscStrs, backend.MiscStrs)
+		assert.Equal(t, test.expectedCheck, backend.Services[0].Endpoint.Check)
+	}
+}
@@ -0,0 +1,92 @@
+package translator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// edgeLBSSLRedirectHostACLName is the name of the HAProxy ACL that matches requests for hosts served over HTTPS.
+	edgeLBSSLRedirectHostACLName = "dklb-https-host"
+	// edgeLBSSLRedirectExceptionACLName is the name of the HAProxy ACL that matches requests for hosts that must not be redirected to the HTTPS frontend.
+	edgeLBSSLRedirectExceptionACLName = "dklb-ssl-redirect-exception"
+	// edgeLBHostACLFormatString is the format string used to compute an HAProxy ACL that matches requests based on the value of the "Host" header.
+	edgeLBHostACLFormatString = "acl %s req.hdr(host) -m reg -i %s"
+	// edgeLBSSLRedirectSchemeFormatString is the format string used to compute the HAProxy directive that redirects requests to the HTTPS frontend when it is bound to the default HTTPS port.
+	edgeLBSSLRedirectSchemeFormatString = "http-request redirect scheme https code %d"
+	// edgeLBSSLRedirectLocationFormatString is the format string used to compute the HAProxy directive that redirects requests to the HTTPS frontend when it is bound to a non-default port.
+	// The port included in the "Host" header (if any) is replaced with the bind port of the HTTPS frontend, and the original URI (including the query string) is preserved.
+	edgeLBSSLRedirectLocationFormatString = "http-request redirect location https://%%[req.hdr(host),field(1,:)]:%d%%[capture.req.uri] code %d"
+	// edgeLBHSTSFormatString is the format string used to compute the HAProxy directive that adds the "Strict-Transport-Security" header to responses served over HTTPS.
+	edgeLBHSTSFormatString = "http-response set-header Strict-Transport-Security \"%s\" if { ssl_fc }"
+	// hstsMaxAgeFormatString is the format string used to compute the "max-age" directive of the "Strict-Transport-Security" header.
+	hstsMaxAgeFormatString = "max-age=%d"
+	// hstsIncludeSubdomainsDirective is the "includeSubDomains" directive of the "Strict-Transport-Security" header.
+	hstsIncludeSubdomainsDirective = "includeSubDomains"
+	// httpsDefaultPort is the default port for HTTPS.
+	httpsDefaultPort = 443
+)
+
+// computeEdgeLBHostACLRegex computes the regular expression used in HAProxy ACLs to match the specified (possibly wildcard) host against the value of the "Host" header.
+func computeEdgeLBHostACLRegex(host string) string {
+	if isWildcardHost(host) {
+		return computeEdgeLBHostRegex(host)
+	}
+	return fmt.Sprintf(edgeLBHostRegexFormatString, regexp.QuoteMeta(host))
+}
+
+// computeEdgeLBHostACL computes the HAProxy ACL with the specified name that matches requests for any of the specified (possibly wildcard) hosts.
+// Hosts are sorted in order to get a predictable output.
+func computeEdgeLBHostACL(name string, hosts []string) string {
+	regexes := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		regexes = append(regexes, computeEdgeLBHostACLRegex(host))
+	}
+	sort.Strings(regexes)
+	return fmt.Sprintf(edgeLBHostACLFormatString, name, strings.Join(regexes, " "))
+}
+
+// computeEdgeLBSSLRedirectDirectives computes the HAProxy directives that redirect requests made to the HTTP frontend of an Ingress resource to its HTTPS frontend.
+// "httpsHosts" is the list of hosts served over HTTPS, an empty list meaning that all hosts are served over HTTPS.
+// Requests for hosts not served over HTTPS, as well as requests for hosts matching any of the specified exceptions, are not redirected.
+func computeEdgeLBSSLRedirectDirectives(options IngressTranslationOptions, httpsHosts []string) []string {
+	code := options.EdgeLBSSLRedirectCode
+	if code == 0 {
+		code = DefaultEdgeLBSSLRedirectCode
+	}
+	// Compute the directive that performs the redirect.
+	// When the HTTPS frontend is bound to the default HTTPS port, it is enough to change the scheme of the request.
+	var redirect string
+	if options.EdgeLBPoolHTTPSPort == 0 || options.EdgeLBPoolHTTPSPort == httpsDefaultPort {
+		redirect = fmt.Sprintf(edgeLBSSLRedirectSchemeFormatString, code)
+	} else {
+		redirect = fmt.Sprintf(edgeLBSSLRedirectLocationFormatString, options.EdgeLBPoolHTTPSPort, code)
+	}
+	// Compute the ACLs that restrict the set of requests being redirected, as well as the corresponding condition.
+	res := make([]string, 0, 3)
+	conditions := make([]string, 0, 2)
+	if len(httpsHosts) > 0 {
+		res = append(res, computeEdgeLBHostACL(edgeLBSSLRedirectHostACLName, httpsHosts))
+		conditions = append(conditions, edgeLBSSLRedirectHostACLName)
+	}
+	if len(options.EdgeLBSSLRedirectExceptions) > 0 {
+		res = append(res, computeEdgeLBHostACL(edgeLBSSLRedirectExceptionACLName, options.EdgeLBSSLRedirectExceptions))
+		conditions = append(conditions, "!"+edgeLBSSLRedirectExceptionACLName)
+	}
+	if len(conditions) > 0 {
+		redirect = redirect + " if " + strings.Join(conditions, " ")
+	}
+	return append(res, redirect)
+}
+
+// computeEdgeLBHSTSDirective computes the HAProxy directive that adds the "Strict-Transport-Security" header with the specified directives to responses served over HTTPS.
+func computeEdgeLBHSTSDirective(maxAge time.Duration, includeSubdomains bool) string {
+	value := fmt.Sprintf(hstsMaxAgeFormatString, int64(maxAge/time.Second))
+	if includeSubdomains {
+		value = value + "; " + hstsIncludeSubdomainsDirective
+	}
+	return fmt.Sprintf(edgeLBHSTSFormatString, value)
+}
@@ -0,0 +1,106 @@
+package translator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	ingresstestutil "github.com/mesosphere/dklb/test/util/kubernetes/ingress"
+)
+
+// TestComputeEdgeLBSSLRedirectDirectives tests the "computeEdgeLBSSLRedirectDirectives" function.
+func TestComputeEdgeLBSSLRedirectDirectives(t *testing.T) {
+	tests := []struct {
+		description string
+		options     IngressTranslationOptions
+		httpsHosts  []string
+		directives  []string
+	}{
+		{
+			description: "all hosts served over https on the default port",
+			options: IngressTranslationOptions{
+				EdgeLBPoolHTTPSPort:      DefaultEdgeLBPoolHTTPSPort,
+				EdgeLBSSLRedirectEnabled: true,
+				EdgeLBSSLRedirectCode:    DefaultEdgeLBSSLRedirectCode,
+			},
+			httpsHosts: nil,
+			directives: []string{
+				"http-request redirect scheme https code 301",
+			},
+		},
+		{
+			description: "some hosts served over https on a custom port, with exceptions",
+			options: IngressTranslationOptions{
+				EdgeLBPoolHTTPSPort:         8443,
+				EdgeLBSSLRedirectEnabled:    true,
+				EdgeLBSSLRedirectCode:       308,
+				EdgeLBSSLRedirectExceptions: []string{"bar.example.com"},
+			},
+			httpsHosts: []string{"foo.example.com", "*.example.com"},
+			directives: []string{
+				"acl dklb-https-host req.hdr(host) -m reg -i ^[^.:]+\\.example\\.com(:[0-9]+)?$ ^foo\\.example\\.com(:[0-9]+)?$",
+				"acl dklb-ssl-redirect-exception req.hdr(host) -m reg -i ^bar\\.example\\.com(:[0-9]+)?$",
+				"http-request redirect location https://%[req.hdr(host),field(1,:)]:8443%[capture.req.uri] code 308 if dklb-https-host !dklb-ssl-redirect-exception",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		assert.Equal(t, test.directives, computeEdgeLBSSLRedirectDirectives(test.options, test.httpsHosts))
+	}
+}
+
+// TestComputeEdgeLBHSTSDirective tests the "computeEdgeLBHSTSDirective" function.
+func TestComputeEdgeLBHSTSDirective(t *testing.T) {
+	tests := []struct {
+		description       string
+		maxAge            time.Duration
+		includeSubdomains bool
+		directive         string
+	}{
+		{
+			description:       "zero max-age",
+			maxAge:            0,
+			includeSubdomains: false,
+			directive:         "http-response set-header Strict-Transport-Security \"max-age=0\" if { ssl_fc }",
+		},
+		{
+			description:       "default max-age including subdomains",
+			maxAge:            DefaultEdgeLBHSTSMaxAge,
+			includeSubdomains: true,
+			directive:         "http-response set-header Strict-Transport-Security \"max-age=31536000; includeSubDomains\" if { ssl_fc }",
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		assert.Equal(t, test.directive, computeEdgeLBHSTSDirective(test.maxAge, test.includeSubdomains))
+	}
+}
+
+// TestComputeEdgeLBFrontendsForIngressWithSSLRedirect tests that requests are only redirected to the HTTPS frontend when it exists.
+func TestComputeEdgeLBFrontendsForIngressWithSSLRedirect(t *testing.T) {
+	ingress := ingresstestutil.DummyIngressResource("foo", "bar")
+	options := IngressTranslationOptions{
+		EdgeLBPoolHTTPPort:       DefaultEdgeLBPoolHTTPPort,
+		EdgeLBPoolHTTPSPort:      DefaultEdgeLBPoolHTTPSPort,
+		EdgeLBSSLRedirectEnabled: true,
+		EdgeLBSSLRedirectCode:    DefaultEdgeLBSSLRedirectCode,
+	}
+
+	// Make sure that requests are not redirected when there are no valid TLS secrets.
+	frontends := computeEdgeLBFrontendsForIngress(testClusterName, ingress, options, nil)
+	assert.Len(t, frontends, 1)
+	assert.Nil(t, frontends[0].MiscStrs)
+
+	// Make sure that requests for the hosts covered by the TLS secrets are redirected otherwise.
+	frontends = computeEdgeLBFrontendsForIngress(testClusterName, ingress, options, []ingressTLSSecret{
+		{
+			FileName: "foo",
+			Hosts:    []string{"foo.example.com"},
+		},
+	})
+	assert.Len(t, frontends, 2)
+	assert.Equal(t, computeEdgeLBSSLRedirectDirectives(options, []string{"foo.example.com"}), frontends[0].MiscStrs)
+	assert.Nil(t, frontends[1].MiscStrs)
+}
@@ -1,8 +1,12 @@
 package translator
 
 import (
+	"encoding/json"
 	"fmt"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
 	extsv1beta1 "k8s.io/api/extensions/v1beta1"
 	"k8s.io/apimachinery/pkg/util/validation"
@@ -10,11 +14,62 @@ import (
 	"github.com/mesosphere/dklb/pkg/constants"
 )
 
+var (
+	// stickySessionsCookieNameRegex is the regular expression used to validate the name of the cookie used to implement sticky sessions.
+	// Cookie names are restricted to alphanumeric characters, dashes and underscores so that they can be safely included in the HAProxy configuration.
+	stickySessionsCookieNameRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+	// sslRedirectCodes is the set of HTTP status codes that can be used when redirecting requests to the HTTPS frontend.
+	sslRedirectCodes = map[int]bool{
+		301: true,
+		302: true,
+		303: true,
+		307: true,
+		308: true,
+	}
+)
+
 // IngressTranslationOptions groups together options used to "translate" an Ingress resource into an EdgeLB pool.
 type IngressTranslationOptions struct {
 	BaseTranslationOptions
-	// EdgeLBPoolPortAnnotationKey is the port to be used as a frontend bind port by the EdgeLB pool.
-	EdgeLBPoolPort int32
+	// EdgeLBPoolHTTPPort is the port to be used as the bind port for the HTTP frontend of the EdgeLB pool.
+	EdgeLBPoolHTTPPort int32
+	// EdgeLBPoolHTTPSPort is the port to be used as the bind port for the HTTPS frontend of the EdgeLB pool.
+	EdgeLBPoolHTTPSPort int32
+	// EdgeLBPathMatchType is the way in which the paths defined in the Ingress resource are matched against the paths of incoming requests.
+	EdgeLBPathMatchType constants.EdgeLBPathMatchType
+	// EdgeLBRewriteTarget is the path with which the paths matched by all the rules of the Ingress resource are replaced before requests are forwarded.
+	// An empty value means that paths are not rewritten (unless a rewrite target is specified for a given path in "EdgeLBRewriteTargets").
+	EdgeLBRewriteTarget string
+	// EdgeLBRewriteTargets is the mapping between paths defined in the Ingress resource and the path with which they are replaced before requests are forwarded.
+	// Entries in this map take precedence over "EdgeLBRewriteTarget".
+	EdgeLBRewriteTargets map[string]string
+	// EdgeLBStickySessionsEnabled indicates whether cookie-based sticky sessions are enabled for the Ingress resource.
+	EdgeLBStickySessionsEnabled bool
+	// EdgeLBStickySessionsCookieName is the name of the cookie used to implement sticky sessions.
+	// It is only set when sticky sessions are enabled.
+	EdgeLBStickySessionsCookieName string
+	// EdgeLBStickySessionsCookieTTL is the maximum lifetime of the cookie used to implement sticky sessions, zero meaning no maximum lifetime.
+	// It is only set when sticky sessions are enabled.
+	EdgeLBStickySessionsCookieTTL time.Duration
+	// EdgeLBSSLRedirectEnabled indicates whether requests made to the HTTP frontend for hosts served over HTTPS should be redirected to the HTTPS frontend.
+	EdgeLBSSLRedirectEnabled bool
+	// EdgeLBSSLRedirectCode is the HTTP status code used when redirecting requests to the HTTPS frontend.
+	// It is only set when redirects are enabled.
+	EdgeLBSSLRedirectCode int
+	// EdgeLBSSLRedirectExceptions is the list of (possibly wildcard) hosts for which requests are not redirected to the HTTPS frontend.
+	// It is only set when redirects are enabled.
+	EdgeLBSSLRedirectExceptions []string
+	// EdgeLBHSTSEnabled indicates whether the "Strict-Transport-Security" header should be added to responses served over HTTPS.
+	EdgeLBHSTSEnabled bool
+	// EdgeLBHSTSMaxAge is the value of the "max-age" directive of the "Strict-Transport-Security" header.
+	// It is only set when HSTS is enabled.
+	EdgeLBHSTSMaxAge time.Duration
+	// EdgeLBHSTSIncludeSubdomains indicates whether the "includeSubDomains" directive should be added to the "Strict-Transport-Security" header.
+	// It is only set when HSTS is enabled.
+	EdgeLBHSTSIncludeSubdomains bool
+	// EdgeLBSourceRanges is the list of source ranges (in CIDR notation) from which connections are allowed.
+	// An empty list means that connections are allowed from any source.
+	EdgeLBSourceRanges []string
 }
 
 // ComputeIngressTranslationOptions computes the set of options to use for "translating" the specified Ingress resource into an EdgeLB pool.
@@ -38,26 +93,197 @@ func ComputeIngressTranslationOptions(clusterName string, obj *extsv1beta1.Ingre
 		return nil, err
 	}
 	res.BaseTranslationOptions = *b
-	// Parse the port to use as a frontend bind port.
-	// TODO (@bcustodio) Split into HTTP/HTTPS port when TLS support is introduced.
-	if v, exists := annotations[constants.EdgeLBPoolPortAnnotationKey]; !exists || v == "" {
-		res.EdgeLBPoolPort = DefaultEdgeLBPoolPort
+	// Parse the port to use as the bind port for the HTTP frontend.
+	// In case the "edgelb-pool-http-port" annotation is not present, we fallback to the (deprecated) "edgelb-pool-port" annotation.
+	httpPort, exists := annotations[constants.EdgeLBPoolHTTPPortAnnotationKey]
+	if !exists || httpPort == "" {
+		httpPort = annotations[constants.EdgeLBPoolPortAnnotationKey]
+	}
+	if res.EdgeLBPoolHTTPPort, err = parseFrontendBindPort(httpPort, DefaultEdgeLBPoolHTTPPort, "http"); err != nil {
+		return nil, err
+	}
+	// Parse the port to use as the bind port for the HTTPS frontend.
+	if res.EdgeLBPoolHTTPSPort, err = parseFrontendBindPort(annotations[constants.EdgeLBPoolHTTPSPortAnnotationKey], DefaultEdgeLBPoolHTTPSPort, "https"); err != nil {
+		return nil, err
+	}
+	// Make sure that the HTTP and HTTPS frontends don't bind to the same port.
+	if res.EdgeLBPoolHTTPPort == res.EdgeLBPoolHTTPSPort {
+		return nil, fmt.Errorf("the http and https frontend bind ports must be different (got %d for both)", res.EdgeLBPoolHTTPPort)
+	}
+
+	// Parse the way in which paths must be matched.
+	if v, exists := annotations[constants.EdgeLBPathMatchTypeAnnotationKey]; !exists || v == "" {
+		res.EdgeLBPathMatchType = DefaultEdgeLBPathMatchType
 	} else {
-		r, err := strconv.Atoi(v)
+		switch v {
+		case string(constants.EdgeLBPathMatchTypeExact):
+			res.EdgeLBPathMatchType = constants.EdgeLBPathMatchTypeExact
+		case string(constants.EdgeLBPathMatchTypePrefix):
+			res.EdgeLBPathMatchType = constants.EdgeLBPathMatchTypePrefix
+		case string(constants.EdgeLBPathMatchTypeRegex):
+			res.EdgeLBPathMatchType = constants.EdgeLBPathMatchTypeRegex
+		default:
+			return nil, fmt.Errorf("failed to parse %q as a path match type", v)
+		}
+	}
+
+	// Parse the rewrite target(s), if specified.
+	// The value of the annotation is either a single rewrite target or a JSON object mapping paths to rewrite targets.
+	if v := annotations[constants.EdgeLBRewriteTargetAnnotationKey]; strings.HasPrefix(v, "{") {
+		targets := make(map[string]string)
+		if err := json.Unmarshal([]byte(v), &targets); err != nil {
+			return nil, fmt.Errorf("failed to parse %q as a mapping between paths and rewrite targets: %v", v, err)
+		}
+		for path, target := range targets {
+			if err := validateRewriteTarget(target); err != nil {
+				return nil, fmt.Errorf("failed to parse %q as the rewrite target for path %q: %v", target, path, err)
+			}
+		}
+		res.EdgeLBRewriteTargets = targets
+	} else if v != "" {
+		if err := validateRewriteTarget(v); err != nil {
+			return nil, fmt.Errorf("failed to parse %q as a rewrite target: %v", v, err)
+		}
+		res.EdgeLBRewriteTarget = v
+	}
+
+	// Parse whether cookie-based sticky sessions should be enabled and, if so, the name and maximum lifetime of the cookie.
+	if v, exists := annotations[constants.EdgeLBStickySessionsAnnotationKey]; exists && v != "" {
+		e, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %q as a boolean value: %v", v, err)
+		}
+		res.EdgeLBStickySessionsEnabled = e
+	}
+	if res.EdgeLBStickySessionsEnabled {
+		if v, exists := annotations[constants.EdgeLBStickySessionsCookieNameAnnotationKey]; !exists || v == "" {
+			res.EdgeLBStickySessionsCookieName = DefaultEdgeLBStickySessionsCookieName
+		} else {
+			if !stickySessionsCookieNameRegex.MatchString(v) {
+				return nil, fmt.Errorf("%q is not a valid cookie name", v)
+			}
+			res.EdgeLBStickySessionsCookieName = v
+		}
+		if v, exists := annotations[constants.EdgeLBStickySessionsCookieTTLAnnotationKey]; !exists || v == "" {
+			res.EdgeLBStickySessionsCookieTTL = DefaultEdgeLBStickySessionsCookieTTL
+		} else {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %q as a cookie ttl: %v", v, err)
+			}
+			// HAProxy expresses the maximum lifetime of a cookie in seconds.
+			if d < 0 || d%time.Second != 0 {
+				return nil, fmt.Errorf("%q is not a valid cookie ttl as it is not a non-negative whole number of seconds", v)
+			}
+			res.EdgeLBStickySessionsCookieTTL = d
+		}
+	}
+
+	// Parse whether requests made to the HTTP frontend should be redirected to the HTTPS frontend and, if so, the status code to use and the hosts for which requests should not be redirected.
+	if v, exists := annotations[constants.EdgeLBSSLRedirectAnnotationKey]; exists && v != "" {
+		e, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %q as a boolean value: %v", v, err)
+		}
+		res.EdgeLBSSLRedirectEnabled = e
+	}
+	if res.EdgeLBSSLRedirectEnabled {
+		if v, exists := annotations[constants.EdgeLBSSLRedirectCodeAnnotationKey]; !exists || v == "" {
+			res.EdgeLBSSLRedirectCode = DefaultEdgeLBSSLRedirectCode
+		} else {
+			r, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %q as a redirect code: %v", v, err)
+			}
+			if !sslRedirectCodes[r] {
+				return nil, fmt.Errorf("%d is not a supported redirect code", r)
+			}
+			res.EdgeLBSSLRedirectCode = r
+		}
+		if v := annotations[constants.EdgeLBSSLRedirectExceptionsAnnotationKey]; v != "" {
+			for _, host := range strings.Split(v, ",") {
+				host = strings.TrimSpace(host)
+				if errs := validation.IsDNS1123Subdomain(strings.TrimPrefix(host, wildcardHostPrefix)); len(errs) > 0 {
+					return nil, fmt.Errorf("%q is not a valid host: %s", host, strings.Join(errs, ", "))
+				}
+				res.EdgeLBSSLRedirectExceptions = append(res.EdgeLBSSLRedirectExceptions, host)
+			}
+		}
+	}
+
+	// Parse whether the "Strict-Transport-Security" header should be added to responses served over HTTPS and, if so, the values of its directives.
+	if v, exists := annotations[constants.EdgeLBHSTSAnnotationKey]; exists && v != "" {
+		e, err := strconv.ParseBool(v)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse %q as the frontend bind port to use: %v", v, err)
+			return nil, fmt.Errorf("failed to parse %q as a boolean value: %v", v, err)
+		}
+		res.EdgeLBHSTSEnabled = e
+	}
+	if res.EdgeLBHSTSEnabled {
+		if v, exists := annotations[constants.EdgeLBHSTSMaxAgeAnnotationKey]; !exists || v == "" {
+			res.EdgeLBHSTSMaxAge = DefaultEdgeLBHSTSMaxAge
+		} else {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %q as a hsts max-age: %v", v, err)
+			}
+			// The "max-age" directive is expressed in seconds.
+			if d < 0 || d%time.Second != 0 {
+				return nil, fmt.Errorf("%q is not a valid hsts max-age as it is not a non-negative whole number of seconds", v)
+			}
+			res.EdgeLBHSTSMaxAge = d
+		}
+		if v, exists := annotations[constants.EdgeLBHSTSIncludeSubdomainsAnnotationKey]; exists && v != "" {
+			e, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %q as a boolean value: %v", v, err)
+			}
+			res.EdgeLBHSTSIncludeSubdomains = e
 		}
-		if validation.IsValidPortNum(r) != nil {
-			return nil, fmt.Errorf("%d is not a valid port number", r)
+	}
+
+	// Parse the source ranges from which connections are allowed.
+	if v := annotations[constants.EdgeLBWhitelistSourceRangeAnnotationKey]; v != "" {
+		r, err := parseSourceRanges(strings.Split(v, ","))
+		if err != nil {
+			return nil, err
 		}
-		res.EdgeLBPoolPort = int32(r)
+		res.EdgeLBSourceRanges = r
 	}
 
 	// Return the computed set of options
 	return res, nil
 }
 
+// rewriteTargetForPath returns the rewrite target to use for the specified path, and a value indicating whether the path must be rewritten at all.
+// Rules that don't specify a path are never rewritten.
+func (o IngressTranslationOptions) rewriteTargetForPath(path string) (string, bool) {
+	if path == "" {
+		return "", false
+	}
+	if target, exists := o.EdgeLBRewriteTargets[path]; exists {
+		return target, true
+	}
+	return o.EdgeLBRewriteTarget, o.EdgeLBRewriteTarget != ""
+}
+
 // ValidateIngressTranslationOptionsUpdate validates the transition between "previousOptions" and "currentOptions".
 func ValidateIngressTranslationOptionsUpdate(previousOptions, currentOptions *IngressTranslationOptions) error {
 	return ValidateBaseTranslationOptionsUpdate(&previousOptions.BaseTranslationOptions, &currentOptions.BaseTranslationOptions)
 }
+
+// parseFrontendBindPort parses the specified value as a frontend bind port, returning "defaultValue" in case said value is empty.
+// "protocol" is used only to make returned error messages clearer.
+func parseFrontendBindPort(v string, defaultValue int32, protocol string) (int32, error) {
+	if v == "" {
+		return defaultValue, nil
+	}
+	r, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse %q as the %s frontend bind port to use: %v", v, protocol, err)
+	}
+	if validation.IsValidPortNum(r) != nil {
+		return 0, fmt.Errorf("%d is not a valid port number", r)
+	}
+	return int32(r), nil
+}
@@ -3,6 +3,7 @@ package translator_test
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -35,8 +36,13 @@ func TestComputeIngressTranslationOptions(t *testing.T) {
 					EdgeLBPoolMem:                  translator.DefaultEdgeLBPoolMem,
 					EdgeLBPoolSize:                 translator.DefaultEdgeLBPoolSize,
 					EdgeLBPoolCreationStrategy:     translator.DefaultEdgeLBPoolCreationStrategy,
+					EdgeLBPoolDriftPolicy:          translator.DefaultEdgeLBPoolDriftPolicy,
+					EdgeLBBackendBalance:           translator.DefaultEdgeLBBackendBalance,
+					EdgeLBBackendTarget:            translator.DefaultEdgeLBBackendTarget,
 				},
-				EdgeLBPoolPort: translator.DefaultEdgeLBPoolPort,
+				EdgeLBPoolHTTPPort:  translator.DefaultEdgeLBPoolHTTPPort,
+				EdgeLBPoolHTTPSPort: translator.DefaultEdgeLBPoolHTTPSPort,
+				EdgeLBPathMatchType: translator.DefaultEdgeLBPathMatchType,
 			},
 			error: nil,
 		},
@@ -57,15 +63,20 @@ func TestComputeIngressTranslationOptions(t *testing.T) {
 					EdgeLBPoolMem:                  translator.DefaultEdgeLBPoolMem,
 					EdgeLBPoolSize:                 translator.DefaultEdgeLBPoolSize,
 					EdgeLBPoolCreationStrategy:     translator.DefaultEdgeLBPoolCreationStrategy,
+					EdgeLBPoolDriftPolicy:          translator.DefaultEdgeLBPoolDriftPolicy,
+					EdgeLBBackendBalance:           translator.DefaultEdgeLBBackendBalance,
+					EdgeLBBackendTarget:            translator.DefaultEdgeLBBackendTarget,
 				},
-				EdgeLBPoolPort: translator.DefaultEdgeLBPoolPort,
+				EdgeLBPoolHTTPPort:  translator.DefaultEdgeLBPoolHTTPPort,
+				EdgeLBPoolHTTPSPort: translator.DefaultEdgeLBPoolHTTPSPort,
+				EdgeLBPathMatchType: translator.DefaultEdgeLBPathMatchType,
 			},
 			error: nil,
 		},
-		// Test computing options for an Ingress resource defining a custom frontend bind port.
-		// Make sure the frontend bind port is captured as expected, and that the default values are used everywhere else.
+		// Test computing options for an Ingress resource defining a custom frontend bind port using the deprecated annotation.
+		// Make sure the frontend bind port is captured as the HTTP frontend bind port, and that the default values are used everywhere else.
 		{
-			description: "compute options for an Ingress resource defining a custom frontend bind port",
+			description: "compute options for an Ingress resource defining a custom frontend bind port using the deprecated annotation",
 			annotations: map[string]string{
 				constants.EdgeLBPoolPortAnnotationKey: "14708",
 			},
@@ -79,11 +90,66 @@ func TestComputeIngressTranslationOptions(t *testing.T) {
 					EdgeLBPoolMem:                  translator.DefaultEdgeLBPoolMem,
 					EdgeLBPoolSize:                 translator.DefaultEdgeLBPoolSize,
 					EdgeLBPoolCreationStrategy:     translator.DefaultEdgeLBPoolCreationStrategy,
+					EdgeLBPoolDriftPolicy:          translator.DefaultEdgeLBPoolDriftPolicy,
+					EdgeLBBackendBalance:           translator.DefaultEdgeLBBackendBalance,
+					EdgeLBBackendTarget:            translator.DefaultEdgeLBBackendTarget,
 				},
-				EdgeLBPoolPort: 14708,
+				EdgeLBPoolHTTPPort:  14708,
+				EdgeLBPoolHTTPSPort: translator.DefaultEdgeLBPoolHTTPSPort,
+				EdgeLBPathMatchType: translator.DefaultEdgeLBPathMatchType,
 			},
 			error: nil,
 		},
+		// Test computing options for an Ingress resource defining custom HTTP and HTTPS frontend bind ports, as well as the deprecated annotation.
+		// Make sure the HTTP and HTTPS frontend bind ports are captured as expected, and that the deprecated annotation is ignored.
+		{
+			description: "compute options for an Ingress resource defining custom http and https frontend bind ports",
+			annotations: map[string]string{
+				constants.EdgeLBPoolPortAnnotationKey:      "14708",
+				constants.EdgeLBPoolHTTPPortAnnotationKey:  "8080",
+				constants.EdgeLBPoolHTTPSPortAnnotationKey: "8443",
+			},
+			options: &translator.IngressTranslationOptions{
+				BaseTranslationOptions: translator.BaseTranslationOptions{
+					CloudLoadBalancerConfigMapName: nil,
+					EdgeLBPoolName:                 "dev--kubernetes01--foo--bar",
+					EdgeLBPoolRole:                 translator.DefaultEdgeLBPoolRole,
+					EdgeLBPoolNetwork:              constants.EdgeLBHostNetwork,
+					EdgeLBPoolCpus:                 translator.DefaultEdgeLBPoolCpus,
+					EdgeLBPoolMem:                  translator.DefaultEdgeLBPoolMem,
+					EdgeLBPoolSize:                 translator.DefaultEdgeLBPoolSize,
+					EdgeLBPoolCreationStrategy:     translator.DefaultEdgeLBPoolCreationStrategy,
+					EdgeLBPoolDriftPolicy:          translator.DefaultEdgeLBPoolDriftPolicy,
+					EdgeLBBackendBalance:           translator.DefaultEdgeLBBackendBalance,
+					EdgeLBBackendTarget:            translator.DefaultEdgeLBBackendTarget,
+				},
+				EdgeLBPoolHTTPPort:  8080,
+				EdgeLBPoolHTTPSPort: 8443,
+				EdgeLBPathMatchType: translator.DefaultEdgeLBPathMatchType,
+			},
+			error: nil,
+		},
+		// Test computing options for an Ingress resource defining the same value for the HTTP and HTTPS frontend bind ports.
+		// Make sure an error is returned.
+		{
+			description: "compute options for an Ingress resource defining the same value for the http and https frontend bind ports",
+			annotations: map[string]string{
+				constants.EdgeLBPoolHTTPPortAnnotationKey:  "8080",
+				constants.EdgeLBPoolHTTPSPortAnnotationKey: "8080",
+			},
+			options: nil,
+			error:   fmt.Errorf("the http and https frontend bind ports must be different (got %d for both)", 8080),
+		},
+		// Test computing options for an Ingress resource defining an invalid value for the HTTPS frontend bind port.
+		// Make sure an error is returned.
+		{
+			description: "compute options for an Ingress resource defining an invalid value for the https frontend bind port",
+			annotations: map[string]string{
+				constants.EdgeLBPoolHTTPSPortAnnotationKey: "foo",
+			},
+			options: nil,
+			error:   fmt.Errorf("failed to parse %q as the https frontend bind port to use: %v", "foo", "strconv.Atoi: parsing \"foo\": invalid syntax"),
+		},
 		// Test computing options for an Ingress resource defining an invalid value for the frontend bind port.
 		// Make sure an error is returned.
 		{
@@ -94,20 +160,288 @@ func TestComputeIngressTranslationOptions(t *testing.T) {
 			options: nil,
 			error:   fmt.Errorf("%d is not a valid port number", 74511),
 		},
+		// Test computing options for an Ingress resource defining an invalid path match type.
+		// Make sure an error is returned.
+		{
+			description: "compute options for an Ingress resource defining an invalid path match type",
+			annotations: map[string]string{
+				constants.EdgeLBPathMatchTypeAnnotationKey: "foo",
+			},
+			options: nil,
+			error:   fmt.Errorf("failed to parse %q as a path match type", "foo"),
+		},
+		// Test computing options for an Ingress resource defining an invalid load-balancing algorithm.
+		// Make sure an error is returned.
+		{
+			description: "compute options for an Ingress resource defining an invalid load-balancing algorithm",
+			annotations: map[string]string{
+				constants.EdgeLBBackendBalanceAnnotationKey: "hdr(X User)",
+			},
+			options: nil,
+			error:   fmt.Errorf("failed to parse %q as a load-balancing algorithm", "hdr(X User)"),
+		},
+		// Test computing options for an Ingress resource enabling sticky sessions without customizing the cookie.
+		// Make sure the default cookie name and maximum lifetime are used.
+		{
+			description: "compute options for an Ingress resource enabling sticky sessions without customizing the cookie",
+			annotations: map[string]string{
+				constants.EdgeLBStickySessionsAnnotationKey: "true",
+			},
+			options: &translator.IngressTranslationOptions{
+				BaseTranslationOptions: translator.BaseTranslationOptions{
+					CloudLoadBalancerConfigMapName: nil,
+					EdgeLBPoolName:                 "dev--kubernetes01--foo--bar",
+					EdgeLBPoolRole:                 translator.DefaultEdgeLBPoolRole,
+					EdgeLBPoolNetwork:              constants.EdgeLBHostNetwork,
+					EdgeLBPoolCpus:                 translator.DefaultEdgeLBPoolCpus,
+					EdgeLBPoolMem:                  translator.DefaultEdgeLBPoolMem,
+					EdgeLBPoolSize:                 translator.DefaultEdgeLBPoolSize,
+					EdgeLBPoolCreationStrategy:     translator.DefaultEdgeLBPoolCreationStrategy,
+					EdgeLBPoolDriftPolicy:          translator.DefaultEdgeLBPoolDriftPolicy,
+					EdgeLBBackendBalance:           translator.DefaultEdgeLBBackendBalance,
+					EdgeLBBackendTarget:            translator.DefaultEdgeLBBackendTarget,
+				},
+				EdgeLBPoolHTTPPort:             translator.DefaultEdgeLBPoolHTTPPort,
+				EdgeLBPoolHTTPSPort:            translator.DefaultEdgeLBPoolHTTPSPort,
+				EdgeLBPathMatchType:            translator.DefaultEdgeLBPathMatchType,
+				EdgeLBStickySessionsEnabled:    true,
+				EdgeLBStickySessionsCookieName: translator.DefaultEdgeLBStickySessionsCookieName,
+				EdgeLBStickySessionsCookieTTL:  translator.DefaultEdgeLBStickySessionsCookieTTL,
+			},
+			error: nil,
+		},
+		// Test computing options for an Ingress resource defining an invalid cookie name for sticky sessions.
+		// Make sure an error is returned.
+		{
+			description: "compute options for an Ingress resource defining an invalid cookie name for sticky sessions",
+			annotations: map[string]string{
+				constants.EdgeLBStickySessionsAnnotationKey:           "true",
+				constants.EdgeLBStickySessionsCookieNameAnnotationKey: "foo;bar",
+			},
+			options: nil,
+			error:   fmt.Errorf("%q is not a valid cookie name", "foo;bar"),
+		},
+		// Test computing options for an Ingress resource defining an invalid cookie ttl for sticky sessions.
+		// Make sure an error is returned.
+		{
+			description: "compute options for an Ingress resource defining an invalid cookie ttl for sticky sessions",
+			annotations: map[string]string{
+				constants.EdgeLBStickySessionsAnnotationKey:          "true",
+				constants.EdgeLBStickySessionsCookieTTLAnnotationKey: "1500ms",
+			},
+			options: nil,
+			error:   fmt.Errorf("%q is not a valid cookie ttl as it is not a non-negative whole number of seconds", "1500ms"),
+		},
+		// Test computing options for an Ingress resource defining a rewrite target for all paths.
+		// Make sure the rewrite target is captured as expected.
+		{
+			description: "compute options for an Ingress resource defining a rewrite target for all paths",
+			annotations: map[string]string{
+				constants.EdgeLBRewriteTargetAnnotationKey: "/",
+			},
+			options: &translator.IngressTranslationOptions{
+				BaseTranslationOptions: translator.BaseTranslationOptions{
+					CloudLoadBalancerConfigMapName: nil,
+					EdgeLBPoolName:                 "dev--kubernetes01--foo--bar",
+					EdgeLBPoolRole:                 translator.DefaultEdgeLBPoolRole,
+					EdgeLBPoolNetwork:              constants.EdgeLBHostNetwork,
+					EdgeLBPoolCpus:                 translator.DefaultEdgeLBPoolCpus,
+					EdgeLBPoolMem:                  translator.DefaultEdgeLBPoolMem,
+					EdgeLBPoolSize:                 translator.DefaultEdgeLBPoolSize,
+					EdgeLBPoolCreationStrategy:     translator.DefaultEdgeLBPoolCreationStrategy,
+					EdgeLBPoolDriftPolicy:          translator.DefaultEdgeLBPoolDriftPolicy,
+					EdgeLBBackendBalance:           translator.DefaultEdgeLBBackendBalance,
+					EdgeLBBackendTarget:            translator.DefaultEdgeLBBackendTarget,
+				},
+				EdgeLBPoolHTTPPort:  translator.DefaultEdgeLBPoolHTTPPort,
+				EdgeLBPoolHTTPSPort: translator.DefaultEdgeLBPoolHTTPSPort,
+				EdgeLBPathMatchType: translator.DefaultEdgeLBPathMatchType,
+				EdgeLBRewriteTarget: "/",
+			},
+			error: nil,
+		},
+		// Test computing options for an Ingress resource defining a mapping between paths and rewrite targets.
+		// Make sure the mapping is captured as expected.
+		{
+			description: "compute options for an Ingress resource defining a mapping between paths and rewrite targets",
+			annotations: map[string]string{
+				constants.EdgeLBRewriteTargetAnnotationKey: `{"/foo": "/", "/bar/(.*)": "/baz/\\1"}`,
+			},
+			options: &translator.IngressTranslationOptions{
+				BaseTranslationOptions: translator.BaseTranslationOptions{
+					CloudLoadBalancerConfigMapName: nil,
+					EdgeLBPoolName:                 "dev--kubernetes01--foo--bar",
+					EdgeLBPoolRole:                 translator.DefaultEdgeLBPoolRole,
+					EdgeLBPoolNetwork:              constants.EdgeLBHostNetwork,
+					EdgeLBPoolCpus:                 translator.DefaultEdgeLBPoolCpus,
+					EdgeLBPoolMem:                  translator.DefaultEdgeLBPoolMem,
+					EdgeLBPoolSize:                 translator.DefaultEdgeLBPoolSize,
+					EdgeLBPoolCreationStrategy:     translator.DefaultEdgeLBPoolCreationStrategy,
+					EdgeLBPoolDriftPolicy:          translator.DefaultEdgeLBPoolDriftPolicy,
+					EdgeLBBackendBalance:           translator.DefaultEdgeLBBackendBalance,
+					EdgeLBBackendTarget:            translator.DefaultEdgeLBBackendTarget,
+				},
+				EdgeLBPoolHTTPPort:  translator.DefaultEdgeLBPoolHTTPPort,
+				EdgeLBPoolHTTPSPort: translator.DefaultEdgeLBPoolHTTPSPort,
+				EdgeLBPathMatchType: translator.DefaultEdgeLBPathMatchType,
+				EdgeLBRewriteTargets: map[string]string{
+					"/foo":      "/",
+					"/bar/(.*)": "/baz/\\1",
+				},
+			},
+			error: nil,
+		},
+		// Test computing options for an Ingress resource defining an invalid rewrite target.
+		// Make sure an error is returned.
+		{
+			description: "compute options for an Ingress resource defining an invalid rewrite target",
+			annotations: map[string]string{
+				constants.EdgeLBRewriteTargetAnnotationKey: "foo",
+			},
+			options: nil,
+			error:   fmt.Errorf("failed to parse %q as a rewrite target: %v", "foo", fmt.Errorf("rewrite target %q must start with %q", "foo", "/")),
+		},
+		// Test computing options for an Ingress resource enabling redirects to https and hsts without customizing them.
+		// Make sure the default redirect code and hsts max-age are used.
+		{
+			description: "compute options for an Ingress resource enabling redirects to https and hsts without customizing them",
+			annotations: map[string]string{
+				constants.EdgeLBSSLRedirectAnnotationKey: "true",
+				constants.EdgeLBHSTSAnnotationKey:        "true",
+			},
+			options: &translator.IngressTranslationOptions{
+				BaseTranslationOptions: translator.BaseTranslationOptions{
+					CloudLoadBalancerConfigMapName: nil,
+					EdgeLBPoolName:                 "dev--kubernetes01--foo--bar",
+					EdgeLBPoolRole:                 translator.DefaultEdgeLBPoolRole,
+					EdgeLBPoolNetwork:              constants.EdgeLBHostNetwork,
+					EdgeLBPoolCpus:                 translator.DefaultEdgeLBPoolCpus,
+					EdgeLBPoolMem:                  translator.DefaultEdgeLBPoolMem,
+					EdgeLBPoolSize:                 translator.DefaultEdgeLBPoolSize,
+					EdgeLBPoolCreationStrategy:     translator.DefaultEdgeLBPoolCreationStrategy,
+					EdgeLBPoolDriftPolicy:          translator.DefaultEdgeLBPoolDriftPolicy,
+					EdgeLBBackendBalance:           translator.DefaultEdgeLBBackendBalance,
+					EdgeLBBackendTarget:            translator.DefaultEdgeLBBackendTarget,
+				},
+				EdgeLBPoolHTTPPort:       translator.DefaultEdgeLBPoolHTTPPort,
+				EdgeLBPoolHTTPSPort:      translator.DefaultEdgeLBPoolHTTPSPort,
+				EdgeLBPathMatchType:      translator.DefaultEdgeLBPathMatchType,
+				EdgeLBSSLRedirectEnabled: true,
+				EdgeLBSSLRedirectCode:    translator.DefaultEdgeLBSSLRedirectCode,
+				EdgeLBHSTSEnabled:        true,
+				EdgeLBHSTSMaxAge:         translator.DefaultEdgeLBHSTSMaxAge,
+			},
+			error: nil,
+		},
+		// Test computing options for an Ingress resource customizing redirects to https and hsts.
+		// Make sure that all values are adequately captured.
+		{
+			description: "compute options for an Ingress resource customizing redirects to https and hsts",
+			annotations: map[string]string{
+				constants.EdgeLBSSLRedirectAnnotationKey:           "true",
+				constants.EdgeLBSSLRedirectCodeAnnotationKey:       "308",
+				constants.EdgeLBSSLRedirectExceptionsAnnotationKey: "foo.example.com, *.apps.example.com",
+				constants.EdgeLBHSTSAnnotationKey:                  "true",
+				constants.EdgeLBHSTSMaxAgeAnnotationKey:            "1h",
+				constants.EdgeLBHSTSIncludeSubdomainsAnnotationKey: "true",
+			},
+			options: &translator.IngressTranslationOptions{
+				BaseTranslationOptions: translator.BaseTranslationOptions{
+					CloudLoadBalancerConfigMapName: nil,
+					EdgeLBPoolName:                 "dev--kubernetes01--foo--bar",
+					EdgeLBPoolRole:                 translator.DefaultEdgeLBPoolRole,
+					EdgeLBPoolNetwork:              constants.EdgeLBHostNetwork,
+					EdgeLBPoolCpus:                 translator.DefaultEdgeLBPoolCpus,
+					EdgeLBPoolMem:                  translator.DefaultEdgeLBPoolMem,
+					EdgeLBPoolSize:                 translator.DefaultEdgeLBPoolSize,
+					EdgeLBPoolCreationStrategy:     translator.DefaultEdgeLBPoolCreationStrategy,
+					EdgeLBPoolDriftPolicy:          translator.DefaultEdgeLBPoolDriftPolicy,
+					EdgeLBBackendBalance:           translator.DefaultEdgeLBBackendBalance,
+					EdgeLBBackendTarget:            translator.DefaultEdgeLBBackendTarget,
+				},
+				EdgeLBPoolHTTPPort:          translator.DefaultEdgeLBPoolHTTPPort,
+				EdgeLBPoolHTTPSPort:         translator.DefaultEdgeLBPoolHTTPSPort,
+				EdgeLBPathMatchType:         translator.DefaultEdgeLBPathMatchType,
+				EdgeLBSSLRedirectEnabled:    true,
+				EdgeLBSSLRedirectCode:       308,
+				EdgeLBSSLRedirectExceptions: []string{"foo.example.com", "*.apps.example.com"},
+				EdgeLBHSTSEnabled:           true,
+				EdgeLBHSTSMaxAge:            time.Hour,
+				EdgeLBHSTSIncludeSubdomains: true,
+			},
+			error: nil,
+		},
+		// Test computing options for an Ingress resource defining an unsupported redirect code.
+		// Make sure an error is returned.
+		{
+			description: "compute options for an Ingress resource defining an unsupported redirect code",
+			annotations: map[string]string{
+				constants.EdgeLBSSLRedirectAnnotationKey:     "true",
+				constants.EdgeLBSSLRedirectCodeAnnotationKey: "200",
+			},
+			options: nil,
+			error:   fmt.Errorf("%d is not a supported redirect code", 200),
+		},
+		// Test computing options for an Ingress resource defining an invalid hsts max-age.
+		// Make sure an error is returned.
+		{
+			description: "compute options for an Ingress resource defining an invalid hsts max-age",
+			annotations: map[string]string{
+				constants.EdgeLBHSTSAnnotationKey:       "true",
+				constants.EdgeLBHSTSMaxAgeAnnotationKey: "-1s",
+			},
+			options: nil,
+			error:   fmt.Errorf("%q is not a valid hsts max-age as it is not a non-negative whole number of seconds", "-1s"),
+		},
+		// Test computing options for an Ingress resource defining source ranges from which connections are allowed.
+		// Make sure the source ranges are captured as expected.
+		{
+			description: "compute options for an Ingress resource defining source ranges from which connections are allowed",
+			annotations: map[string]string{
+				constants.EdgeLBWhitelistSourceRangeAnnotationKey: "10.0.0.0/8, 192.168.0.0/16",
+			},
+			options: &translator.IngressTranslationOptions{
+				BaseTranslationOptions: translator.BaseTranslationOptions{
+					CloudLoadBalancerConfigMapName: nil,
+					EdgeLBPoolName:                 "dev--kubernetes01--foo--bar",
+					EdgeLBPoolRole:                 translator.DefaultEdgeLBPoolRole,
+					EdgeLBPoolNetwork:              constants.EdgeLBHostNetwork,
+					EdgeLBPoolCpus:                 translator.DefaultEdgeLBPoolCpus,
+					EdgeLBPoolMem:                  translator.DefaultEdgeLBPoolMem,
+					EdgeLBPoolSize:                 translator.DefaultEdgeLBPoolSize,
+					EdgeLBPoolCreationStrategy:     translator.DefaultEdgeLBPoolCreationStrategy,
+					EdgeLBPoolDriftPolicy:          translator.DefaultEdgeLBPoolDriftPolicy,
+					EdgeLBBackendBalance:           translator.DefaultEdgeLBBackendBalance,
+					EdgeLBBackendTarget:            translator.DefaultEdgeLBBackendTarget,
+				},
+				EdgeLBPoolHTTPPort:  translator.DefaultEdgeLBPoolHTTPPort,
+				EdgeLBPoolHTTPSPort: translator.DefaultEdgeLBPoolHTTPSPort,
+				EdgeLBPathMatchType: translator.DefaultEdgeLBPathMatchType,
+				EdgeLBSourceRanges:  []string{"10.0.0.0/8", "192.168.0.0/16"},
+			},
+			error: nil,
+		},
 		// Test computing options for an Ingress resource defining custom values for all the options (except cloud load-balancer configuration).
 		// Make sure that all values are adequately captured.
 		{
 			description: "compute options for an Ingress resource defining custom values for all the options (except cloud load-balancer configuration)",
 			annotations: map[string]string{
-				constants.EdgeLBPoolNameAnnotationKey:             "foo",
-				constants.EdgeLBPoolRoleAnnotationKey:             "custom_role",
-				constants.EdgeLBPoolNetworkAnnotationKey:          "foo_network",
-				constants.EdgeLBPoolCpusAnnotationKey:             "250m",
-				constants.EdgeLBPoolMemAnnotationKey:              "2Gi",
-				constants.EdgeLBPoolSizeAnnotationKey:             "3",
-				constants.EdgeLBPoolCreationStrategyAnnotationKey: string(constants.EdgeLBPoolCreationStrategyOnce),
-				constants.EdgeLBPoolPortAnnotationKey:             "14708",
-				constants.EdgeLBPoolTranslationPaused:             "1",
+				constants.EdgeLBPoolNameAnnotationKey:                 "foo",
+				constants.EdgeLBPoolRoleAnnotationKey:                 "custom_role",
+				constants.EdgeLBPoolNetworkAnnotationKey:              "foo_network",
+				constants.EdgeLBPoolCpusAnnotationKey:                 "250m",
+				constants.EdgeLBPoolMemAnnotationKey:                  "2Gi",
+				constants.EdgeLBPoolSizeAnnotationKey:                 "3",
+				constants.EdgeLBPoolCreationStrategyAnnotationKey:     string(constants.EdgeLBPoolCreationStrategyOnce),
+				constants.EdgeLBPoolHTTPPortAnnotationKey:             "14708",
+				constants.EdgeLBPoolHTTPSPortAnnotationKey:            "14709",
+				constants.EdgeLBPoolTranslationPaused:                 "1",
+				constants.EdgeLBPathMatchTypeAnnotationKey:            string(constants.EdgeLBPathMatchTypePrefix),
+				constants.EdgeLBBackendBalanceAnnotationKey:           "hdr(X-User-ID)",
+				constants.EdgeLBBackendTargetAnnotationKey:            string(constants.EdgeLBBackendTargetPodIP),
+				constants.EdgeLBStickySessionsAnnotationKey:           "true",
+				constants.EdgeLBStickySessionsCookieNameAnnotationKey: "route",
+				constants.EdgeLBStickySessionsCookieTTLAnnotationKey:  "1h",
 			},
 			options: &translator.IngressTranslationOptions{
 				BaseTranslationOptions: translator.BaseTranslationOptions{
@@ -119,9 +453,17 @@ func TestComputeIngressTranslationOptions(t *testing.T) {
 					EdgeLBPoolMem:                  resource.MustParse("2Gi"),
 					EdgeLBPoolSize:                 3,
 					EdgeLBPoolCreationStrategy:     constants.EdgeLBPoolCreationStrategyOnce,
+					EdgeLBPoolDriftPolicy:          translator.DefaultEdgeLBPoolDriftPolicy,
 					EdgeLBPoolTranslationPaused:    true,
+					EdgeLBBackendBalance:           "hdr(X-User-ID)",
+					EdgeLBBackendTarget:            constants.EdgeLBBackendTargetPodIP,
 				},
-				EdgeLBPoolPort: 14708,
+				EdgeLBPoolHTTPPort:             14708,
+				EdgeLBPoolHTTPSPort:            14709,
+				EdgeLBPathMatchType:            constants.EdgeLBPathMatchTypePrefix,
+				EdgeLBStickySessionsEnabled:    true,
+				EdgeLBStickySessionsCookieName: "route",
+				EdgeLBStickySessionsCookieTTL:  time.Hour,
 			},
 			error: nil,
 		},
@@ -143,7 +485,7 @@ func TestComputeIngressTranslationOptions(t *testing.T) {
 				constants.EdgeLBPoolPortAnnotationKey: "foo",
 			},
 			options: nil,
-			error:   fmt.Errorf("failed to parse %q as the frontend bind port to use: %v", "foo", "strconv.Atoi: parsing \"foo\": invalid syntax"),
+			error:   fmt.Errorf("failed to parse %q as the http frontend bind port to use: %v", "foo", "strconv.Atoi: parsing \"foo\": invalid syntax"),
 		},
 	}
 	// Run each of the tests defined above.
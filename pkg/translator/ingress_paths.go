@@ -0,0 +1,568 @@
+package translator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	extsv1beta1 "k8s.io/api/extensions/v1beta1"
+
+	"github.com/mesosphere/dklb/pkg/constants"
+	kubernetesutil "github.com/mesosphere/dklb/pkg/util/kubernetes"
+)
+
+const (
+	// edgeLBPathExactFormatString is the format string used to compute the regular expression used by EdgeLB to exactly match a given (escaped) path.
+	edgeLBPathExactFormatString = "^%s$"
+	// edgeLBPathPrefixFormatString is the format string used to compute the regular expression used by EdgeLB to match a given (escaped) path prefix.
+	// The path prefix is matched element-wise (i.e. "/foo" matches "/foo" and "/foo/bar", but not "/foobar").
+	edgeLBPathPrefixFormatString = "^%s(/.*)?$"
+	// edgeLBPathRegexFormatString is the format string used to compute the regular expression used by EdgeLB to match a given (translated) regular expression.
+	// The translated regular expression is grouped so that alternations are anchored as a whole.
+	edgeLBPathRegexFormatString = "^(%s)$"
+	// ereMaxRepetitions is the maximum number of repetitions allowed in an interval expression (i.e. the value of "RE_DUP_MAX").
+	ereMaxRepetitions = 255
+	// edgeLBRewriteSearchFormatString is the format string used to compute the regular expression used by HAProxy to match the request line of requests whose path must be rewritten.
+	// The method is captured by the first group and the remainder of the request line (i.e. the query string and the protocol version) by the last one.
+	edgeLBRewriteSearchFormatString = "^([^\\ ]+\\ )%s([?\\ ].*)$"
+	// edgeLBRewriteReplaceFormatString is the format string used to compute the replacement for the request line of requests whose path must be rewritten.
+	// It must be provided with the target path and the index of the capture group holding the remainder of the request line.
+	edgeLBRewriteReplaceFormatString = "\\1%s\\%d"
+	// edgeLBRewritePathCharacterRegex is the regular expression matching any single character of the path in the request line of a request.
+	// It is used instead of "." when rewriting paths, so that the path cannot be matched past the beginning of the query string or of the protocol version.
+	edgeLBRewritePathCharacterRegex = "[^?\\ ]"
+	// edgeLBRewriteMaxGroup is the index of the last capture group that can be referenced in the replacement string used by HAProxy's "reqrep" directive.
+	edgeLBRewriteMaxGroup = 9
+)
+
+var (
+	// ereCharacterClasses is the set of character classes that can be used in bracket expressions.
+	// These are supported by both egrep and PCRE, and can hence be used as-is.
+	ereCharacterClasses = map[string]bool{
+		"alnum":  true,
+		"alpha":  true,
+		"blank":  true,
+		"cntrl":  true,
+		"digit":  true,
+		"graph":  true,
+		"lower":  true,
+		"print":  true,
+		"punct":  true,
+		"space":  true,
+		"upper":  true,
+		"xdigit": true,
+	}
+	// ereSpecialCharacters is the set of characters that have special meaning in egrep and that can be escaped with a backslash in order to be matched literally.
+	ereSpecialCharacters = ".[]\\()*+?{}|^$"
+)
+
+// ValidateIngressPaths checks whether all the paths defined in the specified Ingress resource can be translated into regular expressions understood by EdgeLB using the specified match type.
+// The ".pathType" field of paths defined in "networking.k8s.io/v1" Ingress resources takes precedence over the specified match type (see "computeIngressPathMatchType").
+func ValidateIngressPaths(ingress *extsv1beta1.Ingress, matchType constants.EdgeLBPathMatchType) error {
+	var err error
+	kubernetesutil.ForEachIngresBackend(ingress, func(host, path *string, _ extsv1beta1.IngressBackend) {
+		if err != nil || path == nil || *path == "" {
+			return
+		}
+		if _, pathErr := computeEdgeLBPathRegex(*path, computeIngressPathMatchType(ingress, *host, *path, matchType)); pathErr != nil {
+			err = fmt.Errorf("path %q for host %q is not valid: %v", *path, *host, pathErr)
+		}
+	})
+	return err
+}
+
+// ValidateIngressRewriteTargets checks whether all the paths defined in the specified Ingress resource can be rewritten as requested by the specified options.
+// It also checks that rewrite targets are only specified for paths that are actually defined in the Ingress resource.
+func ValidateIngressRewriteTargets(ingress *extsv1beta1.Ingress, options IngressTranslationOptions) error {
+	var err error
+	// definedPaths holds the set of paths defined in the Ingress resource.
+	definedPaths := make(map[string]bool)
+	kubernetesutil.ForEachIngresBackend(ingress, func(host, path *string, _ extsv1beta1.IngressBackend) {
+		if err != nil || path == nil || *path == "" {
+			return
+		}
+		definedPaths[*path] = true
+		target, rewrite := options.rewriteTargetForPath(*path)
+		if !rewrite {
+			return
+		}
+		if _, _, rewriteErr := computeEdgeLBPathRewrite(*path, computeIngressPathMatchType(ingress, *host, *path, options.EdgeLBPathMatchType), target); rewriteErr != nil {
+			err = fmt.Errorf("path %q for host %q cannot be rewritten to %q: %v", *path, *host, target, rewriteErr)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	for path := range options.EdgeLBRewriteTargets {
+		if !definedPaths[path] {
+			return fmt.Errorf("a rewrite target has been specified for path %q, which is not defined", path)
+		}
+	}
+	return nil
+}
+
+// computeIngressPathMatchType returns the way in which the specified path defined for the specified host in the specified Ingress resource must be matched against the paths of incoming requests.
+// Paths defined in "networking.k8s.io/v1" Ingress resources whose ".pathType" field is "Exact" or "Prefix" are matched accordingly.
+// All other paths (i.e. paths whose ".pathType" field is "ImplementationSpecific" and paths defined in "extensions/v1beta1" Ingress resources) are matched using the specified match type.
+func computeIngressPathMatchType(ingress *extsv1beta1.Ingress, host, path string, matchType constants.EdgeLBPathMatchType) constants.EdgeLBPathMatchType {
+	switch kubernetesutil.GetIngressPathType(ingress, host, path) {
+	case string(constants.EdgeLBPathMatchTypeExact):
+		return constants.EdgeLBPathMatchTypeExact
+	case string(constants.EdgeLBPathMatchTypePrefix):
+		return constants.EdgeLBPathMatchTypePrefix
+	default:
+		return matchType
+	}
+}
+
+// computeEdgeLBPathRegex computes the (PCRE) regular expression used by EdgeLB to match the specified path using the specified match type.
+func computeEdgeLBPathRegex(path string, matchType constants.EdgeLBPathMatchType) (string, error) {
+	switch matchType {
+	case constants.EdgeLBPathMatchTypeExact:
+		r, err := escapePathForPCRE(path)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf(edgeLBPathExactFormatString, r), nil
+	case constants.EdgeLBPathMatchTypePrefix:
+		// Trailing slashes are not relevant when matching path prefixes element-wise.
+		// The "/" prefix hence results in an empty string, which causes all paths to be matched.
+		r, err := escapePathForPCRE(strings.TrimRight(path, "/"))
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf(edgeLBPathPrefixFormatString, r), nil
+	// An empty match type is treated as the default one.
+	case constants.EdgeLBPathMatchTypeRegex, "":
+		r, err := translateEREToPCRE(path, false)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf(edgeLBPathRegexFormatString, r), nil
+	default:
+		return "", fmt.Errorf("unsupported path match type %q", matchType)
+	}
+}
+
+// parseEdgeLBPathRegex recovers the path and path match type from which the specified regular expression was computed by "computeEdgeLBPathRegex".
+// The regular expression used to catch all requests results in an empty path, and the path recovered for the regex match type is the translated regular expression.
+// In case the regular expression was not computed by "computeEdgeLBPathRegex" (e.g. because it was set by a JSON merge patch), it is returned as a regular expression.
+func parseEdgeLBPathRegex(r string) (string, constants.EdgeLBPathMatchType) {
+	if r == "" || r == edgeLBPathCatchAllRegex {
+		return "", ""
+	}
+	if path, ok := parseFormatString(r, edgeLBPathRegexFormatString); ok {
+		return path, constants.EdgeLBPathMatchTypeRegex
+	}
+	if path, ok := parseFormatString(r, edgeLBPathPrefixFormatString); ok {
+		// Trailing slashes are trimmed when computing the regular expression, so the "/" prefix must be recovered from an empty one.
+		if path == "" {
+			return "/", constants.EdgeLBPathMatchTypePrefix
+		}
+		return unescapeRegex(path), constants.EdgeLBPathMatchTypePrefix
+	}
+	if path, ok := parseFormatString(r, edgeLBPathExactFormatString); ok {
+		return unescapeRegex(path), constants.EdgeLBPathMatchTypeExact
+	}
+	return r, constants.EdgeLBPathMatchTypeRegex
+}
+
+// parseFormatString returns the value that was used for the (single) "%s" verb in the specified format string in order to compute "s", as well as a value indicating whether "s" was computed from the format string at all.
+func parseFormatString(s, format string) (string, bool) {
+	parts := strings.SplitN(format, "%s", 2)
+	if len(parts) != 2 || len(s) < len(parts[0])+len(parts[1]) || !strings.HasPrefix(s, parts[0]) || !strings.HasSuffix(s, parts[1]) {
+		return "", false
+	}
+	return s[len(parts[0]) : len(s)-len(parts[1])], true
+}
+
+// unescapeRegex reverts the escaping performed by "escapePathForPCRE" (or by "regexp.QuoteMeta") on the specified string by removing the backslash preceding each escaped character.
+func unescapeRegex(s string) string {
+	var b strings.Builder
+	escaped := false
+	for _, c := range s {
+		if c == '\\' && !escaped {
+			escaped = true
+			continue
+		}
+		escaped = false
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// escapePathForPCRE escapes the specified path so that it is matched literally by a PCRE regular expression.
+func escapePathForPCRE(path string) (string, error) {
+	var b strings.Builder
+	for _, c := range path {
+		if err := validatePathCharacter(c); err != nil {
+			return "", err
+		}
+		if strings.ContainsRune(ereSpecialCharacters, c) || isHAProxyConfigCharacter(c) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(c)
+	}
+	return b.String(), nil
+}
+
+// translateEREToPCRE translates the specified POSIX extended regular expression (i.e. the syntax used by egrep, as mandated by the Ingress spec) into an equivalent PCRE regular expression.
+// Constructs whose behaviour is undefined in egrep and that would have a different meaning in PCRE (such as "\d", "(?:...)" or lazy quantifiers) are rejected.
+// Characters that are matched literally in egrep but have special meaning in PCRE (such as backslashes inside bracket expressions) are escaped.
+// If "withinRequestLine" is true, the resulting regular expression is meant to match the path in the request line of a request, and "." and bracket expressions are made to never match the characters that end the path (i.e. "?" and " ").
+func translateEREToPCRE(ere string, withinRequestLine bool) (string, error) {
+	var (
+		b     strings.Builder
+		runes = []rune(ere)
+		// depth is the current number of open groups.
+		depth = 0
+		// canQuantify indicates whether the previous token can be followed by a quantifier.
+		canQuantify = false
+	)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		if err := validatePathCharacter(c); err != nil {
+			return "", err
+		}
+		switch c {
+		case '\\':
+			// Only escaped special characters are allowed, as the behaviour of any other escape sequence is undefined in egrep.
+			if i+1 >= len(runes) {
+				return "", fmt.Errorf("trailing backslash")
+			}
+			i++
+			if !strings.ContainsRune(ereSpecialCharacters, runes[i]) {
+				return "", fmt.Errorf("escape sequence %q is not supported", "\\"+string(runes[i]))
+			}
+			b.WriteRune('\\')
+			b.WriteRune(runes[i])
+			canQuantify = true
+		case '[':
+			r, n, err := translateEREBracketExpression(runes[i:], withinRequestLine)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(r)
+			i += n - 1
+			canQuantify = true
+		case '(':
+			if i+1 < len(runes) && runes[i+1] == '?' {
+				return "", fmt.Errorf("group modifiers are not supported")
+			}
+			depth++
+			b.WriteRune(c)
+			canQuantify = false
+		case ')':
+			if depth == 0 {
+				return "", fmt.Errorf("unmatched %q", ")")
+			}
+			depth--
+			b.WriteRune(c)
+			canQuantify = true
+		case '*', '+', '?':
+			if !canQuantify {
+				return "", fmt.Errorf("quantifier %q at position %d does not follow a quantifiable expression", string(c), i)
+			}
+			b.WriteRune(c)
+			canQuantify = false
+		case '{':
+			// A "{" that doesn't start a valid interval expression is matched literally.
+			n, ok, err := parseEREInterval(runes[i:])
+			if err != nil {
+				return "", err
+			}
+			if !ok {
+				b.WriteString("\\{")
+				canQuantify = true
+				continue
+			}
+			if !canQuantify {
+				return "", fmt.Errorf("interval expression at position %d does not follow a quantifiable expression", i)
+			}
+			b.WriteString(string(runes[i : i+n]))
+			i += n - 1
+			canQuantify = false
+		case '}':
+			b.WriteString("\\}")
+			canQuantify = true
+		case '|':
+			b.WriteRune(c)
+			canQuantify = false
+		case '^', '$':
+			b.WriteRune(c)
+			canQuantify = false
+		case '.':
+			if withinRequestLine {
+				b.WriteString(edgeLBRewritePathCharacterRegex)
+			} else {
+				b.WriteRune(c)
+			}
+			canQuantify = true
+		default:
+			if isHAProxyConfigCharacter(c) {
+				b.WriteRune('\\')
+			}
+			b.WriteRune(c)
+			canQuantify = true
+		}
+	}
+	if depth > 0 {
+		return "", fmt.Errorf("unmatched %q", "(")
+	}
+	return b.String(), nil
+}
+
+// translateEREBracketExpression translates the bracket expression at the beginning of the specified runes into an equivalent PCRE bracket expression.
+// It returns the translated bracket expression and the number of runes that were consumed.
+// If "withinRequestLine" is true, the translated bracket expression never matches "?" nor " ".
+func translateEREBracketExpression(runes []rune, withinRequestLine bool) (string, int, error) {
+	var b strings.Builder
+	b.WriteRune('[')
+	i := 1
+	// A leading "^" negates the bracket expression.
+	negated := false
+	if i < len(runes) && runes[i] == '^' {
+		b.WriteRune('^')
+		negated = true
+		i++
+	}
+	// A leading "]" is matched literally.
+	if i < len(runes) && runes[i] == ']' {
+		b.WriteString("\\]")
+		i++
+	}
+	for ; i < len(runes); i++ {
+		c := runes[i]
+		if err := validatePathCharacter(c); err != nil {
+			return "", 0, err
+		}
+		switch {
+		case c == ']':
+			if !withinRequestLine {
+				b.WriteRune(']')
+				return b.String(), i + 1, nil
+			}
+			// Negated bracket expressions are made to exclude "?" and " " as well, while other bracket expressions are preceded by a negative lookahead (which doesn't define a capture group).
+			if negated {
+				b.WriteString("?\\ ]")
+				return b.String(), i + 1, nil
+			}
+			b.WriteRune(']')
+			return "(?:(?![?\\ ])" + b.String() + ")", i + 1, nil
+		case c == '[' && i+1 < len(runes) && runes[i+1] == ':':
+			end := indexOfRunes(runes[i+2:], ":]")
+			if end < 0 {
+				return "", 0, fmt.Errorf("unterminated character class")
+			}
+			name := string(runes[i+2 : i+2+end])
+			if !ereCharacterClasses[name] {
+				return "", 0, fmt.Errorf("unknown character class %q", name)
+			}
+			b.WriteString("[:" + name + ":]")
+			i += 2 + end + 1
+		case c == '[' && i+1 < len(runes) && (runes[i+1] == '.' || runes[i+1] == '='):
+			return "", 0, fmt.Errorf("collating symbols and equivalence classes are not supported")
+		case c == '\\' || c == '[' || isHAProxyConfigCharacter(c):
+			// Backslashes are matched literally inside egrep bracket expressions, but start escape sequences in PCRE.
+			b.WriteRune('\\')
+			b.WriteRune(c)
+		default:
+			b.WriteRune(c)
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated bracket expression")
+}
+
+// parseEREInterval checks whether the specified runes start with a valid interval expression (i.e. "{n}", "{n,}" or "{n,m}").
+// It returns the number of runes in the interval expression and a value indicating whether one was found.
+// An error is returned if the interval expression is syntactically valid but specifies invalid bounds.
+func parseEREInterval(runes []rune) (int, bool, error) {
+	end := indexOfRunes(runes, "}")
+	if end < 0 {
+		return 0, false, nil
+	}
+	bounds := strings.SplitN(string(runes[1:end]), ",", 2)
+	min, err := strconv.Atoi(bounds[0])
+	if err != nil || !isDecimal(bounds[0]) {
+		return 0, false, nil
+	}
+	max := min
+	if len(bounds) == 2 {
+		if bounds[1] == "" {
+			max = ereMaxRepetitions
+		} else if max, err = strconv.Atoi(bounds[1]); err != nil || !isDecimal(bounds[1]) {
+			return 0, false, nil
+		}
+	}
+	if min > max || max > ereMaxRepetitions {
+		return 0, false, fmt.Errorf("invalid interval expression %q", string(runes[:end+1]))
+	}
+	return end + 1, true, nil
+}
+
+// validatePathCharacter checks whether the specified character can be used in a path.
+// Whitespace and control characters are rejected as they cannot be part of a valid HAProxy configuration line.
+func validatePathCharacter(c rune) error {
+	if unicode.IsSpace(c) || unicode.IsControl(c) {
+		return fmt.Errorf("whitespace and control characters are not supported")
+	}
+	return nil
+}
+
+// isHAProxyConfigCharacter indicates whether the specified character has special meaning in the HAProxy configuration and must hence be escaped.
+func isHAProxyConfigCharacter(c rune) bool {
+	return c == '#' || c == '"' || c == '\''
+}
+
+// isDecimal indicates whether the specified string is a non-empty sequence of decimal digits.
+func isDecimal(v string) bool {
+	if v == "" {
+		return false
+	}
+	for _, c := range v {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// indexOfRunes returns the index of the first occurrence of "substr" in "runes", or -1 if it is not present.
+func indexOfRunes(runes []rune, substr string) int {
+	s := []rune(substr)
+	for i := 0; i+len(s) <= len(runes); i++ {
+		if string(runes[i:i+len(s)]) == substr {
+			return i
+		}
+	}
+	return -1
+}
+
+// computeEdgeLBPathRewrite computes the regular expression and the replacement string used by HAProxy (via the "reqrep" directive) to rewrite the request line of requests matching the specified path.
+// The part of the path matched by "path" is replaced by "target" while the query string (if any) is preserved.
+// When paths are matched as prefixes, only the prefix is replaced (i.e. "/foo" rewritten to "/" turns "/foo/bar" into "/bar").
+// When paths are regular expressions, "target" may reference capture groups defined in "path" using "\1" to "\9".
+func computeEdgeLBPathRewrite(path string, matchType constants.EdgeLBPathMatchType, target string) (string, string, error) {
+	if err := validateRewriteTarget(target); err != nil {
+		return "", "", err
+	}
+	switch matchType {
+	case constants.EdgeLBPathMatchTypeExact:
+		if strings.Contains(target, "\\") {
+			return "", "", fmt.Errorf("capture groups can only be referenced when paths are regular expressions")
+		}
+		r, err := escapePathForPCRE(path)
+		if err != nil {
+			return "", "", err
+		}
+		return fmt.Sprintf(edgeLBRewriteSearchFormatString, r), fmt.Sprintf(edgeLBRewriteReplaceFormatString, target, 2), nil
+	case constants.EdgeLBPathMatchTypePrefix:
+		if strings.Contains(target, "\\") {
+			return "", "", fmt.Errorf("capture groups can only be referenced when paths are regular expressions")
+		}
+		r, err := escapePathForPCRE(strings.TrimRight(path, "/"))
+		if err != nil {
+			return "", "", err
+		}
+		// The remainder of the path (without its leading slash) is captured so that it can be appended to the target.
+		// The target is made to end with a slash so that "/foo/bar" rewritten to "/baz" becomes "/baz/bar" (rather than "/bazbar").
+		if !strings.HasSuffix(target, "/") {
+			target = target + "/"
+		}
+		return fmt.Sprintf(edgeLBRewriteSearchFormatString, r+"/?("+edgeLBRewritePathCharacterRegex+"*)"), fmt.Sprintf(edgeLBRewriteReplaceFormatString, target+"\\2", 3), nil
+	// An empty match type is treated as the default one.
+	case constants.EdgeLBPathMatchTypeRegex, "":
+		// Capture groups and anchors are inspected in the regular expression as used to match paths, as the one used to match request lines contains non-capturing groups.
+		m, err := translateEREToPCRE(path, false)
+		if err != nil {
+			return "", "", err
+		}
+		groups, anchored := inspectPCRE(m)
+		if anchored {
+			return "", "", fmt.Errorf("anchors are not supported in paths used together with a rewrite target")
+		}
+		// The request line's method is captured by the first group and the whole path by the second one, so capture groups defined in "path" must be renumbered.
+		// As HAProxy only supports references to the first nine capture groups, the group capturing the remainder of the request line must be at most the ninth one.
+		if groups+3 > edgeLBRewriteMaxGroup {
+			return "", "", fmt.Errorf("at most %d capture groups can be defined in paths used together with a rewrite target", edgeLBRewriteMaxGroup-3)
+		}
+		r, err := translateEREToPCRE(path, true)
+		if err != nil {
+			return "", "", err
+		}
+		var b strings.Builder
+		runes := []rune(target)
+		for i := 0; i < len(runes); i++ {
+			if runes[i] != '\\' {
+				b.WriteRune(runes[i])
+				continue
+			}
+			// "validateRewriteTarget" guarantees that backslashes are always followed by a digit between 1 and 9.
+			i++
+			n := int(runes[i] - '0')
+			if n > groups {
+				return "", "", fmt.Errorf("capture group %d is referenced but path %q only defines %d", n, path, groups)
+			}
+			b.WriteString(fmt.Sprintf("\\%d", n+2))
+		}
+		return fmt.Sprintf(edgeLBRewriteSearchFormatString, "("+r+")"), fmt.Sprintf(edgeLBRewriteReplaceFormatString, b.String(), groups+3), nil
+	default:
+		return "", "", fmt.Errorf("unsupported path match type %q", matchType)
+	}
+}
+
+// validateRewriteTarget checks whether the specified rewrite target is valid.
+// Rewrite targets must start with a slash, and backslashes may only be used to reference capture groups (i.e. "\1" to "\9").
+func validateRewriteTarget(target string) error {
+	if !strings.HasPrefix(target, "/") {
+		return fmt.Errorf("rewrite target %q must start with %q", target, "/")
+	}
+	runes := []rune(target)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		if err := validatePathCharacter(c); err != nil {
+			return err
+		}
+		if isHAProxyConfigCharacter(c) {
+			return fmt.Errorf("character %q is not supported in rewrite targets", c)
+		}
+		if c == '\\' && (i+1 >= len(runes) || runes[i+1] < '1' || runes[i+1] > '9') {
+			return fmt.Errorf("backslashes in rewrite targets must be followed by a digit between 1 and 9")
+		}
+		if c == '\\' {
+			i++
+		}
+	}
+	return nil
+}
+
+// inspectPCRE returns the number of capture groups defined in the specified PCRE regular expression (as produced by "translateEREToPCRE" with "withinRequestLine" set to false), and a value indicating whether it makes use of anchors.
+func inspectPCRE(r string) (int, bool) {
+	var (
+		groups   = 0
+		anchored = false
+		runes    = []rune(r)
+	)
+	for i := 0; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			i++
+		case '[':
+			// Skip the bracket expression, taking into account escaped characters and character classes.
+			// Any "^" found inside a bracket expression negates it, and is hence not an anchor.
+			for i++; i < len(runes) && runes[i] != ']'; i++ {
+				if runes[i] == '\\' {
+					i++
+				} else if runes[i] == '[' && i+1 < len(runes) && runes[i+1] == ':' {
+					i += 2 + indexOfRunes(runes[i+2:], ":]") + 1
+				}
+			}
+		case '(':
+			groups++
+		case '^', '$':
+			anchored = true
+		}
+	}
+	return groups, anchored
+}
@@ -0,0 +1,464 @@
+package translator
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	extsv1beta1 "k8s.io/api/extensions/v1beta1"
+
+	"github.com/mesosphere/dklb/pkg/constants"
+	ingresstestutil "github.com/mesosphere/dklb/test/util/kubernetes/ingress"
+)
+
+// TestComputeEdgeLBPathRegex tests the "computeEdgeLBPathRegex" function.
+func TestComputeEdgeLBPathRegex(t *testing.T) {
+	tests := []struct {
+		description string
+		path        string
+		matchType   constants.EdgeLBPathMatchType
+		regex       string
+		err         error
+	}{
+		{
+			description: "exact path containing special characters",
+			path:        "/foo.bar/(baz)",
+			matchType:   constants.EdgeLBPathMatchTypeExact,
+			regex:       "^/foo\\.bar/\\(baz\\)$",
+		},
+		{
+			description: "prefix path with trailing slash",
+			path:        "/foo/",
+			matchType:   constants.EdgeLBPathMatchTypePrefix,
+			regex:       "^/foo(/.*)?$",
+		},
+		{
+			description: "prefix path matching all paths",
+			path:        "/",
+			matchType:   constants.EdgeLBPathMatchTypePrefix,
+			regex:       "^(/.*)?$",
+		},
+		{
+			description: "prefix path containing whitespace",
+			path:        "/foo bar",
+			matchType:   constants.EdgeLBPathMatchTypePrefix,
+			err:         errors.New("whitespace and control characters are not supported"),
+		},
+		{
+			description: "regex path using alternation",
+			path:        "/foo|/bar",
+			matchType:   constants.EdgeLBPathMatchTypeRegex,
+			regex:       "^(/foo|/bar)$",
+		},
+		{
+			description: "regex path using bracket expressions, character classes and interval expressions",
+			path:        "/[[:digit:]]{1,3}/[^]a-z\\]+",
+			matchType:   constants.EdgeLBPathMatchTypeRegex,
+			regex:       "^(/[[:digit:]]{1,3}/[^\\]a-z\\\\]+)$",
+		},
+		{
+			description: "regex path using escaped special characters",
+			path:        "/foo\\.bar\\*",
+			matchType:   constants.EdgeLBPathMatchTypeRegex,
+			regex:       "^(/foo\\.bar\\*)$",
+		},
+		{
+			description: "regex path using braces that don't start an interval expression",
+			path:        "/{foo}",
+			matchType:   constants.EdgeLBPathMatchTypeRegex,
+			regex:       "^(/\\{foo\\})$",
+		},
+		{
+			description: "regex path using characters with special meaning in the haproxy configuration",
+			path:        "/foo#bar",
+			matchType:   constants.EdgeLBPathMatchTypeRegex,
+			regex:       "^(/foo\\#bar)$",
+		},
+		{
+			description: "regex path using a pcre-only escape sequence",
+			path:        "/foo/\\d+",
+			matchType:   constants.EdgeLBPathMatchTypeRegex,
+			err:         fmt.Errorf("escape sequence %q is not supported", "\\d"),
+		},
+		{
+			description: "regex path using a lazy quantifier",
+			path:        "/foo.*?",
+			matchType:   constants.EdgeLBPathMatchTypeRegex,
+			err:         fmt.Errorf("quantifier %q at position %d does not follow a quantifiable expression", "?", 6),
+		},
+		{
+			description: "regex path using a non-capturing group",
+			path:        "/(?:foo)",
+			matchType:   constants.EdgeLBPathMatchTypeRegex,
+			err:         errors.New("group modifiers are not supported"),
+		},
+		{
+			description: "regex path with unbalanced parentheses",
+			path:        "/(foo",
+			matchType:   constants.EdgeLBPathMatchTypeRegex,
+			err:         fmt.Errorf("unmatched %q", "("),
+		},
+		{
+			description: "regex path with an unterminated bracket expression",
+			path:        "/[foo",
+			matchType:   constants.EdgeLBPathMatchTypeRegex,
+			err:         errors.New("unterminated bracket expression"),
+		},
+		{
+			description: "regex path using an equivalence class",
+			path:        "/[[=a=]]",
+			matchType:   constants.EdgeLBPathMatchTypeRegex,
+			err:         errors.New("collating symbols and equivalence classes are not supported"),
+		},
+		{
+			description: "regex path using an invalid interval expression",
+			path:        "/a{3,1}",
+			matchType:   constants.EdgeLBPathMatchTypeRegex,
+			err:         fmt.Errorf("invalid interval expression %q", "{3,1}"),
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		r, err := computeEdgeLBPathRegex(test.path, test.matchType)
+		if test.err != nil {
+			assert.Equal(t, test.err, err)
+		} else {
+			assert.NoError(t, err)
+			assert.Equal(t, test.regex, r)
+		}
+	}
+}
+
+// TestParseEdgeLBPathRegex tests the "parseEdgeLBPathRegex" function.
+func TestParseEdgeLBPathRegex(t *testing.T) {
+	tests := []struct {
+		description       string
+		regex             string
+		expectedPath      string
+		expectedMatchType constants.EdgeLBPathMatchType
+	}{
+		{
+			description:       "catch-all regular expression",
+			regex:             edgeLBPathCatchAllRegex,
+			expectedPath:      "",
+			expectedMatchType: "",
+		},
+		{
+			description:       "exact path",
+			regex:             "^/foo\\.bar$",
+			expectedPath:      "/foo.bar",
+			expectedMatchType: constants.EdgeLBPathMatchTypeExact,
+		},
+		{
+			description:       "path prefix",
+			regex:             "^/foo/bar(/.*)?$",
+			expectedPath:      "/foo/bar",
+			expectedMatchType: constants.EdgeLBPathMatchTypePrefix,
+		},
+		{
+			description:       "root path prefix",
+			regex:             "^(/.*)?$",
+			expectedPath:      "/",
+			expectedMatchType: constants.EdgeLBPathMatchTypePrefix,
+		},
+		{
+			description:       "regular expression",
+			regex:             "^(/foo/.*)$",
+			expectedPath:      "/foo/.*",
+			expectedMatchType: constants.EdgeLBPathMatchTypeRegex,
+		},
+		{
+			description:       "regular expression not computed by dklb",
+			regex:             "/foo",
+			expectedPath:      "/foo",
+			expectedMatchType: constants.EdgeLBPathMatchTypeRegex,
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		path, matchType := parseEdgeLBPathRegex(test.regex)
+		assert.Equal(t, test.expectedPath, path)
+		assert.Equal(t, test.expectedMatchType, matchType)
+	}
+}
+
+// TestValidateIngressPaths tests the "ValidateIngressPaths" function.
+func TestValidateIngressPaths(t *testing.T) {
+	tests := []struct {
+		description string
+		paths       []string
+		matchType   constants.EdgeLBPathMatchType
+		err         error
+	}{
+		{
+			description: "valid paths",
+			paths:       []string{"", "/foo", "/bar/.*"},
+			matchType:   constants.EdgeLBPathMatchTypeRegex,
+			err:         nil,
+		},
+		{
+			description: "invalid path",
+			paths:       []string{"/foo", "/bar/\\w+"},
+			matchType:   constants.EdgeLBPathMatchTypeRegex,
+			err:         fmt.Errorf("path %q for host %q is not valid: %v", "/bar/\\w+", "foo.com", fmt.Errorf("escape sequence %q is not supported", "\\w")),
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		ingress := ingresstestutil.DummyIngressResource("foo", "bar", func(ingress *extsv1beta1.Ingress) {
+			paths := make([]extsv1beta1.HTTPIngressPath, 0, len(test.paths))
+			for _, path := range test.paths {
+				paths = append(paths, extsv1beta1.HTTPIngressPath{
+					Path: path,
+					Backend: extsv1beta1.IngressBackend{
+						ServiceName: "foo",
+					},
+				})
+			}
+			ingress.Spec.Rules = []extsv1beta1.IngressRule{
+				{
+					Host: "foo.com",
+					IngressRuleValue: extsv1beta1.IngressRuleValue{
+						HTTP: &extsv1beta1.HTTPIngressRuleValue{
+							Paths: paths,
+						},
+					},
+				},
+			}
+		})
+		assert.Equal(t, test.err, ValidateIngressPaths(ingress, test.matchType))
+	}
+}
+
+// TestComputeIngressPathMatchType tests the "computeIngressPathMatchType" function.
+func TestComputeIngressPathMatchType(t *testing.T) {
+	// Create a "networking.k8s.io/v1" Ingress resource declaring paths of every type.
+	v1 := ingresstestutil.DummyIngressResource("foo", "bar", func(ingress *extsv1beta1.Ingress) {
+		ingress.APIVersion = "networking.k8s.io/v1"
+		ingress.Annotations = map[string]string{
+			constants.IngressPathTypesAnnotationKey: `[{"host":"foo.com","path":"/exact","pathType":"Exact"},{"host":"foo.com","path":"/prefix","pathType":"Prefix"},{"host":"foo.com","path":"/specific","pathType":"ImplementationSpecific"}]`,
+		}
+	})
+	tests := []struct {
+		description       string
+		ingress           *extsv1beta1.Ingress
+		host              string
+		path              string
+		expectedMatchType constants.EdgeLBPathMatchType
+	}{
+		{
+			description:       "path with the \"Exact\" path type",
+			ingress:           v1,
+			host:              "foo.com",
+			path:              "/exact",
+			expectedMatchType: constants.EdgeLBPathMatchTypeExact,
+		},
+		{
+			description:       "path with the \"Prefix\" path type",
+			ingress:           v1,
+			host:              "foo.com",
+			path:              "/prefix",
+			expectedMatchType: constants.EdgeLBPathMatchTypePrefix,
+		},
+		{
+			description:       "path with the \"ImplementationSpecific\" path type",
+			ingress:           v1,
+			host:              "foo.com",
+			path:              "/specific",
+			expectedMatchType: constants.EdgeLBPathMatchTypeRegex,
+		},
+		{
+			description:       "path defined for a different host",
+			ingress:           v1,
+			host:              "bar.com",
+			path:              "/exact",
+			expectedMatchType: constants.EdgeLBPathMatchTypeRegex,
+		},
+		{
+			description:       "path defined in an \"extensions/v1beta1\" ingress",
+			ingress:           ingresstestutil.DummyIngressResource("foo", "bar"),
+			host:              "foo.com",
+			path:              "/exact",
+			expectedMatchType: constants.EdgeLBPathMatchTypeRegex,
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		assert.Equal(t, test.expectedMatchType, computeIngressPathMatchType(test.ingress, test.host, test.path, constants.EdgeLBPathMatchTypeRegex))
+	}
+}
+
+// TestComputeEdgeLBPathRewrite tests the "computeEdgeLBPathRewrite" function.
+func TestComputeEdgeLBPathRewrite(t *testing.T) {
+	tests := []struct {
+		description string
+		path        string
+		matchType   constants.EdgeLBPathMatchType
+		target      string
+		search      string
+		replace     string
+		// requestLine is an optional request line used to check the result of applying the rewrite, which must be "rewritten".
+		requestLine string
+		rewritten   string
+		err         error
+	}{
+		{
+			description: "exact path",
+			path:        "/foo.bar",
+			matchType:   constants.EdgeLBPathMatchTypeExact,
+			target:      "/baz",
+			search:      "^([^\\ ]+\\ )/foo\\.bar([?\\ ].*)$",
+			replace:     "\\1/baz\\2",
+		},
+		{
+			description: "path prefix rewritten to the root path",
+			path:        "/foo/",
+			matchType:   constants.EdgeLBPathMatchTypePrefix,
+			target:      "/",
+			search:      "^([^\\ ]+\\ )/foo/?([^?\\ ]*)([?\\ ].*)$",
+			replace:     "\\1/\\2\\3",
+		},
+		{
+			description: "path prefix rewritten to another path prefix",
+			path:        "/foo",
+			matchType:   constants.EdgeLBPathMatchTypePrefix,
+			target:      "/bar",
+			search:      "^([^\\ ]+\\ )/foo/?([^?\\ ]*)([?\\ ].*)$",
+			replace:     "\\1/bar/\\2\\3",
+		},
+		{
+			description: "regular expression with a capture group",
+			path:        "/foo/(.*)",
+			matchType:   constants.EdgeLBPathMatchTypeRegex,
+			target:      "/bar/\\1",
+			search:      "^([^\\ ]+\\ )(/foo/([^?\\ ]*))([?\\ ].*)$",
+			replace:     "\\1/bar/\\3\\4",
+			requestLine: "GET /foo/baz HTTP/1.1",
+			rewritten:   "GET /bar/baz HTTP/1.1",
+		},
+		{
+			description: "greedy regular expression and a request with a query string",
+			path:        "/api/(.*)",
+			matchType:   constants.EdgeLBPathMatchTypeRegex,
+			target:      "/\\1",
+			search:      "^([^\\ ]+\\ )(/api/([^?\\ ]*))([?\\ ].*)$",
+			replace:     "\\1/\\3\\4",
+			requestLine: "GET /api/users?page=2&q=a/b HTTP/1.1",
+			rewritten:   "GET /users?page=2&q=a/b HTTP/1.1",
+		},
+		{
+			description: "regular expression with bracket expressions and a request with a query string",
+			path:        "/api/([^/]+)/[a-z?]+",
+			matchType:   constants.EdgeLBPathMatchTypeRegex,
+			target:      "/\\1",
+			search:      "^([^\\ ]+\\ )(/api/([^/?\\ ]+)/(?:(?![?\\ ])[a-z?])+)([?\\ ].*)$",
+			replace:     "\\1/\\3\\4",
+		},
+		{
+			description: "path prefix and a request with a query string",
+			path:        "/foo",
+			matchType:   constants.EdgeLBPathMatchTypePrefix,
+			target:      "/bar",
+			search:      "^([^\\ ]+\\ )/foo/?([^?\\ ]*)([?\\ ].*)$",
+			replace:     "\\1/bar/\\2\\3",
+			requestLine: "GET /foo/baz?qux=/foo HTTP/1.1",
+			rewritten:   "GET /bar/baz?qux=/foo HTTP/1.1",
+		},
+		{
+			description: "rewrite target not starting with a slash",
+			path:        "/foo",
+			matchType:   constants.EdgeLBPathMatchTypeExact,
+			target:      "bar",
+			err:         fmt.Errorf("rewrite target %q must start with %q", "bar", "/"),
+		},
+		{
+			description: "capture group referenced by a rewrite target for a path prefix",
+			path:        "/foo",
+			matchType:   constants.EdgeLBPathMatchTypePrefix,
+			target:      "/bar\\1",
+			err:         errors.New("capture groups can only be referenced when paths are regular expressions"),
+		},
+		{
+			description: "invalid escape sequence in rewrite target",
+			path:        "/foo",
+			matchType:   constants.EdgeLBPathMatchTypeRegex,
+			target:      "/bar\\x",
+			err:         errors.New("backslashes in rewrite targets must be followed by a digit between 1 and 9"),
+		},
+		{
+			description: "anchored regular expression",
+			path:        "^/foo",
+			matchType:   constants.EdgeLBPathMatchTypeRegex,
+			target:      "/bar",
+			err:         errors.New("anchors are not supported in paths used together with a rewrite target"),
+		},
+		{
+			description: "reference to an undefined capture group",
+			path:        "/foo/(.*)",
+			matchType:   constants.EdgeLBPathMatchTypeRegex,
+			target:      "/bar/\\2",
+			err:         fmt.Errorf("capture group %d is referenced but path %q only defines %d", 2, "/foo/(.*)", 1),
+		},
+		{
+			description: "too many capture groups",
+			path:        "/(a)(b)(c)(d)(e)(f)(g)",
+			matchType:   constants.EdgeLBPathMatchTypeRegex,
+			target:      "/",
+			err:         fmt.Errorf("at most %d capture groups can be defined in paths used together with a rewrite target", 6),
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		search, replace, err := computeEdgeLBPathRewrite(test.path, test.matchType, test.target)
+		if test.err != nil {
+			assert.Equal(t, test.err, err)
+		} else {
+			assert.NoError(t, err)
+			assert.Equal(t, test.search, search)
+			assert.Equal(t, test.replace, replace)
+		}
+		// Make sure that applying the rewrite to the request line (if any) produces the expected result.
+		// HAProxy's "\N" references are converted into the "${N}" syntax used by Go.
+		if test.requestLine != "" {
+			rewritten := regexp.MustCompile(search).ReplaceAllString(test.requestLine, regexp.MustCompile(`\\([1-9])`).ReplaceAllString(replace, "$${$1}"))
+			assert.Equal(t, test.rewritten, rewritten)
+		}
+	}
+}
+
+// TestInspectPCRE tests the "inspectPCRE" function.
+func TestInspectPCRE(t *testing.T) {
+	tests := []struct {
+		description string
+		regex       string
+		groups      int
+		anchored    bool
+	}{
+		{
+			description: "no capture groups",
+			regex:       "/foo/.*",
+			groups:      0,
+			anchored:    false,
+		},
+		{
+			description: "escaped parentheses and bracket expressions",
+			regex:       "/foo\\(bar\\)[(]",
+			groups:      0,
+			anchored:    false,
+		},
+		{
+			description: "capture groups and an anchor",
+			regex:       "[^(]a([[:digit:]\\]])(x)$",
+			groups:      2,
+			anchored:    true,
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		groups, anchored := inspectPCRE(test.regex)
+		assert.Equal(t, test.groups, groups)
+		assert.Equal(t, test.anchored, anchored)
+	}
+}
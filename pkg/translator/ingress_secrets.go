@@ -0,0 +1,64 @@
+package translator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mesosphere/dcos-edge-lb/models"
+
+	"github.com/mesosphere/dklb/pkg/dcos/secrets"
+	dklberrors "github.com/mesosphere/dklb/pkg/errors"
+	dklbstrings "github.com/mesosphere/dklb/pkg/util/strings"
+)
+
+// ComputeOrphanedDCOSSecretPaths returns the paths of the DC/OS secrets reflected by dklb for Ingress resources in the specified Kubernetes cluster that are referenced by the "previous" set of EdgeLB pool secrets but not by the "current" one.
+// "current" should be empty in case the EdgeLB pool is being deleted.
+// As the same DC/OS secret may be referenced by EdgeLB pool secrets owned by different Ingress resources (or by different versions of the same Secret resource), a DC/OS secret is only considered to be orphaned once it is not referenced by any EdgeLB pool secret.
+// The returned slice is sorted in order to get a predictable output.
+func ComputeOrphanedDCOSSecretPaths(clusterName string, previous, current []*models.V2PoolSecretsItems0) []string {
+	// referenced holds the paths of the DC/OS secrets that are still referenced by the EdgeLB pool.
+	referenced := make(map[string]bool, len(current))
+	for _, secret := range current {
+		referenced[secret.Secret] = true
+	}
+	// orphaned holds the paths of the DC/OS secrets that are not referenced by the EdgeLB pool anymore.
+	orphaned := make(map[string]bool)
+	for _, secret := range previous {
+		if !isEdgeLBSecretOwnedByCluster(clusterName, secret) || referenced[secret.Secret] {
+			continue
+		}
+		orphaned[secret.Secret] = true
+	}
+	res := make([]string, 0, len(orphaned))
+	for path := range orphaned {
+		res = append(res, path)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// DeleteDCOSSecrets deletes the DC/OS secrets at the specified paths.
+// DC/OS secrets that don't exist are considered to have been deleted.
+// An attempt is made to delete every DC/OS secret, and an error describing all failed attempts (if any) is returned.
+func DeleteDCOSSecrets(secretsManager secrets.SecretsManager, paths []string) error {
+	failed := make([]string, 0)
+	for _, path := range paths {
+		ctx, fn := context.WithTimeout(context.Background(), defaultSecretsManagerTimeout)
+		err := secretsManager.DeleteSecret(ctx, path)
+		fn()
+		if err != nil && !dklberrors.IsNotFound(err) {
+			failed = append(failed, fmt.Sprintf("%q (%v)", path, err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to delete dc/os secrets %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// isEdgeLBSecretOwnedByCluster indicates whether the specified EdgeLB pool secret is owned by an Ingress resource in the specified Kubernetes cluster (i.e. whether it references a DC/OS secret reflected by dklb).
+func isEdgeLBSecretOwnedByCluster(clusterName string, secret *models.V2PoolSecretsItems0) bool {
+	return strings.HasPrefix(secret.File, dklbstrings.ReplaceForwardSlashesWithDots(clusterName)+edgeLBSecretFileNameSeparator)
+}
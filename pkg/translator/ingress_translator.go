@@ -15,6 +15,7 @@ import (
 
 	dklbcache "github.com/mesosphere/dklb/pkg/cache"
 	"github.com/mesosphere/dklb/pkg/constants"
+	"github.com/mesosphere/dklb/pkg/dcos/secrets"
 	"github.com/mesosphere/dklb/pkg/edgelb/manager"
 	dklberrors "github.com/mesosphere/dklb/pkg/errors"
 	kubernetesutil "github.com/mesosphere/dklb/pkg/util/kubernetes"
@@ -43,26 +44,45 @@ type IngressTranslator struct {
 	kubeCache dklbcache.KubernetesResourceCache
 	// manager is the instance of the EdgeLB manager to use for managing EdgeLB pools.
 	manager manager.EdgeLBManager
+	// secretsManager is the instance of the DC/OS secrets manager to use for reflecting TLS secrets into the DC/OS secret store.
+	secretsManager secrets.SecretsManager
 	// logger is the logger to use when performing translation.
 	logger *log.Entry
 	// recorder is the event recorder used to emit events associated with a given Ingress resource.
 	recorder record.EventRecorder
 	// poolGroup is the DC/OS service group in which to create EdgeLB pools.
 	poolGroup string
+	// appliedStateHash is the hash of the EdgeLB objects computed for the Ingress resource during the last call to "Translate".
+	appliedStateHash string
+	// lastAppliedDiff is the JSON description of the changes made to the target EdgeLB pool during the last call to "Translate".
+	lastAppliedDiff string
+	// appliedResources is the JSON description of the CPU and memory requests and of the size applied to the target EdgeLB pool for the Ingress resource during the last call to "Translate".
+	appliedResources string
+	// appliedPoolPatch is the JSON merge patch applied to the target EdgeLB pool for the Ingress resource.
+	// It is initialized from the Ingress resource's annotations, and is updated whenever the JSON merge patch requested for the Ingress resource is applied to the target EdgeLB pool.
+	appliedPoolPatch string
+	// reportedDriftHash is the hash of the changes made out-of-band to the target EdgeLB pool that have been reported for the Ingress resource.
+	// It is initialized from the Ingress resource's annotations, and is only updated in case the target EdgeLB pool has been checked for such changes.
+	reportedDriftHash string
+	// poolChanged indicates whether the target EdgeLB pool was created or updated during the last call to "Translate".
+	poolChanged bool
 }
 
 // NewIngressTranslator returns an ingress translator that can be used to translate the specified Ingress resource into an EdgeLB pool.
-func NewIngressTranslator(clusterName string, ingress *extsv1beta1.Ingress, options IngressTranslationOptions, kubeCache dklbcache.KubernetesResourceCache, manager manager.EdgeLBManager, recorder record.EventRecorder) *IngressTranslator {
+func NewIngressTranslator(clusterName string, ingress *extsv1beta1.Ingress, options IngressTranslationOptions, kubeCache dklbcache.KubernetesResourceCache, manager manager.EdgeLBManager, secretsManager secrets.SecretsManager, recorder record.EventRecorder) *IngressTranslator {
 	return &IngressTranslator{
 		clusterName: clusterName,
 		// Use a clone of the Ingress resource as we may need to modify it in order to inject the default backend.
-		ingress:   ingress.DeepCopy(),
-		options:   options,
-		kubeCache: kubeCache,
-		manager:   manager,
-		logger:    log.WithField("ingress", kubernetesutil.Key(ingress)),
-		recorder:  recorder,
-		poolGroup: manager.PoolGroup(),
+		ingress:           ingress.DeepCopy(),
+		options:           options,
+		kubeCache:         kubeCache,
+		manager:           manager,
+		secretsManager:    secretsManager,
+		logger:            log.WithField("ingress", kubernetesutil.Key(ingress)),
+		recorder:          recorder,
+		poolGroup:         manager.PoolGroup(),
+		reportedDriftHash: GetEdgeLBPoolReportedDriftHash(ingress),
+		appliedPoolPatch:  GetEdgeLBPoolAppliedPatch(ingress),
 	}
 }
 
@@ -80,8 +100,13 @@ func (it *IngressTranslator) Translate() (*corev1.LoadBalancerStatus, error) {
 		return nil, nil
 	}
 
-	// Compute the mapping between Ingress backends defined on the current Ingress resource and their target node ports.
-	backendMap := it.computeIngressBackendNodePortMap(defaultBackendNodePort)
+	// Report any paths that cannot be translated using the requested match type, as the corresponding rules will be skipped.
+	it.reportInvalidPaths()
+
+	// Compute the mapping between Ingress backends defined on the current Ingress resource and their target node ports (and, if requested, pod addresses).
+	backendMap, endpointsMap := it.computeIngressBackendNodePortMap(defaultBackendNodePort)
+	// Compute the set of TLS secrets to use for serving the current Ingress resource over HTTPS.
+	tlsSecrets := it.computeIngressTLSSecrets()
 
 	// Check whether an EdgeLB pool with the requested name already exists in EdgeLB.
 	ctx, fn := context.WithTimeout(context.Background(), defaultEdgeLBManagerTimeout)
@@ -93,11 +118,115 @@ func (it *IngressTranslator) Translate() (*corev1.LoadBalancerStatus, error) {
 		}
 	}
 	// If the target EdgeLB pool does not exist, we must try to create it,
+	// unless the Ingress resource has been deleted (or is not meant to be provisioned by EdgeLB anymore), in which case there is nothing to clean up.
 	if pool == nil {
-		return it.createEdgeLBPool(backendMap)
+		if it.ingress.DeletionTimestamp != nil || !kubernetesutil.IsEdgeLBIngress(it.ingress) {
+			return &corev1.LoadBalancerStatus{}, nil
+		}
+		return it.createEdgeLBPool(backendMap, endpointsMap, tlsSecrets)
 	}
 	// If the target EdgeLB pool already exists, we must check whether it needs to be updated/deleted.
-	return it.updateOrDeleteEdgeLBPool(pool, backendMap)
+	return it.updateOrDeleteEdgeLBPool(pool, backendMap, endpointsMap, tlsSecrets)
+}
+
+// AppliedStateHash returns the hash of the EdgeLB objects computed for the associated Ingress resource during the last call to "Translate".
+// It must be recorded on the Ingress resource (using "SetEdgeLBPoolAppliedStateHash") so that changes made out-of-band to these EdgeLB objects can be detected.
+// An empty string is returned in case there is nothing to record (e.g. because the Ingress resource has been deleted).
+func (it *IngressTranslator) AppliedStateHash() string {
+	// Nothing is actually applied to the target EdgeLB pool in dry-run mode, so there is nothing to record either.
+	if manager.IsDryRun(it.manager) {
+		return ""
+	}
+	return it.appliedStateHash
+}
+
+// AppliedResources returns the JSON description of the CPU and memory requests and of the size applied to the target EdgeLB pool for the associated Ingress resource during the last call to "Translate".
+// It must be recorded on the Ingress resource (using "SetEdgeLBPoolAppliedResources") so that these fields of the target EdgeLB pool are only changed when the corresponding annotations change.
+// An empty string is returned in case there is nothing to record (e.g. because the Ingress resource has been deleted, or because the EdgeLB pool creation strategy is "Never").
+func (it *IngressTranslator) AppliedResources() string {
+	// Nothing is actually applied to the target EdgeLB pool in dry-run mode, so there is nothing to record either.
+	if manager.IsDryRun(it.manager) {
+		return ""
+	}
+	return it.appliedResources
+}
+
+// AppliedEdgeLBPoolPatch returns the JSON merge patch applied to the target EdgeLB pool for the associated Ingress resource.
+// It must be recorded on the Ingress resource (using "SetEdgeLBPoolAppliedPatch") so that the fields that are no longer set by the requested JSON merge patch can be reset.
+// An empty string is returned in case no JSON merge patch is applied to the target EdgeLB pool for the Ingress resource.
+func (it *IngressTranslator) AppliedEdgeLBPoolPatch() string {
+	// Nothing is actually applied to the target EdgeLB pool in dry-run mode, so the JSON merge patch that has last been recorded is kept.
+	if manager.IsDryRun(it.manager) {
+		return GetEdgeLBPoolAppliedPatch(it.ingress)
+	}
+	return it.appliedPoolPatch
+}
+
+// ReportedDriftHash returns the hash of the changes made out-of-band to the target EdgeLB pool that have been reported for the associated Ingress resource.
+// It must be recorded on the Ingress resource (using "SetEdgeLBPoolReportedDriftHash") so that the same changes are not reported again on every resync.
+// An empty string is returned in case the target EdgeLB pool doesn't contain any such changes.
+func (it *IngressTranslator) ReportedDriftHash() string {
+	return it.reportedDriftHash
+}
+
+// LastAppliedDiff returns the JSON description of the changes made to the target EdgeLB pool during the last call to "Translate".
+// It must be recorded on the Ingress resource (using "SetEdgeLBPoolLastAppliedDiff") so that the reason why the EdgeLB pool changed can be inspected later on.
+// An empty string is returned in case the EdgeLB pool was not created or updated.
+func (it *IngressTranslator) LastAppliedDiff() string {
+	// Nothing is actually applied to the target EdgeLB pool in dry-run mode, so there is nothing to record either.
+	if manager.IsDryRun(it.manager) {
+		return ""
+	}
+	return it.lastAppliedDiff
+}
+
+// EdgeLBPoolChanged returns a value indicating whether the target EdgeLB pool was created or updated during the last call to "Translate".
+// It is used to decide whether changes to the Secret resources referenced by the associated Ingress resource have actually been reflected on the target EdgeLB pool.
+func (it *IngressTranslator) EdgeLBPoolChanged() bool {
+	// Nothing is actually applied to the target EdgeLB pool in dry-run mode.
+	if manager.IsDryRun(it.manager) {
+		return false
+	}
+	return it.poolChanged
+}
+
+// owner returns the identity of the associated Ingress resource as the owner of EdgeLB objects.
+func (it *IngressTranslator) owner() *EdgeLBObjectOwner {
+	return &EdgeLBObjectOwner{Kind: EdgeLBObjectOwnerKindIngress, Namespace: it.ingress.Namespace, Name: it.ingress.Name}
+}
+
+// isEdgeLBObjectOwned returns a value indicating whether the EdgeLB backend/frontend with the specified name is owned by the associated Ingress resource.
+func (it *IngressTranslator) isEdgeLBObjectOwned(name string) bool {
+	metadata, err := computeIngressOwnedEdgeLBObjectMetadata(name)
+	return err == nil && metadata.IsOwnedBy(it.clusterName, it.ingress)
+}
+
+// reportInvalidPaths emits an event for each path defined in the current Ingress resource that cannot be translated using the requested match type or rewritten as requested.
+// This should only happen for Ingress resources that have been created before the admission webhook started validating paths.
+func (it *IngressTranslator) reportInvalidPaths() {
+	// There's nothing to report if the Ingress resource has been deleted.
+	if it.ingress.DeletionTimestamp != nil {
+		return
+	}
+	kubernetesutil.ForEachIngresBackend(it.ingress, func(host, path *string, _ extsv1beta1.IngressBackend) {
+		if path == nil || *path == "" {
+			return
+		}
+		matchType := computeIngressPathMatchType(it.ingress, *host, *path, it.options.EdgeLBPathMatchType)
+		if _, err := computeEdgeLBPathRegex(*path, matchType); err != nil {
+			msg := fmt.Sprintf("path %q for host %q will be ignored as it is not valid: %v", *path, *host, err)
+			it.recorder.Eventf(it.ingress, corev1.EventTypeWarning, constants.ReasonInvalidPath, msg)
+			it.logger.Warn(msg)
+			return
+		}
+		if target, rewrite := it.options.rewriteTargetForPath(*path); rewrite {
+			if _, _, err := computeEdgeLBPathRewrite(*path, matchType, target); err != nil {
+				msg := fmt.Sprintf("path %q for host %q will be ignored as it cannot be rewritten to %q: %v", *path, *host, target, err)
+				it.recorder.Eventf(it.ingress, corev1.EventTypeWarning, constants.ReasonInvalidPath, msg)
+				it.logger.Warn(msg)
+			}
+		}
+	})
 }
 
 // determineDefaultBackendNodePort attempts to determine the node port at which the default backend is exposed.
@@ -122,7 +251,9 @@ func (it *IngressTranslator) determineDefaultBackendNodePort() (int32, error) {
 // In case a default backend hasn't been specified, dklb's default backend is injected as the default one.
 // Then, it iterates over said set and checks whether the referenced service port exists, adding them to the map or using the default backend's node port instead.
 // As the returned object is in fact a map, duplicate Ingress backends are automatically removed.
-func (it *IngressTranslator) computeIngressBackendNodePortMap(defaultBackendNodePort int32) IngressBackendNodePortMap {
+// The mapping between Ingress backends and the addresses they target is computed as well, so that only Ready and schedulable Kubernetes nodes (or the relevant pods) are targeted.
+// dklb's default backend always targets its node port on every Ready and schedulable Kubernetes node.
+func (it *IngressTranslator) computeIngressBackendNodePortMap(defaultBackendNodePort int32) (IngressBackendNodePortMap, IngressBackendEndpointsMap) {
 	// Inject dklb as the default backend in case none is specified.
 	if it.ingress.Spec.Backend == nil {
 		it.ingress.Spec.Backend = &extsv1beta1.IngressBackend{
@@ -137,41 +268,58 @@ func (it *IngressTranslator) computeIngressBackendNodePortMap(defaultBackendNode
 	kubernetesutil.ForEachIngresBackend(it.ingress, func(_, _ *string, backend extsv1beta1.IngressBackend) {
 		backends = append(backends, backend)
 	})
-	// Create the map that we will be populating and returning.
+	// Create the maps that we will be populating and returning.
 	res := make(IngressBackendNodePortMap, len(backends))
-	// Iterate over the set of Ingress backends, computing the target node port.
+	endpointsMap := make(IngressBackendEndpointsMap)
+	// Compute the addresses targeted by dklb's default backend.
+	// In case these cannot be computed, dklb's default backend targets its node port on every Kubernetes node.
+	defaultBackendTarget, err := computeBackendTargetForNodePort(it.kubeCache, defaultBackendNodePort)
+	if err != nil {
+		it.logger.Warnf("failed to compute the addresses targeted by the default backend: %v", err)
+	}
+	// useDefaultBackend makes the specified Ingress backend target dklb's default backend.
+	useDefaultBackend := func(backend extsv1beta1.IngressBackend) {
+		res[backend] = defaultBackendNodePort
+		if defaultBackendTarget != nil {
+			endpointsMap[backend] = *defaultBackendTarget
+		}
+	}
+	// Iterate over the set of Ingress backends, computing the target node port (and, if applicable, the target addresses).
 	for _, backend := range backends {
 		// If the target service's name corresponds to "defaultBackendServiceName", we use the default backend's node port.
 		if backend.ServiceName == defaultBackendServiceName && backend.ServicePort == defaultBackendServicePort {
-			res[backend] = defaultBackendNodePort
+			useDefaultBackend(backend)
 			continue
 		}
-		if nodePort, err := it.computeNodePortForIngressBackend(backend); err == nil {
+		nodePort, err := it.computeNodePortForIngressBackend(backend)
+		if err == nil {
+			var target *backendTarget
+			if target, err = it.computeBackendTargetForIngressBackend(backend); err == nil && target != nil {
+				endpointsMap[backend] = *target
+			}
+		}
+		if err == nil {
 			res[backend] = nodePort
 		} else {
-			// We've failed to compute the target node port for the current backend.
-			// This may be caused by the specified Service resource being absent or not being of NodePort/LoadBalancer type.
+			// We've failed to compute the target node port (or addresses) for the current backend.
+			// This may be caused by the specified Service resource being absent or not being of NodePort/LoadBalancer type, or by the corresponding Endpoints resource being absent.
 			// Hence, we use the default backend's node port and report the error as an event, but do not fail.
 			msg := fmt.Sprintf("using the default backend in place of \"%s:%s\": %v", backend.ServiceName, backend.ServicePort.String(), err)
 			it.recorder.Eventf(it.ingress, corev1.EventTypeWarning, constants.ReasonInvalidBackendService, msg)
 			it.logger.Warn(msg)
-			res[backend] = defaultBackendNodePort
+			useDefaultBackend(backend)
 		}
 	}
-	// Return the populated map.
-	return res
+	// Return the populated maps.
+	return res, endpointsMap
 }
 
-// computeNodePortForIngressBackend computes the node port targeted by the specified Ingress backend.
-func (it *IngressTranslator) computeNodePortForIngressBackend(backend extsv1beta1.IngressBackend) (int32, error) {
+// computeServicePortForIngressBackend returns the Service resource referenced by the specified Ingress backend, as well as the referenced service port.
+func (it *IngressTranslator) computeServicePortForIngressBackend(backend extsv1beta1.IngressBackend) (*corev1.Service, *corev1.ServicePort, error) {
 	// Check whether the referenced Service resource exists.
 	s, err := it.kubeCache.GetService(it.ingress.Namespace, backend.ServiceName)
 	if err != nil {
-		return 0, fmt.Errorf("failed to read service %q referenced by ingress %q: %v", backend.ServiceName, kubernetesutil.Key(it.ingress), err)
-	}
-	// Check whether the referenced Service resource is of type "NodePort" or "LoadBalancer".
-	if s.Spec.Type != corev1.ServiceTypeNodePort && s.Spec.Type != corev1.ServiceTypeLoadBalancer {
-		return 0, fmt.Errorf("service %q referenced by ingress %q is of unexpected type %q", backend.ServiceName, kubernetesutil.Key(it.ingress), s.Spec.Type)
+		return nil, nil, fmt.Errorf("failed to read service %q referenced by ingress %q: %v", backend.ServiceName, kubernetesutil.Key(it.ingress), err)
 	}
 	// Lookup the referenced service port.
 	var servicePort *corev1.ServicePort
@@ -184,15 +332,129 @@ func (it *IngressTranslator) computeNodePortForIngressBackend(backend extsv1beta
 	}
 	// Check whether the referenced service port has been found.
 	if servicePort == nil {
-		return 0, fmt.Errorf("port %q of service %q referenced by ingress %q not found", backend.ServicePort.String(), backend.ServiceName, kubernetesutil.Key(it.ingress))
+		return nil, nil, fmt.Errorf("port %q of service %q referenced by ingress %q not found", backend.ServicePort.String(), backend.ServiceName, kubernetesutil.Key(it.ingress))
+	}
+	return s, servicePort, nil
+}
+
+// computeNodePortForIngressBackend computes the node port targeted by the specified Ingress backend.
+// In case EdgeLB backends have been requested to target pod IPs, Service resources of type "ClusterIP" are valid as well (and the returned node port is zero).
+func (it *IngressTranslator) computeNodePortForIngressBackend(backend extsv1beta1.IngressBackend) (int32, error) {
+	s, servicePort, err := it.computeServicePortForIngressBackend(backend)
+	if err != nil {
+		return 0, err
+	}
+	// Check whether the referenced Service resource is of type "NodePort" or "LoadBalancer" (or "ClusterIP" if pod IPs are to be targeted).
+	switch {
+	case s.Spec.Type == corev1.ServiceTypeNodePort || s.Spec.Type == corev1.ServiceTypeLoadBalancer:
+	case s.Spec.Type == corev1.ServiceTypeClusterIP && it.options.EdgeLBBackendTarget == constants.EdgeLBBackendTargetPodIP:
+	default:
+		return 0, fmt.Errorf("service %q referenced by ingress %q is of unexpected type %q", backend.ServiceName, kubernetesutil.Key(it.ingress), s.Spec.Type)
 	}
 	return servicePort.NodePort, nil
 }
 
+// computeBackendTargetForIngressBackend computes the addresses targeted by the specified Ingress backend, as reported by the corresponding Endpoints resource.
+// nil is returned in case the Ingress backend targets its node port on every Kubernetes node.
+func (it *IngressTranslator) computeBackendTargetForIngressBackend(backend extsv1beta1.IngressBackend) (*backendTarget, error) {
+	s, servicePort, err := it.computeServicePortForIngressBackend(backend)
+	if err != nil {
+		return nil, err
+	}
+	return computeBackendTargetForServicePort(it.kubeCache, s, *servicePort, it.options.BaseTranslationOptions)
+}
+
+// computeIngressTLSSecrets computes the set of TLS secrets to use for serving the current Ingress resource over HTTPS.
+// It iterates over the ".spec.tls" field of the Ingress resource and reads each referenced Secret resource, checking whether it is valid.
+// Missing or otherwise invalid Secret resources are reported as events and skipped, but do not cause translation to fail.
+// The returned slice is sorted by the name of the Secret resources in order to get a predictable output.
+func (it *IngressTranslator) computeIngressTLSSecrets() []ingressTLSSecret {
+	// There is no need to compute TLS secrets for an Ingress resource that has been deleted.
+	if it.ingress.DeletionTimestamp != nil {
+		return nil
+	}
+	// byName holds the TLS secrets computed so far indexed by the name of the corresponding Secret resource.
+	// It is used to merge the lists of hosts of ".spec.tls" entries referencing the same Secret resource.
+	byName := make(map[string]ingressTLSSecret, len(it.ingress.Spec.TLS))
+	for _, tls := range it.ingress.Spec.TLS {
+		// Make sure that the name of a Secret resource has been specified.
+		if tls.SecretName == "" {
+			msg := fmt.Sprintf("ignoring tls configuration for hosts %v as no secret has been specified", tls.Hosts)
+			it.recorder.Eventf(it.ingress, corev1.EventTypeWarning, constants.ReasonInvalidTLSSecret, msg)
+			it.logger.Warn(msg)
+			continue
+		}
+		// Read and validate the referenced Secret resource.
+		s, err := it.kubeCache.GetSecret(it.ingress.Namespace, tls.SecretName)
+		if err == nil {
+			err = validateTLSSecret(s)
+		}
+		if err != nil {
+			msg := fmt.Sprintf("ignoring tls configuration for hosts %v: %v", tls.Hosts, err)
+			it.recorder.Eventf(it.ingress, corev1.EventTypeWarning, constants.ReasonInvalidTLSSecret, msg)
+			it.logger.Warn(msg)
+			continue
+		}
+		// Compute the TLS secret, merging the list of hosts with any previously computed one for the same Secret resource.
+		t := computeIngressTLSSecret(it.clusterName, it.poolGroup, it.options.EdgeLBPoolName, it.ingress, tls, s)
+		if existing, ok := byName[tls.SecretName]; ok {
+			if len(existing.Hosts) == 0 || len(t.Hosts) == 0 {
+				t.Hosts = nil
+			} else {
+				t.Hosts = append(append(make([]string, 0, len(existing.Hosts)+len(t.Hosts)), existing.Hosts...), t.Hosts...)
+			}
+		}
+		byName[tls.SecretName] = t
+	}
+	// Build and return the sorted list of TLS secrets.
+	res := make([]ingressTLSSecret, 0, len(byName))
+	for _, t := range byName {
+		res = append(res, t)
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].SecretName < res[j].SecretName
+	})
+	return res
+}
+
+// reflectIngressTLSSecrets reflects the specified TLS secrets into the DC/OS secret store so that they can be used by the target EdgeLB pool.
+func (it *IngressTranslator) reflectIngressTLSSecrets(tlsSecrets []ingressTLSSecret) error {
+	// Nothing is actually applied to the target EdgeLB pool in dry-run mode, so the DC/OS secret store must be left untouched as well.
+	if manager.IsDryRun(it.manager) {
+		for _, t := range tlsSecrets {
+			it.logger.Debugf("dry-run: skipping reflection of secret %q into the dc/os secret store", t.SecretName)
+		}
+		return nil
+	}
+	for _, t := range tlsSecrets {
+		ctx, fn := context.WithTimeout(context.Background(), defaultSecretsManagerTimeout)
+		err := it.secretsManager.PutSecret(ctx, t.DCOSSecretPath, t.Value)
+		fn()
+		if err != nil {
+			return fmt.Errorf("failed to reflect secret %q into the dc/os secret store: %v", t.SecretName, err)
+		}
+	}
+	return nil
+}
+
+// deleteOrphanedDCOSSecrets deletes the DC/OS secrets that were referenced by the "previous" set of secrets of the target EdgeLB pool but that are not referenced by the "current" one.
+// It must only be called after the target EdgeLB pool has been successfully updated/deleted, as otherwise EdgeLB might fail to read the DC/OS secrets it depends on.
+// Failure to delete a DC/OS secret is reported as an event but doesn't cause translation to fail, as the EdgeLB pool has already been updated.
+func (it *IngressTranslator) deleteOrphanedDCOSSecrets(previous, current []*models.V2PoolSecretsItems0) {
+	// Nothing is actually applied to the target EdgeLB pool in dry-run mode, so the DC/OS secrets it references must be kept.
+	if manager.IsDryRun(it.manager) {
+		return
+	}
+	if err := DeleteDCOSSecrets(it.secretsManager, ComputeOrphanedDCOSSecretPaths(it.clusterName, previous, current)); err != nil {
+		it.recorder.Eventf(it.ingress, corev1.EventTypeWarning, constants.ReasonDCOSSecretCleanupFailed, "%v", err)
+		it.logger.Warn(err)
+	}
+}
+
 // createEdgeLBPool makes a decision on whether an EdgeLB pool should be created for the associated Ingress resource.
 // This decision is based on the EdgeLB pool creation strategy specified for the Ingress resource.
 // In case it should be created, it proceeds to actually creating it.
-func (it *IngressTranslator) createEdgeLBPool(backendMap IngressBackendNodePortMap) (*corev1.LoadBalancerStatus, error) {
+func (it *IngressTranslator) createEdgeLBPool(backendMap IngressBackendNodePortMap, endpointsMap IngressBackendEndpointsMap, tlsSecrets []ingressTLSSecret) (*corev1.LoadBalancerStatus, error) {
 	// If the pool creation strategy is "Never", the target EdgeLB pool must be provisioned manually.
 	// Hence, we should just exit.
 	if it.options.EdgeLBPoolCreationStrategy == constants.EdgeLBPoolCreationStrategyNever {
@@ -201,32 +463,69 @@ func (it *IngressTranslator) createEdgeLBPool(backendMap IngressBackendNodePortM
 
 	// If the Ingress resource's ".status" field contains at least one IP/host, that means an EdgeLB pool has once existed, but has been deleted manually.
 	// Hence, and if the EdgeLB pool creation strategy is "Once", we should also just exit.
-	if len(it.ingress.Status.LoadBalancer.Ingress) > 0 && it.options.EdgeLBPoolCreationStrategy == constants.EdgeLBPoolCreationStrategyOnce {
+	// The ".status" field is not taken into account while the resource is being migrated to the target EdgeLB pool, as it reports the addresses of the old EdgeLB pool.
+	if len(it.ingress.Status.LoadBalancer.Ingress) > 0 && it.options.EdgeLBPoolCreationStrategy == constants.EdgeLBPoolCreationStrategyOnce && !isBeingMigratedTo(it.ingress, it.options.EdgeLBPoolName) {
 		return nil, fmt.Errorf("edgelb pool %q targeted by ingress %q has probably been manually deleted, and the pool creation strategy is %q", it.options.EdgeLBPoolName, kubernetesutil.Key(it.ingress), it.options.EdgeLBPoolCreationStrategy)
 	}
 
 	// At this point, we know that we must create the target EdgeLB pool based on the specified options and Ingress backend map.
-	pool := it.createEdgeLBPoolObject(backendMap)
+	pool, err := it.createEdgeLBPoolObject(backendMap, endpointsMap, tlsSecrets)
+	if err != nil {
+		return nil, err
+	}
 	// Print the compputed EdgeLB pool object in "spew" and JSON formats.
 	prettyprint.LogfSpew(log.Tracef, pool, "computed edgelb pool object for ingress %q", kubernetesutil.Key(it.ingress))
 	prettyprint.LogfJSON(log.Debugf, pool, "computed edgelb pool object for ingress %q", kubernetesutil.Key(it.ingress))
+	// Reflect the TLS secrets into the DC/OS secret store before creating the EdgeLB pool, as the latter depends on the former.
+	if err := it.reflectIngressTLSSecrets(tlsSecrets); err != nil {
+		return nil, err
+	}
 	ctx, fn := context.WithTimeout(context.Background(), defaultEdgeLBManagerTimeout)
 	defer fn()
 	if _, err := it.manager.CreatePool(ctx, pool); err != nil {
 		return nil, err
 	}
+	report := computeCreationReport(pool, it.owner())
+	reportEdgeLBPoolChange(it.manager, it.recorder, it.ingress, "created", report)
+	it.lastAppliedDiff = report.LastAppliedDiff()
+	it.poolChanged = true
+	it.appliedStateHash = computeAppliedStateHash(pool.Name, computeOwnedEdgeLBObjects(pool, it.isEdgeLBObjectOwned))
+	// A newly created EdgeLB pool cannot have been changed out-of-band.
+	it.reportedDriftHash = ""
+	it.appliedResources = computeEdgeLBPoolResources(it.options.BaseTranslationOptions).String()
+	it.appliedPoolPatch = string(it.options.EdgeLBPoolPatch)
 	// Compute and return the status of the load-balancer.
 	return computeLoadBalancerStatus(it.manager, pool.Name, it.clusterName, it.ingress), nil
 }
 
 // updateOrDeleteEdgeLBPool makes a decision on whether the specified EdgeLB pool should be updated/deleted based on the current status of the associated Ingress resource.
 // In case it should be updated/deleted, it proceeds to actually updating/deleting it.
-// TODO (@bcustodio) Decide whether we should also update the EdgeLB pool's role and its CPU/memory/size requests.
-func (it *IngressTranslator) updateOrDeleteEdgeLBPool(pool *models.V2Pool, backendMap IngressBackendNodePortMap) (*corev1.LoadBalancerStatus, error) {
+// The CPU and memory requests and the size of the EdgeLB pool are updated in-place as well, while its role and virtual network are never changed.
+func (it *IngressTranslator) updateOrDeleteEdgeLBPool(pool *models.V2Pool, backendMap IngressBackendNodePortMap, endpointsMap IngressBackendEndpointsMap, tlsSecrets []ingressTLSSecret) (*corev1.LoadBalancerStatus, error) {
+	// Keep a copy of the EdgeLB pool as reported by the EdgeLB API server so that we can later check whether it has drifted from the state last applied for the Ingress resource.
+	live, err := copyEdgeLBPool(pool)
+	if err != nil {
+		return nil, err
+	}
 	// Check whether the EdgeLB pool object must be updated.
-	wasChanged, report := it.updateEdgeLBPoolObject(pool, backendMap)
+	wasChanged, report, err := it.updateEdgeLBPoolObject(pool, backendMap, endpointsMap, tlsSecrets)
+	if err != nil {
+		return nil, err
+	}
+	// Check whether the EdgeLB objects owned by the Ingress resource have been changed out-of-band, unless the Ingress resource has been deleted.
+	if it.ingress.DeletionTimestamp == nil && kubernetesutil.IsEdgeLBIngress(it.ingress) {
+		drift, err := reconcileEdgeLBPoolDrift(it.ingress, it.options.BaseTranslationOptions, live, pool, wasChanged, &report, it.isEdgeLBObjectOwned, it.recorder, it.logger)
+		if err != nil {
+			return nil, err
+		}
+		wasChanged, it.appliedStateHash, it.reportedDriftHash = drift.MustUpdate, drift.AppliedStateHash, drift.ReportedDriftHash
+		// Record the resources requested for the EdgeLB pool, so that they are only applied again in case they change, unless the EdgeLB pool is not managed by the Ingress resource.
+		if it.options.EdgeLBPoolCreationStrategy != constants.EdgeLBPoolCreationStrategyNever {
+			it.appliedResources = computeEdgeLBPoolResources(it.options.BaseTranslationOptions).String()
+		}
+	}
 	// Report the status of the EdgeLB pool.
-	prettyprint.LogfSpew(log.Tracef, report, "inspection report for edgelb pool %q", pool.Name)
+	prettyprint.LogfJSON(log.Debugf, report, "inspection report for edgelb pool %q", pool.Name)
 	// Print the compputed EdgeLB pool object in "spew" and JSON formats.
 	prettyprint.LogfSpew(log.Tracef, pool, "computed edgelb pool object for ingress %q", kubernetesutil.Key(it.ingress))
 	prettyprint.LogfJSON(log.Debugf, pool, "computed edgelb pool object for ingress %q", kubernetesutil.Key(it.ingress))
@@ -249,43 +548,65 @@ func (it *IngressTranslator) updateOrDeleteEdgeLBPool(pool *models.V2Pool, backe
 		if err := it.manager.DeletePool(ctx, pool.Name); err != nil {
 			return nil, err
 		}
+		reportEdgeLBPoolChange(it.manager, it.recorder, it.ingress, "deleted", report)
+		it.deleteOrphanedDCOSSecrets(live.Secrets, nil)
 		return &corev1.LoadBalancerStatus{}, nil
 	}
 
 	// The pool is not empty, so we proceed to actually updating it and reporting its status.
+	// The TLS secrets must be reflected into the DC/OS secret store before updating the EdgeLB pool, as the latter depends on the former.
 	it.logger.Debugf("edgelb pool %q must be updated", pool.Name)
+	if err := it.reflectIngressTLSSecrets(tlsSecrets); err != nil {
+		return nil, err
+	}
 	if _, err := it.manager.UpdatePool(ctx, pool); err != nil {
 		return nil, err
 	}
+	reportEdgeLBPoolChange(it.manager, it.recorder, it.ingress, "updated", report)
+	it.lastAppliedDiff = report.LastAppliedDiff()
+	it.poolChanged = true
+	it.deleteOrphanedDCOSSecrets(live.Secrets, pool.Secrets)
 	return computeLoadBalancerStatus(it.manager, pool.Name, it.clusterName, it.ingress), nil
 }
 
+// computeEdgeLBBackendsAndFrontends computes the EdgeLB backends and frontends that correspond to the current Ingress resource.
+// The JSON merge patches requested for EdgeLB backends and frontends (if any) are applied to the computed objects.
+func (it *IngressTranslator) computeEdgeLBBackendsAndFrontends(backendMap IngressBackendNodePortMap, endpointsMap IngressBackendEndpointsMap, tlsSecrets []ingressTLSSecret) ([]*models.V2Backend, []*models.V2Frontend, error) {
+	backends := computeEdgeLBBackendsForIngress(it.clusterName, it.ingress, backendMap, endpointsMap, it.options)
+	if err := patchEdgeLBBackends(backends, it.options.BaseTranslationOptions); err != nil {
+		return nil, nil, err
+	}
+	frontends := computeEdgeLBFrontendsForIngress(it.clusterName, it.ingress, it.options, tlsSecrets)
+	if err := patchEdgeLBFrontends(frontends, it.options.BaseTranslationOptions); err != nil {
+		return nil, nil, err
+	}
+	return backends, frontends, nil
+}
+
 // createEdgeLBPoolObject creates an EdgeLB pool object that satisfies the current Ingress resource.
-func (it *IngressTranslator) createEdgeLBPoolObject(backendMap IngressBackendNodePortMap) *models.V2Pool {
-	// Iterate over Ingress backends and their target node ports, and create the corresponding EdgeLB backend objects.
-	backends := make([]*models.V2Backend, 0, len(backendMap))
-	for backend, nodePort := range backendMap {
-		backends = append(backends, computeEdgeLBBackendForIngressBackend(it.clusterName, it.ingress, backend, nodePort))
-	}
-	// Sort backends alphabetically in order to get a predictable output, as ranging over a map can produce different results every time.
-	sort.SliceStable(backends, func(i, j int) bool {
-		return backends[i].Name < backends[j].Name
-	})
-	// Create the (single) EdgeLB frontend object.
-	frontend := computeEdgeLBFrontendForIngress(it.clusterName, it.ingress, it.options)
+func (it *IngressTranslator) createEdgeLBPoolObject(backendMap IngressBackendNodePortMap, endpointsMap IngressBackendEndpointsMap, tlsSecrets []ingressTLSSecret) (*models.V2Pool, error) {
+	// Create the EdgeLB backend and frontend objects required by the rules of the current Ingress resource.
+	backends, frontends, err := it.computeEdgeLBBackendsAndFrontends(backendMap, endpointsMap, tlsSecrets)
+	if err != nil {
+		return nil, err
+	}
 	// Create the base EdgeLB pool object.
 	p := &models.V2Pool{
 		Name:      it.options.EdgeLBPoolName,
 		Namespace: &it.poolGroup,
 		Role:      it.options.EdgeLBPoolRole,
-		Cpus:      float64(it.options.EdgeLBPoolCpus.MilliValue()) / 1000,
-		Mem:       int32(it.options.EdgeLBPoolMem.Value() / (1024 * 1024)),
+		Cpus:      computeEdgeLBPoolCpus(it.options.BaseTranslationOptions),
+		Mem:       computeEdgeLBPoolMem(it.options.BaseTranslationOptions),
 		Count:     pointers.NewInt32(int32(it.options.EdgeLBPoolSize)),
 		Haproxy: &models.V2Haproxy{
 			Backends:  backends,
-			Frontends: []*models.V2Frontend{frontend},
+			Frontends: frontends,
 		},
 	}
+	// Request for the TLS secrets to be made available to the EdgeLB pool if applicable.
+	for _, t := range tlsSecrets {
+		p.Secrets = append(p.Secrets, computeEdgeLBPoolSecretForIngressTLSSecret(t))
+	}
 	// Request for the EdgeLB pool to join the requested DC/OS virtual network if applicable.
 	if it.options.EdgeLBPoolNetwork != constants.EdgeLBHostNetwork {
 		p.VirtualNetworks = []*models.V2PoolVirtualNetworksItems0{
@@ -294,74 +615,112 @@ func (it *IngressTranslator) createEdgeLBPoolObject(backendMap IngressBackendNod
 			},
 		}
 	}
-	return p
+	// Apply the JSON merge patch requested for the EdgeLB pool (if any).
+	if _, err := patchEdgeLBPool(p, it.options.EdgeLBPoolPatch, "", nil); err != nil {
+		return nil, err
+	}
+	return p, nil
 }
 
 // updateEdgeLBPoolObject updates the specified EdgeLB pool object in order to reflect the status of the current Ingress resource.
 // It modifies the specified EdgeLB pool in-place and returns a value indicating whether the EdgeLB pool object contains changes.
 // EdgeLB backends and frontends (the "objects") are added/modified/deleted according to the following rules:
-// * If the object is not owned by the current Ingress resource, it is left untouched.
+// * If the object is not owned by the current Ingress resource, it is left untouched (except for EdgeLB frontends owned by other Ingress resources, which are updated so that they serve the rules of the current Ingress resource if they share a bind port with its EdgeLB frontends).
 // * If the current Ingress resource has been marked for deletion, it is removed.
-// * If the object is an EdgeLB backend owned by the current Ingress resource but the corresponding Ingress backend has disappeared, it is removed.
-// * If the object is owned by the current Ingress resource and the corresponding Ingress backend still exists (or the object is an EdgeLB frontend), it is checked for correctness and updated if necessary.
-// Furthermore, Ingress backends are iterated over in order to understand which EdgeLB backends must be added to the EdgeLB pool.
-func (it *IngressTranslator) updateEdgeLBPoolObject(pool *models.V2Pool, backendMap IngressBackendNodePortMap) (wasChanged bool, report poolInspectionReport) {
-	// ingressDeleted holds whether the Ingress resource has been deleted or its "kubernetes.io/ingress.class" has changed.
-	ingressDeleted := it.ingress.DeletionTimestamp != nil || it.ingress.Annotations[constants.EdgeLBIngressClassAnnotationKey] != constants.EdgeLBIngressClassAnnotationValue
-
-	// visitedBackends holds the set of IngressBackend objects that have been visited (i.e. that exist in "pool").
-	// It is used to understand which Ingress backends currently have EdgeLB backends in the EdgeLB pool, and which don't.
-	visitedIngressBackends := make(map[extsv1beta1.IngressBackend]bool, len(pool.Haproxy.Backends))
+// * If the object is an EdgeLB backend owned by the current Ingress resource but is no longer required (e.g. the corresponding Ingress backend has disappeared or a path is no longer rewritten), it is removed.
+// * If the object is an EdgeLB frontend owned by the current Ingress resource but is no longer required (e.g. the HTTPS frontend when no valid TLS secrets exist), it is removed.
+// * If the object is owned by the current Ingress resource and is still required, it is checked for correctness and updated if necessary.
+// Furthermore, desired EdgeLB backends and frontends are iterated over in order to understand which ones must be added to the EdgeLB pool.
+// EdgeLB pool secrets owned by the current Ingress resource are handled in a similar fashion.
+func (it *IngressTranslator) updateEdgeLBPoolObject(pool *models.V2Pool, backendMap IngressBackendNodePortMap, endpointsMap IngressBackendEndpointsMap, tlsSecrets []ingressTLSSecret) (wasChanged bool, report poolInspectionReport, err error) {
+	// ingressDeleted holds whether the Ingress resource has been deleted or its ingress class no longer selects EdgeLB.
+	ingressDeleted := it.ingress.DeletionTimestamp != nil || !kubernetesutil.IsEdgeLBIngress(it.ingress)
+	// owner identifies the current Ingress resource in the EdgeLB pool inspection report.
+	owner := it.owner()
+	report.Pool = pool.Name
+
+	// Compute the EdgeLB backends and frontends that correspond to the current Ingress resource.
+	backends, frontends, err := it.computeEdgeLBBackendsAndFrontends(backendMap, endpointsMap, tlsSecrets)
+	if err != nil {
+		return false, report, err
+	}
+
+	// desiredBackends holds the set of EdgeLB backends that correspond to the current Ingress resource, indexed by name.
+	desiredBackends := make(map[string]*models.V2Backend, len(backendMap))
+	for _, backend := range backends {
+		desiredBackends[backend.Name] = backend
+	}
+	// visitedBackends holds the set of names of EdgeLB backends that have been visited (i.e. that exist in "pool").
+	// It is used to understand which EdgeLB backends must be created after we have iterated over all EdgeLB backends present in the EdgeLB pool.
+	visitedBackends := make(map[string]bool, len(desiredBackends))
 	// updatedBackends holds the set of updated EdgeLB backends.
 	// It is used as the final set of EdgeLB backends for the EdgeLB pool if we find out we need to update it.
 	updatedBackends := make([]*models.V2Backend, 0, len(pool.Haproxy.Backends))
 
 	// Iterate over the EdgeLB pool's EdgeLB backends and check whether each one is owned by the current Ingress.
 	// In case an EdgeLB backend isn't owned by the current Ingress, it is left unchanged and added to the set of "updated" EdgeLB backends.
-	// Otherwise, it is checked for correctness and, if necessary, replaced with the computed EdgeLB backend for the target Ingress backend.
+	// Otherwise, it is checked for correctness and, if necessary, replaced with the computed EdgeLB backend.
 	for _, backend := range pool.Haproxy.Backends {
 		// Parse the name of the EdgeLB backend in order to determine if the current Ingress owns it.
 		// If the current EdgeLB backend isn't owned by the current Ingress, it is left unchanged.
 		backendMetadata, err := computeIngressOwnedEdgeLBObjectMetadata(backend.Name)
 		if err != nil || !backendMetadata.IsOwnedBy(it.clusterName, it.ingress) {
 			updatedBackends = append(updatedBackends, backend)
-			report.Report("no changes required for backend %q (not owned by %s)", backend.Name, kubernetesutil.Key(it.ingress))
+			report.Keep(EdgeLBObjectTypeBackend, backend.Name, ComputeEdgeLBObjectOwner(it.clusterName, backend.Name))
 			continue
 		}
 		// At this point we know the current EdgeLB backend is owned by the current Ingress.
 		// Check whether the Ingress resource has been deleted, and skip (i.e. remove) the EdgeLB backend if it has.
 		if ingressDeleted {
 			wasChanged = true
-			report.Report("must delete backend %q as %q was deleted", backend.Name, kubernetesutil.Key(it.ingress))
+			report.Delete(EdgeLBObjectTypeBackend, backend.Name, owner, "%q was deleted", kubernetesutil.Key(it.ingress))
 			continue
 		}
-		// Check whether the Ingress backend that corresponds to the current EdgeLB backend is still present in the Ingress resource.
-		// If it doesn't, skip (i.e. remove) the EdgeLB backend.
-		currentIngressBackend := *backendMetadata.IngressBackend
-		if _, exists := backendMap[currentIngressBackend]; !exists {
+		// Check whether the current EdgeLB backend is still desired (e.g. the corresponding Ingress backend is still present in the Ingress resource).
+		// If it isn't, skip (i.e. remove) the EdgeLB backend.
+		desiredBackend, desired := desiredBackends[backend.Name]
+		if !desired {
 			wasChanged = true
-			report.Report("must delete edgelb backend %q as the corresponding ingress backend is missing from %q", backend.Name, kubernetesutil.Key(it.ingress))
+			report.Delete(EdgeLBObjectTypeBackend, backend.Name, owner, "it is no longer required by %q", kubernetesutil.Key(it.ingress))
 			continue
 		}
-		// At this point we know the Ingress backend corresponding to the current EdgeLB backend still exists.
-		// Mark the current Ingress backend as having been visited.
-		visitedIngressBackends[currentIngressBackend] = true
-		// Compute the desired state for the current EdgeLB backend.
-		// In case differences are detected, we replace  the existing EdgeLB backend with the computed one.
-		desiredBackend := computeEdgeLBBackendForIngressBackend(it.clusterName, it.ingress, currentIngressBackend, backendMap[currentIngressBackend])
+		// Mark the EdgeLB backend as having been visited.
+		visitedBackends[backend.Name] = true
+		// Check the current EdgeLB backend against its desired state.
+		// In case differences are detected, we replace the existing EdgeLB backend with the computed one.
 		if !reflect.DeepEqual(backend, desiredBackend) {
 			wasChanged = true
 			updatedBackends = append(updatedBackends, desiredBackend)
-			report.Report("must modify backend %q", backend.Name)
+			report.Modify(EdgeLBObjectTypeBackend, backend.Name, owner, backend, desiredBackend)
 		} else {
 			updatedBackends = append(updatedBackends, backend)
-			report.Report("no changes required for backend %q", backend.Name)
+			report.Keep(EdgeLBObjectTypeBackend, backend.Name, owner)
 		}
 	}
 
-	// frontendExists holds whether the EdgeLB frontend that corresponds to the current Ingress resource exists in the EdgeLB pool.
-	// It is used to understand whether we need to create it or not after we have iterated all EdgeLB frontends present in the EdgeLB pool.
-	frontendExists := false
+	// mergedFrontends holds the EdgeLB frontends not owned by the current Ingress resource, as well as the ones that correspond to it (unless it has been deleted).
+	mergedFrontends := make([]*models.V2Frontend, 0, len(pool.Haproxy.Frontends)+len(frontends))
+	for _, frontend := range pool.Haproxy.Frontends {
+		if frontendMetadata, err := computeIngressOwnedEdgeLBObjectMetadata(frontend.Name); err != nil || !frontendMetadata.IsOwnedBy(it.clusterName, it.ingress) {
+			mergedFrontends = append(mergedFrontends, frontend)
+		}
+	}
+	if !ingressDeleted {
+		mergedFrontends = append(mergedFrontends, frontends...)
+	}
+	// mergedMaps holds, for each bind port, the mapping between the rules of all the Ingress resources whose EdgeLB frontends use it and EdgeLB backends, sorted by specificity.
+	mergedMaps := computeMergedEdgeLBLinkBackendMaps(mergedFrontends)
+
+	// desiredFrontends holds the set of EdgeLB frontends that correspond to the current Ingress resource, indexed by name.
+	// Each of them serves the rules of all the Ingress resources whose EdgeLB frontends share its bind port, so that rules are matched in order of decreasing specificity across all of them.
+	desiredFrontends := make(map[string]*models.V2Frontend)
+	for idx, frontend := range frontends {
+		frontends[idx] = computeEdgeLBFrontendWithMergedLinkBackendMap(frontend, mergedMaps)
+		desiredFrontends[frontend.Name] = frontends[idx]
+	}
+	// visitedFrontends holds the set of names of EdgeLB frontends that have been visited (i.e. that exist in "pool").
+	// It is used to understand which EdgeLB frontends must be created after we have iterated over all EdgeLB frontends present in the EdgeLB pool.
+	visitedFrontends := make(map[string]bool, len(desiredFrontends))
 	// updatedFrontends holds the set of updated EdgeLB frontends.
 	// It is used as the final set of EdgeLB frontends for the EdgeLB pool if we find out we need to update it.
 	updatedFrontends := make([]*models.V2Frontend, 0, len(pool.Haproxy.Frontends))
@@ -372,54 +731,103 @@ func (it *IngressTranslator) updateEdgeLBPoolObject(pool *models.V2Pool, backend
 	for _, frontend := range pool.Haproxy.Frontends {
 		// Parse the name of the EdgeLB frontend in order to determine if the current Ingress owns it.
 		// If the current EdgeLB frontend isn't owned by the current Ingress, it is left unchanged.
+		// EdgeLB frontends owned by other Ingress resources are only updated so that they serve the current Ingress resource's (possibly removed) rules in case they share a bind port with its EdgeLB frontends.
 		frontendMetadata, err := computeIngressOwnedEdgeLBObjectMetadata(frontend.Name)
 		if err != nil || !frontendMetadata.IsOwnedBy(it.clusterName, it.ingress) {
-			updatedFrontends = append(updatedFrontends, frontend)
-			report.Report("no changes required for frontend %q (not owned by %s)", frontend.Name, kubernetesutil.Key(it.ingress))
+			if mergedFrontend := computeEdgeLBFrontendWithMergedLinkBackendMap(frontend, mergedMaps); mergedFrontend != frontend {
+				wasChanged = true
+				updatedFrontends = append(updatedFrontends, mergedFrontend)
+				report.Modify(EdgeLBObjectTypeFrontend, frontend.Name, ComputeEdgeLBObjectOwner(it.clusterName, frontend.Name), frontend, mergedFrontend)
+			} else {
+				updatedFrontends = append(updatedFrontends, frontend)
+				report.Keep(EdgeLBObjectTypeFrontend, frontend.Name, ComputeEdgeLBObjectOwner(it.clusterName, frontend.Name))
+			}
 			continue
 		}
 		// At this point we know the current EdgeLB frontend is owned by the current Ingress.
 		// Check whether the Ingress resource has been deleted, and skip (i.e. remove) the EdgeLB frontend if it has.
 		if ingressDeleted {
 			wasChanged = true
-			report.Report("must delete frontend %q as %q was deleted", frontend.Name, kubernetesutil.Key(it.ingress))
+			report.Delete(EdgeLBObjectTypeFrontend, frontend.Name, owner, "%q was deleted", kubernetesutil.Key(it.ingress))
+			continue
+		}
+		// Check whether the current EdgeLB frontend is still desired (e.g. the EdgeLB HTTPS frontend is not desired when no valid TLS secrets exist).
+		// If it isn't, skip (i.e. remove) the EdgeLB frontend.
+		desiredFrontend, desired := desiredFrontends[frontend.Name]
+		if !desired {
+			wasChanged = true
+			report.Delete(EdgeLBObjectTypeFrontend, frontend.Name, owner, "it is no longer required by %q", kubernetesutil.Key(it.ingress))
 			continue
 		}
-		// Mark the EdgeLB frontend as existing.
-		frontendExists = true
-		// Compute the desired state for the current EdgeLB frontend.
+		// Mark the EdgeLB frontend as having been visited.
+		visitedFrontends[frontend.Name] = true
+		// Check the current EdgeLB frontend against its desired state.
 		// In case differences are detected, we replace the existing EdgeLB frontend with the computed one.
-		desiredFrontend := computeEdgeLBFrontendForIngress(it.clusterName, it.ingress, it.options)
 		if !reflect.DeepEqual(frontend, desiredFrontend) {
 			wasChanged = true
 			updatedFrontends = append(updatedFrontends, desiredFrontend)
-			report.Report("must modify frontend %q", frontend.Name)
+			report.Modify(EdgeLBObjectTypeFrontend, frontend.Name, owner, frontend, desiredFrontend)
 		} else {
 			updatedFrontends = append(updatedFrontends, desiredFrontend)
-			report.Report("no changes required for frontend %q", frontend.Name)
+			report.Keep(EdgeLBObjectTypeFrontend, frontend.Name, owner)
 		}
-		// There must be a single EdgeLB frontend per Ingress object.
-		// Hence, we may exit the loop at this point.
-		break
+	}
+
+	// desiredSecrets holds the set of EdgeLB pool secrets that correspond to the current Ingress resource, indexed by file name.
+	desiredSecrets := make(map[string]*models.V2PoolSecretsItems0, len(tlsSecrets))
+	for _, t := range tlsSecrets {
+		desiredSecrets[t.FileName] = computeEdgeLBPoolSecretForIngressTLSSecret(t)
+	}
+	// visitedSecrets holds the set of file names of EdgeLB pool secrets that have been visited (i.e. that exist in "pool").
+	visitedSecrets := make(map[string]bool, len(desiredSecrets))
+	// updatedSecrets holds the set of updated EdgeLB pool secrets.
+	updatedSecrets := make([]*models.V2PoolSecretsItems0, 0, len(pool.Secrets))
+
+	// Iterate over the EdgeLB pool's secrets and check whether each one is owned by the current Ingress.
+	// As the name of the file holding each secret includes a hash of its contents, an EdgeLB pool secret that is no longer desired is removed, while a missing one is added.
+	for _, secret := range pool.Secrets {
+		if !isEdgeLBSecretOwnedByIngress(it.clusterName, it.ingress, secret) {
+			updatedSecrets = append(updatedSecrets, secret)
+			continue
+		}
+		desiredSecret, desired := desiredSecrets[secret.File]
+		if ingressDeleted || !desired || !reflect.DeepEqual(secret, desiredSecret) {
+			wasChanged = true
+			report.Delete(EdgeLBObjectTypeSecret, secret.File, owner, "it is no longer required by %q", kubernetesutil.Key(it.ingress))
+			continue
+		}
+		visitedSecrets[secret.File] = true
+		updatedSecrets = append(updatedSecrets, secret)
+		report.Keep(EdgeLBObjectTypeSecret, secret.File, owner)
 	}
 
 	// Replace the EdgeLB pool's backends and frontends with the (possibly empty) updated lists.
 	pool.Haproxy.Backends, pool.Haproxy.Frontends = updatedBackends, updatedFrontends
+	// Replace the EdgeLB pool's secrets with the (possibly empty) updated list.
+	// In order to not introduce spurious changes, we keep the EdgeLB pool's secrets unset if there is none.
+	if len(updatedSecrets) > 0 {
+		pool.Secrets = updatedSecrets
+	} else {
+		pool.Secrets = nil
+	}
 
-	// If the current Ingress resource was deleted, there is nothing else to do.
+	// If the current Ingress resource was deleted, there is nothing else to do other than resetting the fields set by the JSON merge patch last applied for it.
 	if ingressDeleted {
-		return wasChanged, report
+		patched, err := it.applyEdgeLBPoolPatch(pool, ingressDeleted, &report)
+		if err != nil {
+			return false, report, err
+		}
+		return wasChanged || patched, report, nil
 	}
 
-	// Iterate over all desired Ingress backends in order to understand whether there are new ones.
-	// For every Ingress backend, if the corresponding EdgeLB backend is not present in the EdgeLB pool, we add it.
+	// Iterate over all desired EdgeLB backends in order to understand whether there are new ones.
+	// For every desired EdgeLB backend that is not present in the EdgeLB pool, we add it.
 	newBackends := make([]*models.V2Backend, 0)
-	for ingressBackend, nodePort := range backendMap {
-		if _, visited := visitedIngressBackends[ingressBackend]; !visited {
+	for name, desiredBackend := range desiredBackends {
+		if !visitedBackends[name] {
 			wasChanged = true
-			desiredBackend := computeEdgeLBBackendForIngressBackend(it.clusterName, it.ingress, ingressBackend, nodePort)
 			newBackends = append(newBackends, desiredBackend)
-			report.Report("must create backend %q", desiredBackend.Name)
+			report.Create(EdgeLBObjectTypeBackend, desiredBackend.Name, owner)
 		}
 	}
 	// Sort new EdgeLB backends by their name before adding them to the EdgeLB pool in order to guarantee a predictable order.
@@ -428,14 +836,67 @@ func (it *IngressTranslator) updateEdgeLBPoolObject(pool *models.V2Pool, backend
 	})
 	pool.Haproxy.Backends = append(pool.Haproxy.Backends, newBackends...)
 
-	// If the EdgeLB frontend for the current Ingress resource does not exist in the EdgeLB pool, create it now.
-	if !frontendExists {
+	// Iterate over all desired EdgeLB frontends in order to understand whether there are new ones.
+	// The order in which desired EdgeLB frontends are computed is preserved in order to guarantee a predictable order.
+	for _, desiredFrontend := range frontends {
+		if !visitedFrontends[desiredFrontend.Name] {
+			wasChanged = true
+			pool.Haproxy.Frontends = append(pool.Haproxy.Frontends, desiredFrontend)
+			report.Create(EdgeLBObjectTypeFrontend, desiredFrontend.Name, owner)
+		}
+	}
+
+	// Iterate over all desired EdgeLB pool secrets in order to understand whether there are new ones.
+	for _, t := range tlsSecrets {
+		if !visitedSecrets[t.FileName] {
+			wasChanged = true
+			pool.Secrets = append(pool.Secrets, desiredSecrets[t.FileName])
+			report.Create(EdgeLBObjectTypeSecret, t.FileName, owner)
+		}
+	}
+
+	// Update the CPU and memory requests and the size of the EdgeLB pool as required.
+	if updateEdgeLBPoolResources(pool, it.options.BaseTranslationOptions, GetEdgeLBPoolAppliedResources(it.ingress), &report) {
+		wasChanged = true
+	}
+
+	// Apply the JSON merge patch requested for the EdgeLB pool (if any).
+	patched, err := it.applyEdgeLBPoolPatch(pool, ingressDeleted, &report)
+	if err != nil {
+		return false, report, err
+	}
+	if patched {
 		wasChanged = true
-		desiredFrontend := computeEdgeLBFrontendForIngress(it.clusterName, it.ingress, it.options)
-		pool.Haproxy.Frontends = append(pool.Haproxy.Frontends, desiredFrontend)
-		report.Report("must create frontend %q", desiredFrontend.Name)
 	}
 
 	// Return a value indicating whether the pool was changed, and the EdgeLB pool inspection report.
-	return wasChanged, report
+	return wasChanged, report, nil
+}
+
+// applyEdgeLBPoolPatch applies the JSON merge patch requested for the specified EdgeLB pool (if any), keeping a copy of the unpatched EdgeLB pool so that the changes can be recorded in the specified EdgeLB pool inspection report.
+// In case the Ingress resource has been deleted, only the fields set by the JSON merge patch last applied for it are reset.
+// It modifies the specified EdgeLB pool in-place and returns a value indicating whether it was changed.
+func (it *IngressTranslator) applyEdgeLBPoolPatch(pool *models.V2Pool, ingressDeleted bool, report *poolInspectionReport) (bool, error) {
+	unpatched, err := copyEdgeLBPool(pool)
+	if err != nil {
+		return false, err
+	}
+	var patch []byte
+	if !ingressDeleted {
+		patch = it.options.EdgeLBPoolPatch
+	}
+	// Make sure that the requested JSON merge patch doesn't diverge from the one applied for other resources sharing the pool, and that the fields these resources rely on are not reset.
+	shared, err := computeSharedEdgeLBPoolPatch(it.kubeCache, it.clusterName, pool, it.owner(), patch)
+	if err != nil {
+		return false, err
+	}
+	patched, err := patchEdgeLBPool(pool, patch, GetEdgeLBPoolAppliedPatch(it.ingress), shared)
+	if err != nil {
+		return false, err
+	}
+	it.appliedPoolPatch = string(patch)
+	if patched {
+		report.ModifyPool(computeFieldDiffs(unpatched, pool)...)
+	}
+	return patched, nil
 }
@@ -1,11 +1,18 @@
 package translator
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/mesosphere/dcos-edge-lb/models"
+	corev1 "k8s.io/api/core/v1"
 	extsv1beta1 "k8s.io/api/extensions/v1beta1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
@@ -19,18 +26,74 @@ const (
 	// edgeLBIngressBackendNameFormatString is the format string used to compute the name for an EdgeLB backend corresponding to a given Ingress backend.
 	// The resulting name is of the form "<cluster-name>:<ingress-namespace>:<ingress-name>:<service-name>:<service-port>".
 	edgeLBIngressBackendNameFormatString = "%s" + separator + "%s" + separator + "%s" + separator + "%s" + separator + "%s"
+	// edgeLBIngressRewriteBackendNameFormatString is the format string used to compute the name for an EdgeLB backend that rewrites the path of requests matching a given Ingress rule.
+	// The resulting name is of the form "<cluster-name>:<ingress-namespace>:<ingress-name>:<service-name>:<service-port>:<rewrite-hash>".
+	edgeLBIngressRewriteBackendNameFormatString = "%s" + separator + "%s"
+	// edgeLBRewriteDirectiveFormatString is the format string used to compute the HAProxy directive that rewrites the request line of requests whose path must be rewritten.
+	edgeLBRewriteDirectiveFormatString = "reqrep %s %s"
+	// rewriteHashLength is the number of (hexadecimal) characters of the hash identifying a path rewrite that are used when computing the names of EdgeLB backends.
+	rewriteHashLength = 10
 	// edgeLBIngressFrontendNameFormatString is the format string used to compute the name for an EdgeLB frontend corresponding to a given Ingress resource.
 	// The resulting name is of the form "<cluster-name>:<ingress-namespace>:<ingress-name>".
 	edgeLBIngressFrontendNameFormatString = "%s" + separator + "%s" + separator + "%s"
+	// edgeLBIngressHTTPSFrontendNameFormatString is the format string used to compute the name for the EdgeLB HTTPS frontend corresponding to a given Ingress resource.
+	// The resulting name is of the form "<cluster-name>:<ingress-namespace>:<ingress-name>:https".
+	edgeLBIngressHTTPSFrontendNameFormatString = edgeLBIngressFrontendNameFormatString + separator + edgeLBIngressHTTPSFrontendNameSuffix
+	// edgeLBIngressHTTPSFrontendNameSuffix is the suffix used in the name of EdgeLB HTTPS frontends corresponding to a given Ingress resource.
+	edgeLBIngressHTTPSFrontendNameSuffix = "https"
+	// edgeLBSecretFileNameSeparator is the separator used to compute the name of the files holding certificates reflected from Kubernetes secrets.
+	// "__" is used as the separator since it cannot be part of the name of a Kubernetes resource nor of the name of a DC/OS service.
+	edgeLBSecretFileNameSeparator = "__"
+	// edgeLBSecretFileNameFormatString is the format string used to compute the name of the file holding the certificate reflected from a given Kubernetes secret.
+	// The resulting name is of the form "<cluster-name>__<ingress-namespace>__<ingress-name>__<secret-name>__<content-hash>".
+	edgeLBSecretFileNameFormatString = "%s" + edgeLBSecretFileNameSeparator + "%s" + edgeLBSecretFileNameSeparator + "%s" + edgeLBSecretFileNameSeparator + "%s" + edgeLBSecretFileNameSeparator + "%s"
+	// edgeLBSecretFileNamePrefixFormatString is the format string used to compute the prefix shared by the names of all the files holding certificates used by a given Ingress resource.
+	edgeLBSecretFileNamePrefixFormatString = "%s" + edgeLBSecretFileNameSeparator + "%s" + edgeLBSecretFileNameSeparator + "%s" + edgeLBSecretFileNameSeparator
+	// edgeLBSecretFileReferenceFormatString is the format string used to reference a file holding a certificate from an EdgeLB frontend.
+	edgeLBSecretFileReferenceFormatString = "$SECRETS/%s"
+	// dcosSecretPathFormatString is the format string used to compute the path of the DC/OS secret that a given Kubernetes secret is reflected into.
+	// The resulting path is of the form "<pool-group>/<pool-name>/<cluster-name>__<secret-namespace>__<secret-name>".
+	dcosSecretPathFormatString = "%s/%s/%s" + edgeLBSecretFileNameSeparator + "%s" + edgeLBSecretFileNameSeparator + "%s"
+	// secretContentHashLength is the number of (hexadecimal) characters of the hash of a secret's contents that are used when computing file names.
+	secretContentHashLength = 10
 	// edgeLBPathCatchAllRegex is the regular expression used by EdgeLB to match all paths.
 	edgeLBPathCatchAllRegex = "^.*$"
-	// edgeLBPathRegexFormatString is the format string used to compute the regular expression used by EdgeLB to match a given path.
-	edgeLBPathRegexFormatString = "^%s$"
+	// edgeLBHostRegexFormatString is the format string used to compute the regular expression used by EdgeLB to match a given wildcard host.
+	// The "Host" header may include a port number, which is ignored.
+	edgeLBHostRegexFormatString = "^%s(:[0-9]+)?$"
+	// wildcardHostPrefix is the prefix of wildcard hosts (e.g. "*.apps.example.com").
+	wildcardHostPrefix = "*."
+	// wildcardHostLabelRegex is the regular expression that matches the (single) DNS label replaced by the wildcard in a wildcard host.
+	wildcardHostLabelRegex = "[^.:]+"
+	// edgeLBStickySessionsCookieFormatString is the format string used to compute the HAProxy "cookie" directive used to implement sticky sessions.
+	// The cookie is inserted by HAProxy in responses, removed from requests before they are forwarded to the backend ("indirect") and never cached ("nocache").
+	edgeLBStickySessionsCookieFormatString = "cookie %s insert indirect nocache"
+	// edgeLBStickySessionsCookieWithMaxLifeFormatString is the format string used to compute the HAProxy "cookie" directive used to implement sticky sessions with a cookie having a maximum lifetime (in seconds).
+	edgeLBStickySessionsCookieWithMaxLifeFormatString = edgeLBStickySessionsCookieFormatString + " maxlife %ds"
 )
 
 // IngressBackendNodePortMap represents a mapping between Ingress backends and their target node ports.
 type IngressBackendNodePortMap map[extsv1beta1.IngressBackend]int32
 
+// IngressBackendEndpointsMap represents a mapping between Ingress backends and the addresses (of pods or Kubernetes nodes) they target.
+// Ingress backends absent from it target their node ports on every Kubernetes node.
+type IngressBackendEndpointsMap map[extsv1beta1.IngressBackend]backendTarget
+
+// ingressTLSSecret groups together information about a Kubernetes secret referenced by the ".spec.tls" field of an Ingress resource.
+type ingressTLSSecret struct {
+	// Hosts is the list of hosts covered by the certificate contained in the Kubernetes secret.
+	// An empty list means that the certificate is to be used for all hosts.
+	Hosts []string
+	// SecretName is the name of the Kubernetes secret.
+	SecretName string
+	// DCOSSecretPath is the path of the DC/OS secret the Kubernetes secret is reflected into.
+	DCOSSecretPath string
+	// FileName is the name of the file holding the certificate in the EdgeLB pool.
+	FileName string
+	// Value is the PEM-encoded certificate and private key contained in the Kubernetes secret.
+	Value string
+}
+
 // ingressOwnedEdgeLBObjectMetadata groups together information about the Ingress resource that owns a given EdgeLB backend/frontend.
 type ingressOwnedEdgeLBObjectMetadata struct {
 	// ClusterName is the name of the Kubernetes cluster to which the Ingress resource belongs.
@@ -49,9 +112,14 @@ func (m *ingressOwnedEdgeLBObjectMetadata) IsOwnedBy(clusterName string, ingress
 }
 
 // computeEdgeLBBackendForIngressBackend computes the EdgeLB backend that corresponds to the specified Ingress backend.
-func computeEdgeLBBackendForIngressBackend(clusterName string, ingress *extsv1beta1.Ingress, backend extsv1beta1.IngressBackend, nodePort int32) *models.V2Backend {
-	return &models.V2Backend{
-		Balance: constants.EdgeLBBackendBalanceLeastConnections,
+// The EdgeLB backend uses the load-balancing algorithm and health checks specified in "options" (or the default ones in case none are specified), and implements cookie-based sticky sessions and HSTS if requested.
+func computeEdgeLBBackendForIngressBackend(clusterName string, ingress *extsv1beta1.Ingress, backend extsv1beta1.IngressBackend, nodePort int32, options IngressTranslationOptions) *models.V2Backend {
+	balance := options.EdgeLBBackendBalance
+	if balance == "" {
+		balance = DefaultEdgeLBBackendBalance
+	}
+	res := &models.V2Backend{
+		Balance: balance,
 		Name:    computeEdgeLBBackendNameForIngressBackend(clusterName, ingress, backend),
 		// TODO (@bcustodio) Understand if/when we need to use HTTPS here.
 		Protocol: models.V2ProtocolHTTP,
@@ -93,6 +161,32 @@ func computeEdgeLBBackendForIngressBackend(clusterName string, ingress *extsv1be
 			},
 		},
 	}
+	// If sticky sessions have been requested, instruct HAProxy to insert a cookie identifying the server that handled the first request of each session.
+	if options.EdgeLBStickySessionsEnabled {
+		res.RewriteHTTP.Sticky = &models.V2RewriteHTTPSticky{
+			Enabled:   pointers.NewBool(true),
+			CustomStr: computeEdgeLBStickySessionsCookieDirective(options.EdgeLBStickySessionsCookieName, options.EdgeLBStickySessionsCookieTTL),
+		}
+	}
+	// If HSTS has been requested, instruct HAProxy to add the "Strict-Transport-Security" header to responses served over HTTPS.
+	if options.EdgeLBHSTSEnabled {
+		res.MiscStrs = append(res.MiscStrs, computeEdgeLBHSTSDirective(options.EdgeLBHSTSMaxAge, options.EdgeLBHSTSIncludeSubdomains))
+	}
+	// Configure health checks as requested.
+	applyHealthCheckOptions(res, options.EdgeLBBackendHealthCheck)
+	return res
+}
+
+// computeEdgeLBStickySessionsCookieDirective computes the HAProxy "cookie" directive used to implement sticky sessions using a cookie with the specified name and maximum lifetime.
+// A maximum lifetime of zero means that the cookie is kept for as long as the client's session lasts.
+func computeEdgeLBStickySessionsCookieDirective(name string, ttl time.Duration) string {
+	if name == "" {
+		name = DefaultEdgeLBStickySessionsCookieName
+	}
+	if ttl <= 0 {
+		return fmt.Sprintf(edgeLBStickySessionsCookieFormatString, name)
+	}
+	return fmt.Sprintf(edgeLBStickySessionsCookieWithMaxLifeFormatString, name, int64(ttl/time.Second))
 }
 
 // computeEdgeLBBackendNameForIngressBackend computes the name of the EdgeLB backend that corresponds to the specified Ingress backend.
@@ -100,59 +194,399 @@ func computeEdgeLBBackendNameForIngressBackend(clusterName string, ingress *exts
 	return fmt.Sprintf(edgeLBIngressBackendNameFormatString, dklbstrings.ReplaceForwardSlashesWithDots(clusterName), ingress.Namespace, ingress.Name, backend.ServiceName, backend.ServicePort.String())
 }
 
-// computeEdgeLBFrontendForIngress computes the EdgeLB frontend that corresponds to the specified Ingress resource.
+// computeEdgeLBBackendNameForIngressRule computes the name of the EdgeLB backend that requests matching the rule with the specified path and Ingress backend are forwarded to.
+// Rules whose path must be rewritten require a dedicated EdgeLB backend, whose name includes a hash of the path, the path match type and the rewrite target.
+// An error is returned if the path cannot be rewritten as requested.
+func computeEdgeLBBackendNameForIngressRule(clusterName string, ingress *extsv1beta1.Ingress, host, path string, backend extsv1beta1.IngressBackend, options IngressTranslationOptions) (string, error) {
+	name := computeEdgeLBBackendNameForIngressBackend(clusterName, ingress, backend)
+	target, rewrite := options.rewriteTargetForPath(path)
+	if !rewrite {
+		return name, nil
+	}
+	matchType := computeIngressPathMatchType(ingress, host, path, options.EdgeLBPathMatchType)
+	if _, _, err := computeEdgeLBPathRewrite(path, matchType, target); err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256([]byte(strings.Join([]string{path, string(matchType), target}, "\x00")))
+	return fmt.Sprintf(edgeLBIngressRewriteBackendNameFormatString, name, hex.EncodeToString(hash[:])[:rewriteHashLength]), nil
+}
+
+// computeEdgeLBBackendForIngressRule computes the EdgeLB backend that requests matching the rule with the specified path and Ingress backend are forwarded to.
+// In case the path must be rewritten, the computed EdgeLB backend rewrites the request line of each request before it is forwarded.
+// An error is returned if the path cannot be rewritten as requested.
+func computeEdgeLBBackendForIngressRule(clusterName string, ingress *extsv1beta1.Ingress, host, path string, backend extsv1beta1.IngressBackend, nodePort int32, options IngressTranslationOptions) (*models.V2Backend, error) {
+	res := computeEdgeLBBackendForIngressBackend(clusterName, ingress, backend, nodePort, options)
+	target, rewrite := options.rewriteTargetForPath(path)
+	if !rewrite {
+		return res, nil
+	}
+	search, replace, err := computeEdgeLBPathRewrite(path, computeIngressPathMatchType(ingress, host, path, options.EdgeLBPathMatchType), target)
+	if err != nil {
+		return nil, err
+	}
+	if res.Name, err = computeEdgeLBBackendNameForIngressRule(clusterName, ingress, host, path, backend, options); err != nil {
+		return nil, err
+	}
+	res.MiscStrs = append(res.MiscStrs, fmt.Sprintf(edgeLBRewriteDirectiveFormatString, search, replace))
+	return res, nil
+}
+
+// computeEdgeLBBackendsForIngress computes the EdgeLB backends required by the rules of the specified Ingress resource, sorted by name.
+// "backendMap" is the mapping between the Ingress backends defined in the Ingress resource and their target node ports.
+// Rules whose path cannot be rewritten as requested use the EdgeLB backend that corresponds to their Ingress backend, even though they are not included in the EdgeLB frontends.
+func computeEdgeLBBackendsForIngress(clusterName string, ingress *extsv1beta1.Ingress, backendMap IngressBackendNodePortMap, endpointsMap IngressBackendEndpointsMap, options IngressTranslationOptions) []*models.V2Backend {
+	// byName holds the computed EdgeLB backends indexed by name, and is used to remove duplicates.
+	byName := make(map[string]*models.V2Backend, len(backendMap))
+	kubernetesutil.ForEachIngresBackend(ingress, func(host, path *string, backend extsv1beta1.IngressBackend) {
+		nodePort, exists := backendMap[backend]
+		if !exists {
+			return
+		}
+		h, p := "", ""
+		if host != nil && path != nil {
+			h, p = *host, *path
+		}
+		b, err := computeEdgeLBBackendForIngressRule(clusterName, ingress, h, p, backend, nodePort, options)
+		if err != nil {
+			b = computeEdgeLBBackendForIngressBackend(clusterName, ingress, backend, nodePort, options)
+		}
+		// Target the addresses computed for the Ingress backend, if any.
+		if target, exists := endpointsMap[backend]; exists {
+			applyBackendTarget(b, target)
+		}
+		byName[b.Name] = b
+	})
+	// Sort EdgeLB backends alphabetically in order to get a predictable output, as ranging over a map can produce different results every time.
+	res := make([]*models.V2Backend, 0, len(byName))
+	for _, b := range byName {
+		res = append(res, b)
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
+
+// computeEdgeLBFrontendsForIngress computes the set of EdgeLB frontends that correspond to the specified Ingress resource.
+// An EdgeLB HTTP frontend is always computed, while an EdgeLB HTTPS frontend is computed only if valid TLS secrets are provided.
+func computeEdgeLBFrontendsForIngress(clusterName string, ingress *extsv1beta1.Ingress, options IngressTranslationOptions, tlsSecrets []ingressTLSSecret) []*models.V2Frontend {
+	res := []*models.V2Frontend{
+		computeEdgeLBFrontendForIngress(clusterName, ingress, options),
+	}
+	if len(tlsSecrets) > 0 {
+		// If requested, redirect requests made to the HTTP frontend for hosts served over HTTPS to the HTTPS frontend.
+		if options.EdgeLBSSLRedirectEnabled {
+			allHostsCovered, coveredHosts := computeHostsCoveredByTLSSecrets(tlsSecrets)
+			if allHostsCovered {
+				coveredHosts = nil
+			}
+			res[0].MiscStrs = computeEdgeLBSSLRedirectDirectives(options, coveredHosts)
+		}
+		res = append(res, computeEdgeLBHTTPSFrontendForIngress(clusterName, ingress, options, tlsSecrets))
+	}
+	// If source ranges have been specified, make every EdgeLB frontend reject connections originating outside them.
+	// This must happen before requests are redirected to the HTTPS frontend, so the corresponding directive is placed first.
+	if len(options.EdgeLBSourceRanges) > 0 {
+		for _, frontend := range res {
+			frontend.MiscStrs = append([]string{computeSourceRangesConnectionRejectDirective(options.EdgeLBSourceRanges)}, frontend.MiscStrs...)
+		}
+	}
+	return res
+}
+
+// computeEdgeLBFrontendForIngress computes the EdgeLB HTTP frontend that corresponds to the specified Ingress resource.
 func computeEdgeLBFrontendForIngress(clusterName string, ingress *extsv1beta1.Ingress, options IngressTranslationOptions) *models.V2Frontend {
-	// Compute the base frontend object.
-	frontend := &models.V2Frontend{
+	return &models.V2Frontend{
 		BindAddress: constants.EdgeLBFrontendBindAddress,
 		Name:        computeEdgeLBFrontendNameForIngress(clusterName, ingress),
-		// TODO (@bcustodio) Split into HTTP/HTTPS port when TLS support is introduced.
 		Protocol:    models.V2ProtocolHTTP,
-		BindPort:    &options.EdgeLBPoolPort,
-		LinkBackend: &models.V2FrontendLinkBackend{},
+		BindPort:    &options.EdgeLBPoolHTTPPort,
+		// All the Ingress resource's rules are served by the HTTP frontend.
+		LinkBackend: computeEdgeLBLinkBackendForIngress(clusterName, ingress, options, func(_ string) bool {
+			return true
+		}),
 	}
+}
 
-	// hostItems will contain "V2FrontendLinkBackendMapItems0" items for rules that specify a ".host" field.
-	// These should take precedence over (i.e. be matched before) any rules that don't specify said field.
-	hostItems := make([]*models.V2FrontendLinkBackendMapItems0, 0)
-	// pathItems will contain "V2FrontendLinkBackendMapItems0" items for rules that don't specify a ".host" field.
-	pathItems := make([]*models.V2FrontendLinkBackendMapItems0, 0)
+// computeEdgeLBHTTPSFrontendForIngress computes the EdgeLB HTTPS frontend that corresponds to the specified Ingress resource.
+// The certificates contained in the specified TLS secrets are bound to the frontend, and HAProxy uses SNI to pick the certificate to present for each host.
+// Only rules for hosts covered by (at least) one of the specified TLS secrets are served by the HTTPS frontend.
+func computeEdgeLBHTTPSFrontendForIngress(clusterName string, ingress *extsv1beta1.Ingress, options IngressTranslationOptions, tlsSecrets []ingressTLSSecret) *models.V2Frontend {
+	// Compute the list of certificates to bind to the frontend, as well as the set of hosts covered by said certificates.
+	certificates := make([]string, 0, len(tlsSecrets))
+	for _, secret := range tlsSecrets {
+		certificates = append(certificates, fmt.Sprintf(edgeLBSecretFileReferenceFormatString, secret.FileName))
+	}
+	allHostsCovered, hosts := computeHostsCoveredByTLSSecrets(tlsSecrets)
+	coveredHosts := make(map[string]bool, len(hosts))
+	for _, host := range hosts {
+		coveredHosts[host] = true
+	}
+	return &models.V2Frontend{
+		BindAddress:  constants.EdgeLBFrontendBindAddress,
+		Certificates: certificates,
+		Name:         computeEdgeLBHTTPSFrontendNameForIngress(clusterName, ingress),
+		Protocol:     models.V2ProtocolHTTPS,
+		BindPort:     &options.EdgeLBPoolHTTPSPort,
+		LinkBackend: computeEdgeLBLinkBackendForIngress(clusterName, ingress, options, func(host string) bool {
+			if allHostsCovered || coveredHosts[host] {
+				return true
+			}
+			for coveredHost := range coveredHosts {
+				if hostMatches(coveredHost, host) {
+					return true
+				}
+			}
+			return false
+		}),
+	}
+}
+
+// computeHostsCoveredByTLSSecrets computes the list of (possibly wildcard) hosts covered by the specified TLS secrets, as well as a value indicating whether all hosts are covered.
+// If a TLS secret doesn't specify any hosts, all hosts are considered to be covered by it.
+// Wildcard hosts specified in a TLS secret cover all the hosts they match.
+func computeHostsCoveredByTLSSecrets(tlsSecrets []ingressTLSSecret) (bool, []string) {
+	allHostsCovered := false
+	coveredHosts := make([]string, 0)
+	for _, secret := range tlsSecrets {
+		if len(secret.Hosts) == 0 {
+			allHostsCovered = true
+		}
+		coveredHosts = append(coveredHosts, secret.Hosts...)
+	}
+	return allHostsCovered, coveredHosts
+}
+
+// computeEdgeLBLinkBackendForIngress computes the mapping between the rules of the specified Ingress resource and EdgeLB backends.
+// Only rules whose host satisfies "includeHost" are included in the mapping, while the default backend is always included.
+// Paths are matched according to the path match type specified in "options", and rules whose path cannot be translated (or rewritten as requested) are not included in the mapping.
+func computeEdgeLBLinkBackendForIngress(clusterName string, ingress *extsv1beta1.Ingress, options IngressTranslationOptions, includeHost func(host string) bool) *models.V2FrontendLinkBackend {
+	// Compute the base object.
+	linkBackend := &models.V2FrontendLinkBackend{}
 
 	// Iterate over Ingress backends, building the corresponding "V2FrontendLinkBackendMapItems0" EdgeLB object.
 	kubernetesutil.ForEachIngresBackend(ingress, func(host, path *string, backend extsv1beta1.IngressBackend) {
 		switch {
 		case host == nil && path == nil:
-			frontend.LinkBackend.DefaultBackend = computeEdgeLBBackendNameForIngressBackend(clusterName, ingress, backend)
+			linkBackend.DefaultBackend = computeEdgeLBBackendNameForIngressBackend(clusterName, ingress, backend)
+		case !includeHost(*host):
+			// The current rule must not be included in the mapping.
+			return
 		default:
+			// Compute the name of the EdgeLB backend for the current rule, which depends on whether its path must be rewritten.
+			// Rules whose path cannot be rewritten as requested are skipped, as their requests would otherwise be forwarded with the wrong path.
+			backendName, err := computeEdgeLBBackendNameForIngressRule(clusterName, ingress, *host, *path, backend, options)
+			if err != nil {
+				return
+			}
 			item := &models.V2FrontendLinkBackendMapItems0{
-				Backend: computeEdgeLBBackendNameForIngressBackend(clusterName, ingress, backend),
-				HostEq:  *host,
+				Backend: backendName,
+			}
+			if isWildcardHost(*host) {
+				// A wildcard ".host" field has been specified, so we must match it using a regular expression.
+				item.HostReg = computeEdgeLBHostRegex(*host)
+			} else {
+				item.HostEq = *host
 			}
 			if *path == "" {
 				// A ".path" field has not been specified, so the current rule should catch all requests.
 				item.PathReg = edgeLBPathCatchAllRegex
 			} else {
-				// A ".path" field has been specified, so we should use it to match requests.
-				// TODO (@bcustodio) HAProxy uses PCRE regular expressions, while the Ingress spec dictates that regular expressions follow the egrep (IEEE Std 1003.1) syntax.
-				// TODO (@bcustodio) https://kubernetes.io/docs/reference/generated/kubernetes-api/v1.13/#httpingresspath-v1beta1-extensions
-				// TODO (@bcustodio) We need to understand whether "translation" is required/desirable (and possible), or accept PCRE and document that the syntax for paths does not follow the spec.
-				item.PathReg = fmt.Sprintf(edgeLBPathRegexFormatString, *path)
+				// A ".path" field has been specified, so we should translate it into a regular expression understood by EdgeLB according to the requested match type (which takes the rule's ".pathType" field into account).
+				// Rules whose path cannot be translated are skipped, as they would otherwise produce an invalid HAProxy configuration.
+				r, err := computeEdgeLBPathRegex(*path, computeIngressPathMatchType(ingress, *host, *path, options.EdgeLBPathMatchType))
+				if err != nil {
+					return
+				}
+				item.PathReg = r
 			}
-			if *host == "" {
-				// A ".host" field has not been specified, so the current rule should be matched last.
-				pathItems = append(pathItems, item)
-			} else {
-				// A ".host" field has been specified, so the current rule should be matched first.
-				hostItems = append(hostItems, item)
+			linkBackend.Map = append(linkBackend.Map, item)
+		}
+	})
+
+	// Sort the items by specificity, so that the most specific rules are matched first regardless of the order in which they are declared.
+	sortEdgeLBLinkBackendMap(linkBackend.Map)
+	// Return the computed object.
+	return linkBackend
+}
+
+// computeMergedEdgeLBLinkBackendMaps computes, for each bind port used by the specified EdgeLB frontends, the mapping between the rules of all the Ingress resources owning them and EdgeLB backends.
+// The rules of a given Ingress resource are the items of the mappings of its EdgeLB frontends that target one of its EdgeLB backends, and the resulting mappings are sorted by specificity.
+// This allows for rules to be matched in order of decreasing specificity across all the Ingress resources sharing an EdgeLB pool and frontend bind port.
+// EdgeLB frontends not owned by Ingress resources are ignored.
+func computeMergedEdgeLBLinkBackendMaps(frontends []*models.V2Frontend) map[int32][]*models.V2FrontendLinkBackendMapItems0 {
+	res := make(map[int32][]*models.V2FrontendLinkBackendMapItems0)
+	for _, frontend := range frontends {
+		frontendMetadata, err := computeIngressOwnedEdgeLBObjectMetadata(frontend.Name)
+		if err != nil || frontend.BindPort == nil || frontend.LinkBackend == nil {
+			continue
+		}
+		items := res[*frontend.BindPort]
+		for _, item := range frontend.LinkBackend.Map {
+			// Skip items that target EdgeLB backends not owned by the Ingress resource that owns the current EdgeLB frontend (i.e. rules previously merged from other Ingress resources).
+			backendMetadata, err := computeIngressOwnedEdgeLBObjectMetadata(item.Backend)
+			if err != nil || backendMetadata.ClusterName != frontendMetadata.ClusterName || backendMetadata.Namespace != frontendMetadata.Namespace || backendMetadata.Name != frontendMetadata.Name {
+				continue
 			}
+			// Skip items that have already been included (e.g. because the Ingress resource owns several EdgeLB frontends using the current bind port).
+			if containsEdgeLBLinkBackendMapItem(items, item) {
+				continue
+			}
+			items = append(items, item)
+		}
+		res[*frontend.BindPort] = items
+	}
+	for _, items := range res {
+		sortEdgeLBLinkBackendMap(items)
+	}
+	return res
+}
+
+// containsEdgeLBLinkBackendMapItem indicates whether the specified list of "V2FrontendLinkBackendMapItems0" items contains an item equal to "item".
+func containsEdgeLBLinkBackendMapItem(items []*models.V2FrontendLinkBackendMapItems0, item *models.V2FrontendLinkBackendMapItems0) bool {
+	for _, i := range items {
+		if reflect.DeepEqual(i, item) {
+			return true
+		}
+	}
+	return false
+}
+
+// computeEdgeLBFrontendWithMergedLinkBackendMap returns a copy of the specified EdgeLB frontend whose mapping between rules and EdgeLB backends is replaced with the merged one for its bind port.
+// In case the specified EdgeLB frontend is not owned by an Ingress resource, or in case it already uses the merged mapping, it is returned as is.
+func computeEdgeLBFrontendWithMergedLinkBackendMap(frontend *models.V2Frontend, mergedMaps map[int32][]*models.V2FrontendLinkBackendMapItems0) *models.V2Frontend {
+	if _, err := computeIngressOwnedEdgeLBObjectMetadata(frontend.Name); err != nil || frontend.BindPort == nil || frontend.LinkBackend == nil {
+		return frontend
+	}
+	items, exists := mergedMaps[*frontend.BindPort]
+	if !exists || reflect.DeepEqual(frontend.LinkBackend.Map, items) {
+		return frontend
+	}
+	res := *frontend
+	linkBackend := *frontend.LinkBackend
+	linkBackend.Map = append([]*models.V2FrontendLinkBackendMapItems0{}, items...)
+	res.LinkBackend = &linkBackend
+	return &res
+}
+
+// linkBackendMapItem groups together a "V2FrontendLinkBackendMapItems0" item and the Ingress rule it was computed from.
+type linkBackendMapItem struct {
+	// item is the "V2FrontendLinkBackendMapItems0" item computed from the Ingress rule.
+	item *models.V2FrontendLinkBackendMapItems0
+	// host is the (possibly empty or wildcard) value of the ".host" field of the Ingress rule.
+	host string
+	// path is the (possibly empty) value of the ".path" field of the Ingress rule.
+	path string
+	// pathMatchType is the way in which "path" is matched against the paths of incoming requests.
+	pathMatchType constants.EdgeLBPathMatchType
+}
+
+// hostSpecificity returns a value indicating how specific the item's host is, lower values being more specific.
+// Non-wildcard hosts are more specific than wildcard hosts, which are more specific than an empty host.
+func (i linkBackendMapItem) hostSpecificity() int {
+	switch {
+	case i.host == "":
+		return 2
+	case isWildcardHost(i.host):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// pathSpecificity returns a value indicating how specific the item's path is, lower values being more specific.
+// Paths matched exactly are more specific than paths matched as prefixes or regular expressions, which are more specific than an empty path.
+func (i linkBackendMapItem) pathSpecificity() int {
+	switch {
+	case i.path == "":
+		return 2
+	case i.pathMatchType == constants.EdgeLBPathMatchTypeExact:
+		return 0
+	default:
+		return 1
+	}
+}
+
+// sortLinkBackendMapItems sorts the specified items by decreasing specificity.
+// Items are compared by the specificity of their hosts (longer wildcard hosts being more specific), and then by the specificity of their paths (longer paths being more specific).
+// Any remaining ties are broken by comparing the items' hosts, paths and backends, so that the resulting order depends only on the set of items (and not on the order in which they were declared or computed).
+// This keeps the order stable between syncs, and makes it hold across all the Ingress resources that share an EdgeLB pool.
+func sortLinkBackendMapItems(items []linkBackendMapItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if a.hostSpecificity() != b.hostSpecificity() {
+			return a.hostSpecificity() < b.hostSpecificity()
+		}
+		if len(a.host) != len(b.host) {
+			return len(a.host) > len(b.host)
+		}
+		if a.pathSpecificity() != b.pathSpecificity() {
+			return a.pathSpecificity() < b.pathSpecificity()
+		}
+		if len(a.path) != len(b.path) {
+			return len(a.path) > len(b.path)
 		}
+		if a.host != b.host {
+			return a.host < b.host
+		}
+		if a.path != b.path {
+			return a.path < b.path
+		}
+		return a.item.Backend < b.item.Backend
 	})
+}
 
-	// Build the final map by concatenating "hostItems" and  "pathItems".
-	frontend.LinkBackend.Map = append(frontend.LinkBackend.Map, hostItems...)
-	frontend.LinkBackend.Map = append(frontend.LinkBackend.Map, pathItems...)
-	// Return the computed EdgeLB frontend object.
-	return frontend
+// sortEdgeLBLinkBackendMap sorts the specified "V2FrontendLinkBackendMapItems0" items by decreasing specificity.
+// The host, path and path match type of the Ingress rule each item was computed from are recovered from the item itself, so that items computed for different Ingress resources can be sorted together.
+func sortEdgeLBLinkBackendMap(m []*models.V2FrontendLinkBackendMapItems0) {
+	items := make([]linkBackendMapItem, 0, len(m))
+	for _, item := range m {
+		path, pathMatchType := parseEdgeLBPathRegex(item.PathReg)
+		i := linkBackendMapItem{
+			item:          item,
+			host:          item.HostEq,
+			path:          path,
+			pathMatchType: pathMatchType,
+		}
+		if item.HostReg != "" {
+			i.host = parseEdgeLBHostRegex(item.HostReg)
+		}
+		items = append(items, i)
+	}
+	sortLinkBackendMapItems(items)
+	for idx, item := range items {
+		m[idx] = item.item
+	}
+}
+
+// isWildcardHost indicates whether the specified host is a wildcard host (e.g. "*.apps.example.com").
+func isWildcardHost(host string) bool {
+	return strings.HasPrefix(host, wildcardHostPrefix)
+}
+
+// computeEdgeLBHostRegex computes the regular expression used by EdgeLB to match the specified wildcard host.
+// As mandated by the Ingress spec, the wildcard matches exactly one DNS label (i.e. "*.example.com" matches "foo.example.com" but neither "example.com" nor "foo.bar.example.com").
+func computeEdgeLBHostRegex(host string) string {
+	return fmt.Sprintf(edgeLBHostRegexFormatString, wildcardHostLabelRegex+regexp.QuoteMeta(strings.TrimPrefix(host, "*")))
+}
+
+// parseEdgeLBHostRegex recovers the wildcard host from which the specified regular expression was computed by "computeEdgeLBHostRegex".
+// In case the regular expression was not computed by "computeEdgeLBHostRegex" (e.g. because it was set by a JSON merge patch), it is returned as a wildcard host so that it is still sorted among wildcard hosts.
+func parseEdgeLBHostRegex(r string) string {
+	host, ok := parseFormatString(r, edgeLBHostRegexFormatString)
+	if !ok || !strings.HasPrefix(host, wildcardHostLabelRegex) {
+		return wildcardHostPrefix + r
+	}
+	return "*" + unescapeRegex(strings.TrimPrefix(host, wildcardHostLabelRegex))
+}
+
+// hostMatches indicates whether the specified host is matched by the specified (possibly wildcard) pattern.
+func hostMatches(pattern, host string) bool {
+	if !isWildcardHost(pattern) {
+		return pattern == host
+	}
+	suffix := strings.TrimPrefix(pattern, "*")
+	return strings.HasSuffix(host, suffix) && len(host) > len(suffix) && !strings.Contains(strings.TrimSuffix(host, suffix), ".")
 }
 
 // computeEdgeLBFrontendNameForIngress computes the name of the EdgeLB frontend that corresponds to the specified Ingress resource.
@@ -160,15 +594,62 @@ func computeEdgeLBFrontendNameForIngress(clusterName string, ingress *extsv1beta
 	return fmt.Sprintf(edgeLBIngressFrontendNameFormatString, dklbstrings.ReplaceForwardSlashesWithDots(clusterName), ingress.Namespace, ingress.Name)
 }
 
+// computeEdgeLBHTTPSFrontendNameForIngress computes the name of the EdgeLB HTTPS frontend that corresponds to the specified Ingress resource.
+func computeEdgeLBHTTPSFrontendNameForIngress(clusterName string, ingress *extsv1beta1.Ingress) string {
+	return fmt.Sprintf(edgeLBIngressHTTPSFrontendNameFormatString, dklbstrings.ReplaceForwardSlashesWithDots(clusterName), ingress.Namespace, ingress.Name)
+}
+
+// computeIngressTLSSecret computes the information required to use the certificate contained in the specified Kubernetes secret for serving the specified Ingress resource.
+// The name of the file holding the certificate includes a hash of the secret's contents so that changes to said contents cause the EdgeLB pool to be updated.
+func computeIngressTLSSecret(clusterName, poolGroup, poolName string, ingress *extsv1beta1.Ingress, tls extsv1beta1.IngressTLS, secret *corev1.Secret) ingressTLSSecret {
+	// HAProxy expects the certificate and the private key to be concatenated in a single PEM file.
+	value := fmt.Sprintf("%s\n%s\n", strings.TrimSpace(string(secret.Data[corev1.TLSCertKey])), strings.TrimSpace(string(secret.Data[corev1.TLSPrivateKeyKey])))
+	hash := sha256.Sum256([]byte(value))
+	cluster := dklbstrings.ReplaceForwardSlashesWithDots(clusterName)
+	return ingressTLSSecret{
+		Hosts:          tls.Hosts,
+		SecretName:     secret.Name,
+		DCOSSecretPath: fmt.Sprintf(dcosSecretPathFormatString, strings.Trim(poolGroup, "/"), poolName, cluster, secret.Namespace, secret.Name),
+		FileName:       fmt.Sprintf(edgeLBSecretFileNameFormatString, cluster, ingress.Namespace, ingress.Name, secret.Name, hex.EncodeToString(hash[:])[:secretContentHashLength]),
+		Value:          value,
+	}
+}
+
+// computeEdgeLBPoolSecretForIngressTLSSecret computes the EdgeLB pool secret that corresponds to the specified TLS secret.
+func computeEdgeLBPoolSecretForIngressTLSSecret(t ingressTLSSecret) *models.V2PoolSecretsItems0 {
+	return &models.V2PoolSecretsItems0{
+		File:   t.FileName,
+		Secret: t.DCOSSecretPath,
+	}
+}
+
+// isEdgeLBSecretOwnedByIngress indicates whether the specified EdgeLB pool secret is owned by the specified Ingress resource.
+func isEdgeLBSecretOwnedByIngress(clusterName string, ingress *extsv1beta1.Ingress, secret *models.V2PoolSecretsItems0) bool {
+	return strings.HasPrefix(secret.File, fmt.Sprintf(edgeLBSecretFileNamePrefixFormatString, dklbstrings.ReplaceForwardSlashesWithDots(clusterName), ingress.Namespace, ingress.Name))
+}
+
+// validateTLSSecret checks whether the specified Kubernetes secret can be used to serve an Ingress resource over HTTPS.
+func validateTLSSecret(secret *corev1.Secret) error {
+	if secret.Type != corev1.SecretTypeTLS {
+		return fmt.Errorf("secret %q is of unexpected type %q", secret.Name, secret.Type)
+	}
+	for _, key := range []string{corev1.TLSCertKey, corev1.TLSPrivateKeyKey} {
+		if len(secret.Data[key]) == 0 {
+			return fmt.Errorf("secret %q is missing the %q key", secret.Name, key)
+		}
+	}
+	return nil
+}
+
 // computeServiceOwnedEdgeLBObjectMetadata parses the provided EdgeLB backend/frontend name and returns metadata about the Ingress resource that owns it.
 func computeIngressOwnedEdgeLBObjectMetadata(name string) (*ingressOwnedEdgeLBObjectMetadata, error) {
 	// Split the provided name by "separator".
 	parts := strings.Split(name, separator)
 	// Check how many parts we are dealing with, and act accordingly.
 	switch len(parts) {
-	case 5:
-		// The provided name is composed of 5 parts separated by "separator".
-		// Hence, it most likely corresponds to an EdgeLB backend owned by an Ingress resource.
+	case 6, 5:
+		// The provided name is composed of 5 (or 6) parts separated by "separator".
+		// Hence, it most likely corresponds to an EdgeLB backend owned by an Ingress resource (which, in the case of 6 parts, rewrites the path of requests matching a given rule).
 		return &ingressOwnedEdgeLBObjectMetadata{
 			ClusterName: dklbstrings.ReplaceDotsWithForwardSlashes(parts[0]),
 			Namespace:   parts[1],
@@ -179,6 +660,18 @@ func computeIngressOwnedEdgeLBObjectMetadata(name string) (*ingressOwnedEdgeLBOb
 				ServicePort: intstr.Parse(parts[4]),
 			},
 		}, nil
+	case 4:
+		// The provided name is composed of 4 parts separated by "separator".
+		// Hence, and if the last part is "edgeLBIngressHTTPSFrontendNameSuffix", it most likely corresponds to an EdgeLB HTTPS frontend owned by an Ingress resource.
+		if parts[3] != edgeLBIngressHTTPSFrontendNameSuffix {
+			return nil, errors.New("invalid backend/frontend name for ingress")
+		}
+		return &ingressOwnedEdgeLBObjectMetadata{
+			ClusterName:    dklbstrings.ReplaceDotsWithForwardSlashes(parts[0]),
+			Namespace:      parts[1],
+			Name:           parts[2],
+			IngressBackend: nil,
+		}, nil
 	case 3:
 		// The provided name is composed of 3 parts separated by "separator".
 		// Hence, it most likely corresponds to an EdgeLB frontend owned by an Ingress resource.
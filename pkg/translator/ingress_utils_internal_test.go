@@ -2,12 +2,19 @@ package translator
 
 import (
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
+	"github.com/mesosphere/dcos-edge-lb/models"
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 	extsv1beta1 "k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
+	"github.com/mesosphere/dklb/pkg/constants"
+	"github.com/mesosphere/dklb/pkg/util/pointers"
 	ingresstestutil "github.com/mesosphere/dklb/test/util/kubernetes/ingress"
 )
 
@@ -104,6 +111,19 @@ func TestComputeIngressOwnedEdgeLBObjectMetadata(t *testing.T) {
 				},
 			},
 		},
+		{
+			description: "name is a valid ingress-owned edgelb backend name for a rewritten path",
+			name:        "dev.kubernetes01:foo:bar:baz:80:0123456789",
+			metadata: &ingressOwnedEdgeLBObjectMetadata{
+				ClusterName: "dev/kubernetes01",
+				Namespace:   "foo",
+				Name:        "bar",
+				IngressBackend: &extsv1beta1.IngressBackend{
+					ServiceName: "baz",
+					ServicePort: intstr.FromInt(80),
+				},
+			},
+		},
 		{
 			description: "name is a valid ingress-owned edgelb frontend name",
 			name:        "dev.kubernetes01:foo:bar",
@@ -113,6 +133,15 @@ func TestComputeIngressOwnedEdgeLBObjectMetadata(t *testing.T) {
 				Name:        "bar",
 			},
 		},
+		{
+			description: "name is a valid ingress-owned edgelb https frontend name",
+			name:        "dev.kubernetes01:foo:bar:https",
+			metadata: &ingressOwnedEdgeLBObjectMetadata{
+				ClusterName: "dev/kubernetes01",
+				Namespace:   "foo",
+				Name:        "bar",
+			},
+		},
 	}
 	for _, test := range tests {
 		t.Logf("test case: %s", test.description)
@@ -124,3 +153,419 @@ func TestComputeIngressOwnedEdgeLBObjectMetadata(t *testing.T) {
 		}
 	}
 }
+
+// TestValidateTLSSecret tests the "validateTLSSecret" function.
+func TestValidateTLSSecret(t *testing.T) {
+	tests := []struct {
+		description string
+		secretType  corev1.SecretType
+		data        map[string][]byte
+		err         error
+	}{
+		{
+			description: "secret is a valid tls secret",
+			secretType:  corev1.SecretTypeTLS,
+			data: map[string][]byte{
+				corev1.TLSCertKey:       []byte("foo"),
+				corev1.TLSPrivateKeyKey: []byte("bar"),
+			},
+			err: nil,
+		},
+		{
+			description: "secret is of an unexpected type",
+			secretType:  corev1.SecretTypeOpaque,
+			data: map[string][]byte{
+				corev1.TLSCertKey:       []byte("foo"),
+				corev1.TLSPrivateKeyKey: []byte("bar"),
+			},
+			err: fmt.Errorf("secret %q is of unexpected type %q", "foo", corev1.SecretTypeOpaque),
+		},
+		{
+			description: "secret is missing the private key",
+			secretType:  corev1.SecretTypeTLS,
+			data: map[string][]byte{
+				corev1.TLSCertKey: []byte("foo"),
+			},
+			err: fmt.Errorf("secret %q is missing the %q key", "foo", corev1.TLSPrivateKeyKey),
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		s := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "bar",
+				Name:      "foo",
+			},
+			Type: test.secretType,
+			Data: test.data,
+		}
+		assert.Equal(t, test.err, validateTLSSecret(s))
+	}
+}
+
+// TestComputeEdgeLBHostRegex tests the "computeEdgeLBHostRegex" and "parseEdgeLBHostRegex" functions.
+func TestComputeEdgeLBHostRegex(t *testing.T) {
+	tests := []struct {
+		description string
+		host        string
+		regex       string
+		matches     []string
+		nonMatches  []string
+	}{
+		{
+			description: "wildcard host",
+			host:        "*.apps.example.com",
+			regex:       "^[^.:]+\\.apps\\.example\\.com(:[0-9]+)?$",
+			matches:     []string{"foo.apps.example.com", "foo.apps.example.com:8080"},
+			nonMatches:  []string{"apps.example.com", "foo.bar.apps.example.com", "fooapps.example.com"},
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		r := computeEdgeLBHostRegex(test.host)
+		assert.Equal(t, test.regex, r)
+		// Make sure that the wildcard host can be recovered from the regular expression.
+		assert.Equal(t, test.host, parseEdgeLBHostRegex(r))
+		for _, host := range test.matches {
+			assert.Regexp(t, r, host)
+		}
+		for _, host := range test.nonMatches {
+			assert.NotRegexp(t, r, host)
+		}
+	}
+}
+
+// TestHostMatches tests the "hostMatches" function.
+func TestHostMatches(t *testing.T) {
+	tests := []struct {
+		description string
+		pattern     string
+		host        string
+		result      bool
+	}{
+		{
+			description: "non-wildcard pattern matching the host",
+			pattern:     "foo.example.com",
+			host:        "foo.example.com",
+			result:      true,
+		},
+		{
+			description: "non-wildcard pattern not matching the host",
+			pattern:     "foo.example.com",
+			host:        "bar.example.com",
+			result:      false,
+		},
+		{
+			description: "wildcard pattern matching the host",
+			pattern:     "*.example.com",
+			host:        "foo.example.com",
+			result:      true,
+		},
+		{
+			description: "wildcard pattern not matching a host with more than one extra label",
+			pattern:     "*.example.com",
+			host:        "foo.bar.example.com",
+			result:      false,
+		},
+		{
+			description: "wildcard pattern not matching the parent domain",
+			pattern:     "*.example.com",
+			host:        "example.com",
+			result:      false,
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		assert.Equal(t, test.result, hostMatches(test.pattern, test.host))
+	}
+}
+
+// TestComputeEdgeLBLinkBackendForIngress tests the "computeEdgeLBLinkBackendForIngress" function.
+func TestComputeEdgeLBLinkBackendForIngress(t *testing.T) {
+	// Create an Ingress resource declaring a path-only rule, a wildcard host rule and a non-wildcard host rule (in this order).
+	ingress := ingresstestutil.DummyIngressResource("foo", "bar", func(ingress *extsv1beta1.Ingress) {
+		ingress.Spec.Backend = &extsv1beta1.IngressBackend{
+			ServiceName: "default",
+			ServicePort: intstr.FromInt(80),
+		}
+		for _, host := range []string{"", "*.example.com", "foo.example.com"} {
+			ingress.Spec.Rules = append(ingress.Spec.Rules, extsv1beta1.IngressRule{
+				Host: host,
+				IngressRuleValue: extsv1beta1.IngressRuleValue{
+					HTTP: &extsv1beta1.HTTPIngressRuleValue{
+						Paths: []extsv1beta1.HTTPIngressPath{
+							{
+								Backend: extsv1beta1.IngressBackend{
+									ServiceName: "svc",
+									ServicePort: intstr.FromInt(80),
+								},
+							},
+						},
+					},
+				},
+			})
+		}
+	})
+	backend := computeEdgeLBBackendNameForIngressBackend(testClusterName, ingress, ingress.Spec.Rules[0].HTTP.Paths[0].Backend)
+	// Make sure that the non-wildcard host rule comes first, followed by the wildcard host rule and by the path-only rule.
+	linkBackend := computeEdgeLBLinkBackendForIngress(testClusterName, ingress, IngressTranslationOptions{EdgeLBPathMatchType: DefaultEdgeLBPathMatchType}, func(_ string) bool {
+		return true
+	})
+	assert.Equal(t, computeEdgeLBBackendNameForIngressBackend(testClusterName, ingress, *ingress.Spec.Backend), linkBackend.DefaultBackend)
+	assert.Equal(t, []*models.V2FrontendLinkBackendMapItems0{
+		{
+			Backend: backend,
+			HostEq:  "foo.example.com",
+			PathReg: edgeLBPathCatchAllRegex,
+		},
+		{
+			Backend: backend,
+			HostReg: computeEdgeLBHostRegex("*.example.com"),
+			PathReg: edgeLBPathCatchAllRegex,
+		},
+		{
+			Backend: backend,
+			PathReg: edgeLBPathCatchAllRegex,
+		},
+	}, linkBackend.Map)
+}
+
+// TestComputeEdgeLBBackendsForIngress tests the "computeEdgeLBBackendsForIngress" function.
+func TestComputeEdgeLBBackendsForIngress(t *testing.T) {
+	// Create an Ingress resource declaring two rules targeting the same Ingress backend, only one of which has its path rewritten.
+	svc := extsv1beta1.IngressBackend{
+		ServiceName: "svc",
+		ServicePort: intstr.FromInt(80),
+	}
+	ingress := ingresstestutil.DummyIngressResource("foo", "bar", func(ingress *extsv1beta1.Ingress) {
+		ingress.Spec.Backend = &extsv1beta1.IngressBackend{
+			ServiceName: "default",
+			ServicePort: intstr.FromInt(80),
+		}
+		ingress.Spec.Rules = []extsv1beta1.IngressRule{
+			{
+				IngressRuleValue: extsv1beta1.IngressRuleValue{
+					HTTP: &extsv1beta1.HTTPIngressRuleValue{
+						Paths: []extsv1beta1.HTTPIngressPath{
+							{
+								Path:    "/foo",
+								Backend: svc,
+							},
+							{
+								Path:    "/bar",
+								Backend: svc,
+							},
+						},
+					},
+				},
+			},
+		}
+	})
+	backendMap := IngressBackendNodePortMap{
+		*ingress.Spec.Backend: 30000,
+		svc:                   30001,
+	}
+	options := IngressTranslationOptions{
+		EdgeLBPathMatchType: constants.EdgeLBPathMatchTypePrefix,
+		EdgeLBRewriteTargets: map[string]string{
+			"/foo": "/",
+		},
+	}
+	search, replace, err := computeEdgeLBPathRewrite("/foo", options.EdgeLBPathMatchType, "/")
+	assert.NoError(t, err)
+	rewriteBackendName, err := computeEdgeLBBackendNameForIngressRule(testClusterName, ingress, "", "/foo", svc, options)
+	assert.NoError(t, err)
+
+	// Make sure that a dedicated EdgeLB backend is computed for the rule whose path is rewritten, and that the other rule uses the EdgeLB backend that corresponds to its Ingress backend.
+	backends := computeEdgeLBBackendsForIngress(testClusterName, ingress, backendMap, nil, options)
+	names := make([]string, 0, len(backends))
+	for _, backend := range backends {
+		names = append(names, backend.Name)
+	}
+	if !assert.Equal(t, []string{
+		computeEdgeLBBackendNameForIngressBackend(testClusterName, ingress, *ingress.Spec.Backend),
+		computeEdgeLBBackendNameForIngressBackend(testClusterName, ingress, svc),
+		rewriteBackendName,
+	}, names) {
+		return
+	}
+	assert.Equal(t, fmt.Sprintf(edgeLBIngressRewriteBackendNameFormatString, backends[1].Name, rewriteBackendName[len(rewriteBackendName)-rewriteHashLength:]), rewriteBackendName)
+	assert.Empty(t, backends[1].MiscStrs)
+	assert.Equal(t, []string{fmt.Sprintf(edgeLBRewriteDirectiveFormatString, search, replace)}, backends[2].MiscStrs)
+
+	// Make sure that the rule whose path is rewritten is forwarded to the dedicated EdgeLB backend.
+	linkBackend := computeEdgeLBLinkBackendForIngress(testClusterName, ingress, options, func(_ string) bool {
+		return true
+	})
+	for _, item := range linkBackend.Map {
+		if item.PathReg == computeEdgeLBPathRegexOrDie(t, "/foo", options.EdgeLBPathMatchType) {
+			assert.Equal(t, rewriteBackendName, item.Backend)
+		} else {
+			assert.Equal(t, backends[1].Name, item.Backend)
+		}
+	}
+}
+
+// computeEdgeLBPathRegexOrDie computes the regular expression used by EdgeLB to match the specified path, failing the current test in case of error.
+func computeEdgeLBPathRegexOrDie(t *testing.T, path string, matchType constants.EdgeLBPathMatchType) string {
+	r, err := computeEdgeLBPathRegex(path, matchType)
+	assert.NoError(t, err)
+	return r
+}
+
+// TestSortLinkBackendMapItems tests the "sortLinkBackendMapItems" function.
+func TestSortLinkBackendMapItems(t *testing.T) {
+	tests := []struct {
+		description   string
+		pathMatchType constants.EdgeLBPathMatchType
+		rules         [][2]string
+		expectedRules [][2]string
+	}{
+		{
+			description:   "exact hosts before wildcard hosts before empty hosts",
+			pathMatchType: constants.EdgeLBPathMatchTypePrefix,
+			rules:         [][2]string{{"", "/"}, {"*.example.com", "/"}, {"foo.example.com", "/"}},
+			expectedRules: [][2]string{{"foo.example.com", "/"}, {"*.example.com", "/"}, {"", "/"}},
+		},
+		{
+			description:   "longer wildcard hosts before shorter wildcard hosts",
+			pathMatchType: constants.EdgeLBPathMatchTypePrefix,
+			rules:         [][2]string{{"*.com", "/"}, {"*.example.com", "/"}},
+			expectedRules: [][2]string{{"*.example.com", "/"}, {"*.com", "/"}},
+		},
+		{
+			description:   "longer paths before shorter paths before empty paths",
+			pathMatchType: constants.EdgeLBPathMatchTypePrefix,
+			rules:         [][2]string{{"foo.com", ""}, {"foo.com", "/"}, {"foo.com", "/api"}, {"foo.com", "/api/v1"}},
+			expectedRules: [][2]string{{"foo.com", "/api/v1"}, {"foo.com", "/api"}, {"foo.com", "/"}, {"foo.com", ""}},
+		},
+		{
+			description:   "host specificity takes precedence over path specificity",
+			pathMatchType: constants.EdgeLBPathMatchTypeExact,
+			rules:         [][2]string{{"", "/foo/bar/baz"}, {"foo.com", ""}, {"*.foo.com", "/foo"}},
+			expectedRules: [][2]string{{"foo.com", ""}, {"*.foo.com", "/foo"}, {"", "/foo/bar/baz"}},
+		},
+		{
+			description:   "ties are broken lexicographically",
+			pathMatchType: constants.EdgeLBPathMatchTypeRegex,
+			rules:         [][2]string{{"foo.com", "/b.*"}, {"bar.com", "/b.*"}, {"foo.com", "/a.*"}},
+			expectedRules: [][2]string{{"bar.com", "/b.*"}, {"foo.com", "/a.*"}, {"foo.com", "/b.*"}},
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		items := make([]linkBackendMapItem, 0, len(test.rules))
+		for _, rule := range test.rules {
+			items = append(items, linkBackendMapItem{
+				item:          &models.V2FrontendLinkBackendMapItems0{},
+				host:          rule[0],
+				path:          rule[1],
+				pathMatchType: test.pathMatchType,
+			})
+		}
+		sortLinkBackendMapItems(items)
+		rules := make([][2]string, 0, len(items))
+		for _, item := range items {
+			rules = append(rules, [2]string{item.host, item.path})
+		}
+		assert.Equal(t, test.expectedRules, rules)
+	}
+}
+
+// TestComputeMergedEdgeLBLinkBackendMaps tests the "computeMergedEdgeLBLinkBackendMaps" function.
+func TestComputeMergedEdgeLBLinkBackendMaps(t *testing.T) {
+	// frontend returns an EdgeLB frontend with the specified name, bind port and mapping.
+	frontend := func(name string, bindPort int32, items ...*models.V2FrontendLinkBackendMapItems0) *models.V2Frontend {
+		return &models.V2Frontend{
+			Name:     name,
+			BindPort: pointers.NewInt32(bindPort),
+			LinkBackend: &models.V2FrontendLinkBackend{
+				Map: items,
+			},
+		}
+	}
+	// Create items for the rules of two Ingress resources ("foo/bar" and "foo/qux"), as well as an item targeting an EdgeLB backend owned by a Service resource.
+	bar1 := &models.V2FrontendLinkBackendMapItems0{Backend: "dev.kubernetes01:foo:bar:svc:80", HostReg: computeEdgeLBHostRegex("*.example.com"), PathReg: edgeLBPathCatchAllRegex}
+	bar2 := &models.V2FrontendLinkBackendMapItems0{Backend: "dev.kubernetes01:foo:bar:svc:80", PathReg: computeEdgeLBPathRegexOrDie(t, "/api", constants.EdgeLBPathMatchTypePrefix)}
+	qux1 := &models.V2FrontendLinkBackendMapItems0{Backend: "dev.kubernetes01:foo:qux:svc:80", HostEq: "foo.example.com", PathReg: edgeLBPathCatchAllRegex}
+	qux2 := &models.V2FrontendLinkBackendMapItems0{Backend: "dev.kubernetes01:foo:qux:svc:80", HostEq: "foo.example.com", PathReg: computeEdgeLBPathRegexOrDie(t, "/api", constants.EdgeLBPathMatchTypeExact)}
+	svc := &models.V2FrontendLinkBackendMapItems0{Backend: "dev.kubernetes01:foo:svc:80", HostEq: "bar.example.com"}
+
+	tests := []struct {
+		description  string
+		frontends    []*models.V2Frontend
+		expectedMaps map[int32][]*models.V2FrontendLinkBackendMapItems0
+	}{
+		{
+			description: "rules of ingresses sharing a bind port are merged and sorted by specificity",
+			frontends: []*models.V2Frontend{
+				frontend("dev.kubernetes01:foo:bar", 80, bar1, bar2),
+				frontend("dev.kubernetes01:foo:qux", 80, qux1, qux2),
+			},
+			expectedMaps: map[int32][]*models.V2FrontendLinkBackendMapItems0{
+				80: {qux2, qux1, bar1, bar2},
+			},
+		},
+		{
+			description: "rules previously merged from other ingresses are ignored",
+			frontends: []*models.V2Frontend{
+				frontend("dev.kubernetes01:foo:bar", 80, qux2, qux1, bar1, bar2),
+			},
+			expectedMaps: map[int32][]*models.V2FrontendLinkBackendMapItems0{
+				80: {bar1, bar2},
+			},
+		},
+		{
+			description: "rules of ingresses using different bind ports are not merged",
+			frontends: []*models.V2Frontend{
+				frontend("dev.kubernetes01:foo:bar", 80, bar1, bar2),
+				frontend("dev.kubernetes01:foo:qux:https", 443, qux1),
+			},
+			expectedMaps: map[int32][]*models.V2FrontendLinkBackendMapItems0{
+				80:  {bar1, bar2},
+				443: {qux1},
+			},
+		},
+		{
+			description: "frontends not owned by ingresses are ignored",
+			frontends: []*models.V2Frontend{
+				frontend("dev.kubernetes01:foo:svc:80", 80, svc),
+				frontend("dev.kubernetes01:foo:bar", 80, bar2),
+			},
+			expectedMaps: map[int32][]*models.V2FrontendLinkBackendMapItems0{
+				80: {bar2},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		assert.Equal(t, test.expectedMaps, computeMergedEdgeLBLinkBackendMaps(test.frontends))
+	}
+}
+
+// TestComputeEdgeLBStickySessionsCookieDirective tests the "computeEdgeLBStickySessionsCookieDirective" function.
+func TestComputeEdgeLBStickySessionsCookieDirective(t *testing.T) {
+	tests := []struct {
+		description string
+		name        string
+		ttl         time.Duration
+		directive   string
+	}{
+		{
+			description: "default cookie name and no maximum lifetime",
+			name:        "",
+			ttl:         0,
+			directive:   "cookie SERVERID insert indirect nocache",
+		},
+		{
+			description: "custom cookie name and maximum lifetime",
+			name:        "route",
+			ttl:         90 * time.Minute,
+			directive:   "cookie route insert indirect nocache maxlife 5400s",
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		assert.Equal(t, test.directive, computeEdgeLBStickySessionsCookieDirective(test.name, test.ttl))
+	}
+}
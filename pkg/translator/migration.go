@@ -0,0 +1,135 @@
+package translator
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/mesosphere/dklb/pkg/constants"
+)
+
+// EdgeLBPoolMigrationStatus represents the status of the migration of an Ingress/Service resource from one EdgeLB pool to another.
+// It is stored as JSON in the "kubernetes.dcos.io/edgelb-pool-migration-status" annotation.
+type EdgeLBPoolMigrationStatus struct {
+	// Phase is the current phase of the migration.
+	Phase constants.EdgeLBPoolMigrationPhase `json:"phase"`
+	// SourcePool is the name of the EdgeLB pool from which the resource is being migrated.
+	SourcePool string `json:"sourcePool"`
+	// TargetPool is the name of the EdgeLB pool to which the resource is being migrated.
+	TargetPool string `json:"targetPool"`
+	// SwitchedOverAt is the time at which the status of the resource was switched to the addresses of the target EdgeLB pool.
+	SwitchedOverAt *metav1.Time `json:"switchedOverAt,omitempty"`
+}
+
+// EdgeLBPoolMigrationResult represents the outcome of advancing the migration of an Ingress/Service resource from one EdgeLB pool to another.
+type EdgeLBPoolMigrationResult struct {
+	// Status is the status that must be reported for the resource.
+	Status *corev1.LoadBalancerStatus
+	// Migration is the updated status of the migration.
+	// A nil value means that the migration is complete, and that the corresponding annotation must be removed.
+	Migration *EdgeLBPoolMigrationStatus
+	// RequeueAfter is the amount of time after which the resource must be processed again in order for the migration to progress.
+	// A value of zero means that the resource doesn't need to be processed again.
+	RequeueAfter time.Duration
+}
+
+// GetEdgeLBPoolMigrationStatus returns the status of the migration of the specified resource from one EdgeLB pool to another.
+// nil is returned in case the resource is not being migrated.
+func GetEdgeLBPoolMigrationStatus(obj metav1.Object) (*EdgeLBPoolMigrationStatus, error) {
+	v, exists := obj.GetAnnotations()[constants.EdgeLBPoolMigrationStatusAnnotationKey]
+	if !exists || v == "" {
+		return nil, nil
+	}
+	var res EdgeLBPoolMigrationStatus
+	if err := json.Unmarshal([]byte(v), &res); err != nil {
+		return nil, fmt.Errorf("failed to parse %q as the status of an edgelb pool migration: %v", v, err)
+	}
+	if res.SourcePool == "" || res.TargetPool == "" {
+		return nil, fmt.Errorf("failed to parse %q as the status of an edgelb pool migration: the source and target edgelb pools must be specified", v)
+	}
+	return &res, nil
+}
+
+// SetEdgeLBPoolMigrationStatus sets the status of the migration of the specified resource from one EdgeLB pool to another.
+// In case the specified status is nil, the corresponding annotation is removed.
+func SetEdgeLBPoolMigrationStatus(obj metav1.Object, status *EdgeLBPoolMigrationStatus) {
+	annotations := obj.GetAnnotations()
+	if status == nil {
+		delete(annotations, constants.EdgeLBPoolMigrationStatusAnnotationKey)
+		obj.SetAnnotations(annotations)
+		return
+	}
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	// Marshaling a struct containing only strings and timestamps never fails, so we can safely ignore the error.
+	v, _ := json.Marshal(status)
+	annotations[constants.EdgeLBPoolMigrationStatusAnnotationKey] = string(v)
+	obj.SetAnnotations(annotations)
+}
+
+// isBeingMigratedTo returns a value indicating whether the specified resource is being migrated to the EdgeLB pool with the specified name.
+func isBeingMigratedTo(obj metav1.Object, poolName string) bool {
+	migration, err := GetEdgeLBPoolMigrationStatus(obj)
+	return err == nil && migration != nil && migration.TargetPool == poolName
+}
+
+// AdvanceEdgeLBPoolMigration advances the migration of the specified resource from one EdgeLB pool to another, and computes the status that must be reported for the resource.
+// "currentStatus" is the status currently reported for the resource (i.e. the addresses of the source EdgeLB pool), and "targetStatus" is the status of the target EdgeLB pool as computed by the most recent translation.
+// The migration progresses as follows:
+// * While the target EdgeLB pool hasn't reported any endpoints, the current status is kept and the resource must be processed again after the poll interval.
+// * As soon as the target EdgeLB pool reports its endpoints, the status of the resource is switched to the addresses of the target EdgeLB pool and the grace period starts.
+// * Once the grace period expires, "cleanup" is called in order to remove the EdgeLB objects owned by the resource from the source EdgeLB pool, and the migration is complete.
+// Progress is reported as Kubernetes events associated with the resource.
+func AdvanceEdgeLBPoolMigration(obj runtime.Object, migration EdgeLBPoolMigrationStatus, currentStatus, targetStatus *corev1.LoadBalancerStatus, now time.Time, cleanup func() error, recorder record.EventRecorder) (*EdgeLBPoolMigrationResult, error) {
+	switch migration.Phase {
+	case constants.EdgeLBPoolMigrationPhasePending:
+		recorder.Eventf(obj, corev1.EventTypeNormal, constants.ReasonEdgeLBPoolMigrationStarted, "migrating from edgelb pool %q to edgelb pool %q", migration.SourcePool, migration.TargetPool)
+		migration.Phase = constants.EdgeLBPoolMigrationPhaseProvisioning
+		fallthrough
+	case constants.EdgeLBPoolMigrationPhaseProvisioning:
+		// Keep reporting the addresses of the source EdgeLB pool until the target EdgeLB pool reports its endpoints.
+		if targetStatus == nil || len(targetStatus.Ingress) == 0 {
+			return &EdgeLBPoolMigrationResult{
+				Status:       currentStatus,
+				Migration:    &migration,
+				RequeueAfter: DefaultEdgeLBPoolMigrationPollInterval,
+			}, nil
+		}
+		switchedOverAt := metav1.NewTime(now)
+		migration.Phase = constants.EdgeLBPoolMigrationPhaseDraining
+		migration.SwitchedOverAt = &switchedOverAt
+		recorder.Eventf(obj, corev1.EventTypeNormal, constants.ReasonEdgeLBPoolSwitchedOver, "switched over to edgelb pool %q, edgelb pool %q will stop serving the resource in %s", migration.TargetPool, migration.SourcePool, DefaultEdgeLBPoolMigrationGracePeriod)
+		return &EdgeLBPoolMigrationResult{
+			Status:       targetStatus,
+			Migration:    &migration,
+			RequeueAfter: DefaultEdgeLBPoolMigrationGracePeriod,
+		}, nil
+	case constants.EdgeLBPoolMigrationPhaseDraining:
+		// Wait for the grace period to expire before cleaning up the source EdgeLB pool.
+		if migration.SwitchedOverAt != nil {
+			if remaining := migration.SwitchedOverAt.Add(DefaultEdgeLBPoolMigrationGracePeriod).Sub(now); remaining > 0 {
+				return &EdgeLBPoolMigrationResult{
+					Status:       targetStatus,
+					Migration:    &migration,
+					RequeueAfter: remaining,
+				}, nil
+			}
+		}
+		if err := cleanup(); err != nil {
+			return nil, fmt.Errorf("failed to clean up edgelb pool %q: %v", migration.SourcePool, err)
+		}
+		recorder.Eventf(obj, corev1.EventTypeNormal, constants.ReasonEdgeLBPoolMigrationCompleted, "migrated from edgelb pool %q to edgelb pool %q", migration.SourcePool, migration.TargetPool)
+		return &EdgeLBPoolMigrationResult{
+			Status:    targetStatus,
+			Migration: nil,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown edgelb pool migration phase %q", migration.Phase)
+	}
+}
@@ -0,0 +1,157 @@
+package translator_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/mesosphere/dklb/pkg/constants"
+	"github.com/mesosphere/dklb/pkg/translator"
+	servicetestutil "github.com/mesosphere/dklb/test/util/kubernetes/service"
+)
+
+// TestEdgeLBPoolMigrationStatusRoundTrip tests that the status of an EdgeLB pool migration can be stored in and read back from the corresponding annotation.
+func TestEdgeLBPoolMigrationStatusRoundTrip(t *testing.T) {
+	service := servicetestutil.DummyServiceResource("foo", "bar")
+	// Make sure a resource without the annotation is reported as not being migrated.
+	migration, err := translator.GetEdgeLBPoolMigrationStatus(service)
+	assert.NoError(t, err)
+	assert.Nil(t, migration)
+	// Make sure the status of the migration is read back as it was stored.
+	switchedOverAt := metav1.NewTime(time.Date(2019, 5, 1, 10, 0, 0, 0, time.UTC))
+	status := &translator.EdgeLBPoolMigrationStatus{
+		Phase:          constants.EdgeLBPoolMigrationPhaseDraining,
+		SourcePool:     "old",
+		TargetPool:     "new",
+		SwitchedOverAt: &switchedOverAt,
+	}
+	translator.SetEdgeLBPoolMigrationStatus(service, status)
+	migration, err = translator.GetEdgeLBPoolMigrationStatus(service)
+	assert.NoError(t, err)
+	assert.Equal(t, status.Phase, migration.Phase)
+	assert.Equal(t, status.SourcePool, migration.SourcePool)
+	assert.Equal(t, status.TargetPool, migration.TargetPool)
+	assert.True(t, status.SwitchedOverAt.Equal(migration.SwitchedOverAt))
+	// Make sure the annotation is removed when the migration is complete.
+	translator.SetEdgeLBPoolMigrationStatus(service, nil)
+	assert.NotContains(t, service.Annotations, constants.EdgeLBPoolMigrationStatusAnnotationKey)
+	// Make sure an invalid value is reported as an error.
+	service.Annotations[constants.EdgeLBPoolMigrationStatusAnnotationKey] = `{"phase":"Pending"}`
+	_, err = translator.GetEdgeLBPoolMigrationStatus(service)
+	assert.Error(t, err)
+}
+
+// TestAdvanceEdgeLBPoolMigration tests the "AdvanceEdgeLBPoolMigration" function.
+func TestAdvanceEdgeLBPoolMigration(t *testing.T) {
+	var (
+		now            = time.Date(2019, 5, 1, 10, 0, 0, 0, time.UTC)
+		recentSwitch   = metav1.NewTime(now.Add(-time.Minute))
+		expiredSwitch  = metav1.NewTime(now.Add(-translator.DefaultEdgeLBPoolMigrationGracePeriod))
+		currentStatus  = &corev1.LoadBalancerStatus{Ingress: []corev1.LoadBalancerIngress{{IP: "10.0.0.1"}}}
+		targetStatus   = &corev1.LoadBalancerStatus{Ingress: []corev1.LoadBalancerIngress{{IP: "10.0.0.2"}}}
+		emptyStatus    = &corev1.LoadBalancerStatus{}
+		cleanupFailure = errors.New("edgelb is unavailable")
+	)
+	tests := []struct {
+		description          string
+		migration            translator.EdgeLBPoolMigrationStatus
+		targetStatus         *corev1.LoadBalancerStatus
+		cleanupError         error
+		expectedStatus       *corev1.LoadBalancerStatus
+		expectedPhase        constants.EdgeLBPoolMigrationPhase
+		expectedCompleted    bool
+		expectedCleanup      bool
+		expectedRequeueAfter time.Duration
+		expectedEventCount   int
+		expectedError        bool
+	}{
+		{
+			description:          "pending migration to an edgelb pool that hasn't reported its endpoints",
+			migration:            translator.EdgeLBPoolMigrationStatus{Phase: constants.EdgeLBPoolMigrationPhasePending},
+			targetStatus:         emptyStatus,
+			expectedStatus:       currentStatus,
+			expectedPhase:        constants.EdgeLBPoolMigrationPhaseProvisioning,
+			expectedRequeueAfter: translator.DefaultEdgeLBPoolMigrationPollInterval,
+			expectedEventCount:   1,
+		},
+		{
+			description:          "provisioning migration to an edgelb pool whose metadata cannot be read",
+			migration:            translator.EdgeLBPoolMigrationStatus{Phase: constants.EdgeLBPoolMigrationPhaseProvisioning},
+			targetStatus:         nil,
+			expectedStatus:       currentStatus,
+			expectedPhase:        constants.EdgeLBPoolMigrationPhaseProvisioning,
+			expectedRequeueAfter: translator.DefaultEdgeLBPoolMigrationPollInterval,
+		},
+		{
+			description:          "provisioning migration to an edgelb pool that has reported its endpoints",
+			migration:            translator.EdgeLBPoolMigrationStatus{Phase: constants.EdgeLBPoolMigrationPhaseProvisioning},
+			targetStatus:         targetStatus,
+			expectedStatus:       targetStatus,
+			expectedPhase:        constants.EdgeLBPoolMigrationPhaseDraining,
+			expectedRequeueAfter: translator.DefaultEdgeLBPoolMigrationGracePeriod,
+			expectedEventCount:   1,
+		},
+		{
+			description:          "draining migration within the grace period",
+			migration:            translator.EdgeLBPoolMigrationStatus{Phase: constants.EdgeLBPoolMigrationPhaseDraining, SwitchedOverAt: &recentSwitch},
+			targetStatus:         targetStatus,
+			expectedStatus:       targetStatus,
+			expectedPhase:        constants.EdgeLBPoolMigrationPhaseDraining,
+			expectedRequeueAfter: translator.DefaultEdgeLBPoolMigrationGracePeriod - time.Minute,
+		},
+		{
+			description:        "draining migration after the grace period",
+			migration:          translator.EdgeLBPoolMigrationStatus{Phase: constants.EdgeLBPoolMigrationPhaseDraining, SwitchedOverAt: &expiredSwitch},
+			targetStatus:       targetStatus,
+			expectedStatus:     targetStatus,
+			expectedCompleted:  true,
+			expectedCleanup:    true,
+			expectedEventCount: 1,
+		},
+		{
+			description:     "draining migration after the grace period failing to clean up the old edgelb pool",
+			migration:       translator.EdgeLBPoolMigrationStatus{Phase: constants.EdgeLBPoolMigrationPhaseDraining, SwitchedOverAt: &expiredSwitch},
+			targetStatus:    targetStatus,
+			cleanupError:    cleanupFailure,
+			expectedCleanup: true,
+			expectedError:   true,
+		},
+		{
+			description:   "migration in an unknown phase",
+			migration:     translator.EdgeLBPoolMigrationStatus{Phase: "Unknown"},
+			targetStatus:  targetStatus,
+			expectedError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		test.migration.SourcePool = "old"
+		test.migration.TargetPool = "new"
+		recorder := record.NewFakeRecorder(test.expectedEventCount + 1)
+		cleanedUp := false
+		cleanup := func() error {
+			cleanedUp = true
+			return test.cleanupError
+		}
+		res, err := translator.AdvanceEdgeLBPoolMigration(servicetestutil.DummyServiceResource("foo", "bar"), test.migration, currentStatus, test.targetStatus, now, cleanup, recorder)
+		assert.Equal(t, test.expectedCleanup, cleanedUp)
+		assert.Len(t, recorder.Events, test.expectedEventCount)
+		if test.expectedError {
+			assert.Error(t, err)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, test.expectedStatus, res.Status)
+		assert.Equal(t, test.expectedRequeueAfter, res.RequeueAfter)
+		if test.expectedCompleted {
+			assert.Nil(t, res.Migration)
+		} else {
+			assert.Equal(t, test.expectedPhase, res.Migration.Phase)
+		}
+	}
+}
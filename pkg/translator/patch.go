@@ -0,0 +1,312 @@
+package translator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/mesosphere/dcos-edge-lb/models"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	dklbcache "github.com/mesosphere/dklb/pkg/cache"
+	"github.com/mesosphere/dklb/pkg/constants"
+)
+
+var (
+	// forbiddenEdgeLBPoolPatchFields holds the fields of an EdgeLB pool that cannot be changed using a JSON merge patch, as they are either managed by dklb or configurable using dedicated annotations.
+	forbiddenEdgeLBPoolPatchFields = []string{"cloudProvider", "count", "cpus", "mem", "name", "namespace", "role", "secrets", "virtualNetworks"}
+	// forbiddenEdgeLBPoolHaproxyPatchFields holds the fields of the HAProxy configuration of an EdgeLB pool that cannot be changed using a JSON merge patch, as they are managed by dklb.
+	forbiddenEdgeLBPoolHaproxyPatchFields = []string{"backends", "frontends"}
+	// forbiddenEdgeLBObjectPatchFields holds the fields of an EdgeLB backend or frontend that cannot be changed using a JSON merge patch, as dklb relies on them to determine ownership.
+	forbiddenEdgeLBObjectPatchFields = []string{"name"}
+)
+
+// parseEdgeLBPoolPatch parses the specified value as a JSON merge patch to apply to the target EdgeLB pool, making sure that it applies cleanly.
+// nil is returned in case said value is empty.
+func parseEdgeLBPoolPatch(v string) ([]byte, error) {
+	patch, err := parseJSONMergePatch(v, "edgelb pool", forbiddenEdgeLBPoolPatchFields)
+	if err != nil || patch == nil {
+		return nil, err
+	}
+	// Make sure that the fields of the HAProxy configuration managed by dklb are not changed.
+	var fields map[string]json.RawMessage
+	_ = json.Unmarshal(patch, &fields)
+	if haproxy, exists := fields["haproxy"]; exists {
+		if _, err := parseJSONMergePatch(string(haproxy), "edgelb pool's haproxy configuration", forbiddenEdgeLBPoolHaproxyPatchFields); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := applyJSONMergePatch(&models.V2Pool{}, patch); err != nil {
+		return nil, fmt.Errorf("failed to apply %q to an edgelb pool: %v", v, err)
+	}
+	return patch, nil
+}
+
+// parseEdgeLBBackendPatch parses the specified value as a JSON merge patch to apply to the EdgeLB backends corresponding to an Ingress/Service resource, making sure that it applies cleanly.
+// nil is returned in case said value is empty.
+func parseEdgeLBBackendPatch(v string) ([]byte, error) {
+	patch, err := parseJSONMergePatch(v, "edgelb backend", forbiddenEdgeLBObjectPatchFields)
+	if err != nil || patch == nil {
+		return nil, err
+	}
+	if _, err := applyJSONMergePatch(&models.V2Backend{}, patch); err != nil {
+		return nil, fmt.Errorf("failed to apply %q to an edgelb backend: %v", v, err)
+	}
+	return patch, nil
+}
+
+// parseEdgeLBFrontendPatch parses the specified value as a JSON merge patch to apply to the EdgeLB frontends corresponding to an Ingress/Service resource, making sure that it applies cleanly.
+// nil is returned in case said value is empty.
+func parseEdgeLBFrontendPatch(v string) ([]byte, error) {
+	patch, err := parseJSONMergePatch(v, "edgelb frontend", forbiddenEdgeLBObjectPatchFields)
+	if err != nil || patch == nil {
+		return nil, err
+	}
+	if _, err := applyJSONMergePatch(&models.V2Frontend{}, patch); err != nil {
+		return nil, fmt.Errorf("failed to apply %q to an edgelb frontend: %v", v, err)
+	}
+	return patch, nil
+}
+
+// parseJSONMergePatch parses the specified value as a JSON merge patch (RFC 7386) to apply to an object of the specified kind.
+// The patch must be a JSON object which doesn't change any of the specified fields.
+// nil is returned in case said value is empty.
+func parseJSONMergePatch(v, kind string, forbiddenFields []string) ([]byte, error) {
+	if v == "" {
+		return nil, nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(v), &fields); err != nil {
+		return nil, fmt.Errorf("failed to parse %q as a json merge patch for an %s: %v", v, kind, err)
+	}
+	// A "null" value is valid JSON, but it would remove the whole object.
+	if fields == nil {
+		return nil, fmt.Errorf("failed to parse %q as a json merge patch for an %s: the patch must be a json object", v, kind)
+	}
+	for _, field := range forbiddenFields {
+		if _, exists := fields[field]; exists {
+			return nil, fmt.Errorf("the %q field of an %s cannot be patched", field, kind)
+		}
+	}
+	return []byte(v), nil
+}
+
+// applyJSONMergePatch applies the specified JSON merge patch (RFC 7386) to the specified EdgeLB object (i.e. a pointer to an EdgeLB pool, backend or frontend) in-place.
+// It returns a value indicating whether the object was changed by the patch.
+// Fields that are not known to the EdgeLB object are rejected, as they would otherwise be silently dropped.
+func applyJSONMergePatch(obj interface{}, patch []byte) (bool, error) {
+	if len(patch) == 0 {
+		return false, nil
+	}
+	original, err := json.Marshal(obj)
+	if err != nil {
+		return false, err
+	}
+	patched, err := jsonpatch.MergePatch(original, patch)
+	if err != nil {
+		return false, err
+	}
+	// Decode the patched object into a new value so that any fields removed by the patch are reset.
+	v := reflect.New(reflect.TypeOf(obj).Elem())
+	d := json.NewDecoder(bytes.NewReader(patched))
+	d.DisallowUnknownFields()
+	if err := d.Decode(v.Interface()); err != nil {
+		return false, err
+	}
+	// Compare the JSON representations of the original and patched objects rather than the objects themselves, as these may differ in details that are not meaningful (e.g. nil vs. empty slices).
+	result, err := json.Marshal(v.Interface())
+	if err != nil {
+		return false, err
+	}
+	if jsonpatch.Equal(original, result) {
+		return false, nil
+	}
+	reflect.ValueOf(obj).Elem().Set(v.Elem())
+	return true, nil
+}
+
+// patchEdgeLBBackends applies the JSON merge patch requested for EdgeLB backends (if any) to each of the specified EdgeLB backends.
+func patchEdgeLBBackends(backends []*models.V2Backend, options BaseTranslationOptions) error {
+	for _, backend := range backends {
+		if _, err := applyJSONMergePatch(backend, options.EdgeLBBackendPatch); err != nil {
+			return fmt.Errorf("failed to patch backend %q: %v", backend.Name, err)
+		}
+	}
+	return nil
+}
+
+// patchEdgeLBFrontends applies the JSON merge patch requested for EdgeLB frontends (if any) to each of the specified EdgeLB frontends.
+func patchEdgeLBFrontends(frontends []*models.V2Frontend, options BaseTranslationOptions) error {
+	for _, frontend := range frontends {
+		if _, err := applyJSONMergePatch(frontend, options.EdgeLBFrontendPatch); err != nil {
+			return fmt.Errorf("failed to patch frontend %q: %v", frontend.Name, err)
+		}
+	}
+	return nil
+}
+
+// patchEdgeLBPool applies the specified JSON merge patch (which is nil in case none has been requested) to the specified EdgeLB pool, on top of its current state.
+// Fields set by the JSON merge patch last applied to the EdgeLB pool for the Ingress/Service resource ("lastApplied") that are not set by the specified JSON merge patch anymore are reset first.
+// Fields that are still set by the JSON merge patch applied by other Ingress/Service resources sharing the EdgeLB pool ("shared") are not reset, as these resources still rely on them.
+// It returns a value indicating whether the EdgeLB pool was changed.
+func patchEdgeLBPool(pool *models.V2Pool, patch []byte, lastApplied string, shared []byte) (bool, error) {
+	// Compute the JSON merge patch that is to remain applied to the EdgeLB pool, and reset the fields that are not set by it anymore.
+	remaining := patch
+	if len(remaining) == 0 {
+		remaining = shared
+	}
+	reset, err := computeJSONMergePatchReset([]byte(lastApplied), remaining)
+	if err != nil {
+		return false, fmt.Errorf("failed to compute the fields of edgelb pool %q to reset: %v", pool.Name, err)
+	}
+	wasReset, err := applyJSONMergePatch(pool, reset)
+	if err != nil {
+		return false, fmt.Errorf("failed to reset edgelb pool %q: %v", pool.Name, err)
+	}
+	wasPatched, err := applyJSONMergePatch(pool, patch)
+	if err != nil {
+		return false, fmt.Errorf("failed to patch edgelb pool %q: %v", pool.Name, err)
+	}
+	return wasReset || wasPatched, nil
+}
+
+// computeJSONMergePatchReset computes a JSON merge patch that resets the fields set by the "previous" JSON merge patch that are not set by the "current" one.
+// Fields of nested JSON objects are reset individually rather than the nested objects as a whole, as these may hold fields that are not set by either JSON merge patch (e.g. the EdgeLB backends and frontends of the HAProxy configuration of an EdgeLB pool).
+// nil is returned in case there is nothing to reset.
+func computeJSONMergePatchReset(previous, current []byte) ([]byte, error) {
+	if len(previous) == 0 {
+		return nil, nil
+	}
+	var p, c map[string]interface{}
+	if err := json.Unmarshal(previous, &p); err != nil {
+		return nil, err
+	}
+	if len(current) > 0 {
+		if err := json.Unmarshal(current, &c); err != nil {
+			return nil, err
+		}
+	}
+	fields := computeJSONMergePatchResetFields(p, c)
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(fields)
+}
+
+// computeJSONMergePatchResetFields computes the fields of a JSON merge patch that reset the fields set by "previous" that are not set by "current".
+func computeJSONMergePatchResetFields(previous, current map[string]interface{}) map[string]interface{} {
+	res := make(map[string]interface{})
+	for key, pv := range previous {
+		cv, exists := current[key]
+		if pm, ok := pv.(map[string]interface{}); ok {
+			// A nested JSON object replaced by a value of a different type in "current" is overwritten as a whole, so there is nothing to reset.
+			cm, ok := cv.(map[string]interface{})
+			if exists && !ok {
+				continue
+			}
+			if fields := computeJSONMergePatchResetFields(pm, cm); len(fields) > 0 {
+				res[key] = fields
+			}
+			continue
+		}
+		// A "null" value in "previous" has removed the field already, so there is nothing to reset.
+		if !exists && pv != nil {
+			res[key] = nil
+		}
+	}
+	return res
+}
+
+// computeSharedEdgeLBPoolPatch returns the JSON merge patch last applied to the specified EdgeLB pool for the Ingress/Service resources other than "owner" that share it (if any).
+// As these resources would otherwise keep overwriting each other's changes, an error is returned in case "patch" is not empty and differs from the JSON merge patch applied for any of them.
+func computeSharedEdgeLBPoolPatch(kubeCache dklbcache.KubernetesResourceCache, clusterName string, pool *models.V2Pool, owner *EdgeLBObjectOwner, patch []byte) ([]byte, error) {
+	var res []byte
+	for _, other := range computeEdgeLBPoolOwners(pool, clusterName) {
+		if other == *owner {
+			continue
+		}
+		var (
+			obj metav1.Object
+			err error
+		)
+		switch other.Kind {
+		case EdgeLBObjectOwnerKindIngress:
+			obj, err = kubeCache.GetIngress(other.Namespace, other.Name)
+		case EdgeLBObjectOwnerKindService:
+			obj, err = kubeCache.GetService(other.Namespace, other.Name)
+		default:
+			continue
+		}
+		if err != nil {
+			// Resources that don't exist anymore don't rely on the EdgeLB pool, and their EdgeLB objects are eventually removed by the garbage collector.
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read %s: %v", other.String(), err)
+		}
+		applied := GetEdgeLBPoolAppliedPatch(obj)
+		if applied == "" {
+			continue
+		}
+		if len(patch) > 0 && !jsonpatch.Equal(patch, []byte(applied)) {
+			return nil, fmt.Errorf("the json merge patch requested for edgelb pool %q differs from the one applied for %s (%q), which shares it", pool.Name, other.String(), applied)
+		}
+		if res == nil {
+			res = []byte(applied)
+		}
+	}
+	return res, nil
+}
+
+// computeEdgeLBPoolOwners returns the Ingress/Service resources in the specified Kubernetes cluster that own EdgeLB backends or frontends in the specified EdgeLB pool.
+func computeEdgeLBPoolOwners(pool *models.V2Pool, clusterName string) []EdgeLBObjectOwner {
+	res := make([]EdgeLBObjectOwner, 0)
+	if pool.Haproxy == nil {
+		return res
+	}
+	visited := make(map[EdgeLBObjectOwner]bool)
+	names := make([]string, 0, len(pool.Haproxy.Backends)+len(pool.Haproxy.Frontends))
+	for _, backend := range pool.Haproxy.Backends {
+		names = append(names, backend.Name)
+	}
+	for _, frontend := range pool.Haproxy.Frontends {
+		names = append(names, frontend.Name)
+	}
+	for _, name := range names {
+		if owner := ComputeEdgeLBObjectOwner(clusterName, name); owner != nil && !visited[*owner] {
+			visited[*owner] = true
+			res = append(res, *owner)
+		}
+	}
+	return res
+}
+
+// GetEdgeLBPoolAppliedPatch returns the JSON merge patch last applied to the target EdgeLB pool for the specified resource.
+// An empty string is returned in case no JSON merge patch has been applied.
+func GetEdgeLBPoolAppliedPatch(obj metav1.Object) string {
+	return obj.GetAnnotations()[constants.EdgeLBPoolAppliedPatchAnnotationKey]
+}
+
+// SetEdgeLBPoolAppliedPatch sets the JSON merge patch last applied to the target EdgeLB pool for the specified resource.
+// The corresponding annotation is removed in case the specified JSON merge patch is empty.
+// It returns a value indicating whether the value of the corresponding annotation was changed.
+func SetEdgeLBPoolAppliedPatch(obj metav1.Object, patch string) bool {
+	if GetEdgeLBPoolAppliedPatch(obj) == patch {
+		return false
+	}
+	annotations := obj.GetAnnotations()
+	if patch == "" {
+		delete(annotations, constants.EdgeLBPoolAppliedPatchAnnotationKey)
+		obj.SetAnnotations(annotations)
+		return true
+	}
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[constants.EdgeLBPoolAppliedPatchAnnotationKey] = patch
+	obj.SetAnnotations(annotations)
+	return true
+}
@@ -0,0 +1,514 @@
+package translator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mesosphere/dcos-edge-lb/models"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+
+	dklbcache "github.com/mesosphere/dklb/pkg/cache"
+	"github.com/mesosphere/dklb/pkg/constants"
+	cachetestutil "github.com/mesosphere/dklb/test/util/cache"
+	secretstestutil "github.com/mesosphere/dklb/test/util/dcos/secrets"
+	edgelbmanagertestutil "github.com/mesosphere/dklb/test/util/edgelb/manager"
+	edgelbpooltestutil "github.com/mesosphere/dklb/test/util/edgelb/pool"
+	ingresstestutil "github.com/mesosphere/dklb/test/util/kubernetes/ingress"
+	servicetestutil "github.com/mesosphere/dklb/test/util/kubernetes/service"
+)
+
+// TestParseEdgeLBPatches tests the "parseEdgeLBPoolPatch", "parseEdgeLBBackendPatch" and "parseEdgeLBFrontendPatch" functions.
+func TestParseEdgeLBPatches(t *testing.T) {
+	tests := []struct {
+		description   string
+		parseFn       func(string) ([]byte, error)
+		value         string
+		expectedPatch []byte
+		expectedError bool
+	}{
+		{
+			description:   "empty pool patch",
+			parseFn:       parseEdgeLBPoolPatch,
+			value:         "",
+			expectedPatch: nil,
+		},
+		{
+			description:   "valid pool patch",
+			parseFn:       parseEdgeLBPoolPatch,
+			value:         `{"constraints":"[[\"hostname\",\"UNIQUE\"]]"}`,
+			expectedPatch: []byte(`{"constraints":"[[\"hostname\",\"UNIQUE\"]]"}`),
+		},
+		{
+			description:   "pool patch changing a field managed by dklb",
+			parseFn:       parseEdgeLBPoolPatch,
+			value:         `{"count":3}`,
+			expectedError: true,
+		},
+		{
+			description:   "pool patch changing the backends of the pool",
+			parseFn:       parseEdgeLBPoolPatch,
+			value:         `{"haproxy":{"backends":[]}}`,
+			expectedError: true,
+		},
+		{
+			description:   "pool patch with an unknown field",
+			parseFn:       parseEdgeLBPoolPatch,
+			value:         `{"foo":"bar"}`,
+			expectedError: true,
+		},
+		{
+			description:   "pool patch that is not a json object",
+			parseFn:       parseEdgeLBPoolPatch,
+			value:         `null`,
+			expectedError: true,
+		},
+		{
+			description:   "valid backend patch",
+			parseFn:       parseEdgeLBBackendPatch,
+			value:         `{"balance":"leastconn"}`,
+			expectedPatch: []byte(`{"balance":"leastconn"}`),
+		},
+		{
+			description:   "backend patch changing the name of the backend",
+			parseFn:       parseEdgeLBBackendPatch,
+			value:         `{"name":"foo"}`,
+			expectedError: true,
+		},
+		{
+			description:   "malformed backend patch",
+			parseFn:       parseEdgeLBBackendPatch,
+			value:         `{"balance":`,
+			expectedError: true,
+		},
+		{
+			description:   "valid frontend patch",
+			parseFn:       parseEdgeLBFrontendPatch,
+			value:         `{"bindAddress":"127.0.0.1"}`,
+			expectedPatch: []byte(`{"bindAddress":"127.0.0.1"}`),
+		},
+		{
+			description:   "frontend patch with a value of the wrong type",
+			parseFn:       parseEdgeLBFrontendPatch,
+			value:         `{"bindPort":"foo"}`,
+			expectedError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		patch, err := test.parseFn(test.value)
+		if test.expectedError {
+			assert.Error(t, err)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, test.expectedPatch, patch)
+	}
+}
+
+// TestApplyJSONMergePatch tests the "applyJSONMergePatch" function.
+func TestApplyJSONMergePatch(t *testing.T) {
+	tests := []struct {
+		description        string
+		backend            *models.V2Backend
+		patch              []byte
+		expectedBackend    *models.V2Backend
+		expectedWasChanged bool
+	}{
+		{
+			description: "no patch",
+			backend: &models.V2Backend{
+				Balance: "roundrobin",
+				Name:    "foo",
+			},
+			patch: nil,
+			expectedBackend: &models.V2Backend{
+				Balance: "roundrobin",
+				Name:    "foo",
+			},
+			expectedWasChanged: false,
+		},
+		{
+			description: "patch changing a field",
+			backend: &models.V2Backend{
+				Balance: "roundrobin",
+				Name:    "foo",
+			},
+			patch: []byte(`{"balance":"leastconn"}`),
+			expectedBackend: &models.V2Backend{
+				Balance: "leastconn",
+				Name:    "foo",
+			},
+			expectedWasChanged: true,
+		},
+		{
+			description: "patch removing a field",
+			backend: &models.V2Backend{
+				Balance: "roundrobin",
+				Name:    "foo",
+			},
+			patch: []byte(`{"balance":null}`),
+			expectedBackend: &models.V2Backend{
+				Name: "foo",
+			},
+			expectedWasChanged: true,
+		},
+		{
+			description: "patch that has already been applied",
+			backend: &models.V2Backend{
+				Balance: "leastconn",
+				Name:    "foo",
+			},
+			patch: []byte(`{"balance":"leastconn"}`),
+			expectedBackend: &models.V2Backend{
+				Balance: "leastconn",
+				Name:    "foo",
+			},
+			expectedWasChanged: false,
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		wasChanged, err := applyJSONMergePatch(test.backend, test.patch)
+		assert.NoError(t, err)
+		assert.Equal(t, test.expectedWasChanged, wasChanged)
+		assert.Equal(t, test.expectedBackend, test.backend)
+	}
+}
+
+// TestComputeJSONMergePatchReset tests the "computeJSONMergePatchReset" function.
+func TestComputeJSONMergePatchReset(t *testing.T) {
+	tests := []struct {
+		description   string
+		previous      string
+		current       string
+		expectedReset string
+	}{
+		{
+			description:   "no previous patch",
+			previous:      "",
+			current:       `{"constraints":"[[\"hostname\",\"UNIQUE\"]]"}`,
+			expectedReset: "",
+		},
+		{
+			description:   "unchanged patch",
+			previous:      `{"constraints":"[[\"hostname\",\"UNIQUE\"]]"}`,
+			current:       `{"constraints":"[[\"hostname\",\"UNIQUE\"]]"}`,
+			expectedReset: "",
+		},
+		{
+			description:   "field changed by the current patch",
+			previous:      `{"constraints":"[[\"hostname\",\"UNIQUE\"]]"}`,
+			current:       `{"constraints":"[[\"hostname\",\"GROUP_BY\"]]"}`,
+			expectedReset: "",
+		},
+		{
+			description:   "removed patch",
+			previous:      `{"constraints":"[[\"hostname\",\"UNIQUE\"]]","haproxy":{"stats":{"bindPort":9090}}}`,
+			current:       "",
+			expectedReset: `{"constraints":null,"haproxy":{"stats":{"bindPort":null}}}`,
+		},
+		{
+			description:   "field removed from a nested object",
+			previous:      `{"haproxy":{"stats":{"bindAddress":"0.0.0.0","bindPort":9090}}}`,
+			current:       `{"haproxy":{"stats":{"bindPort":9090}}}`,
+			expectedReset: `{"haproxy":{"stats":{"bindAddress":null}}}`,
+		},
+		{
+			description:   "nested object replaced by another value",
+			previous:      `{"haproxy":{"stats":{"bindPort":9090}}}`,
+			current:       `{"haproxy":{"stats":null}}`,
+			expectedReset: "",
+		},
+		{
+			description:   "field removed by the previous patch",
+			previous:      `{"constraints":null}`,
+			current:       "",
+			expectedReset: "",
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		reset, err := computeJSONMergePatchReset([]byte(test.previous), []byte(test.current))
+		assert.NoError(t, err)
+		if test.expectedReset == "" {
+			assert.Nil(t, reset)
+			continue
+		}
+		assert.JSONEq(t, test.expectedReset, string(reset))
+	}
+}
+
+// edgeLBPoolConstraints returns the value of the "constraints" field of the specified EdgeLB pool.
+func edgeLBPoolConstraints(pool *models.V2Pool) string {
+	v, _ := json.Marshal(pool)
+	var fields struct {
+		Constraints *string `json:"constraints"`
+	}
+	_ = json.Unmarshal(v, &fields)
+	if fields.Constraints == nil {
+		return ""
+	}
+	return *fields.Constraints
+}
+
+// TestPatchEdgeLBPool tests the "patchEdgeLBPool" function.
+func TestPatchEdgeLBPool(t *testing.T) {
+	const (
+		unique  = `{"constraints":"[[\"hostname\",\"UNIQUE\"]]"}`
+		groupBy = `{"constraints":"[[\"hostname\",\"GROUP_BY\"]]"}`
+	)
+	tests := []struct {
+		description         string
+		live                string
+		patch               string
+		lastApplied         string
+		shared              string
+		expectedWasChanged  bool
+		expectedConstraints string
+	}{
+		{
+			description:         "patch applied for the first time",
+			live:                "",
+			patch:               unique,
+			lastApplied:         "",
+			expectedWasChanged:  true,
+			expectedConstraints: `[["hostname","UNIQUE"]]`,
+		},
+		{
+			description:         "patch already applied",
+			live:                unique,
+			patch:               unique,
+			lastApplied:         unique,
+			expectedWasChanged:  false,
+			expectedConstraints: `[["hostname","UNIQUE"]]`,
+		},
+		{
+			description:         "patch reverted out-of-band",
+			live:                "",
+			patch:               unique,
+			lastApplied:         unique,
+			expectedWasChanged:  true,
+			expectedConstraints: `[["hostname","UNIQUE"]]`,
+		},
+		{
+			description:         "patch removed",
+			live:                unique,
+			patch:               "",
+			lastApplied:         unique,
+			expectedWasChanged:  true,
+			expectedConstraints: "",
+		},
+		{
+			description:         "patch removed while still applied for a resource sharing the pool",
+			live:                unique,
+			patch:               "",
+			lastApplied:         unique,
+			shared:              unique,
+			expectedWasChanged:  false,
+			expectedConstraints: `[["hostname","UNIQUE"]]`,
+		},
+		{
+			description:         "field set out-of-band and not set by any patch",
+			live:                groupBy,
+			patch:               "",
+			lastApplied:         "",
+			expectedWasChanged:  false,
+			expectedConstraints: `[["hostname","GROUP_BY"]]`,
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		pool := edgelbpooltestutil.DummyEdgeLBPool("foo", func(p *models.V2Pool) {
+			p.Haproxy.Backends = []*models.V2Backend{
+				{Name: "dev.kubernetes01:foo:bar:baz:80"},
+			}
+		})
+		_, err := applyJSONMergePatch(pool, []byte(test.live))
+		assert.NoError(t, err)
+		var patch, shared []byte
+		if test.patch != "" {
+			patch = []byte(test.patch)
+		}
+		if test.shared != "" {
+			shared = []byte(test.shared)
+		}
+		wasChanged, err := patchEdgeLBPool(pool, patch, test.lastApplied, shared)
+		assert.NoError(t, err)
+		assert.Equal(t, test.expectedWasChanged, wasChanged)
+		assert.Equal(t, test.expectedConstraints, edgeLBPoolConstraints(pool))
+		// Make sure that the fields of the pool managed by dklb are never reset.
+		assert.Equal(t, []*models.V2Backend{{Name: "dev.kubernetes01:foo:bar:baz:80"}}, pool.Haproxy.Backends)
+	}
+}
+
+// TestComputeSharedEdgeLBPoolPatch tests the "computeSharedEdgeLBPoolPatch" function.
+func TestComputeSharedEdgeLBPoolPatch(t *testing.T) {
+	const (
+		unique  = `{"constraints":"[[\"hostname\",\"UNIQUE\"]]"}`
+		groupBy = `{"constraints":"[[\"hostname\",\"GROUP_BY\"]]"}`
+	)
+	owner := &EdgeLBObjectOwner{Kind: EdgeLBObjectOwnerKindIngress, Namespace: "foo", Name: "bar"}
+	// pool is an EdgeLB pool shared by the "foo/bar" Ingress resource, the "foo/baz" Ingress resource, the "foo/qux" Service resource and the (deleted) "foo/quux" Service resource.
+	pool := edgelbpooltestutil.DummyEdgeLBPool("foo", func(p *models.V2Pool) {
+		p.Haproxy.Backends = []*models.V2Backend{
+			{Name: "dev.kubernetes01:foo:bar:svc:80"},
+			{Name: "dev.kubernetes01:foo:baz:svc:80"},
+			{Name: "dev.kubernetes01:foo:qux:80"},
+			{Name: "dev.kubernetes01:foo:quux:80"},
+		}
+	})
+	tests := []struct {
+		description   string
+		bazPatch      string
+		quxPatch      string
+		patch         string
+		expectedPatch []byte
+		expectedError bool
+	}{
+		{
+			description:   "no patch applied for any resource",
+			patch:         unique,
+			expectedPatch: nil,
+		},
+		{
+			description:   "same patch applied for another resource",
+			bazPatch:      unique,
+			patch:         unique,
+			expectedPatch: []byte(unique),
+		},
+		{
+			description:   "patch applied for another resource and no patch requested",
+			quxPatch:      unique,
+			patch:         "",
+			expectedPatch: []byte(unique),
+		},
+		{
+			description:   "diverging patch applied for another resource",
+			bazPatch:      unique,
+			quxPatch:      groupBy,
+			patch:         unique,
+			expectedError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		kubeCache := cachetestutil.NewFakeKubernetesResourceCache(
+			ingresstestutil.DummyIngressResource("foo", "bar", ingresstestutil.WithAnnotations(map[string]string{
+				constants.EdgeLBPoolAppliedPatchAnnotationKey: groupBy,
+			})),
+			ingresstestutil.DummyIngressResource("foo", "baz", ingresstestutil.WithAnnotations(map[string]string{
+				constants.EdgeLBPoolAppliedPatchAnnotationKey: test.bazPatch,
+			})),
+			servicetestutil.DummyServiceResource("foo", "qux", func(service *corev1.Service) {
+				service.Annotations = map[string]string{
+					constants.EdgeLBPoolAppliedPatchAnnotationKey: test.quxPatch,
+				}
+			}),
+		)
+		var patch []byte
+		if test.patch != "" {
+			patch = []byte(test.patch)
+		}
+		shared, err := computeSharedEdgeLBPoolPatch(kubeCache, testClusterName, pool, owner, patch)
+		if test.expectedError {
+			assert.Error(t, err)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, test.expectedPatch, shared)
+	}
+}
+
+// TestUpdateEdgeLBPoolObjectForDeletedResource tests that the fields set by the JSON merge patch last applied for a deleted Ingress/Service resource are reset when updating the EdgeLB pool object.
+func TestUpdateEdgeLBPoolObjectForDeletedResource(t *testing.T) {
+	const (
+		unique = `{"constraints":"[[\"hostname\",\"UNIQUE\"]]"}`
+	)
+	// withAppliedPatch sets the JSON merge patch last applied for the specified resource.
+	withAppliedPatch := func(obj metav1.Object, patch string) {
+		obj.SetAnnotations(map[string]string{
+			constants.EdgeLBPoolAppliedPatchAnnotationKey: patch,
+		})
+	}
+	// deletedIngress is a deleted Ingress resource for which "unique" was last applied.
+	deletedIngress := deletedDummyIngress1.DeepCopy()
+	withAppliedPatch(deletedIngress, unique)
+	// deletedService is a deleted Service resource for which "unique" was last applied.
+	deletedService := deletedServiceExposingPort80.DeepCopy()
+	withAppliedPatch(deletedService, unique)
+	// sharingIngress is an Ingress resource sharing the EdgeLB pool for which "unique" was last applied as well.
+	sharingIngress := ingresstestutil.DummyIngressResource("foo", "qux")
+	withAppliedPatch(sharingIngress, unique)
+	sharingBackend := &models.V2Backend{Name: "dev.kubernetes01:foo:qux:svc:80"}
+
+	tests := []struct {
+		description         string
+		update              func(pool *models.V2Pool, kubeCache dklbcache.KubernetesResourceCache) (bool, error)
+		backends            []*models.V2Backend
+		frontends           []*models.V2Frontend
+		resources           []runtime.Object
+		expectedConstraints string
+	}{
+		{
+			description: "deleted ingress",
+			update: func(pool *models.V2Pool, kubeCache dklbcache.KubernetesResourceCache) (bool, error) {
+				translator := NewIngressTranslator(testClusterName, deletedIngress, dummyIngress1TranslationOptions, kubeCache, edgeLBManagerForPoolGroup(), new(secretstestutil.MockSecretsManager), record.NewFakeRecorder(1))
+				m, e := translator.computeIngressBackendNodePortMap(defaultBackendNodePort)
+				wasChanged, _, err := translator.updateEdgeLBPoolObject(pool, m, e, translator.computeIngressTLSSecrets())
+				return wasChanged, err
+			},
+			backends:            []*models.V2Backend{backendForDummyIngress1Bar, backendForDummyIngress1Baz, defaultBackendForDummyIngress1},
+			frontends:           []*models.V2Frontend{frontendForDummyIngress1},
+			expectedConstraints: "",
+		},
+		{
+			description: "deleted ingress sharing the pool with a resource for which the same patch was applied",
+			update: func(pool *models.V2Pool, kubeCache dklbcache.KubernetesResourceCache) (bool, error) {
+				translator := NewIngressTranslator(testClusterName, deletedIngress, dummyIngress1TranslationOptions, kubeCache, edgeLBManagerForPoolGroup(), new(secretstestutil.MockSecretsManager), record.NewFakeRecorder(1))
+				m, e := translator.computeIngressBackendNodePortMap(defaultBackendNodePort)
+				wasChanged, _, err := translator.updateEdgeLBPoolObject(pool, m, e, translator.computeIngressTLSSecrets())
+				return wasChanged, err
+			},
+			backends:            []*models.V2Backend{backendForDummyIngress1Bar, backendForDummyIngress1Baz, defaultBackendForDummyIngress1, sharingBackend},
+			frontends:           []*models.V2Frontend{frontendForDummyIngress1},
+			resources:           []runtime.Object{sharingIngress},
+			expectedConstraints: `[["hostname","UNIQUE"]]`,
+		},
+		{
+			description: "deleted service",
+			update: func(pool *models.V2Pool, kubeCache dklbcache.KubernetesResourceCache) (bool, error) {
+				wasChanged, _, err := NewServiceTranslator(testClusterName, deletedService, serviceTranslationOptionsForPort80, kubeCache, edgeLBManagerForPoolGroup(), record.NewFakeRecorder(1)).updateEdgeLBPoolObject(pool)
+				return wasChanged, err
+			},
+			backends:            []*models.V2Backend{backendForServiceExposingPort80},
+			frontends:           []*models.V2Frontend{frontendForServiceExposingPort80},
+			expectedConstraints: "",
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		resources := append([]runtime.Object{dummyIngress1BackendFoo, dummyIngress1BackendBar, dummyIngress1BackendBaz}, test.resources...)
+		pool := edgelbpooltestutil.DummyEdgeLBPool("baz", func(p *models.V2Pool) {
+			p.Haproxy.Backends = test.backends
+			p.Haproxy.Frontends = test.frontends
+		})
+		_, err := applyJSONMergePatch(pool, []byte(unique))
+		assert.NoError(t, err)
+		wasChanged, err := test.update(pool, cachetestutil.NewFakeKubernetesResourceCache(resources...))
+		assert.NoError(t, err)
+		// The EdgeLB objects owned by the deleted resource are always removed.
+		assert.True(t, wasChanged)
+		assert.Equal(t, test.expectedConstraints, edgeLBPoolConstraints(pool))
+	}
+}
+
+// edgeLBManagerForPoolGroup returns a mock EdgeLB manager that reports "testEdgeLBPoolGroup" as the EdgeLB pool group.
+func edgeLBManagerForPoolGroup() *edgelbmanagertestutil.MockEdgeLBManager {
+	manager := new(edgelbmanagertestutil.MockEdgeLBManager)
+	manager.On("PoolGroup").Return(testEdgeLBPoolGroup)
+	return manager
+}
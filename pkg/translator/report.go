@@ -1,15 +1,238 @@
 package translator
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
+
+	"github.com/mesosphere/dcos-edge-lb/models"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/mesosphere/dklb/pkg/constants"
+	"github.com/mesosphere/dklb/pkg/edgelb/manager"
+)
+
+const (
+	// maxLastAppliedDiffLength is the maximum length of the value of the "kubernetes.dcos.io/last-applied-diff" annotation.
+	// Field-level diffs are omitted from said value whenever it would be longer than this.
+	maxLastAppliedDiffLength = 16 * 1024
+	// maxReportSummaryItems is the maximum number of changes included in the summary of a pool inspection report.
+	maxReportSummaryItems = 10
+)
+
+// poolInspectionAction is the action required for an EdgeLB object upon inspection of an EdgeLB pool.
+type poolInspectionAction string
+
+const (
+	// poolInspectionActionCreate indicates that the EdgeLB object must be created.
+	poolInspectionActionCreate = poolInspectionAction("create")
+	// poolInspectionActionDelete indicates that the EdgeLB object must be deleted.
+	poolInspectionActionDelete = poolInspectionAction("delete")
+	// poolInspectionActionKeep indicates that the EdgeLB object must be kept as is.
+	poolInspectionActionKeep = poolInspectionAction("keep")
+	// poolInspectionActionModify indicates that the EdgeLB object must be modified.
+	poolInspectionActionModify = poolInspectionAction("modify")
 )
 
+// poolInspectionItem describes the action required for a single EdgeLB object (or for the EdgeLB pool itself) upon inspection of an EdgeLB pool.
+type poolInspectionItem struct {
+	// Type is the type of the EdgeLB object (e.g. "backend").
+	Type string `json:"type"`
+	// Name is the name of the EdgeLB object.
+	Name string `json:"name"`
+	// Action is the action required for the EdgeLB object.
+	Action poolInspectionAction `json:"action"`
+	// Owner is the Ingress/Service resource that owns the EdgeLB object, if any.
+	Owner *EdgeLBObjectOwner `json:"owner,omitempty"`
+	// Reason is a human-readable explanation of why the action is required, if any.
+	Reason string `json:"reason,omitempty"`
+	// Diff holds the differences between the current and desired versions of the EdgeLB object, if it must be modified.
+	Diff []fieldDiff `json:"diff,omitempty"`
+}
+
+// String returns a condensed, human-readable representation of the item.
+func (i poolInspectionItem) String() string {
+	res := fmt.Sprintf("%s %s %q", i.Action, i.Type, i.Name)
+	if len(i.Diff) > 0 {
+		fields := make([]string, 0, len(i.Diff))
+		for _, d := range i.Diff {
+			fields = append(fields, d.Field)
+		}
+		res = fmt.Sprintf("%s (%s)", res, strings.Join(fields, ", "))
+	}
+	if i.Reason != "" {
+		res = fmt.Sprintf("%s as %s", res, i.Reason)
+	}
+	return res
+}
+
 // poolInspectionReport is a utility struct used to convey information about the status of an EdgeLB pool (and the required changes) upon inspection.
 type poolInspectionReport struct {
-	lines []string
+	// Pool is the name of the inspected EdgeLB pool.
+	Pool string `json:"pool"`
+	// Items holds the action required for each inspected EdgeLB object, in the order in which they were inspected.
+	Items []poolInspectionItem `json:"items"`
+	// Notes holds additional messages that don't refer to the action required for a given EdgeLB object.
+	Notes []string `json:"notes,omitempty"`
 }
 
 // Report adds a formatted message to the pool inspection report.
 func (pir *poolInspectionReport) Report(message string, args ...interface{}) {
-	pir.lines = append(pir.lines, fmt.Sprintf(message, args...))
+	pir.Notes = append(pir.Notes, fmt.Sprintf(message, args...))
+}
+
+// Keep records the fact that the specified EdgeLB object must be kept as is.
+func (pir *poolInspectionReport) Keep(objectType, name string, owner *EdgeLBObjectOwner) {
+	pir.Items = append(pir.Items, poolInspectionItem{Type: objectType, Name: name, Action: poolInspectionActionKeep, Owner: owner})
+}
+
+// Create records the fact that the specified EdgeLB object must be created.
+func (pir *poolInspectionReport) Create(objectType, name string, owner *EdgeLBObjectOwner) {
+	pir.Items = append(pir.Items, poolInspectionItem{Type: objectType, Name: name, Action: poolInspectionActionCreate, Owner: owner})
+}
+
+// Delete records the fact that the specified EdgeLB object must be deleted for the specified reason.
+func (pir *poolInspectionReport) Delete(objectType, name string, owner *EdgeLBObjectOwner, reason string, args ...interface{}) {
+	pir.Items = append(pir.Items, poolInspectionItem{Type: objectType, Name: name, Action: poolInspectionActionDelete, Owner: owner, Reason: fmt.Sprintf(reason, args...)})
+}
+
+// Modify records the fact that the specified EdgeLB object must be modified from "current" to "desired".
+func (pir *poolInspectionReport) Modify(objectType, name string, owner *EdgeLBObjectOwner, current, desired interface{}) {
+	pir.Items = append(pir.Items, poolInspectionItem{Type: objectType, Name: name, Action: poolInspectionActionModify, Owner: owner, Diff: computeFieldDiffs(current, desired)})
+}
+
+// ModifyPool records the fact that the EdgeLB pool itself must be modified as described by the specified field-level diffs.
+// All changes to the EdgeLB pool itself are grouped under a single item.
+func (pir *poolInspectionReport) ModifyPool(diffs ...fieldDiff) {
+	for idx := range pir.Items {
+		if pir.Items[idx].Type == EdgeLBObjectTypePool {
+			pir.Items[idx].Diff = append(pir.Items[idx].Diff, diffs...)
+			return
+		}
+	}
+	pir.Items = append(pir.Items, poolInspectionItem{Type: EdgeLBObjectTypePool, Name: pir.Pool, Action: poolInspectionActionModify, Diff: diffs})
+}
+
+// KeepAll records the fact that all EdgeLB objects of the specified types must be kept as is, regardless of any action previously recorded for them.
+func (pir *poolInspectionReport) KeepAll(objectTypes ...string) {
+	for idx := range pir.Items {
+		for _, objectType := range objectTypes {
+			if pir.Items[idx].Type == objectType {
+				pir.Items[idx].Action, pir.Items[idx].Reason, pir.Items[idx].Diff = poolInspectionActionKeep, "", nil
+			}
+		}
+	}
+}
+
+// newFieldDiff returns a field-level diff describing the change of the specified field from "current" to "desired".
+func newFieldDiff(field string, current, desired interface{}) fieldDiff {
+	d := fieldDiff{Field: field}
+	// Marshaling these values never fails, so we can safely ignore errors.
+	if !isNil(current) {
+		d.From, _ = json.Marshal(current)
+	}
+	if !isNil(desired) {
+		d.To, _ = json.Marshal(desired)
+	}
+	return d
+}
+
+// Changes returns the items that require an action other than "keep".
+func (pir *poolInspectionReport) Changes() []poolInspectionItem {
+	res := make([]poolInspectionItem, 0)
+	for _, item := range pir.Items {
+		if item.Action != poolInspectionActionKeep {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
+// Summary returns a condensed, human-readable description of the changes required to the EdgeLB pool.
+// At most "maxReportSummaryItems" changes are included.
+func (pir *poolInspectionReport) Summary() string {
+	changes := pir.Changes()
+	if len(changes) == 0 {
+		return "no changes"
+	}
+	lines := make([]string, 0, maxReportSummaryItems+1)
+	for idx, item := range changes {
+		if idx == maxReportSummaryItems {
+			lines = append(lines, fmt.Sprintf("and %d more", len(changes)-maxReportSummaryItems))
+			break
+		}
+		lines = append(lines, item.String())
+	}
+	return strings.Join(lines, "; ")
+}
+
+// LastAppliedDiff returns the JSON representation of the changes required to the EdgeLB pool, to be used as the value of the "kubernetes.dcos.io/last-applied-diff" annotation.
+// Field-level diffs are omitted in case the result would otherwise be longer than "maxLastAppliedDiffLength".
+func (pir *poolInspectionReport) LastAppliedDiff() string {
+	r := poolInspectionReport{Pool: pir.Pool, Items: pir.Changes(), Notes: pir.Notes}
+	// Marshaling the report never fails, so we can safely ignore errors.
+	v, _ := json.Marshal(r)
+	if len(v) <= maxLastAppliedDiffLength {
+		return string(v)
+	}
+	for idx := range r.Items {
+		r.Items[idx].Diff = nil
+	}
+	v, _ = json.Marshal(r)
+	return string(v)
+}
+
+// computeCreationReport returns a pool inspection report describing the creation of the specified EdgeLB pool, whose EdgeLB backends and frontends are owned by the specified Ingress/Service resource (except for EdgeLB frontends shared via TLS SNI).
+func computeCreationReport(pool *models.V2Pool, owner *EdgeLBObjectOwner) poolInspectionReport {
+	report := poolInspectionReport{Pool: pool.Name}
+	report.Create(EdgeLBObjectTypePool, pool.Name, nil)
+	for _, backend := range pool.Haproxy.Backends {
+		report.Create(EdgeLBObjectTypeBackend, backend.Name, owner)
+	}
+	for _, frontend := range pool.Haproxy.Frontends {
+		if _, err := computeSNIFrontendBindPort(frontend.Name); err == nil {
+			report.Create(EdgeLBObjectTypeFrontend, frontend.Name, nil)
+			continue
+		}
+		report.Create(EdgeLBObjectTypeFrontend, frontend.Name, owner)
+	}
+	for _, secret := range pool.Secrets {
+		report.Create(EdgeLBObjectTypeSecret, secret.File, owner)
+	}
+	return report
+}
+
+// reportEdgeLBPoolChange emits an event associated with the specified Ingress/Service resource summarizing the changes described by the specified report, which have just been made to the target EdgeLB pool.
+// "verb" describes the operation performed on the EdgeLB pool (e.g. "updated").
+// In dry-run mode, the operation recorded by the EdgeLB manager is described instead.
+func reportEdgeLBPoolChange(m manager.EdgeLBManager, recorder record.EventRecorder, obj runtime.Object, verb string, report poolInspectionReport) {
+	if manager.IsDryRun(m) {
+		reportDryRunOperation(m, recorder, obj, report.Pool)
+		return
+	}
+	recorder.Eventf(obj, corev1.EventTypeNormal, constants.ReasonEdgeLBPoolChanged, "%s edgelb pool %q: %s", verb, report.Pool, report.Summary())
+}
+
+// GetEdgeLBPoolLastAppliedDiff returns the JSON description of the changes last made to the target EdgeLB pool for the specified resource.
+// An empty string is returned in case no changes have been made yet.
+func GetEdgeLBPoolLastAppliedDiff(obj metav1.Object) string {
+	return obj.GetAnnotations()[constants.EdgeLBPoolLastAppliedDiffAnnotationKey]
+}
+
+// SetEdgeLBPoolLastAppliedDiff sets the JSON description of the changes last made to the target EdgeLB pool for the specified resource.
+// It returns a value indicating whether the value of the corresponding annotation was changed.
+func SetEdgeLBPoolLastAppliedDiff(obj metav1.Object, diff string) bool {
+	if diff == "" || GetEdgeLBPoolLastAppliedDiff(obj) == diff {
+		return false
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[constants.EdgeLBPoolLastAppliedDiffAnnotationKey] = diff
+	obj.SetAnnotations(annotations)
+	return true
 }
@@ -0,0 +1,110 @@
+package translator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mesosphere/dcos-edge-lb/models"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mesosphere/dklb/pkg/util/pointers"
+)
+
+// TestComputeFieldDiffs tests the "computeFieldDiffs" function.
+func TestComputeFieldDiffs(t *testing.T) {
+	tests := []struct {
+		description  string
+		from         interface{}
+		to           interface{}
+		expectedDiff []fieldDiff
+	}{
+		{
+			description:  "no differences",
+			from:         &models.V2Backend{Name: "foo", Balance: "roundrobin"},
+			to:           &models.V2Backend{Name: "foo", Balance: "roundrobin"},
+			expectedDiff: []fieldDiff{},
+		},
+		{
+			description: "changed field",
+			from:        &models.V2Backend{Name: "foo", Balance: "roundrobin"},
+			to:          &models.V2Backend{Name: "foo", Balance: "leastconn"},
+			expectedDiff: []fieldDiff{
+				{Field: "balance", From: json.RawMessage(`"roundrobin"`), To: json.RawMessage(`"leastconn"`)},
+			},
+		},
+		{
+			description: "set and unset fields",
+			from:        map[string]interface{}{"bar": 1, "foo": "x"},
+			to:          map[string]interface{}{"baz": []string{"y"}, "foo": "x"},
+			expectedDiff: []fieldDiff{
+				{Field: "bar", From: json.RawMessage(`1`)},
+				{Field: "baz", To: json.RawMessage(`["y"]`)},
+			},
+		},
+		{
+			description: "nil \"from\" version",
+			from:        nil,
+			to:          map[string]interface{}{"foo": "x"},
+			expectedDiff: []fieldDiff{
+				{Field: "foo", To: json.RawMessage(`"x"`)},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		assert.Equal(t, test.expectedDiff, computeFieldDiffs(test.from, test.to))
+	}
+}
+
+// TestPoolInspectionReport tests the summary and the JSON representation of a pool inspection report.
+func TestPoolInspectionReport(t *testing.T) {
+	owner := &EdgeLBObjectOwner{Kind: EdgeLBObjectOwnerKindService, Namespace: "foo", Name: "bar"}
+
+	report := poolInspectionReport{Pool: "baz"}
+	report.Keep(EdgeLBObjectTypeBackend, "kept", owner)
+	report.Create(EdgeLBObjectTypeBackend, "created", owner)
+	report.Delete(EdgeLBObjectTypeFrontend, "deleted", owner, "port %d is not defined anymore", 80)
+	report.Modify(EdgeLBObjectTypeBackend, "modified", owner, &models.V2Backend{Balance: "roundrobin"}, &models.V2Backend{Balance: "leastconn"})
+	report.ModifyPool(newFieldDiff("count", pointers.NewInt32(1), pointers.NewInt32(2)))
+	report.ModifyPool(newFieldDiff("cpus", 0.1, 0.2))
+	report.Report("something worth noting")
+
+	// Make sure that kept EdgeLB objects are not included in the summary, and that changes to the EdgeLB pool itself are grouped together.
+	assert.Equal(t, `create backend "created"; delete frontend "deleted" as port 80 is not defined anymore; modify backend "modified" (balance); modify pool "baz" (count, cpus)`, report.Summary())
+
+	// Make sure that the JSON representation of the report includes the changes (and notes), but not kept EdgeLB objects.
+	var r poolInspectionReport
+	assert.NoError(t, json.Unmarshal([]byte(report.LastAppliedDiff()), &r))
+	assert.Equal(t, "baz", r.Pool)
+	assert.Len(t, r.Items, 4)
+	assert.Equal(t, owner, r.Items[0].Owner)
+	assert.Equal(t, []fieldDiff{{Field: "balance", From: json.RawMessage(`"roundrobin"`), To: json.RawMessage(`"leastconn"`)}}, r.Items[2].Diff)
+	assert.Equal(t, []string{"something worth noting"}, r.Notes)
+
+	// Make sure that resetting changes to EdgeLB backends and frontends leaves only changes to the EdgeLB pool itself.
+	report.KeepAll(EdgeLBObjectTypeBackend, EdgeLBObjectTypeFrontend)
+	assert.Equal(t, `modify pool "baz" (count, cpus)`, report.Summary())
+}
+
+// TestPoolInspectionReportLimits tests that the summary and the JSON representation of a pool inspection report with a large number of changes are kept short.
+func TestPoolInspectionReportLimits(t *testing.T) {
+	report := poolInspectionReport{Pool: "baz"}
+	for idx := 0; idx < 2*maxReportSummaryItems; idx++ {
+		report.Modify(EdgeLBObjectTypeBackend, fmt.Sprintf("backend-%d", idx), nil, &models.V2Backend{Balance: "roundrobin"}, &models.V2Backend{Balance: strings.Repeat("x", maxLastAppliedDiffLength/maxReportSummaryItems)})
+	}
+
+	// Make sure that the summary includes at most "maxReportSummaryItems" changes.
+	assert.True(t, strings.HasSuffix(report.Summary(), fmt.Sprintf("; and %d more", maxReportSummaryItems)))
+
+	// Make sure that field-level diffs are dropped from the JSON representation of the report, but that all changes are kept.
+	v := report.LastAppliedDiff()
+	assert.True(t, len(v) <= maxLastAppliedDiffLength)
+	var r poolInspectionReport
+	assert.NoError(t, json.Unmarshal([]byte(v), &r))
+	assert.Len(t, r.Items, 2*maxReportSummaryItems)
+	for _, item := range r.Items {
+		assert.Nil(t, item.Diff)
+	}
+}
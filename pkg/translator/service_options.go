@@ -3,6 +3,7 @@ package translator
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/validation"
@@ -15,6 +16,12 @@ type ServiceTranslationOptions struct {
 	BaseTranslationOptions
 	// EdgeLBPoolPortMap is the mapping between ports defined in the Service resource and the frontend bind ports used by the EdgeLB pool.
 	EdgeLBPoolPortMap map[int32]int32
+	// EdgeLBPoolSNIHostnames is the mapping between ports defined in the Service resource and the TLS SNI hostnames for which traffic should be routed to them.
+	// Service ports present in this map are exposed via a TCP frontend shared with other Service resources using the same EdgeLB pool and frontend bind port.
+	EdgeLBPoolSNIHostnames map[int32][]string
+	// EdgeLBSourceRanges is the list of source ranges (in CIDR notation) from which connections are allowed, as specified in ".spec.loadBalancerSourceRanges".
+	// An empty list means that connections are allowed from any source.
+	EdgeLBSourceRanges []string
 }
 
 // ComputeServiceTranslationOptions computes the set of options to use for "translating" the specified Service resource into an EdgeLB pool.
@@ -40,6 +47,10 @@ func ComputeServiceTranslationOptions(clusterName string, obj *corev1.Service) (
 		return nil, err
 	}
 	res.BaseTranslationOptions = *b
+	// Make sure that the requested load-balancing algorithm can be used, as the EdgeLB backends corresponding to Service resources operate in TCP mode.
+	if isHTTPOnlyEdgeLBBackendBalance(res.EdgeLBBackendBalance) {
+		return nil, fmt.Errorf("load-balancing algorithm %q is only supported for ingresses", res.EdgeLBBackendBalance)
+	}
 
 	// Parse any port mappings that may have been provided.
 	// If no mapping for a port has been specified, the original service port is used.
@@ -84,10 +95,56 @@ func ComputeServiceTranslationOptions(clusterName string, obj *corev1.Service) (
 		}
 	}
 
+	// Parse any TLS SNI hostnames that may have been provided.
+	// Since traffic is routed to the target service port based on the TLS SNI hostname, the frontend bind port must be known in advance (i.e. it cannot be dynamic).
+	for _, port := range obj.Spec.Ports {
+		// Compute the key of the annotation that must be checked based on the current port.
+		key := fmt.Sprintf("%s%d", constants.EdgeLBPoolSNIHostnamesKeyPrefix, port.Port)
+		v, exists := obj.Annotations[key]
+		if !exists || v == "" {
+			continue
+		}
+		if res.EdgeLBPoolPortMap[port.Port] == 0 {
+			return nil, fmt.Errorf("tls sni hostnames cannot be specified for port %d as it uses a dynamic frontend bind port", port.Port)
+		}
+		hostnames, err := parseSNIHostnames(v)
+		if err != nil {
+			return nil, err
+		}
+		if res.EdgeLBPoolSNIHostnames == nil {
+			res.EdgeLBPoolSNIHostnames = make(map[int32][]string)
+		}
+		res.EdgeLBPoolSNIHostnames[port.Port] = hostnames
+	}
+
+	// Parse the source ranges from which connections are allowed.
+	if res.EdgeLBSourceRanges, err = parseSourceRanges(obj.Spec.LoadBalancerSourceRanges); err != nil {
+		return nil, err
+	}
+
 	// Return the computed set of options
 	return res, nil
 }
 
+// parseSNIHostnames parses the provided comma-separated list of TLS SNI hostnames.
+// Duplicate hostnames are ignored, and an error is returned if any of the hostnames is not a valid DNS-1123 subdomain.
+func parseSNIHostnames(v string) ([]string, error) {
+	res := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, hostname := range strings.Split(v, ",") {
+		hostname = strings.TrimSpace(hostname)
+		if hostname == "" || seen[hostname] {
+			continue
+		}
+		if errs := validation.IsDNS1123Subdomain(hostname); len(errs) > 0 {
+			return nil, fmt.Errorf("%q is not a valid tls sni hostname: %s", hostname, strings.Join(errs, ", "))
+		}
+		seen[hostname] = true
+		res = append(res, hostname)
+	}
+	return res, nil
+}
+
 // ValidateServiceTranslationOptionsUpdate validates the transition between "previousOptions" and "currentOptions".
 func ValidateServiceTranslationOptionsUpdate(previousOptions, currentOptions *ServiceTranslationOptions) error {
 	return ValidateBaseTranslationOptionsUpdate(&previousOptions.BaseTranslationOptions, &currentOptions.BaseTranslationOptions)
@@ -2,10 +2,13 @@ package translator_test
 
 import (
 	"fmt"
+	"net"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	"github.com/mesosphere/dklb/pkg/constants"
 	"github.com/mesosphere/dklb/pkg/translator"
@@ -16,11 +19,12 @@ import (
 // TestComputeServiceTranslationOptions tests parsing of annotations defined in a Service resource.
 func TestComputeServiceTranslationOptions(t *testing.T) {
 	tests := []struct {
-		description string
-		annotations map[string]string
-		ports       []corev1.ServicePort
-		options     *translator.ServiceTranslationOptions
-		error       error
+		description  string
+		annotations  map[string]string
+		ports        []corev1.ServicePort
+		sourceRanges []string
+		options      *translator.ServiceTranslationOptions
+		error        error
 	}{
 		// Test computing options for a Service resource without any annotations.
 		// Make sure the name of the EdgeLB pool is computed as expected, and that the default values are used everywhere else.
@@ -42,6 +46,9 @@ func TestComputeServiceTranslationOptions(t *testing.T) {
 					EdgeLBPoolMem:                  translator.DefaultEdgeLBPoolMem,
 					EdgeLBPoolSize:                 translator.DefaultEdgeLBPoolSize,
 					EdgeLBPoolCreationStrategy:     translator.DefaultEdgeLBPoolCreationStrategy,
+					EdgeLBPoolDriftPolicy:          translator.DefaultEdgeLBPoolDriftPolicy,
+					EdgeLBBackendBalance:           translator.DefaultEdgeLBBackendBalance,
+					EdgeLBBackendTarget:            translator.DefaultEdgeLBBackendTarget,
 				},
 				EdgeLBPoolPortMap: map[int32]int32{
 					80: 80,
@@ -71,6 +78,9 @@ func TestComputeServiceTranslationOptions(t *testing.T) {
 					EdgeLBPoolMem:                  translator.DefaultEdgeLBPoolMem,
 					EdgeLBPoolSize:                 translator.DefaultEdgeLBPoolSize,
 					EdgeLBPoolCreationStrategy:     translator.DefaultEdgeLBPoolCreationStrategy,
+					EdgeLBPoolDriftPolicy:          translator.DefaultEdgeLBPoolDriftPolicy,
+					EdgeLBBackendBalance:           translator.DefaultEdgeLBBackendBalance,
+					EdgeLBBackendTarget:            translator.DefaultEdgeLBBackendTarget,
 				},
 				EdgeLBPoolPortMap: map[int32]int32{
 					80: 80,
@@ -103,6 +113,9 @@ func TestComputeServiceTranslationOptions(t *testing.T) {
 					EdgeLBPoolMem:                  translator.DefaultEdgeLBPoolMem,
 					EdgeLBPoolSize:                 translator.DefaultEdgeLBPoolSize,
 					EdgeLBPoolCreationStrategy:     translator.DefaultEdgeLBPoolCreationStrategy,
+					EdgeLBPoolDriftPolicy:          translator.DefaultEdgeLBPoolDriftPolicy,
+					EdgeLBBackendBalance:           translator.DefaultEdgeLBBackendBalance,
+					EdgeLBBackendTarget:            translator.DefaultEdgeLBBackendTarget,
 				},
 				EdgeLBPoolPortMap: map[int32]int32{
 					80:  8080,
@@ -183,6 +196,157 @@ func TestComputeServiceTranslationOptions(t *testing.T) {
 			options: nil,
 			error:   fmt.Errorf("failed to parse %q as a frontend bind port: %v", "foo", "strconv.Atoi: parsing \"foo\": invalid syntax"),
 		},
+		// Test computing options for a Service resource specifying tls sni hostnames for one of its ports.
+		// Make sure the hostnames are captured as expected (ignoring whitespace and duplicates), and that the default values are used everywhere else.
+		{
+			description: "compute options for a Service resource specifying tls sni hostnames for one of its ports",
+			annotations: map[string]string{
+				fmt.Sprintf("%s%d", constants.EdgeLBPoolPortMapKeyPrefix, 443):      "8443",
+				fmt.Sprintf("%s%d", constants.EdgeLBPoolSNIHostnamesKeyPrefix, 443): "foo.example.com, bar.example.com,foo.example.com",
+			},
+			ports: []corev1.ServicePort{
+				{
+					Port: 80,
+				},
+				{
+					Port: 443,
+				},
+			},
+			options: &translator.ServiceTranslationOptions{
+				BaseTranslationOptions: translator.BaseTranslationOptions{
+					CloudLoadBalancerConfigMapName: nil,
+					EdgeLBPoolName:                 "dev--kubernetes01--foo--bar",
+					EdgeLBPoolRole:                 translator.DefaultEdgeLBPoolRole,
+					EdgeLBPoolNetwork:              constants.EdgeLBHostNetwork,
+					EdgeLBPoolCpus:                 translator.DefaultEdgeLBPoolCpus,
+					EdgeLBPoolMem:                  translator.DefaultEdgeLBPoolMem,
+					EdgeLBPoolSize:                 translator.DefaultEdgeLBPoolSize,
+					EdgeLBPoolCreationStrategy:     translator.DefaultEdgeLBPoolCreationStrategy,
+					EdgeLBPoolDriftPolicy:          translator.DefaultEdgeLBPoolDriftPolicy,
+					EdgeLBBackendBalance:           translator.DefaultEdgeLBBackendBalance,
+					EdgeLBBackendTarget:            translator.DefaultEdgeLBBackendTarget,
+				},
+				EdgeLBPoolPortMap: map[int32]int32{
+					80:  80,
+					443: 8443,
+				},
+				EdgeLBPoolSNIHostnames: map[int32][]string{
+					443: {"foo.example.com", "bar.example.com"},
+				},
+			},
+			error: nil,
+		},
+		// Test computing options for a Service resource specifying an invalid tls sni hostname.
+		// Make sure an error is returned.
+		{
+			description: "compute options for a Service resource specifying an invalid tls sni hostname",
+			annotations: map[string]string{
+				fmt.Sprintf("%s%d", constants.EdgeLBPoolSNIHostnamesKeyPrefix, 443): "Foo_Bar",
+			},
+			ports: []corev1.ServicePort{
+				{
+					Port: 443,
+				},
+			},
+			options: nil,
+			error:   fmt.Errorf("%q is not a valid tls sni hostname: %s", "Foo_Bar", strings.Join(validation.IsDNS1123Subdomain("Foo_Bar"), ", ")),
+		},
+		// Test computing options for a Service resource specifying tls sni hostnames for a port using a dynamic frontend bind port.
+		// Make sure an error is returned.
+		{
+			description: "compute options for a Service resource specifying tls sni hostnames for a port using a dynamic frontend bind port",
+			annotations: map[string]string{
+				fmt.Sprintf("%s%d", constants.EdgeLBPoolPortMapKeyPrefix, 443):      "0",
+				fmt.Sprintf("%s%d", constants.EdgeLBPoolSNIHostnamesKeyPrefix, 443): "foo.example.com",
+			},
+			ports: []corev1.ServicePort{
+				{
+					Port: 443,
+				},
+			},
+			options: nil,
+			error:   fmt.Errorf("tls sni hostnames cannot be specified for port %d as it uses a dynamic frontend bind port", 443),
+		},
+		// Test computing options for a Service resource requesting a load-balancing algorithm that requires http.
+		// Make sure an error is returned.
+		{
+			description: "compute options for a Service resource requesting a load-balancing algorithm that requires http",
+			annotations: map[string]string{
+				constants.EdgeLBBackendBalanceAnnotationKey: constants.EdgeLBBackendBalanceURIHash,
+			},
+			ports: []corev1.ServicePort{
+				{
+					Port: 80,
+				},
+			},
+			options: nil,
+			error:   fmt.Errorf("load-balancing algorithm %q is only supported for ingresses", constants.EdgeLBBackendBalanceURIHash),
+		},
+		// Test computing options for a Service resource requesting pod ips to be targeted by a pool that doesn't join a dcos virtual network.
+		// Make sure an error is returned.
+		{
+			description: "compute options for a Service resource requesting pod ips to be targeted by a pool that doesn't join a dcos virtual network",
+			annotations: map[string]string{
+				constants.EdgeLBBackendTargetAnnotationKey: string(constants.EdgeLBBackendTargetPodIP),
+			},
+			ports: []corev1.ServicePort{
+				{
+					Port: 80,
+				},
+			},
+			options: nil,
+			error:   fmt.Errorf("cannot target pod ips when the pool doesn't join a dcos virtual network"),
+		},
+		// Test computing options for a Service resource specifying source ranges.
+		// Make sure the source ranges are captured as expected.
+		{
+			description: "compute options for a Service resource specifying source ranges",
+			annotations: map[string]string{
+				constants.EdgeLBPoolNameAnnotationKey: "foo",
+			},
+			ports: []corev1.ServicePort{
+				{
+					Port: 80,
+				},
+			},
+			sourceRanges: []string{"10.0.0.0/8", " 192.168.1.1/32"},
+			options: &translator.ServiceTranslationOptions{
+				BaseTranslationOptions: translator.BaseTranslationOptions{
+					CloudLoadBalancerConfigMapName: nil,
+					EdgeLBPoolName:                 "foo",
+					EdgeLBPoolRole:                 translator.DefaultEdgeLBPoolRole,
+					EdgeLBPoolNetwork:              constants.EdgeLBHostNetwork,
+					EdgeLBPoolCpus:                 translator.DefaultEdgeLBPoolCpus,
+					EdgeLBPoolMem:                  translator.DefaultEdgeLBPoolMem,
+					EdgeLBPoolSize:                 translator.DefaultEdgeLBPoolSize,
+					EdgeLBPoolCreationStrategy:     translator.DefaultEdgeLBPoolCreationStrategy,
+					EdgeLBPoolDriftPolicy:          translator.DefaultEdgeLBPoolDriftPolicy,
+					EdgeLBBackendBalance:           translator.DefaultEdgeLBBackendBalance,
+					EdgeLBBackendTarget:            translator.DefaultEdgeLBBackendTarget,
+				},
+				EdgeLBPoolPortMap: map[int32]int32{
+					80: 80,
+				},
+				EdgeLBSourceRanges: []string{"10.0.0.0/8", "192.168.1.1/32"},
+			},
+			error: nil,
+		},
+		// Test computing options for a Service resource specifying an invalid source range.
+		// Make sure an error is returned.
+		{
+			description: "compute options for a Service resource specifying an invalid source range",
+			annotations: map[string]string{
+				constants.EdgeLBPoolNameAnnotationKey: "foo",
+			},
+			ports: []corev1.ServicePort{
+				{
+					Port: 80,
+				},
+			},
+			sourceRanges: []string{"10.0.0.0/33"},
+			options:      nil,
+			error:        fmt.Errorf("%q is not a valid source range: %v", "10.0.0.0/33", &net.ParseError{Type: "CIDR address", Text: "10.0.0.0/33"}),
+		},
 		// Test computing options for a Service resource having an invalid CPU request.
 		// Make sure an error is returned.
 		{
@@ -243,6 +407,194 @@ func TestComputeServiceTranslationOptions(t *testing.T) {
 			options: nil,
 			error:   fmt.Errorf("%d is not a valid size", -1),
 		},
+		// Test computing options for a Service resource requesting autoscaling of the target EdgeLB pool.
+		// Make sure the autoscaling configuration is captured as expected, and that the requested size is adjusted to the requested bounds.
+		{
+			description: "compute options for a Service resource requesting autoscaling of the target EdgeLB pool",
+			annotations: map[string]string{
+				constants.EdgeLBPoolNameAnnotationKey:                         "foo",
+				constants.EdgeLBPoolSizeAnnotationKey:                         "1",
+				constants.EdgeLBPoolMinSizeAnnotationKey:                      "2",
+				constants.EdgeLBPoolMaxSizeAnnotationKey:                      "5",
+				constants.EdgeLBPoolTargetConnectionsPerInstanceAnnotationKey: "500",
+			},
+			ports: []corev1.ServicePort{
+				{
+					Port: 80,
+				},
+			},
+			options: &translator.ServiceTranslationOptions{
+				BaseTranslationOptions: translator.BaseTranslationOptions{
+					CloudLoadBalancerConfigMapName:         nil,
+					EdgeLBPoolName:                         "foo",
+					EdgeLBPoolRole:                         translator.DefaultEdgeLBPoolRole,
+					EdgeLBPoolNetwork:                      constants.EdgeLBHostNetwork,
+					EdgeLBPoolCpus:                         translator.DefaultEdgeLBPoolCpus,
+					EdgeLBPoolMem:                          translator.DefaultEdgeLBPoolMem,
+					EdgeLBPoolSize:                         2,
+					EdgeLBPoolMinSize:                      2,
+					EdgeLBPoolMaxSize:                      5,
+					EdgeLBPoolTargetConnectionsPerInstance: 500,
+					EdgeLBPoolCreationStrategy:             translator.DefaultEdgeLBPoolCreationStrategy,
+					EdgeLBPoolDriftPolicy:                  translator.DefaultEdgeLBPoolDriftPolicy,
+					EdgeLBBackendBalance:                   translator.DefaultEdgeLBBackendBalance,
+					EdgeLBBackendTarget:                    translator.DefaultEdgeLBBackendTarget,
+				},
+				EdgeLBPoolPortMap: map[int32]int32{
+					80: 80,
+				},
+			},
+			error: nil,
+		},
+		// Test computing options for a Service resource specifying the minimum size of the target EdgeLB pool but not its maximum size.
+		// Make sure an error is returned.
+		{
+			description: "compute options for a Service resource specifying the minimum size of the target EdgeLB pool but not its maximum size",
+			annotations: map[string]string{
+				constants.EdgeLBPoolMinSizeAnnotationKey: "2",
+			},
+			ports: []corev1.ServicePort{
+				{
+					Port: 80,
+				},
+			},
+			options: nil,
+			error:   fmt.Errorf("the maximum size of the edgelb pool must be specified in order to enable autoscaling"),
+		},
+		// Test computing options for a Service resource specifying a minimum size greater than the maximum size of the target EdgeLB pool.
+		// Make sure an error is returned.
+		{
+			description: "compute options for a Service resource specifying a minimum size greater than the maximum size of the target EdgeLB pool",
+			annotations: map[string]string{
+				constants.EdgeLBPoolMinSizeAnnotationKey: "4",
+				constants.EdgeLBPoolMaxSizeAnnotationKey: "3",
+			},
+			ports: []corev1.ServicePort{
+				{
+					Port: 80,
+				},
+			},
+			options: nil,
+			error:   fmt.Errorf("the minimum size of the edgelb pool (%d) cannot be greater than its maximum size (%d)", 4, 3),
+		},
+		// Test computing options for a Service resource specifying an invalid target number of connections per instance.
+		// Make sure an error is returned.
+		{
+			description: "compute options for a Service resource specifying an invalid target number of connections per instance",
+			annotations: map[string]string{
+				constants.EdgeLBPoolMaxSizeAnnotationKey:                      "3",
+				constants.EdgeLBPoolTargetConnectionsPerInstanceAnnotationKey: "0",
+			},
+			ports: []corev1.ServicePort{
+				{
+					Port: 80,
+				},
+			},
+			options: nil,
+			error:   fmt.Errorf("%d is not a valid %s", 0, "target number of connections per instance"),
+		},
+		// Test computing options for a Service resource specifying JSON merge patches for the target EdgeLB pool and for its EdgeLB backends and frontends.
+		// Make sure the patches are captured as expected.
+		{
+			description: "compute options for a Service resource specifying json merge patches",
+			annotations: map[string]string{
+				constants.EdgeLBPoolNameAnnotationKey:      "foo",
+				constants.EdgeLBPoolPatchAnnotationKey:     `{"constraints":"[[\"hostname\",\"UNIQUE\"]]"}`,
+				constants.EdgeLBBackendPatchAnnotationKey:  `{"balance":"leastconn"}`,
+				constants.EdgeLBFrontendPatchAnnotationKey: `{"bindAddress":"127.0.0.1"}`,
+			},
+			ports: []corev1.ServicePort{
+				{
+					Port: 80,
+				},
+			},
+			options: &translator.ServiceTranslationOptions{
+				BaseTranslationOptions: translator.BaseTranslationOptions{
+					CloudLoadBalancerConfigMapName: nil,
+					EdgeLBPoolName:                 "foo",
+					EdgeLBPoolRole:                 translator.DefaultEdgeLBPoolRole,
+					EdgeLBPoolNetwork:              constants.EdgeLBHostNetwork,
+					EdgeLBPoolCpus:                 translator.DefaultEdgeLBPoolCpus,
+					EdgeLBPoolMem:                  translator.DefaultEdgeLBPoolMem,
+					EdgeLBPoolSize:                 translator.DefaultEdgeLBPoolSize,
+					EdgeLBPoolCreationStrategy:     translator.DefaultEdgeLBPoolCreationStrategy,
+					EdgeLBPoolDriftPolicy:          translator.DefaultEdgeLBPoolDriftPolicy,
+					EdgeLBBackendBalance:           translator.DefaultEdgeLBBackendBalance,
+					EdgeLBBackendTarget:            translator.DefaultEdgeLBBackendTarget,
+					EdgeLBPoolPatch:                []byte(`{"constraints":"[[\"hostname\",\"UNIQUE\"]]"}`),
+					EdgeLBBackendPatch:             []byte(`{"balance":"leastconn"}`),
+					EdgeLBFrontendPatch:            []byte(`{"bindAddress":"127.0.0.1"}`),
+				},
+				EdgeLBPoolPortMap: map[int32]int32{
+					80: 80,
+				},
+			},
+			error: nil,
+		},
+		// Test computing options for a Service resource specifying a JSON merge patch that changes the size of the target EdgeLB pool.
+		// Make sure an error is returned.
+		{
+			description: "compute options for a Service resource specifying a json merge patch that changes the size of the target EdgeLB pool",
+			annotations: map[string]string{
+				constants.EdgeLBPoolPatchAnnotationKey: `{"count":3}`,
+			},
+			ports: []corev1.ServicePort{
+				{
+					Port: 80,
+				},
+			},
+			options: nil,
+			error:   fmt.Errorf("the %q field of an %s cannot be patched", "count", "edgelb pool"),
+		},
+		// Test computing options for a Service resource requesting changes made out-of-band to be reported only.
+		// Make sure the drift policy is captured as expected.
+		{
+			description: "compute options for a Service resource specifying a drift policy",
+			annotations: map[string]string{
+				constants.EdgeLBPoolNameAnnotationKey:        "foo",
+				constants.EdgeLBPoolDriftPolicyAnnotationKey: string(constants.EdgeLBPoolDriftPolicyReport),
+			},
+			ports: []corev1.ServicePort{
+				{
+					Port: 80,
+				},
+			},
+			options: &translator.ServiceTranslationOptions{
+				BaseTranslationOptions: translator.BaseTranslationOptions{
+					CloudLoadBalancerConfigMapName: nil,
+					EdgeLBPoolName:                 "foo",
+					EdgeLBPoolRole:                 translator.DefaultEdgeLBPoolRole,
+					EdgeLBPoolNetwork:              constants.EdgeLBHostNetwork,
+					EdgeLBPoolCpus:                 translator.DefaultEdgeLBPoolCpus,
+					EdgeLBPoolMem:                  translator.DefaultEdgeLBPoolMem,
+					EdgeLBPoolSize:                 translator.DefaultEdgeLBPoolSize,
+					EdgeLBPoolCreationStrategy:     translator.DefaultEdgeLBPoolCreationStrategy,
+					EdgeLBPoolDriftPolicy:          constants.EdgeLBPoolDriftPolicyReport,
+					EdgeLBBackendBalance:           translator.DefaultEdgeLBBackendBalance,
+					EdgeLBBackendTarget:            translator.DefaultEdgeLBBackendTarget,
+				},
+				EdgeLBPoolPortMap: map[int32]int32{
+					80: 80,
+				},
+			},
+			error: nil,
+		},
+		// Test computing options for a Service resource specifying an invalid drift policy.
+		// Make sure an error is returned.
+		{
+			description: "compute options for a Service resource specifying an invalid drift policy",
+			annotations: map[string]string{
+				constants.EdgeLBPoolNameAnnotationKey:        "foo",
+				constants.EdgeLBPoolDriftPolicyAnnotationKey: "Ignore",
+			},
+			ports: []corev1.ServicePort{
+				{
+					Port: 80,
+				},
+			},
+			options: nil,
+			error:   fmt.Errorf("failed to parse %q as a drift policy", "Ignore"),
+		},
 		// Test computing options for a Service resource requested for public exposure in an empty DC/OS virtual network.
 		// Make sure that no error occurs.
 		{
@@ -266,6 +618,9 @@ func TestComputeServiceTranslationOptions(t *testing.T) {
 					EdgeLBPoolMem:                  translator.DefaultEdgeLBPoolMem,
 					EdgeLBPoolSize:                 translator.DefaultEdgeLBPoolSize,
 					EdgeLBPoolCreationStrategy:     translator.DefaultEdgeLBPoolCreationStrategy,
+					EdgeLBPoolDriftPolicy:          translator.DefaultEdgeLBPoolDriftPolicy,
+					EdgeLBBackendBalance:           translator.DefaultEdgeLBBackendBalance,
+					EdgeLBBackendTarget:            translator.DefaultEdgeLBBackendTarget,
 				},
 				EdgeLBPoolPortMap: map[int32]int32{
 					80: 80,
@@ -312,6 +667,9 @@ func TestComputeServiceTranslationOptions(t *testing.T) {
 					EdgeLBPoolMem:                  translator.DefaultEdgeLBPoolMem,
 					EdgeLBPoolSize:                 translator.DefaultEdgeLBPoolSize,
 					EdgeLBPoolCreationStrategy:     translator.DefaultEdgeLBPoolCreationStrategy,
+					EdgeLBPoolDriftPolicy:          translator.DefaultEdgeLBPoolDriftPolicy,
+					EdgeLBBackendBalance:           translator.DefaultEdgeLBBackendBalance,
+					EdgeLBBackendTarget:            translator.DefaultEdgeLBBackendTarget,
 				},
 				EdgeLBPoolPortMap: map[int32]int32{
 					80: 80,
@@ -341,6 +699,9 @@ func TestComputeServiceTranslationOptions(t *testing.T) {
 					EdgeLBPoolMem:                  translator.DefaultEdgeLBPoolMem,
 					EdgeLBPoolSize:                 translator.DefaultEdgeLBPoolSize,
 					EdgeLBPoolCreationStrategy:     translator.DefaultEdgeLBPoolCreationStrategy,
+					EdgeLBPoolDriftPolicy:          translator.DefaultEdgeLBPoolDriftPolicy,
+					EdgeLBBackendBalance:           translator.DefaultEdgeLBBackendBalance,
+					EdgeLBBackendTarget:            translator.DefaultEdgeLBBackendTarget,
 				},
 				EdgeLBPoolPortMap: map[int32]int32{
 					80: 80,
@@ -369,6 +730,9 @@ func TestComputeServiceTranslationOptions(t *testing.T) {
 					EdgeLBPoolMem:                  translator.DefaultEdgeLBPoolMem,
 					EdgeLBPoolSize:                 translator.DefaultEdgeLBPoolSize,
 					EdgeLBPoolCreationStrategy:     translator.DefaultEdgeLBPoolCreationStrategy,
+					EdgeLBPoolDriftPolicy:          translator.DefaultEdgeLBPoolDriftPolicy,
+					EdgeLBBackendBalance:           translator.DefaultEdgeLBBackendBalance,
+					EdgeLBBackendTarget:            translator.DefaultEdgeLBBackendTarget,
 				},
 				EdgeLBPoolPortMap: map[int32]int32{
 					80: 0,
@@ -380,7 +744,7 @@ func TestComputeServiceTranslationOptions(t *testing.T) {
 	for _, test := range tests {
 		t.Logf("test case: %s", test.description)
 		// Create a dummy Service resource containing the annotations for the current test.
-		r := servicetestutil.DummyServiceResource("foo", "bar", servicetestutil.WithAnnotations(test.annotations), servicetestutil.WithPorts(test.ports))
+		r := servicetestutil.DummyServiceResource("foo", "bar", servicetestutil.WithAnnotations(test.annotations), servicetestutil.WithPorts(test.ports), servicetestutil.WithLoadBalancerSourceRanges(test.sourceRanges))
 		// Compute the translation options for the resource.
 		o, err := translator.ComputeServiceTranslationOptions(testClusterName, r)
 		if err != nil {
@@ -8,6 +8,7 @@ import (
 	"github.com/mesosphere/dcos-edge-lb/models"
 	log "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
 
 	dklbcache "github.com/mesosphere/dklb/pkg/cache"
 	"github.com/mesosphere/dklb/pkg/constants"
@@ -33,18 +34,35 @@ type ServiceTranslator struct {
 	logger *log.Entry
 	// poolGroup is the DC/OS service group in which to create EdgeLB pools.
 	poolGroup string
+	// recorder is the event recorder used to emit events associated with a given Service resource.
+	recorder record.EventRecorder
+	// appliedStateHash is the hash of the EdgeLB objects computed for the Service resource during the last call to "Translate".
+	appliedStateHash string
+	// lastAppliedDiff is the JSON description of the changes made to the target EdgeLB pool during the last call to "Translate".
+	lastAppliedDiff string
+	// appliedResources is the JSON description of the CPU and memory requests and of the size applied to the target EdgeLB pool for the Service resource during the last call to "Translate".
+	appliedResources string
+	// appliedPoolPatch is the JSON merge patch applied to the target EdgeLB pool for the Service resource.
+	// It is initialized from the Service resource's annotations, and is updated whenever the JSON merge patch requested for the Service resource is applied to the target EdgeLB pool.
+	appliedPoolPatch string
+	// reportedDriftHash is the hash of the changes made out-of-band to the target EdgeLB pool that have been reported for the Service resource.
+	// It is initialized from the Service resource's annotations, and is only updated in case the target EdgeLB pool has been checked for such changes.
+	reportedDriftHash string
 }
 
 // NewServiceTranslator returns a service translator that can be used to translate the specified Service resource into an EdgeLB pool.
-func NewServiceTranslator(clusterName string, service *corev1.Service, options ServiceTranslationOptions, kubeCache dklbcache.KubernetesResourceCache, manager manager.EdgeLBManager) *ServiceTranslator {
+func NewServiceTranslator(clusterName string, service *corev1.Service, options ServiceTranslationOptions, kubeCache dklbcache.KubernetesResourceCache, manager manager.EdgeLBManager, recorder record.EventRecorder) *ServiceTranslator {
 	return &ServiceTranslator{
-		clusterName: clusterName,
-		service:     service,
-		options:     options,
-		kubeCache:   kubeCache,
-		manager:     manager,
-		logger:      log.WithField("service", kubernetesutil.Key(service)),
-		poolGroup:   manager.PoolGroup(),
+		clusterName:       clusterName,
+		service:           service,
+		options:           options,
+		kubeCache:         kubeCache,
+		manager:           manager,
+		logger:            log.WithField("service", kubernetesutil.Key(service)),
+		poolGroup:         manager.PoolGroup(),
+		recorder:          recorder,
+		reportedDriftHash: GetEdgeLBPoolReportedDriftHash(service),
+		appliedPoolPatch:  GetEdgeLBPoolAppliedPatch(service),
 	}
 }
 
@@ -66,13 +84,79 @@ func (st *ServiceTranslator) Translate() (*corev1.LoadBalancerStatus, error) {
 		}
 	}
 	// If the target EdgeLB pool does not exist, we must try to create it,
+	// unless the Service resource has been deleted (or is not of type "LoadBalancer" anymore), in which case there is nothing to clean up.
 	if pool == nil {
+		if st.service.DeletionTimestamp != nil || st.service.Spec.Type != corev1.ServiceTypeLoadBalancer {
+			return &corev1.LoadBalancerStatus{}, nil
+		}
 		return st.createEdgeLBPool()
 	}
 	// If the target EdgeLB pool already exists, we must check whether it needs to be updated/deleted.
 	return st.updateOrDeleteEdgeLBPool(pool)
 }
 
+// AppliedStateHash returns the hash of the EdgeLB objects computed for the associated Service resource during the last call to "Translate".
+// It must be recorded on the Service resource (using "SetEdgeLBPoolAppliedStateHash") so that changes made out-of-band to these EdgeLB objects can be detected.
+// An empty string is returned in case there is nothing to record (e.g. because the Service resource has been deleted).
+func (st *ServiceTranslator) AppliedStateHash() string {
+	// Nothing is actually applied to the target EdgeLB pool in dry-run mode, so there is nothing to record either.
+	if manager.IsDryRun(st.manager) {
+		return ""
+	}
+	return st.appliedStateHash
+}
+
+// AppliedResources returns the JSON description of the CPU and memory requests and of the size applied to the target EdgeLB pool for the associated Service resource during the last call to "Translate".
+// It must be recorded on the Service resource (using "SetEdgeLBPoolAppliedResources") so that these fields of the target EdgeLB pool are only changed when the corresponding annotations change.
+// An empty string is returned in case there is nothing to record (e.g. because the Service resource has been deleted, or because the EdgeLB pool creation strategy is "Never").
+func (st *ServiceTranslator) AppliedResources() string {
+	// Nothing is actually applied to the target EdgeLB pool in dry-run mode, so there is nothing to record either.
+	if manager.IsDryRun(st.manager) {
+		return ""
+	}
+	return st.appliedResources
+}
+
+// AppliedEdgeLBPoolPatch returns the JSON merge patch applied to the target EdgeLB pool for the associated Service resource.
+// It must be recorded on the Service resource (using "SetEdgeLBPoolAppliedPatch") so that the fields that are no longer set by the requested JSON merge patch can be reset.
+// An empty string is returned in case no JSON merge patch is applied to the target EdgeLB pool for the Service resource.
+func (st *ServiceTranslator) AppliedEdgeLBPoolPatch() string {
+	// Nothing is actually applied to the target EdgeLB pool in dry-run mode, so the JSON merge patch that has last been recorded is kept.
+	if manager.IsDryRun(st.manager) {
+		return GetEdgeLBPoolAppliedPatch(st.service)
+	}
+	return st.appliedPoolPatch
+}
+
+// ReportedDriftHash returns the hash of the changes made out-of-band to the target EdgeLB pool that have been reported for the associated Service resource.
+// It must be recorded on the Service resource (using "SetEdgeLBPoolReportedDriftHash") so that the same changes are not reported again on every resync.
+// An empty string is returned in case the target EdgeLB pool doesn't contain any such changes.
+func (st *ServiceTranslator) ReportedDriftHash() string {
+	return st.reportedDriftHash
+}
+
+// LastAppliedDiff returns the JSON description of the changes made to the target EdgeLB pool during the last call to "Translate".
+// It must be recorded on the Service resource (using "SetEdgeLBPoolLastAppliedDiff") so that the reason why the EdgeLB pool changed can be inspected later on.
+// An empty string is returned in case the EdgeLB pool was not created or updated.
+func (st *ServiceTranslator) LastAppliedDiff() string {
+	// Nothing is actually applied to the target EdgeLB pool in dry-run mode, so there is nothing to record either.
+	if manager.IsDryRun(st.manager) {
+		return ""
+	}
+	return st.lastAppliedDiff
+}
+
+// owner returns the identity of the associated Service resource as the owner of EdgeLB objects.
+func (st *ServiceTranslator) owner() *EdgeLBObjectOwner {
+	return &EdgeLBObjectOwner{Kind: EdgeLBObjectOwnerKindService, Namespace: st.service.Namespace, Name: st.service.Name}
+}
+
+// isEdgeLBObjectOwned returns a value indicating whether the EdgeLB backend/frontend with the specified name is owned by the associated Service resource.
+func (st *ServiceTranslator) isEdgeLBObjectOwned(name string) bool {
+	metadata, err := computeServiceOwnedEdgeLBObjectMetadata(name)
+	return err == nil && metadata.IsOwnedBy(st.clusterName, st.service)
+}
+
 // createEdgeLBPool makes a decision on whether an EdgeLB pool should be created for the associated Service resource.
 // This decision is based on the pool creation strategy specified for the Service resource.
 // In case it should be created, it proceeds to actually creating it.
@@ -85,7 +169,8 @@ func (st *ServiceTranslator) createEdgeLBPool() (*corev1.LoadBalancerStatus, err
 
 	// If the Service resource's ".status" field contains at least one IP/host, that means a pool has once existed, but has been deleted manually.
 	// Hence, and if the pool creation strategy is "Once", we should also just exit.
-	if len(st.service.Status.LoadBalancer.Ingress) > 0 && st.options.EdgeLBPoolCreationStrategy == constants.EdgeLBPoolCreationStrategyOnce {
+	// The ".status" field is not taken into account while the resource is being migrated to the target EdgeLB pool, as it reports the addresses of the old EdgeLB pool.
+	if len(st.service.Status.LoadBalancer.Ingress) > 0 && st.options.EdgeLBPoolCreationStrategy == constants.EdgeLBPoolCreationStrategyOnce && !isBeingMigratedTo(st.service, st.options.EdgeLBPoolName) {
 		return nil, fmt.Errorf("edgelb pool %q targeted by service %q has probably been manually deleted, and the pool creation strategy is %q", st.options.EdgeLBPoolName, kubernetesutil.Key(st.service), st.options.EdgeLBPoolCreationStrategy)
 	}
 
@@ -102,21 +187,46 @@ func (st *ServiceTranslator) createEdgeLBPool() (*corev1.LoadBalancerStatus, err
 	if _, err := st.manager.CreatePool(ctx, pool); err != nil {
 		return nil, err
 	}
+	report := computeCreationReport(pool, st.owner())
+	reportEdgeLBPoolChange(st.manager, st.recorder, st.service, "created", report)
+	st.lastAppliedDiff = report.LastAppliedDiff()
+	st.appliedStateHash = computeAppliedStateHash(pool.Name, computeOwnedEdgeLBObjects(pool, st.isEdgeLBObjectOwned))
+	// A newly created EdgeLB pool cannot have been changed out-of-band.
+	st.reportedDriftHash = ""
+	st.appliedResources = computeEdgeLBPoolResources(st.options.BaseTranslationOptions).String()
+	st.appliedPoolPatch = string(st.options.EdgeLBPoolPatch)
 	// Compute and return the status of the load-balancer.
 	return computeLoadBalancerStatus(st.manager, pool.Name, st.clusterName, st.service), nil
 }
 
 // updateOrDeleteEdgeLBPool makes a decision on whether the specified EdgeLB pool should be updated/deleted based on the current status of the associated Service resource.
 // In case it should be updated/deleted, it proceeds to actually updating/deleting it.
-// TODO (@bcustodio) Decide whether we should also update the pool's role and its CPU/memory/size requests when updating a pool.
+// The CPU and memory requests and the size of the pool are updated in-place as well, while its role and virtual network are never changed.
 func (st *ServiceTranslator) updateOrDeleteEdgeLBPool(pool *models.V2Pool) (*corev1.LoadBalancerStatus, error) {
+	// Keep a copy of the pool as reported by the EdgeLB API server so that we can later check whether it has drifted from the state last applied for the Service resource.
+	live, err := copyEdgeLBPool(pool)
+	if err != nil {
+		return nil, err
+	}
 	// Check whether the pool object must be updated.
 	wasChanged, report, err := st.updateEdgeLBPoolObject(pool)
 	if err != nil {
 		return nil, err
 	}
+	// Check whether the objects owned by the Service resource have been changed out-of-band, unless the Service resource has been deleted.
+	if st.service.DeletionTimestamp == nil && st.service.Spec.Type == corev1.ServiceTypeLoadBalancer {
+		drift, err := reconcileEdgeLBPoolDrift(st.service, st.options.BaseTranslationOptions, live, pool, wasChanged, &report, st.isEdgeLBObjectOwned, st.recorder, st.logger)
+		if err != nil {
+			return nil, err
+		}
+		wasChanged, st.appliedStateHash, st.reportedDriftHash = drift.MustUpdate, drift.AppliedStateHash, drift.ReportedDriftHash
+		// Record the resources requested for the EdgeLB pool, so that they are only applied again in case they change, unless the EdgeLB pool is not managed by the Service resource.
+		if st.options.EdgeLBPoolCreationStrategy != constants.EdgeLBPoolCreationStrategyNever {
+			st.appliedResources = computeEdgeLBPoolResources(st.options.BaseTranslationOptions).String()
+		}
+	}
 	// Report the status of the pool.
-	prettyprint.LogfSpew(log.Tracef, report, "inspection report for edgelb pool %q", pool.Name)
+	prettyprint.LogfJSON(log.Debugf, report, "inspection report for edgelb pool %q", pool.Name)
 	// Print the compputed EdgeLB pool object in "spew" and JSON formats.
 	prettyprint.LogfSpew(log.Tracef, pool, "computed edgelb pool object for service %q", kubernetesutil.Key(st.service))
 	prettyprint.LogfJSON(log.Debugf, pool, "computed edgelb pool object for service %q", kubernetesutil.Key(st.service))
@@ -139,6 +249,7 @@ func (st *ServiceTranslator) updateOrDeleteEdgeLBPool(pool *models.V2Pool) (*cor
 		if err := st.manager.DeletePool(ctx, pool.Name); err != nil {
 			return nil, err
 		}
+		reportEdgeLBPoolChange(st.manager, st.recorder, st.service, "deleted", report)
 		return &corev1.LoadBalancerStatus{}, nil
 	}
 
@@ -147,6 +258,8 @@ func (st *ServiceTranslator) updateOrDeleteEdgeLBPool(pool *models.V2Pool) (*cor
 	if _, err := st.manager.UpdatePool(ctx, pool); err != nil {
 		return nil, err
 	}
+	reportEdgeLBPoolChange(st.manager, st.recorder, st.service, "updated", report)
+	st.lastAppliedDiff = report.LastAppliedDiff()
 	return computeLoadBalancerStatus(st.manager, pool.Name, st.clusterName, st.service), nil
 }
 
@@ -155,14 +268,36 @@ func (st *ServiceTranslator) createEdgeLBPoolObject() (*models.V2Pool, error) {
 	backends := make([]*models.V2Backend, 0, len(st.service.Spec.Ports))
 	frontends := make([]*models.V2Frontend, 0, len(st.service.Spec.Ports))
 
+	// sniFrontends holds the frontends shared by service ports exposed via TLS SNI, indexed by frontend bind port.
+	sniFrontends := make(map[int32]*models.V2Frontend)
+
 	// Iterate over port definitions and create the corresponding backend and frontend objects.
 	for _, port := range st.service.Spec.Ports {
-		// Compute the backend and frontend for the current service port.
-		backend, frontend := computeBackendForServicePort(st.clusterName, st.service, port), computeFrontendForServicePort(st.clusterName, st.service, port, st.options)
-		// Append the backend to the slice of backends.
+		// Compute the backend for the current service port and append it to the slice of backends.
+		backend, err := st.computeBackendForServicePort(port)
+		if err != nil {
+			return nil, err
+		}
 		backends = append(backends, backend)
-		// Append the frontend to the slice of frontends.
-		frontends = append(frontends, frontend)
+		// If the current service port is not exposed via TLS SNI, compute its frontend and append it to the slice of frontends.
+		hostnames, isSNI := st.options.EdgeLBPoolSNIHostnames[port.Port]
+		if !isSNI {
+			frontend, err := st.computeFrontendForServicePort(port)
+			if err != nil {
+				return nil, err
+			}
+			frontends = append(frontends, frontend)
+			continue
+		}
+		// Otherwise, add the mapping between the TLS SNI hostnames and the backend to the frontend shared by service ports using the same frontend bind port.
+		bindPort := computeBindPortForServicePort(port, st.options)
+		frontend, exists := sniFrontends[bindPort]
+		if !exists {
+			frontend = computeSNIFrontendForBindPort(bindPort, nil)
+			sniFrontends[bindPort] = frontend
+			frontends = append(frontends, frontend)
+		}
+		frontend.LinkBackend.Map = append(frontend.LinkBackend.Map, computeSNIFrontendMapItemsForServicePort(st.clusterName, st.service, port, hostnames)...)
 	}
 
 	// Create and return the pool object.
@@ -171,8 +306,8 @@ func (st *ServiceTranslator) createEdgeLBPoolObject() (*models.V2Pool, error) {
 		Name:      st.options.EdgeLBPoolName,
 		Namespace: &st.poolGroup,
 		Role:      st.options.EdgeLBPoolRole,
-		Cpus:      float64(st.options.EdgeLBPoolCpus.MilliValue()) / 1000,
-		Mem:       int32(st.options.EdgeLBPoolMem.Value() / (1024 * 1024)),
+		Cpus:      computeEdgeLBPoolCpus(st.options.BaseTranslationOptions),
+		Mem:       computeEdgeLBPoolMem(st.options.BaseTranslationOptions),
 		Count:     &s,
 		Haproxy: &models.V2Haproxy{
 			Backends:  backends,
@@ -197,9 +332,44 @@ func (st *ServiceTranslator) createEdgeLBPoolObject() (*models.V2Pool, error) {
 			},
 		}
 	}
+
+	// Apply the JSON merge patch requested for the pool (if any).
+	if _, err := patchEdgeLBPool(p, st.options.EdgeLBPoolPatch, "", nil); err != nil {
+		return nil, err
+	}
 	return p, nil
 }
 
+// computeBackendForServicePort computes the backend that corresponds to the specified service port.
+// In case EdgeLB backends have been requested to target pod IPs or the Service resource has "externalTrafficPolicy: Local", the addresses to target are computed from the corresponding Endpoints resource.
+// Otherwise, only Ready and schedulable Kubernetes nodes are targeted.
+func (st *ServiceTranslator) computeBackendForServicePort(port corev1.ServicePort) (*models.V2Backend, error) {
+	backend := computeBackendForServicePort(st.clusterName, st.service, port, st.options)
+	target, err := computeBackendTargetForServicePort(st.kubeCache, st.service, port, st.options.BaseTranslationOptions)
+	if err != nil {
+		return nil, err
+	}
+	if target != nil {
+		applyBackendTarget(backend, *target)
+	}
+	// Apply the JSON merge patch requested for EdgeLB backends (if any).
+	if err := patchEdgeLBBackends([]*models.V2Backend{backend}, st.options.BaseTranslationOptions); err != nil {
+		return nil, err
+	}
+	return backend, nil
+}
+
+// computeFrontendForServicePort computes the frontend that corresponds to the specified service port, which must not be exposed via TLS SNI.
+// Frontends shared by service ports exposed via TLS SNI are not patched, as they may be shared by multiple Service resources.
+func (st *ServiceTranslator) computeFrontendForServicePort(port corev1.ServicePort) (*models.V2Frontend, error) {
+	frontend := computeFrontendForServicePort(st.clusterName, st.service, port, st.options)
+	// Apply the JSON merge patch requested for EdgeLB frontends (if any).
+	if err := patchEdgeLBFrontends([]*models.V2Frontend{frontend}, st.options.BaseTranslationOptions); err != nil {
+		return nil, err
+	}
+	return frontend, nil
+}
+
 // updateEdgeLBPoolObject updates the specified pool object in order to reflect the status of the current Service resource.
 // It modifies the specified pool in-place and returns a value indicating whether the pool object contains changes.
 // Backends and frontends (the "objects") are added/modified/deleted according to the following rules:
@@ -211,16 +381,43 @@ func (st *ServiceTranslator) createEdgeLBPoolObject() (*models.V2Pool, error) {
 func (st *ServiceTranslator) updateEdgeLBPoolObject(pool *models.V2Pool) (wasChanged bool, report poolInspectionReport, err error) {
 	// serviceDeleted holds whether the Service resource has been deleted (or changed to a different type, which must produce a similar effect).
 	serviceDeleted := st.service.DeletionTimestamp != nil || st.service.Spec.Type != corev1.ServiceTypeLoadBalancer
+	// owner identifies the current Service resource in the pool inspection report.
+	owner := st.owner()
+	report.Pool = pool.Name
 
 	// If the service has not been deleted, we iterate over ports defined on the service and re-compute the corresponding backend and frontend objects.
 	// These will be later compared with the backend and frontend objects reported by the EdgeLB API server (i.e. those in "pool").
+	// Service ports exposed via TLS SNI don't have a frontend of their own, and the items that must be added to the shared frontend for the target frontend bind port are computed instead.
 	desiredBackendFrontends := make(map[int32]servicePortBackendFrontend, len(st.service.Spec.Ports))
+	desiredSNIMapItems := make(map[int32][]*models.V2FrontendLinkBackendMapItems0)
+	// sniBindPorts holds the frontend bind ports used by service ports exposed via TLS SNI, in the order in which they were found.
+	sniBindPorts := make([]int32, 0)
 	if !serviceDeleted {
 		for _, port := range st.service.Spec.Ports {
+			backend, err := st.computeBackendForServicePort(port)
+			if err != nil {
+				return false, report, err
+			}
+			hostnames, isSNI := st.options.EdgeLBPoolSNIHostnames[port.Port]
+			if !isSNI {
+				frontend, err := st.computeFrontendForServicePort(port)
+				if err != nil {
+					return false, report, err
+				}
+				desiredBackendFrontends[port.Port] = servicePortBackendFrontend{
+					Backend:  backend,
+					Frontend: frontend,
+				}
+				continue
+			}
 			desiredBackendFrontends[port.Port] = servicePortBackendFrontend{
-				Backend:  computeBackendForServicePort(st.clusterName, st.service, port),
-				Frontend: computeFrontendForServicePort(st.clusterName, st.service, port, st.options),
+				Backend: backend,
+			}
+			bindPort := computeBindPortForServicePort(port, st.options)
+			if _, exists := desiredSNIMapItems[bindPort]; !exists {
+				sniBindPorts = append(sniBindPorts, bindPort)
 			}
+			desiredSNIMapItems[bindPort] = append(desiredSNIMapItems[bindPort], computeSNIFrontendMapItemsForServicePort(st.clusterName, st.service, port, hostnames)...)
 		}
 	}
 
@@ -240,20 +437,20 @@ func (st *ServiceTranslator) updateEdgeLBPoolObject(pool *models.V2Pool) (wasCha
 		backendMetadata, err := computeServiceOwnedEdgeLBObjectMetadata(backend.Name)
 		if err != nil || !backendMetadata.IsOwnedBy(st.clusterName, st.service) {
 			updatedBackends = append(updatedBackends, backend)
-			report.Report("no changes required for backend %q (not owned by %s)", backend.Name, kubernetesutil.Key(st.service))
+			report.Keep(EdgeLBObjectTypeBackend, backend.Name, ComputeEdgeLBObjectOwner(st.clusterName, backend.Name))
 			continue
 		}
 		// At this point we know the current backend is owned by the current service.
 		// Check whether the service has been deleted, and skip (i.e. remove) the backend if it has.
 		if serviceDeleted {
 			wasChanged = true
-			report.Report("must delete backend %q as %q was deleted or its type has changed", backend.Name, kubernetesutil.Key(st.service))
+			report.Delete(EdgeLBObjectTypeBackend, backend.Name, owner, "%q was deleted or its type has changed", kubernetesutil.Key(st.service))
 			continue
 		}
 		// Check whether the target service port is still present in the service and skip (i.e. remove) the backend if it doesn't.
 		if _, exists := desiredBackendFrontends[backendMetadata.ServicePort]; !exists {
 			wasChanged = true
-			report.Report("must delete backend %q as port %d is missing from %s", backend.Name, backendMetadata.ServicePort, kubernetesutil.Key(st.service))
+			report.Delete(EdgeLBObjectTypeBackend, backend.Name, owner, "port %d is missing from %s", backendMetadata.ServicePort, kubernetesutil.Key(st.service))
 			continue
 		}
 		// At this point we know the service port corresponding to the current backend still exists.
@@ -264,16 +461,18 @@ func (st *ServiceTranslator) updateEdgeLBPoolObject(pool *models.V2Pool) (wasCha
 		if !reflect.DeepEqual(backend, desiredBackendFrontends[backendMetadata.ServicePort].Backend) {
 			wasChanged = true
 			updatedBackends = append(updatedBackends, desiredBackendFrontends[backendMetadata.ServicePort].Backend)
-			report.Report("must modify backend %q", backend.Name)
+			report.Modify(EdgeLBObjectTypeBackend, backend.Name, owner, backend, desiredBackendFrontends[backendMetadata.ServicePort].Backend)
 		} else {
 			updatedBackends = append(updatedBackends, backend)
-			report.Report("no changes required for backend %q", backend.Name)
+			report.Keep(EdgeLBObjectTypeBackend, backend.Name, owner)
 		}
 	}
 
 	// visitedFrontends holds the set of service ports corresponding to visited (existing) frontends.
 	// It is used to understand which service ports currently have frontend objects in the pool, and which don't.
 	visitedFrontends := make(map[int32]bool, len(pool.Haproxy.Frontends))
+	// visitedSNIFrontends holds the set of frontend bind ports corresponding to visited (existing) frontends shared by service ports exposed via TLS SNI.
+	visitedSNIFrontends := make(map[int32]bool)
 	// updatedFrontends holds the set of updated frontend objects.
 	// It is used as the final set of frontends for the pool if we find out we need to update it.
 	updatedFrontends := make([]*models.V2Frontend, 0, len(pool.Haproxy.Frontends))
@@ -282,25 +481,50 @@ func (st *ServiceTranslator) updateEdgeLBPoolObject(pool *models.V2Pool) (wasCha
 	// In case a frontend isn't owned by the current service, it is left unchanged and added to the set of "updated" frontends.
 	// Otherwise, it is checked for correctness and, if necessary, replaced with the computed frontends for the target service port.
 	for _, frontend := range pool.Haproxy.Frontends {
+		// Check whether the current frontend is shared by service ports exposed via TLS SNI.
+		// In case it is, the items owned by the current service are updated, while the remaining ones are left untouched.
+		if bindPort, err := computeSNIFrontendBindPort(frontend.Name); err == nil {
+			visitedSNIFrontends[bindPort] = true
+			updatedFrontend, frontendChanged := st.updateSNIFrontend(frontend, bindPort, desiredSNIMapItems[bindPort], &report)
+			switch {
+			case !frontendChanged:
+				updatedFrontends = append(updatedFrontends, frontend)
+				report.Keep(EdgeLBObjectTypeFrontend, frontend.Name, nil)
+			case len(updatedFrontend.LinkBackend.Map) == 0:
+				wasChanged = true
+				report.Delete(EdgeLBObjectTypeFrontend, frontend.Name, nil, "it is not used by any service anymore")
+			default:
+				wasChanged = true
+				updatedFrontends = append(updatedFrontends, updatedFrontend)
+				report.Modify(EdgeLBObjectTypeFrontend, frontend.Name, nil, frontend, updatedFrontend)
+			}
+			continue
+		}
 		// Parse the name of the frontend in order to determine if the current service owns it.
 		// If the current frontend isn't owned by the current service, it is left unchanged.
 		frontendMetadata, err := computeServiceOwnedEdgeLBObjectMetadata(frontend.Name)
 		if err != nil || !frontendMetadata.IsOwnedBy(st.clusterName, st.service) {
 			updatedFrontends = append(updatedFrontends, frontend)
-			report.Report("no changes required for frontend %q (not owned by %s)", frontend.Name, kubernetesutil.Key(st.service))
+			report.Keep(EdgeLBObjectTypeFrontend, frontend.Name, ComputeEdgeLBObjectOwner(st.clusterName, frontend.Name))
 			continue
 		}
 		// At this point we know the current frontend is owned by the current service.
 		// Check whether the service has been deleted, and skip (i.e. remove) the frontend if it has.
 		if serviceDeleted {
 			wasChanged = true
-			report.Report("must delete frontend %q as %q was deleted or its type has changed", frontend.Name, kubernetesutil.Key(st.service))
+			report.Delete(EdgeLBObjectTypeFrontend, frontend.Name, owner, "%q was deleted or its type has changed", kubernetesutil.Key(st.service))
 			continue
 		}
 		// Check whether the target service port is still present in the service and skip (i.e. remove) the frontend if it doesn't.
 		if _, exists := desiredBackendFrontends[frontendMetadata.ServicePort]; !exists {
 			wasChanged = true
-			report.Report("must delete backend %q as port %d is missing from %s", frontend.Name, frontendMetadata.ServicePort, kubernetesutil.Key(st.service))
+			report.Delete(EdgeLBObjectTypeFrontend, frontend.Name, owner, "port %d is missing from %s", frontendMetadata.ServicePort, kubernetesutil.Key(st.service))
+			continue
+		}
+		// Check whether the target service port is now exposed via TLS SNI and skip (i.e. remove) the frontend if it is.
+		if desiredBackendFrontends[frontendMetadata.ServicePort].Frontend == nil {
+			wasChanged = true
+			report.Delete(EdgeLBObjectTypeFrontend, frontend.Name, owner, "port %d is exposed via tls sni", frontendMetadata.ServicePort)
 			continue
 		}
 		// At this point we know the service port corresponding to the current frontend still exists.
@@ -311,19 +535,23 @@ func (st *ServiceTranslator) updateEdgeLBPoolObject(pool *models.V2Pool) (wasCha
 		if !reflect.DeepEqual(frontend, desiredBackendFrontends[frontendMetadata.ServicePort].Frontend) {
 			wasChanged = true
 			updatedFrontends = append(updatedFrontends, desiredBackendFrontends[frontendMetadata.ServicePort].Frontend)
-			report.Report("must modify frontend %q", frontend.Name)
+			report.Modify(EdgeLBObjectTypeFrontend, frontend.Name, owner, frontend, desiredBackendFrontends[frontendMetadata.ServicePort].Frontend)
 		} else {
 			updatedFrontends = append(updatedFrontends, frontend)
-			report.Report("no changes required for frontend %q", frontend.Name)
+			report.Keep(EdgeLBObjectTypeFrontend, frontend.Name, owner)
 		}
 	}
 
 	// Replace the pool's backends and frontends with the (possibly empty) updated lists.
 	pool.Haproxy.Backends, pool.Haproxy.Frontends = updatedBackends, updatedFrontends
 
-	// If the current Service resource was deleted, there is nothing else to compute.
+	// If the current Service resource was deleted, there is nothing else to compute other than resetting the fields set by the JSON merge patch last applied for it.
 	if serviceDeleted {
-		return wasChanged, report, err
+		patched, err := st.applyEdgeLBPoolPatch(pool, serviceDeleted, &report)
+		if err != nil {
+			return false, report, err
+		}
+		return wasChanged || patched, report, nil
 	}
 
 	// Iterate over all the service ports, in order to understand whether there are new service port definitions.
@@ -334,17 +562,42 @@ func (st *ServiceTranslator) updateEdgeLBPoolObject(pool *models.V2Pool) (wasCha
 			// Hence, we add it to the set of updated backends and mark the pool as requiring an update.
 			wasChanged = true
 			pool.Haproxy.Backends = append(pool.Haproxy.Backends, dbf.Backend)
-			report.Report("must create backend %q", dbf.Backend.Name)
+			report.Create(EdgeLBObjectTypeBackend, dbf.Backend.Name, owner)
 		}
-		if _, visited := visitedFrontends[port]; !visited {
+		if _, visited := visitedFrontends[port]; !visited && dbf.Frontend != nil {
 			// The current service port doesn't have a matching frontend.
 			// Hence, we add it to the set of updated frontends and mark the pool as requiring an update.
 			wasChanged = true
 			pool.Haproxy.Frontends = append(pool.Haproxy.Frontends, dbf.Frontend)
-			report.Report("must create frontend %q", dbf.Frontend.Name)
+			report.Create(EdgeLBObjectTypeFrontend, dbf.Frontend.Name, owner)
+		}
+	}
+
+	// Iterate over all the frontend bind ports used by service ports exposed via TLS SNI.
+	// For every such frontend bind port, if the corresponding shared frontend isn't present, add it.
+	for _, bindPort := range sniBindPorts {
+		if _, visited := visitedSNIFrontends[bindPort]; !visited {
+			wasChanged = true
+			frontend := computeSNIFrontendForBindPort(bindPort, desiredSNIMapItems[bindPort])
+			pool.Haproxy.Frontends = append(pool.Haproxy.Frontends, frontend)
+			report.Create(EdgeLBObjectTypeFrontend, frontend.Name, nil)
 		}
 	}
 
+	// Update the CPU and memory requests and the size of the pool as required.
+	if updateEdgeLBPoolResources(pool, st.options.BaseTranslationOptions, GetEdgeLBPoolAppliedResources(st.service), &report) {
+		wasChanged = true
+	}
+
+	// Apply the JSON merge patch requested for the pool (if any).
+	patched, err := st.applyEdgeLBPoolPatch(pool, serviceDeleted, &report)
+	if err != nil {
+		return false, report, err
+	}
+	if patched {
+		wasChanged = true
+	}
+
 	// Update the cloud load-balancer configuration as required.
 	if st.options.CloudLoadBalancerConfigMapName != nil {
 		// Grab the current value of the ".cloudProvider" field.
@@ -355,15 +608,84 @@ func (st *ServiceTranslator) updateEdgeLBPoolObject(pool *models.V2Pool) (wasCha
 			return false, report, err
 		}
 		// Update the pool with the desired configuration if and only if the current and desired configurations differ.
-		if reflect.DeepEqual(currentCloudLoadBalancerObject, desiredCloudLoadBalancerObject) {
-			report.Report("no changes to cloud loadbalancer configuration are required")
-		} else {
+		if !reflect.DeepEqual(currentCloudLoadBalancerObject, desiredCloudLoadBalancerObject) {
 			pool.CloudProvider = desiredCloudLoadBalancerObject
 			wasChanged = true
-			report.Report("must update cloud loadbalancer configuration")
+			report.ModifyPool(newFieldDiff("cloudProvider", currentCloudLoadBalancerObject, desiredCloudLoadBalancerObject))
 		}
 	}
 
 	// Return a value indicating whether the pool was changed, and the pool inspection report.
 	return wasChanged, report, err
 }
+
+// applyEdgeLBPoolPatch applies the JSON merge patch requested for the specified pool (if any), keeping a copy of the unpatched pool so that the changes can be recorded in the specified pool inspection report.
+// In case the Service resource has been deleted, only the fields set by the JSON merge patch last applied for it are reset.
+// It modifies the specified pool in-place and returns a value indicating whether it was changed.
+func (st *ServiceTranslator) applyEdgeLBPoolPatch(pool *models.V2Pool, serviceDeleted bool, report *poolInspectionReport) (bool, error) {
+	unpatched, err := copyEdgeLBPool(pool)
+	if err != nil {
+		return false, err
+	}
+	var patch []byte
+	if !serviceDeleted {
+		patch = st.options.EdgeLBPoolPatch
+	}
+	// Make sure that the requested JSON merge patch doesn't diverge from the one applied for other resources sharing the pool, and that the fields these resources rely on are not reset.
+	shared, err := computeSharedEdgeLBPoolPatch(st.kubeCache, st.clusterName, pool, st.owner(), patch)
+	if err != nil {
+		return false, err
+	}
+	patched, err := patchEdgeLBPool(pool, patch, GetEdgeLBPoolAppliedPatch(st.service), shared)
+	if err != nil {
+		return false, err
+	}
+	st.appliedPoolPatch = string(patch)
+	if patched {
+		report.ModifyPool(computeFieldDiffs(unpatched, pool)...)
+	}
+	return patched, nil
+}
+
+// updateSNIFrontend computes the desired state of the specified frontend (shared by service ports exposed via TLS SNI) based on the specified items for the current Service resource.
+// Items owned by the current Service resource are replaced by the specified ones, while items owned by other Service resources are left untouched.
+// Whenever a TLS SNI hostname requested by the current Service resource is already in use by a different Service resource, it is skipped and an event is emitted.
+// It returns the updated frontend and a value indicating whether it differs from the specified one.
+func (st *ServiceTranslator) updateSNIFrontend(frontend *models.V2Frontend, bindPort int32, desiredItems []*models.V2FrontendLinkBackendMapItems0, report *poolInspectionReport) (*models.V2Frontend, bool) {
+	// items holds the final set of items for the frontend.
+	items := make([]*models.V2FrontendLinkBackendMapItems0, 0)
+	// hostnamesInUse holds the TLS SNI hostnames that are in use by other Service resources, and the backend to which they point.
+	hostnamesInUse := make(map[string]string)
+
+	// Iterate over the frontend's items, keeping only those that are not owned by the current Service resource.
+	if frontend.LinkBackend != nil {
+		for _, item := range frontend.LinkBackend.Map {
+			backendMetadata, err := computeServiceOwnedEdgeLBObjectMetadata(item.Backend)
+			if err == nil && backendMetadata.IsOwnedBy(st.clusterName, st.service) {
+				continue
+			}
+			items = append(items, item)
+			hostnamesInUse[item.HostEq] = item.Backend
+		}
+	}
+
+	// Iterate over the desired items, adding them to the frontend unless the TLS SNI hostname is already in use.
+	for _, item := range desiredItems {
+		if backend, inUse := hostnamesInUse[item.HostEq]; inUse {
+			st.recorder.Eventf(st.service, corev1.EventTypeWarning, constants.ReasonSNIHostnameConflict, "tls sni hostname %q on frontend bind port %d is already in use by backend %q", item.HostEq, bindPort, backend)
+			report.Report("skipping tls sni hostname %q as it is already in use by backend %q", item.HostEq, backend)
+			continue
+		}
+		items = append(items, item)
+	}
+
+	// Compute the updated frontend, preserving any other fields the existing frontend may define.
+	updatedFrontend := *frontend
+	updatedLinkBackend := models.V2FrontendLinkBackend{}
+	if frontend.LinkBackend != nil {
+		updatedLinkBackend = *frontend.LinkBackend
+	}
+	updatedLinkBackend.Map = items
+	updatedFrontend.LinkBackend = &updatedLinkBackend
+	return &updatedFrontend, !reflect.DeepEqual(frontend, &updatedFrontend)
+}
@@ -10,7 +10,9 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
 
+	"github.com/mesosphere/dklb/pkg/constants"
 	"github.com/mesosphere/dklb/pkg/util/pointers"
 	cachetestutil "github.com/mesosphere/dklb/test/util/cache"
 	edgelbmanagertestutil "github.com/mesosphere/dklb/test/util/edgelb/manager"
@@ -68,9 +70,59 @@ var (
 		},
 	}
 	// backendForServiceExposingPort80 is the computed (expected) backend for port 80 of serviceExposingPort80.
-	backendForServiceExposingPort80 = computeBackendForServicePort(testClusterName, serviceExposingPort80, serviceExposingPort80.Spec.Ports[0])
+	backendForServiceExposingPort80 = computeBackendForServicePort(testClusterName, serviceExposingPort80, serviceExposingPort80.Spec.Ports[0], serviceTranslationOptionsForPort80)
 	// frontendForServiceExposingPort80 is the computed (expected) frontend for port 80 of serviceExposingPort80.
 	frontendForServiceExposingPort80 = computeFrontendForServicePort(testClusterName, serviceExposingPort80, serviceExposingPort80.Spec.Ports[0], serviceTranslationOptionsForPort80)
+	// serviceExposingPort443ViaSNI is a dummy Kubernetes Service resource that exposes port 443 via TLS SNI.
+	serviceExposingPort443ViaSNI = service.DummyServiceResource("foo", "sni", func(service *v1.Service) {
+		service.Spec.Ports = []v1.ServicePort{
+			{
+				Name:       "https",
+				Protocol:   v1.ProtocolTCP,
+				Port:       443,
+				NodePort:   34443,
+				TargetPort: intstr.FromInt(8443),
+			},
+		}
+		service.Spec.Type = v1.ServiceTypeLoadBalancer
+	})
+	// deletedServiceExposingPort443ViaSNI is a dummy Kubernetes Service resource that exposes port 443 via TLS SNI but has been marked for deletion.
+	deletedServiceExposingPort443ViaSNI = service.DummyServiceResource("foo", "sni", func(service *v1.Service) {
+		deletionTimestamp := metav1.NewTime(time.Now())
+		service.ObjectMeta.DeletionTimestamp = &deletionTimestamp
+		service.Spec.Ports = serviceExposingPort443ViaSNI.Spec.Ports
+		service.Spec.Type = v1.ServiceTypeLoadBalancer
+	})
+	// serviceTranslationOptionsForPort443ViaSNI is a set of translation options that exposes a Service's port 443 on EdgeLB frontend bind port 8443 for the "foo.example.com" TLS SNI hostname.
+	serviceTranslationOptionsForPort443ViaSNI = ServiceTranslationOptions{
+		BaseTranslationOptions: BaseTranslationOptions{
+			EdgeLBPoolName: "baz",
+			EdgeLBPoolRole: "custom_role",
+			EdgeLBPoolCpus: resource.MustParse("5010203m"),
+			EdgeLBPoolMem:  resource.MustParse("3724Mi"),
+			EdgeLBPoolSize: 3,
+		},
+		EdgeLBPoolPortMap: map[int32]int32{
+			443: 8443,
+		},
+		EdgeLBPoolSNIHostnames: map[int32][]string{
+			443: {"foo.example.com"},
+		},
+	}
+	// backendForServiceExposingPort443ViaSNI is the computed (expected) backend for port 443 of serviceExposingPort443ViaSNI.
+	backendForServiceExposingPort443ViaSNI = computeBackendForServicePort(testClusterName, serviceExposingPort443ViaSNI, serviceExposingPort443ViaSNI.Spec.Ports[0], serviceTranslationOptionsForPort443ViaSNI)
+	// mapItemsForServiceExposingPort443ViaSNI are the computed (expected) shared frontend items for port 443 of serviceExposingPort443ViaSNI.
+	mapItemsForServiceExposingPort443ViaSNI = computeSNIFrontendMapItemsForServicePort(testClusterName, serviceExposingPort443ViaSNI, serviceExposingPort443ViaSNI.Spec.Ports[0], []string{"foo.example.com"})
+	// otherServiceMapItem is a shared frontend item owned by a different Service resource and using a different TLS SNI hostname.
+	otherServiceMapItem = &models.V2FrontendLinkBackendMapItems0{
+		Backend: "dev.kubernetes01:foo:other:443",
+		HostEq:  "bar.example.com",
+	}
+	// conflictingServiceMapItem is a shared frontend item owned by a different Service resource and using the same TLS SNI hostname as serviceExposingPort443ViaSNI.
+	conflictingServiceMapItem = &models.V2FrontendLinkBackendMapItems0{
+		Backend: "dev.kubernetes01:foo:other:443",
+		HostEq:  "foo.example.com",
+	}
 	// preExistingBackend1 is used to represent a pre-existing EdgeLB backend.
 	preExistingBackend1 = &models.V2Backend{
 		Name: "pre-existing-backend-1",
@@ -91,6 +143,12 @@ var (
 	preExistingFrontend2 = &models.V2Frontend{
 		Name: "pre-existing-frontend-2",
 	}
+	// withPoolResourcesForTranslationOptions sets the CPU and memory requests and the size of an EdgeLB pool to the values requested by the translation options used in tests.
+	withPoolResourcesForTranslationOptions = func(p *models.V2Pool) {
+		p.Cpus = 5010.203
+		p.Mem = 3724
+		p.Count = pointers.NewInt32(3)
+	}
 )
 
 func TestCreateEdgeLBPoolObject(t *testing.T) {
@@ -124,6 +182,23 @@ func TestCreateEdgeLBPoolObject(t *testing.T) {
 			},
 			expectedError: nil,
 		},
+		{
+			description:  "create an edgelb pool for a service exposing a port via tls sni",
+			service:      serviceExposingPort443ViaSNI,
+			options:      serviceTranslationOptionsForPort443ViaSNI,
+			expectedName: serviceTranslationOptionsForPort443ViaSNI.EdgeLBPoolName,
+			expectedRole: serviceTranslationOptionsForPort443ViaSNI.EdgeLBPoolRole,
+			expectedCpus: 5010.203,
+			expectedMem:  3724,
+			expectedSize: serviceTranslationOptionsForPort443ViaSNI.EdgeLBPoolSize,
+			expectedBackends: []*models.V2Backend{
+				backendForServiceExposingPort443ViaSNI,
+			},
+			expectedFrontends: []*models.V2Frontend{
+				computeSNIFrontendForBindPort(8443, mapItemsForServiceExposingPort443ViaSNI),
+			},
+			expectedError: nil,
+		},
 	}
 	for _, test := range tests {
 		t.Logf("test case: %s", test.description)
@@ -132,7 +207,9 @@ func TestCreateEdgeLBPoolObject(t *testing.T) {
 		// Create and customize a mock EdgeLB manager.
 		manager := new(edgelbmanagertestutil.MockEdgeLBManager)
 		manager.On("PoolGroup").Return(testEdgeLBPoolGroup)
-		pool, err := NewServiceTranslator(testClusterName, test.service, test.options, kubeCache, manager).createEdgeLBPoolObject()
+		// Create a new fake event recorder.
+		recorder := record.NewFakeRecorder(1)
+		pool, err := NewServiceTranslator(testClusterName, test.service, test.options, kubeCache, manager, recorder).createEdgeLBPoolObject()
 		assert.Equal(t, err, test.expectedError)
 		assert.Equal(t, testEdgeLBPoolGroup, *pool.Namespace)
 		assert.Equal(t, test.expectedName, pool.Name)
@@ -155,6 +232,7 @@ func TestUpdateEdgeLBPoolObject(t *testing.T) {
 		expectedWasChanged bool
 		expectedBackends   []*models.V2Backend
 		expectedFrontends  []*models.V2Frontend
+		expectedEvents     []string
 		expectedError      error
 	}{
 		{
@@ -169,7 +247,7 @@ func TestUpdateEdgeLBPoolObject(t *testing.T) {
 				p.Haproxy.Frontends = []*models.V2Frontend{
 					frontendForServiceExposingPort80,
 				}
-			}),
+			}, withPoolResourcesForTranslationOptions),
 			expectedWasChanged: false,
 			expectedBackends: []*models.V2Backend{
 				backendForServiceExposingPort80,
@@ -179,6 +257,32 @@ func TestUpdateEdgeLBPoolObject(t *testing.T) {
 			},
 			expectedError: nil,
 		},
+		{
+			// Test that a pool whose size differs from the requested one is detected as requiring an update.
+			description: "pool whose size differs from the requested one is detected as requiring an update",
+			service:     serviceExposingPort80,
+			options: func() ServiceTranslationOptions {
+				o := serviceTranslationOptionsForPort80
+				o.EdgeLBPoolSize = 5
+				return o
+			}(),
+			pool: edgelbpooltestutil.DummyEdgeLBPool("baz", func(p *models.V2Pool) {
+				p.Haproxy.Backends = []*models.V2Backend{
+					backendForServiceExposingPort80,
+				}
+				p.Haproxy.Frontends = []*models.V2Frontend{
+					frontendForServiceExposingPort80,
+				}
+			}, withPoolResourcesForTranslationOptions),
+			expectedWasChanged: true,
+			expectedBackends: []*models.V2Backend{
+				backendForServiceExposingPort80,
+			},
+			expectedFrontends: []*models.V2Frontend{
+				frontendForServiceExposingPort80,
+			},
+			expectedError: nil,
+		},
 		{
 			// Test that a pool that was "in sync" with a deleted Service resource is detected as requiring an update.
 			description: "pool that was \"in sync\" with a deleted Service resource is detected as requiring an update",
@@ -204,7 +308,7 @@ func TestUpdateEdgeLBPoolObject(t *testing.T) {
 			options:     serviceTranslationOptionsForPort80,
 			pool: edgelbpooltestutil.DummyEdgeLBPool("baz", func(p *models.V2Pool) {
 				// Change the target port for the backend.
-				backend := computeBackendForServicePort(testClusterName, serviceExposingPort80, serviceExposingPort80.Spec.Ports[0])
+				backend := computeBackendForServicePort(testClusterName, serviceExposingPort80, serviceExposingPort80.Spec.Ports[0], serviceTranslationOptionsForPort80)
 				backend.Services[0].Endpoint.Port = 10101
 				p.Haproxy.Backends = []*models.V2Backend{
 					// Will have to be replaced by "backendForServiceExposingPort80".
@@ -223,6 +327,33 @@ func TestUpdateEdgeLBPoolObject(t *testing.T) {
 			},
 			expectedError: nil,
 		},
+		{
+			// Test that a pool for which the load-balancing algorithm was changed is detected as requiring an update.
+			description: "pool for which the load-balancing algorithm was changed is detected as requiring an update",
+			service:     serviceExposingPort80,
+			options: func() ServiceTranslationOptions {
+				o := serviceTranslationOptionsForPort80
+				o.EdgeLBBackendBalance = constants.EdgeLBBackendBalanceRoundRobin
+				return o
+			}(),
+			pool: edgelbpooltestutil.DummyEdgeLBPool("baz", func(p *models.V2Pool) {
+				p.Haproxy.Backends = []*models.V2Backend{
+					// Will have to be replaced by a backend using the "roundrobin" algorithm.
+					backendForServiceExposingPort80,
+				}
+				p.Haproxy.Frontends = []*models.V2Frontend{
+					frontendForServiceExposingPort80,
+				}
+			}),
+			expectedWasChanged: true,
+			expectedBackends: []*models.V2Backend{
+				computeBackendForServicePort(testClusterName, serviceExposingPort80, serviceExposingPort80.Spec.Ports[0], ServiceTranslationOptions{BaseTranslationOptions: BaseTranslationOptions{EdgeLBBackendBalance: constants.EdgeLBBackendBalanceRoundRobin}}),
+			},
+			expectedFrontends: []*models.V2Frontend{
+				frontendForServiceExposingPort80,
+			},
+			expectedError: nil,
+		},
 		{
 			// Test that a pool for which a frontend was manually changed is detected as requiring an update.
 			description: "pool for which a frontend was manually changed is detected as requiring an update",
@@ -298,7 +429,7 @@ func TestUpdateEdgeLBPoolObject(t *testing.T) {
 						Port:       90,
 						Protocol:   v1.ProtocolTCP,
 						TargetPort: intstr.FromInt(8080),
-					}),
+					}, serviceTranslationOptionsForPort80),
 				}
 				p.Haproxy.Frontends = []*models.V2Frontend{
 					preExistingFrontend1,
@@ -327,6 +458,94 @@ func TestUpdateEdgeLBPoolObject(t *testing.T) {
 			},
 			expectedError: nil,
 		},
+		{
+			// Test that a pool with a frontend shared by a different Service resource exposing a port via tls sni is detected as requiring an update, and that the existing items are preserved.
+			description: "pool with a frontend shared by a different Service resource exposing a port via tls sni is detected as requiring an update",
+			service:     serviceExposingPort443ViaSNI,
+			options:     serviceTranslationOptionsForPort443ViaSNI,
+			pool: edgelbpooltestutil.DummyEdgeLBPool("baz", func(p *models.V2Pool) {
+				p.Haproxy.Backends = []*models.V2Backend{
+					preExistingBackend1,
+				}
+				p.Haproxy.Frontends = []*models.V2Frontend{
+					computeSNIFrontendForBindPort(8443, []*models.V2FrontendLinkBackendMapItems0{otherServiceMapItem}),
+				}
+			}),
+			expectedWasChanged: true,
+			expectedBackends: []*models.V2Backend{
+				preExistingBackend1,
+				backendForServiceExposingPort443ViaSNI,
+			},
+			expectedFrontends: []*models.V2Frontend{
+				computeSNIFrontendForBindPort(8443, append([]*models.V2FrontendLinkBackendMapItems0{otherServiceMapItem}, mapItemsForServiceExposingPort443ViaSNI...)),
+			},
+			expectedError: nil,
+		},
+		{
+			// Test that a pool that is "in sync" with a Service resource exposing a port via tls sni is detected as not requiring an update.
+			description: "pool that is \"in sync\" with a Service resource exposing a port via tls sni is detected as not requiring an update",
+			service:     serviceExposingPort443ViaSNI,
+			options:     serviceTranslationOptionsForPort443ViaSNI,
+			pool: edgelbpooltestutil.DummyEdgeLBPool("baz", func(p *models.V2Pool) {
+				p.Haproxy.Backends = []*models.V2Backend{
+					backendForServiceExposingPort443ViaSNI,
+				}
+				p.Haproxy.Frontends = []*models.V2Frontend{
+					computeSNIFrontendForBindPort(8443, append([]*models.V2FrontendLinkBackendMapItems0{otherServiceMapItem}, mapItemsForServiceExposingPort443ViaSNI...)),
+				}
+			}, withPoolResourcesForTranslationOptions),
+			expectedWasChanged: false,
+			expectedBackends: []*models.V2Backend{
+				backendForServiceExposingPort443ViaSNI,
+			},
+			expectedFrontends: []*models.V2Frontend{
+				computeSNIFrontendForBindPort(8443, append([]*models.V2FrontendLinkBackendMapItems0{otherServiceMapItem}, mapItemsForServiceExposingPort443ViaSNI...)),
+			},
+			expectedError: nil,
+		},
+		{
+			// Test that a tls sni hostname already in use by a different Service resource is not taken over, and that an event is emitted.
+			description: "tls sni hostname already in use by a different Service resource is not taken over",
+			service:     serviceExposingPort443ViaSNI,
+			options:     serviceTranslationOptionsForPort443ViaSNI,
+			pool: edgelbpooltestutil.DummyEdgeLBPool("baz", func(p *models.V2Pool) {
+				p.Haproxy.Backends = []*models.V2Backend{
+					backendForServiceExposingPort443ViaSNI,
+				}
+				p.Haproxy.Frontends = []*models.V2Frontend{
+					computeSNIFrontendForBindPort(8443, []*models.V2FrontendLinkBackendMapItems0{conflictingServiceMapItem}),
+				}
+			}, withPoolResourcesForTranslationOptions),
+			expectedWasChanged: false,
+			expectedBackends: []*models.V2Backend{
+				backendForServiceExposingPort443ViaSNI,
+			},
+			expectedFrontends: []*models.V2Frontend{
+				computeSNIFrontendForBindPort(8443, []*models.V2FrontendLinkBackendMapItems0{conflictingServiceMapItem}),
+			},
+			expectedEvents: []string{
+				"Warning SNIHostnameConflict tls sni hostname \"foo.example.com\" on frontend bind port 8443 is already in use by backend \"dev.kubernetes01:foo:other:443\"",
+			},
+			expectedError: nil,
+		},
+		{
+			// Test that a pool that was "in sync" with a deleted Service resource exposing a port via tls sni is detected as requiring an update, and that the shared frontend is removed when it becomes empty.
+			description: "pool that was \"in sync\" with a deleted Service resource exposing a port via tls sni is detected as requiring an update",
+			service:     deletedServiceExposingPort443ViaSNI,
+			options:     serviceTranslationOptionsForPort443ViaSNI,
+			pool: edgelbpooltestutil.DummyEdgeLBPool("baz", func(p *models.V2Pool) {
+				p.Haproxy.Backends = []*models.V2Backend{
+					backendForServiceExposingPort443ViaSNI,
+				}
+				p.Haproxy.Frontends = []*models.V2Frontend{
+					computeSNIFrontendForBindPort(8443, mapItemsForServiceExposingPort443ViaSNI),
+				}
+			}),
+			expectedWasChanged: true,
+			expectedBackends:   []*models.V2Backend{},
+			expectedFrontends:  []*models.V2Frontend{},
+			expectedError:      nil,
+		},
 	}
 	for _, test := range tests {
 		t.Logf("test case: %s", test.description)
@@ -335,8 +554,10 @@ func TestUpdateEdgeLBPoolObject(t *testing.T) {
 		// Create and customize a mock EdgeLB manager.
 		manager := new(edgelbmanagertestutil.MockEdgeLBManager)
 		manager.On("PoolGroup").Return(testEdgeLBPoolGroup)
+		// Create a new fake event recorder.
+		recorder := record.NewFakeRecorder(len(test.expectedEvents) + 1)
 		// Update the EdgeLB pool object in-place.
-		mustUpdate, _, err := NewServiceTranslator(testClusterName, test.service, test.options, kubeCache, manager).updateEdgeLBPoolObject(test.pool)
+		mustUpdate, _, err := NewServiceTranslator(testClusterName, test.service, test.options, kubeCache, manager, recorder).updateEdgeLBPoolObject(test.pool)
 		// Check that expected errors have been propagated (if any).
 		assert.Equal(t, test.expectedError, err)
 		// Check that the need for a pool update was adequately detected.
@@ -345,5 +566,15 @@ func TestUpdateEdgeLBPoolObject(t *testing.T) {
 		assert.Equal(t, test.expectedBackends, test.pool.Haproxy.Backends)
 		// Check that all expected frontends are present.
 		assert.Equal(t, test.expectedFrontends, test.pool.Haproxy.Frontends)
+		// Check that all expected events have been emitted.
+		close(recorder.Events)
+		events := make([]string, 0)
+		for event := range recorder.Events {
+			events = append(events, event)
+		}
+		if test.expectedEvents == nil {
+			test.expectedEvents = []string{}
+		}
+		assert.Equal(t, test.expectedEvents, events)
 	}
 }
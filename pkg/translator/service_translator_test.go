@@ -9,7 +9,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
 
 	"github.com/mesosphere/dklb/pkg/constants"
 	dklberrors "github.com/mesosphere/dklb/pkg/errors"
@@ -95,6 +97,26 @@ func TestServiceTranslator_Translate(t *testing.T) {
 			},
 			expectedError: fmt.Errorf("edgelb pool %q targeted by service %q has probably been manually deleted, and the pool creation strategy is %q", "foo", "foo/bar", constants.EdgeLBPoolCreationStrategyOnce),
 		},
+		// Tests that a pool is not created when it doesn't exist and the target Service resource has been deleted.
+		{
+			description: "pool is not created when it doesn't exist and the target Service resource has been deleted",
+			service: servicetestutil.DummyServiceResource("foo", "bar", func(service *v1.Service) {
+				deletionTimestamp := metav1.Now()
+				service.DeletionTimestamp = &deletionTimestamp
+				service.Spec.Type = v1.ServiceTypeLoadBalancer
+			}),
+			poolName: "foo",
+			mockCustomizer: func(manager *edgelbmanagertestutil.MockEdgeLBManager) {
+				manager.On("GetPool", mock.Anything, "foo").Return(nil, dklberrors.NotFound(errors.New("not found")))
+			},
+			options: translator.ServiceTranslationOptions{
+				BaseTranslationOptions: translator.BaseTranslationOptions{
+					EdgeLBPoolName:             "foo",
+					EdgeLBPoolCreationStrategy: constants.EdgeLBPoolCreationStrategyIfNotPresent,
+				},
+			},
+			expectedError: nil,
+		},
 		// Tests that a pool is updated whenever it exists but is not in sync with the target Service resource.
 		{
 			description: "pool is updated whenever it exists but is not in sync with the target Service resource",
@@ -135,8 +157,11 @@ func TestServiceTranslator_Translate(t *testing.T) {
 		test.mockCustomizer(m)
 		m.On("PoolGroup").Return(testEdgeLBPoolGroup)
 		m.On("GetPoolMetadata", mock.Anything, mock.Anything).Return(&models.V2PoolMetadata{}, nil)
+		// Create a new fake event recorder.
+		// Its buffer must be large enough to hold every event emitted during translation (e.g. warnings about the resource and the report of the changes made to the EdgeLB pool), as otherwise emitting an event blocks forever.
+		recorder := record.NewFakeRecorder(10)
 		// Perform translation of the Service resource.
-		status, err := translator.NewServiceTranslator(testClusterName, test.service, test.options, kubeCache, m).Translate()
+		status, err := translator.NewServiceTranslator(testClusterName, test.service, test.options, kubeCache, m, recorder).Translate()
 		if test.expectedError != nil {
 			// Make sure we've got the expected error.
 			assert.Nil(t, status)
@@ -20,8 +20,17 @@ const (
 	serviceBackendNameFormatString = "%s" + separator + "%s" + separator + "%s" + separator + "%d"
 	// serviceFrontendNameFormatString is the format string used to compute the name for a frontend for a given Service resource.
 	serviceFrontendNameFormatString = serviceBackendNameFormatString
+	// sniFrontendNameFormatString is the format string used to compute the name for the frontend shared by all service ports exposed via TLS SNI on a given frontend bind port.
+	sniFrontendNameFormatString = sniFrontendNamePrefix + separator + "%d"
+	// sniFrontendNamePrefix is the prefix used in the name of frontends shared by service ports exposed via TLS SNI.
+	sniFrontendNamePrefix = "sni"
 	// separator is the separator used between the different parts that comprise the name of a backend/frontend.
 	separator = ":"
+	// sourceStickTableFormatString is the format string used to compute the HAProxy "stick-table" directive used to implement "ClientIP" session affinity.
+	// Entries expire after the specified number of seconds without activity.
+	sourceStickTableFormatString = "stick-table type ip size 200k expire %ds"
+	// sourceStickOnDirective is the HAProxy directive that makes connections from a given source address stick to the same server.
+	sourceStickOnDirective = "stick on src"
 )
 
 // servicePortBackendFrontend groups together the backend and frontend that correspond to a given service port.
@@ -40,6 +49,12 @@ func frontendNameForServicePort(clusterName string, service *corev1.Service, por
 	return fmt.Sprintf(serviceFrontendNameFormatString, stringsutil.ReplaceForwardSlashesWithDots(clusterName), service.Namespace, service.Name, port.Port)
 }
 
+// sniFrontendNameForBindPort computes the name of the frontend shared by all service ports exposed via TLS SNI on the specified frontend bind port.
+// As this frontend may be shared by several Service resources, its name does not include any information about a particular Service resource.
+func sniFrontendNameForBindPort(bindPort int32) string {
+	return fmt.Sprintf(sniFrontendNameFormatString, bindPort)
+}
+
 // serviceOwnedEdgeLBObjectMetadata groups together information about about the Service resource that owns a given EdgeLB backend/frontend.
 type serviceOwnedEdgeLBObjectMetadata struct {
 	// ClusterName is the name of the Kubernetes cluster to which the Service resource belongs.
@@ -58,10 +73,16 @@ func (sp *serviceOwnedEdgeLBObjectMetadata) IsOwnedBy(clusterName string, servic
 }
 
 // computeBackendForServicePort computes the backend that correspond to the specified service port.
-func computeBackendForServicePort(clusterName string, service *corev1.Service, servicePort corev1.ServicePort) *models.V2Backend {
+// The backend uses the load-balancing algorithm and health checks specified in "options" (or the default ones in case none are specified).
+// In case the Service resource requests "ClientIP" session affinity, connections from a given client IP are kept on the same Kubernetes node for the requested amount of time.
+func computeBackendForServicePort(clusterName string, service *corev1.Service, servicePort corev1.ServicePort, options ServiceTranslationOptions) *models.V2Backend {
+	balance := options.EdgeLBBackendBalance
+	if balance == "" {
+		balance = DefaultEdgeLBBackendBalance
+	}
 	// Compute the name to give to the backend.
-	return &models.V2Backend{
-		Balance:  constants.EdgeLBBackendBalanceLeastConnections,
+	res := &models.V2Backend{
+		Balance:  balance,
 		Name:     backendNameForServicePort(clusterName, service, servicePort),
 		Protocol: models.V2ProtocolTCP,
 		Services: []*models.V2Service{
@@ -96,25 +117,44 @@ func computeBackendForServicePort(clusterName string, service *corev1.Service, s
 			},
 		},
 	}
+	// If "ClientIP" session affinity has been requested, use a stick table keyed by the source address of each connection.
+	if service.Spec.SessionAffinity == corev1.ServiceAffinityClientIP {
+		res.MiscStrs = []string{
+			fmt.Sprintf(sourceStickTableFormatString, computeClientIPAffinityTimeoutSeconds(service)),
+			sourceStickOnDirective,
+		}
+	}
+	// If source ranges have been specified and the service port is exposed via TLS SNI, make the backend reject connections originating outside them.
+	// This is required as the frontend is shared with other Service resources, and hence cannot be used to enforce the source ranges of the current one.
+	if _, isSNI := options.EdgeLBPoolSNIHostnames[servicePort.Port]; isSNI && len(options.EdgeLBSourceRanges) > 0 {
+		res.MiscStrs = append(res.MiscStrs, computeSourceRangesContentRejectDirective(options.EdgeLBSourceRanges))
+	}
+	// Configure health checks as requested.
+	applyHealthCheckOptions(res, options.EdgeLBBackendHealthCheck)
+	return res
+}
+
+// computeClientIPAffinityTimeoutSeconds returns the "ClientIP" session affinity timeout (in seconds) requested by the specified Service resource, or the Kubernetes default in case none is specified.
+func computeClientIPAffinityTimeoutSeconds(service *corev1.Service) int32 {
+	if c := service.Spec.SessionAffinityConfig; c != nil && c.ClientIP != nil && c.ClientIP.TimeoutSeconds != nil {
+		return *c.ClientIP.TimeoutSeconds
+	}
+	return corev1.DefaultClientIPServiceAffinitySeconds
 }
 
 // computeFrontendForServicePort computes the frontend that correspond to the specified service port.
+// In case source ranges have been specified, the frontend rejects connections originating outside them.
 func computeFrontendForServicePort(clusterName string, service *corev1.Service, servicePort corev1.ServicePort, options ServiceTranslationOptions) *models.V2Frontend {
 	var (
 		bindPort     int32
 		frontendName string
 	)
-	// Compute the value to use as the frontend bind port, falling back to the service port in case one isn't provided.
-	portOverride, exists := options.EdgeLBPoolPortMap[servicePort.Port]
-	if !exists {
-		bindPort = servicePort.Port
-	} else {
-		bindPort = portOverride
-	}
+	// Compute the value to use as the frontend bind port.
+	bindPort = computeBindPortForServicePort(servicePort, options)
 	// Compute the name to give to the frontend.
 	frontendName = frontendNameForServicePort(clusterName, service, servicePort)
 	// Compute the backend and frontend objects and return them.
-	return &models.V2Frontend{
+	res := &models.V2Frontend{
 		BindAddress: constants.EdgeLBFrontendBindAddress,
 		Name:        frontendName,
 		Protocol:    models.V2ProtocolTCP,
@@ -123,6 +163,81 @@ func computeFrontendForServicePort(clusterName string, service *corev1.Service,
 			DefaultBackend: backendNameForServicePort(clusterName, service, servicePort),
 		},
 	}
+	// If source ranges have been specified, make the frontend reject connections originating outside them.
+	if len(options.EdgeLBSourceRanges) > 0 {
+		res.MiscStrs = []string{
+			computeSourceRangesConnectionRejectDirective(options.EdgeLBSourceRanges),
+		}
+	}
+	return res
+}
+
+// computeSNIFrontendForBindPort computes the frontend shared by all service ports exposed via TLS SNI on the specified frontend bind port.
+// Traffic is routed to the backends referenced by the specified items based on the TLS SNI hostname sent by clients, and TLS is not terminated by EdgeLB.
+func computeSNIFrontendForBindPort(bindPort int32, items []*models.V2FrontendLinkBackendMapItems0) *models.V2Frontend {
+	if items == nil {
+		items = make([]*models.V2FrontendLinkBackendMapItems0, 0)
+	}
+	return &models.V2Frontend{
+		BindAddress: constants.EdgeLBFrontendBindAddress,
+		Name:        sniFrontendNameForBindPort(bindPort),
+		Protocol:    models.V2ProtocolTCP,
+		BindPort:    &bindPort,
+		LinkBackend: &models.V2FrontendLinkBackend{
+			Map: items,
+		},
+	}
+}
+
+// computeSNIFrontendMapItemsForServicePort computes the items that route traffic for the specified TLS SNI hostnames to the backend corresponding to the specified service port.
+func computeSNIFrontendMapItemsForServicePort(clusterName string, service *corev1.Service, servicePort corev1.ServicePort, hostnames []string) []*models.V2FrontendLinkBackendMapItems0 {
+	res := make([]*models.V2FrontendLinkBackendMapItems0, 0, len(hostnames))
+	for _, hostname := range hostnames {
+		res = append(res, &models.V2FrontendLinkBackendMapItems0{
+			Backend: backendNameForServicePort(clusterName, service, servicePort),
+			HostEq:  hostname,
+		})
+	}
+	return res
+}
+
+// computeBindPortForServicePort computes the frontend bind port to use for the specified service port, falling back to the service port in case one isn't provided.
+func computeBindPortForServicePort(servicePort corev1.ServicePort, options ServiceTranslationOptions) int32 {
+	if portOverride, exists := options.EdgeLBPoolPortMap[servicePort.Port]; exists {
+		return portOverride
+	}
+	return servicePort.Port
+}
+
+// computeSNIFrontendBindPort parses the provided frontend name and returns the corresponding frontend bind port in case it is the name of a frontend shared by service ports exposed via TLS SNI.
+func computeSNIFrontendBindPort(name string) (int32, error) {
+	parts := strings.Split(name, separator)
+	if len(parts) != 2 || parts[0] != sniFrontendNamePrefix {
+		return 0, errors.New("invalid tls sni frontend name")
+	}
+	p, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, errors.New("invalid tls sni frontend name")
+	}
+	return int32(p), nil
+}
+
+// isSNIFrontendUsedByService indicates whether the frontend with the specified name is shared by service ports exposed via TLS SNI, and whether at least one of said service ports belongs to the specified Service resource.
+func isSNIFrontendUsedByService(clusterName string, service *corev1.Service, name string) bool {
+	bindPort, err := computeSNIFrontendBindPort(name)
+	if err != nil {
+		return false
+	}
+	options, err := ComputeServiceTranslationOptions(clusterName, service)
+	if err != nil {
+		return false
+	}
+	for _, port := range service.Spec.Ports {
+		if _, isSNI := options.EdgeLBPoolSNIHostnames[port.Port]; isSNI && computeBindPortForServicePort(port, *options) == bindPort {
+			return true
+		}
+	}
+	return false
 }
 
 // computeServiceOwnedEdgeLBObjectMetadata parses the provided backend/frontend name and returns metadata about the Service resource that owns it.
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"k8s.io/api/core/v1"
 
+	"github.com/mesosphere/dklb/pkg/util/pointers"
 	servicetestutil "github.com/mesosphere/dklb/test/util/kubernetes/service"
 )
 
@@ -199,3 +200,133 @@ func TestComputeServiceOwnedEdgeLBObjectMetadataRoundTrip(t *testing.T) {
 		assert.Equal(t, port.Port, metadata.ServicePort)
 	}
 }
+
+// TestComputeSNIFrontendBindPort tests the "computeSNIFrontendBindPort" function.
+func TestComputeSNIFrontendBindPort(t *testing.T) {
+	tests := []struct {
+		description string
+		name        string
+		bindPort    int32
+		err         error
+	}{
+		{
+			description: "name is the name of a frontend owned by a service",
+			name:        "dev.kubernetes01:foo:bar:80",
+			err:         errors.New("invalid tls sni frontend name"),
+		},
+		{
+			description: "name has invalid second component",
+			name:        "sni:foo",
+			err:         errors.New("invalid tls sni frontend name"),
+		},
+		{
+			description: "name is valid",
+			name:        sniFrontendNameForBindPort(8443),
+			bindPort:    8443,
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		r, err := computeSNIFrontendBindPort(test.name)
+		if err != nil {
+			assert.Equal(t, test.err, err)
+		} else {
+			assert.Equal(t, test.bindPort, r)
+		}
+	}
+}
+
+// TestComputeBackendForServicePortWithSessionAffinity tests that the "computeBackendForServicePort" function honors the session affinity requested by a Service resource.
+func TestComputeBackendForServicePortWithSessionAffinity(t *testing.T) {
+	tests := []struct {
+		description      string
+		affinity         v1.ServiceAffinity
+		affinityConfig   *v1.SessionAffinityConfig
+		expectedMiscStrs []string
+	}{
+		{
+			description:      "service without session affinity",
+			affinity:         v1.ServiceAffinityNone,
+			expectedMiscStrs: nil,
+		},
+		{
+			description: "service with client ip session affinity and the default timeout",
+			affinity:    v1.ServiceAffinityClientIP,
+			expectedMiscStrs: []string{
+				"stick-table type ip size 200k expire 10800s",
+				"stick on src",
+			},
+		},
+		{
+			description: "service with client ip session affinity and a custom timeout",
+			affinity:    v1.ServiceAffinityClientIP,
+			affinityConfig: &v1.SessionAffinityConfig{
+				ClientIP: &v1.ClientIPConfig{
+					TimeoutSeconds: pointers.NewInt32(60),
+				},
+			},
+			expectedMiscStrs: []string{
+				"stick-table type ip size 200k expire 60s",
+				"stick on src",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		s := servicetestutil.DummyServiceResource("foo", "bar", func(service *v1.Service) {
+			service.Spec.SessionAffinity = test.affinity
+			service.Spec.SessionAffinityConfig = test.affinityConfig
+		})
+		b := computeBackendForServicePort(testClusterName, s, v1.ServicePort{Port: 80, NodePort: 30080}, ServiceTranslationOptions{})
+		assert.Equal(t, test.expectedMiscStrs, b.MiscStrs)
+	}
+}
+
+// TestComputeFrontendAndBackendForServicePortWithSourceRanges tests that the source ranges specified for a Service resource are enforced by the corresponding frontends and backends.
+func TestComputeFrontendAndBackendForServicePortWithSourceRanges(t *testing.T) {
+	tests := []struct {
+		description              string
+		options                  ServiceTranslationOptions
+		expectedFrontendMiscStrs []string
+		expectedBackendMiscStrs  []string
+	}{
+		{
+			description:              "service without source ranges",
+			options:                  ServiceTranslationOptions{},
+			expectedFrontendMiscStrs: nil,
+			expectedBackendMiscStrs:  nil,
+		},
+		{
+			description: "service with source ranges",
+			options: ServiceTranslationOptions{
+				EdgeLBSourceRanges: []string{"10.0.0.0/8", "192.168.0.0/16"},
+			},
+			expectedFrontendMiscStrs: []string{
+				"tcp-request connection reject if !{ src 10.0.0.0/8 192.168.0.0/16 }",
+			},
+			expectedBackendMiscStrs: nil,
+		},
+		{
+			description: "service exposed via tls sni with source ranges",
+			options: ServiceTranslationOptions{
+				EdgeLBPoolSNIHostnames: map[int32][]string{
+					80: {"foo.example.com"},
+				},
+				EdgeLBSourceRanges: []string{"10.0.0.0/8"},
+			},
+			expectedFrontendMiscStrs: []string{
+				"tcp-request connection reject if !{ src 10.0.0.0/8 }",
+			},
+			expectedBackendMiscStrs: []string{
+				"tcp-request content reject if !{ src 10.0.0.0/8 }",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		s := servicetestutil.DummyServiceResource("foo", "bar")
+		p := v1.ServicePort{Port: 80, NodePort: 30080}
+		assert.Equal(t, test.expectedFrontendMiscStrs, computeFrontendForServicePort(testClusterName, s, p, test.options).MiscStrs)
+		assert.Equal(t, test.expectedBackendMiscStrs, computeBackendForServicePort(testClusterName, s, p, test.options).MiscStrs)
+	}
+}
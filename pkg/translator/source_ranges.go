@@ -0,0 +1,42 @@
+package translator
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+const (
+	// sourceRangesConnectionRejectFormatString is the format string used to compute the HAProxy directive that makes a frontend reject connections originating outside the allowed source ranges.
+	sourceRangesConnectionRejectFormatString = "tcp-request connection reject if !{ src %s }"
+	// sourceRangesContentRejectFormatString is the format string used to compute the HAProxy directive that makes a backend reject connections originating outside the allowed source ranges.
+	// It is used whenever the frontend is shared with other resources, and hence cannot be used to enforce the source ranges of a single resource.
+	sourceRangesContentRejectFormatString = "tcp-request content reject if !{ src %s }"
+)
+
+// parseSourceRanges parses and validates the specified list of source ranges (in CIDR notation).
+// Empty items are ignored, and an empty (nil) list is returned in case no source ranges have been specified.
+func parseSourceRanges(ranges []string) ([]string, error) {
+	var res []string
+	for _, r := range ranges {
+		r = strings.TrimSpace(r)
+		if r == "" {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(r); err != nil {
+			return nil, fmt.Errorf("%q is not a valid source range: %v", r, err)
+		}
+		res = append(res, r)
+	}
+	return res, nil
+}
+
+// computeSourceRangesConnectionRejectDirective computes the HAProxy directive that makes a frontend reject connections originating outside the specified source ranges.
+func computeSourceRangesConnectionRejectDirective(ranges []string) string {
+	return fmt.Sprintf(sourceRangesConnectionRejectFormatString, strings.Join(ranges, " "))
+}
+
+// computeSourceRangesContentRejectDirective computes the HAProxy directive that makes a backend reject connections originating outside the specified source ranges.
+func computeSourceRangesContentRejectDirective(ranges []string) string {
+	return fmt.Sprintf(sourceRangesContentRejectFormatString, strings.Join(ranges, " "))
+}
@@ -0,0 +1,50 @@
+package translator
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseSourceRanges tests the "parseSourceRanges" function.
+func TestParseSourceRanges(t *testing.T) {
+	tests := []struct {
+		description string
+		ranges      []string
+		result      []string
+		err         error
+	}{
+		{
+			description: "no source ranges",
+			ranges:      nil,
+			result:      nil,
+		},
+		{
+			description: "empty source ranges",
+			ranges:      []string{"", " "},
+			result:      nil,
+		},
+		{
+			description: "valid ipv4 and ipv6 source ranges",
+			ranges:      []string{"10.0.0.0/8", " 2001:db8::/32 "},
+			result:      []string{"10.0.0.0/8", "2001:db8::/32"},
+		},
+		{
+			description: "invalid source range",
+			ranges:      []string{"10.0.0.0/8", "10.0.0.1"},
+			err:         fmt.Errorf("%q is not a valid source range: %v", "10.0.0.1", &net.ParseError{Type: "CIDR address", Text: "10.0.0.1"}),
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		r, err := parseSourceRanges(test.ranges)
+		if test.err != nil {
+			assert.Equal(t, test.err, err)
+		} else {
+			assert.NoError(t, err)
+			assert.Equal(t, test.result, r)
+		}
+	}
+}
@@ -70,7 +70,7 @@ func computeLoadBalancerStatus(manager manager.EdgeLBManager, poolName, clusterN
 		switch t := obj.(type) {
 		case *corev1.Service:
 			m, err := computeServiceOwnedEdgeLBObjectMetadata(frontend.Name)
-			isOwnedByObj = err == nil && m.IsOwnedBy(clusterName, t)
+			isOwnedByObj = err == nil && m.IsOwnedBy(clusterName, t) || isSNIFrontendUsedByService(clusterName, t, frontend.Name)
 		case *extsv1beta1.Ingress:
 			m, err := computeIngressOwnedEdgeLBObjectMetadata(frontend.Name)
 			isOwnedByObj = err == nil && m.IsOwnedBy(clusterName, t)
@@ -7,6 +7,7 @@ import (
 )
 
 // ForEachIngressBackend iterates over Ingress backends defined on the specified Ingress resource, calling "fn" with each Ingress backend object and the associated host and path whenever applicable.
+// "networking.k8s.io/v1" Ingress resources must have been converted using "ConvertNetworkingV1Ingress", in which case their ".spec.defaultBackend" field is visited as the default Ingress backend.
 func ForEachIngresBackend(ingress *extsv1beta1.Ingress, fn func(host *string, path *string, backend extsv1beta1.IngressBackend)) {
 	if ingress.Spec.Backend != nil {
 		// Use nil values for "host" and "path" so that the caller can identify the current Ingress backend as the default one if it needs to.
@@ -27,7 +28,15 @@ func ForEachIngresBackend(ingress *extsv1beta1.Ingress, fn func(host *string, pa
 }
 
 // IsEdgeLBIngress returns a value indicating whether the specified Ingress resource is meant to be provisioned by EdgeLB.
+// "networking.k8s.io/v1" Ingress resources referencing an IngressClass resource are provisioned by EdgeLB whenever said IngressClass resource selects dklb as the controller.
+// All other Ingress resources are provisioned by EdgeLB whenever the (legacy) "kubernetes.io/ingress.class" annotation selects EdgeLB.
 func IsEdgeLBIngress(ingress *extsv1beta1.Ingress) bool {
+	// If the Ingress resource references an IngressClass resource, return whether said IngressClass resource selects dklb as the controller.
+	if IsNetworkingV1Ingress(ingress) {
+		if controller, exists := ingress.Annotations[constants.IngressClassControllerAnnotationKey]; exists {
+			return controller == constants.EdgeLBIngressClassController
+		}
+	}
 	// If the required annotation is not present, return false.
 	v, exists := ingress.Annotations[constants.EdgeLBIngressClassAnnotationKey]
 	if !exists {
@@ -0,0 +1,279 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+
+	extsv1beta1 "k8s.io/api/extensions/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/discovery"
+
+	"github.com/mesosphere/dklb/pkg/constants"
+)
+
+const (
+	// networkingV1APIVersion is the API version of "networking.k8s.io/v1" Ingress resources.
+	networkingV1APIVersion = "networking.k8s.io/v1"
+	// ingressKind is the kind of Ingress resources.
+	ingressKind = "Ingress"
+)
+
+var (
+	// NetworkingV1IngressResource is the "networking.k8s.io/v1" resource that represents Ingress resources.
+	NetworkingV1IngressResource = schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"}
+	// NetworkingV1IngressClassResource is the "networking.k8s.io/v1" resource that represents IngressClass resources.
+	NetworkingV1IngressClassResource = schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingressclasses"}
+)
+
+// networkingV1Ingress mirrors the subset of the "networking.k8s.io/v1" Ingress API that is relevant to dklb.
+// It is required because the version of the Kubernetes client libraries used by dklb does not include said API.
+type networkingV1Ingress struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              networkingV1IngressSpec   `json:"spec,omitempty"`
+	Status            extsv1beta1.IngressStatus `json:"status,omitempty"`
+}
+
+// networkingV1IngressSpec mirrors the ".spec" field of a "networking.k8s.io/v1" Ingress resource.
+type networkingV1IngressSpec struct {
+	IngressClassName *string                   `json:"ingressClassName,omitempty"`
+	DefaultBackend   *networkingV1Backend      `json:"defaultBackend,omitempty"`
+	TLS              []extsv1beta1.IngressTLS  `json:"tls,omitempty"`
+	Rules            []networkingV1IngressRule `json:"rules,omitempty"`
+}
+
+// networkingV1IngressRule mirrors an item of the ".spec.rules" field of a "networking.k8s.io/v1" Ingress resource.
+type networkingV1IngressRule struct {
+	Host string                     `json:"host,omitempty"`
+	HTTP *networkingV1HTTPRuleValue `json:"http,omitempty"`
+}
+
+// networkingV1HTTPRuleValue mirrors the ".http" field of a rule of a "networking.k8s.io/v1" Ingress resource.
+type networkingV1HTTPRuleValue struct {
+	Paths []networkingV1HTTPPath `json:"paths"`
+}
+
+// networkingV1HTTPPath mirrors a path of a rule of a "networking.k8s.io/v1" Ingress resource.
+type networkingV1HTTPPath struct {
+	Path     string              `json:"path,omitempty"`
+	PathType string              `json:"pathType,omitempty"`
+	Backend  networkingV1Backend `json:"backend"`
+}
+
+// networkingV1Backend mirrors a backend of a "networking.k8s.io/v1" Ingress resource.
+type networkingV1Backend struct {
+	Service  *networkingV1ServiceBackend `json:"service,omitempty"`
+	Resource map[string]interface{}      `json:"resource,omitempty"`
+}
+
+// networkingV1ServiceBackend mirrors the ".service" field of a backend of a "networking.k8s.io/v1" Ingress resource.
+type networkingV1ServiceBackend struct {
+	Name string `json:"name"`
+	Port struct {
+		Name   string `json:"name,omitempty"`
+		Number int32  `json:"number,omitempty"`
+	} `json:"port,omitempty"`
+}
+
+// ingressPathType holds the ".pathType" field of a given path of a "networking.k8s.io/v1" Ingress resource.
+type ingressPathType struct {
+	Host     string `json:"host"`
+	Path     string `json:"path"`
+	PathType string `json:"pathType"`
+}
+
+// internalIngressAnnotationKeys is the set of annotations that dklb sets on its in-memory representation of "networking.k8s.io/v1" Ingress resources, and that must never be persisted.
+var internalIngressAnnotationKeys = []string{
+	constants.IngressClassControllerAnnotationKey,
+	constants.IngressPathTypesAnnotationKey,
+}
+
+// ConvertNetworkingV1Ingress converts the specified "networking.k8s.io/v1" Ingress resource into the representation used internally by dklb.
+// "getIngressClass" is used to read the IngressClass resource referenced by the Ingress resource (if any), so that its controller can be recorded.
+// Information that cannot be represented by the internal representation (such as the type of each path and the controller of the IngressClass resource) is recorded in internal annotations.
+func ConvertNetworkingV1Ingress(obj *unstructured.Unstructured, getIngressClass func(name string) (*unstructured.Unstructured, error)) (*extsv1beta1.Ingress, error) {
+	// Convert the unstructured object into the mirror representation.
+	v1 := &networkingV1Ingress{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), v1); err != nil {
+		return nil, fmt.Errorf("failed to convert ingress %q: %v", obj.GetNamespace()+"/"+obj.GetName(), err)
+	}
+	// Create the internal representation, recording the API version the Ingress resource was read from.
+	ingress := &extsv1beta1.Ingress{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: networkingV1APIVersion,
+			Kind:       ingressKind,
+		},
+		ObjectMeta: *v1.ObjectMeta.DeepCopy(),
+		Status:     *v1.Status.DeepCopy(),
+	}
+	// Make sure that internal annotations that may have been set by users are ignored.
+	for _, key := range internalIngressAnnotationKeys {
+		delete(ingress.Annotations, key)
+	}
+	if ingress.Annotations == nil {
+		ingress.Annotations = make(map[string]string)
+	}
+	// Record the controller of the referenced IngressClass resource (if any).
+	if v1.Spec.IngressClassName != nil && *v1.Spec.IngressClassName != "" {
+		controller, err := getIngressClassController(*v1.Spec.IngressClassName, getIngressClass)
+		if err != nil {
+			return nil, err
+		}
+		ingress.Annotations[constants.IngressClassControllerAnnotationKey] = controller
+	}
+	// Convert the default backend (if any).
+	if v1.Spec.DefaultBackend != nil {
+		b := convertNetworkingV1Backend(*v1.Spec.DefaultBackend)
+		ingress.Spec.Backend = &b
+	}
+	// Convert the TLS configuration.
+	ingress.Spec.TLS = v1.Spec.TLS
+	// Convert the rules, recording the type of each path.
+	pathTypes := make([]ingressPathType, 0)
+	for _, rule := range v1.Spec.Rules {
+		r := extsv1beta1.IngressRule{
+			Host: rule.Host,
+		}
+		if rule.HTTP != nil {
+			r.HTTP = &extsv1beta1.HTTPIngressRuleValue{
+				Paths: make([]extsv1beta1.HTTPIngressPath, 0, len(rule.HTTP.Paths)),
+			}
+			for _, path := range rule.HTTP.Paths {
+				r.HTTP.Paths = append(r.HTTP.Paths, extsv1beta1.HTTPIngressPath{
+					Path:    path.Path,
+					Backend: convertNetworkingV1Backend(path.Backend),
+				})
+				pathTypes = append(pathTypes, ingressPathType{Host: rule.Host, Path: path.Path, PathType: path.PathType})
+			}
+		}
+		ingress.Spec.Rules = append(ingress.Spec.Rules, r)
+	}
+	if len(pathTypes) > 0 {
+		v, err := json.Marshal(pathTypes)
+		if err != nil {
+			return nil, err
+		}
+		ingress.Annotations[constants.IngressPathTypesAnnotationKey] = string(v)
+	}
+	return ingress, nil
+}
+
+// getIngressClassController returns the controller of the IngressClass resource with the specified name.
+func getIngressClassController(name string, getIngressClass func(name string) (*unstructured.Unstructured, error)) (string, error) {
+	c, err := getIngressClass(name)
+	if err != nil {
+		// An IngressClass resource that does not exist doesn't select any controller.
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read ingress class %q: %v", name, err)
+	}
+	controller, _, err := unstructured.NestedString(c.UnstructuredContent(), "spec", "controller")
+	if err != nil {
+		return "", fmt.Errorf("failed to read the controller of ingress class %q: %v", name, err)
+	}
+	return controller, nil
+}
+
+// convertNetworkingV1Backend converts the specified "networking.k8s.io/v1" Ingress backend into the representation used internally by dklb.
+// Resource backends are not supported by EdgeLB, and are converted into backends with an empty service name so that they are reported as invalid.
+func convertNetworkingV1Backend(backend networkingV1Backend) extsv1beta1.IngressBackend {
+	if backend.Service == nil {
+		return extsv1beta1.IngressBackend{}
+	}
+	r := extsv1beta1.IngressBackend{
+		ServiceName: backend.Service.Name,
+	}
+	if backend.Service.Port.Name != "" {
+		r.ServicePort = intstr.FromString(backend.Service.Port.Name)
+	} else {
+		r.ServicePort = intstr.FromInt(int(backend.Service.Port.Number))
+	}
+	return r
+}
+
+// IsNetworkingV1Ingress returns a value indicating whether the specified Ingress resource was read from the "networking.k8s.io/v1" API.
+func IsNetworkingV1Ingress(ingress *extsv1beta1.Ingress) bool {
+	return ingress.APIVersion == networkingV1APIVersion
+}
+
+// GetIngressPathType returns the ".pathType" field of the path with the specified host and path in the specified Ingress resource.
+// An empty string is returned for Ingress resources not read from the "networking.k8s.io/v1" API, as well as for paths that do not exist.
+func GetIngressPathType(ingress *extsv1beta1.Ingress, host, path string) string {
+	v, exists := ingress.Annotations[constants.IngressPathTypesAnnotationKey]
+	if !exists {
+		return ""
+	}
+	var pathTypes []ingressPathType
+	if err := json.Unmarshal([]byte(v), &pathTypes); err != nil {
+		return ""
+	}
+	for _, pt := range pathTypes {
+		if pt.Host == host && pt.Path == path {
+			return pt.PathType
+		}
+	}
+	return ""
+}
+
+// SetNetworkingV1IngressAnnotations copies the annotations of the specified Ingress resource into the specified "networking.k8s.io/v1" Ingress resource.
+// Internal annotations are never copied.
+func SetNetworkingV1IngressAnnotations(obj *unstructured.Unstructured, ingress *extsv1beta1.Ingress) {
+	annotations := make(map[string]string, len(ingress.Annotations))
+	for key, value := range ingress.Annotations {
+		annotations[key] = value
+	}
+	for _, key := range internalIngressAnnotationKeys {
+		delete(annotations, key)
+	}
+	// Avoid adding an empty ".metadata.annotations" field to Ingress resources that have no annotations.
+	if len(annotations) == 0 {
+		unstructured.RemoveNestedField(obj.Object, "metadata", "annotations")
+		return
+	}
+	obj.SetAnnotations(annotations)
+}
+
+// SetNetworkingV1IngressMetadata copies the annotations, finalizers and resource version of the specified Ingress resource into the specified "networking.k8s.io/v1" Ingress resource.
+// Internal annotations are never copied.
+func SetNetworkingV1IngressMetadata(obj *unstructured.Unstructured, ingress *extsv1beta1.Ingress) {
+	SetNetworkingV1IngressAnnotations(obj, ingress)
+	if len(ingress.Finalizers) == 0 {
+		unstructured.RemoveNestedField(obj.Object, "metadata", "finalizers")
+	} else {
+		obj.SetFinalizers(ingress.Finalizers)
+	}
+	obj.SetResourceVersion(ingress.ResourceVersion)
+}
+
+// SetNetworkingV1IngressStatus copies the status of the specified Ingress resource into the specified "networking.k8s.io/v1" Ingress resource.
+func SetNetworkingV1IngressStatus(obj *unstructured.Unstructured, ingress *extsv1beta1.Ingress) error {
+	status, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&ingress.Status)
+	if err != nil {
+		return err
+	}
+	return unstructured.SetNestedField(obj.UnstructuredContent(), status, "status")
+}
+
+// ServesNetworkingV1Ingresses returns a value indicating whether the Kubernetes API serves Ingress resources using the "networking.k8s.io/v1" API.
+func ServesNetworkingV1Ingresses(client discovery.DiscoveryInterface) (bool, error) {
+	resources, err := client.ServerResourcesForGroupVersion(NetworkingV1IngressResource.GroupVersion().String())
+	if err != nil {
+		// Report the API as not being served if the group version is not known by the Kubernetes API.
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, r := range resources.APIResources {
+		if r.Name == NetworkingV1IngressResource.Resource {
+			return true, nil
+		}
+	}
+	return false, nil
+}
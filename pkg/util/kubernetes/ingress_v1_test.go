@@ -0,0 +1,254 @@
+package kubernetes_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	extsv1beta1 "k8s.io/api/extensions/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/mesosphere/dklb/pkg/constants"
+	"github.com/mesosphere/dklb/pkg/util/kubernetes"
+	ingresstestutil "github.com/mesosphere/dklb/test/util/kubernetes/ingress"
+)
+
+// networkingV1Ingress returns a "networking.k8s.io/v1" Ingress resource with the specified annotations and spec.
+func networkingV1Ingress(annotations map[string]interface{}, spec map[string]interface{}) *unstructured.Unstructured {
+	metadata := map[string]interface{}{
+		"namespace": "foo",
+		"name":      "bar",
+	}
+	if annotations != nil {
+		metadata["annotations"] = annotations
+	}
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "networking.k8s.io/v1",
+			"kind":       "Ingress",
+			"metadata":   metadata,
+			"spec":       spec,
+		},
+	}
+}
+
+// getIngressClass returns the IngressClass resource with the specified name from a fixed set of IngressClass resources.
+// The "edgelb" IngressClass resource selects dklb as the controller, the "nginx" one selects a different controller, and the "broken" one cannot be read.
+func getIngressClass(name string) (*unstructured.Unstructured, error) {
+	controllers := map[string]string{
+		"edgelb": constants.EdgeLBIngressClassController,
+		"nginx":  "k8s.io/ingress-nginx",
+	}
+	if name == "broken" {
+		return nil, errors.New("connection refused")
+	}
+	controller, exists := controllers[name]
+	if !exists {
+		return nil, apierrors.NewNotFound(kubernetes.NetworkingV1IngressClassResource.GroupResource(), name)
+	}
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "networking.k8s.io/v1",
+			"kind":       "IngressClass",
+			"metadata": map[string]interface{}{
+				"name": name,
+			},
+			"spec": map[string]interface{}{
+				"controller": controller,
+			},
+		},
+	}, nil
+}
+
+// TestConvertNetworkingV1Ingress tests the "ConvertNetworkingV1Ingress" function.
+func TestConvertNetworkingV1Ingress(t *testing.T) {
+	obj := networkingV1Ingress(map[string]interface{}{
+		// Internal annotations set by users must be ignored.
+		constants.IngressPathTypesAnnotationKey: "[]",
+		"foo":                                   "bar",
+	}, map[string]interface{}{
+		"ingressClassName": "edgelb",
+		"defaultBackend": map[string]interface{}{
+			"service": map[string]interface{}{
+				"name": "default",
+				"port": map[string]interface{}{"number": int64(80)},
+			},
+		},
+		"tls": []interface{}{
+			map[string]interface{}{
+				"hosts":      []interface{}{"foo.com"},
+				"secretName": "tls-1",
+			},
+		},
+		"rules": []interface{}{
+			map[string]interface{}{
+				"host": "foo.com",
+				"http": map[string]interface{}{
+					"paths": []interface{}{
+						map[string]interface{}{
+							"path":     "/exact",
+							"pathType": "Exact",
+							"backend": map[string]interface{}{
+								"service": map[string]interface{}{
+									"name": "svc-1",
+									"port": map[string]interface{}{"name": "http"},
+								},
+							},
+						},
+						map[string]interface{}{
+							"path":     "/resource",
+							"pathType": "Prefix",
+							"backend": map[string]interface{}{
+								"resource": map[string]interface{}{
+									"apiGroup": "example.com",
+									"kind":     "Bucket",
+									"name":     "assets",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+
+	ingress, err := kubernetes.ConvertNetworkingV1Ingress(obj, getIngressClass)
+	assert.NoError(t, err)
+	// Make sure that the Ingress resource is identified as having been read from the "networking.k8s.io/v1" API, and as being meant to be provisioned by EdgeLB.
+	assert.True(t, kubernetes.IsNetworkingV1Ingress(ingress))
+	assert.True(t, kubernetes.IsEdgeLBIngress(ingress))
+	assert.Equal(t, "foo", ingress.Namespace)
+	assert.Equal(t, "bar", ingress.Name)
+	assert.Equal(t, "bar", ingress.Annotations["foo"])
+	// Make sure that the default backend, the TLS configuration and the rules have been converted.
+	assert.Equal(t, &extsv1beta1.IngressBackend{ServiceName: "default", ServicePort: intstr.FromInt(80)}, ingress.Spec.Backend)
+	assert.Equal(t, []extsv1beta1.IngressTLS{{Hosts: []string{"foo.com"}, SecretName: "tls-1"}}, ingress.Spec.TLS)
+	assert.Equal(t, []extsv1beta1.IngressRule{
+		{
+			Host: "foo.com",
+			IngressRuleValue: extsv1beta1.IngressRuleValue{
+				HTTP: &extsv1beta1.HTTPIngressRuleValue{
+					Paths: []extsv1beta1.HTTPIngressPath{
+						{Path: "/exact", Backend: extsv1beta1.IngressBackend{ServiceName: "svc-1", ServicePort: intstr.FromString("http")}},
+						// Resource backends are not supported, and are converted into backends with an empty service name.
+						{Path: "/resource", Backend: extsv1beta1.IngressBackend{}},
+					},
+				},
+			},
+		},
+	}, ingress.Spec.Rules)
+	// Make sure that the type of each path has been recorded.
+	assert.Equal(t, "Exact", kubernetes.GetIngressPathType(ingress, "foo.com", "/exact"))
+	assert.Equal(t, "Prefix", kubernetes.GetIngressPathType(ingress, "foo.com", "/resource"))
+	assert.Equal(t, "", kubernetes.GetIngressPathType(ingress, "bar.com", "/exact"))
+
+	// Make sure that an error is returned in case the referenced IngressClass resource cannot be read.
+	_, err = kubernetes.ConvertNetworkingV1Ingress(networkingV1Ingress(nil, map[string]interface{}{"ingressClassName": "broken"}), getIngressClass)
+	assert.Error(t, err)
+}
+
+// TestIsEdgeLBIngress tests the "IsEdgeLBIngress" function.
+func TestIsEdgeLBIngress(t *testing.T) {
+	// legacyAnnotation is the (legacy) annotation that selects EdgeLB as the ingress controller.
+	legacyAnnotation := map[string]interface{}{
+		constants.EdgeLBIngressClassAnnotationKey: constants.EdgeLBIngressClassAnnotationValue,
+	}
+	tests := []struct {
+		description    string
+		ingress        func() *extsv1beta1.Ingress
+		expectedResult bool
+	}{
+		{
+			description: "extensions/v1beta1 ingress with the ingress class annotation",
+			ingress: func() *extsv1beta1.Ingress {
+				return ingresstestutil.DummyIngressResource("foo", "bar", ingresstestutil.WithAnnotations(map[string]string{
+					constants.EdgeLBIngressClassAnnotationKey: constants.EdgeLBIngressClassAnnotationValue,
+				}))
+			},
+			expectedResult: true,
+		},
+		{
+			description: "extensions/v1beta1 ingress with the internal ingress class controller annotation",
+			ingress: func() *extsv1beta1.Ingress {
+				return ingresstestutil.DummyIngressResource("foo", "bar", ingresstestutil.WithAnnotations(map[string]string{
+					constants.IngressClassControllerAnnotationKey: constants.EdgeLBIngressClassController,
+				}))
+			},
+			expectedResult: false,
+		},
+		{
+			description: "networking.k8s.io/v1 ingress referencing an ingress class whose controller is dklb",
+			ingress: func() *extsv1beta1.Ingress {
+				ingress, _ := kubernetes.ConvertNetworkingV1Ingress(networkingV1Ingress(nil, map[string]interface{}{"ingressClassName": "edgelb"}), getIngressClass)
+				return ingress
+			},
+			expectedResult: true,
+		},
+		{
+			description: "networking.k8s.io/v1 ingress referencing an ingress class whose controller is not dklb",
+			ingress: func() *extsv1beta1.Ingress {
+				ingress, _ := kubernetes.ConvertNetworkingV1Ingress(networkingV1Ingress(legacyAnnotation, map[string]interface{}{"ingressClassName": "nginx"}), getIngressClass)
+				return ingress
+			},
+			expectedResult: false,
+		},
+		{
+			description: "networking.k8s.io/v1 ingress referencing an ingress class that does not exist",
+			ingress: func() *extsv1beta1.Ingress {
+				ingress, _ := kubernetes.ConvertNetworkingV1Ingress(networkingV1Ingress(legacyAnnotation, map[string]interface{}{"ingressClassName": "missing"}), getIngressClass)
+				return ingress
+			},
+			expectedResult: false,
+		},
+		{
+			description: "networking.k8s.io/v1 ingress not referencing an ingress class but having the ingress class annotation",
+			ingress: func() *extsv1beta1.Ingress {
+				ingress, _ := kubernetes.ConvertNetworkingV1Ingress(networkingV1Ingress(legacyAnnotation, map[string]interface{}{}), getIngressClass)
+				return ingress
+			},
+			expectedResult: true,
+		},
+		{
+			description: "networking.k8s.io/v1 ingress not referencing an ingress class and setting the internal ingress class controller annotation",
+			ingress: func() *extsv1beta1.Ingress {
+				ingress, _ := kubernetes.ConvertNetworkingV1Ingress(networkingV1Ingress(map[string]interface{}{
+					constants.IngressClassControllerAnnotationKey: constants.EdgeLBIngressClassController,
+				}, map[string]interface{}{}), getIngressClass)
+				return ingress
+			},
+			expectedResult: false,
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		assert.Equal(t, test.expectedResult, kubernetes.IsEdgeLBIngress(test.ingress()))
+	}
+}
+
+// TestSetNetworkingV1IngressMetadata tests the "SetNetworkingV1IngressMetadata" function.
+func TestSetNetworkingV1IngressMetadata(t *testing.T) {
+	obj := networkingV1Ingress(nil, map[string]interface{}{"ingressClassName": "edgelb"})
+	ingress, err := kubernetes.ConvertNetworkingV1Ingress(obj, getIngressClass)
+	assert.NoError(t, err)
+	ingress.Annotations["foo"] = "bar"
+	ingress.Finalizers = []string{"example.com/finalizer"}
+	ingress.ResourceVersion = "1"
+
+	kubernetes.SetNetworkingV1IngressMetadata(obj, ingress)
+	// Make sure that internal annotations are never persisted.
+	assert.Equal(t, map[string]string{"foo": "bar"}, obj.GetAnnotations())
+	assert.Equal(t, []string{"example.com/finalizer"}, obj.GetFinalizers())
+	assert.Equal(t, "1", obj.GetResourceVersion())
+	// Make sure that the spec has not been touched.
+	v, _, _ := unstructured.NestedString(obj.Object, "spec", "ingressClassName")
+	assert.Equal(t, "edgelb", v)
+
+	// Make sure that finalizers and annotations are removed whenever the Ingress resource has none.
+	ingress.Annotations = nil
+	ingress.Finalizers = nil
+	kubernetes.SetNetworkingV1IngressMetadata(obj, ingress)
+	assert.Empty(t, obj.GetAnnotations())
+	assert.Empty(t, obj.GetFinalizers())
+}
@@ -1,6 +1,7 @@
 package kubernetes
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/cache"
 )
 
@@ -13,3 +14,39 @@ func Key(obj interface{}) string {
 	}
 	return res
 }
+
+// HasFinalizer returns a value indicating whether the specified Kubernetes resource has the specified finalizer.
+func HasFinalizer(obj metav1.Object, finalizer string) bool {
+	for _, f := range obj.GetFinalizers() {
+		if f == finalizer {
+			return true
+		}
+	}
+	return false
+}
+
+// AddFinalizer adds the specified finalizer to the specified Kubernetes resource in-place.
+// It returns a value indicating whether the Kubernetes resource was changed (i.e. whether it didn't have the finalizer yet).
+func AddFinalizer(obj metav1.Object, finalizer string) bool {
+	if HasFinalizer(obj, finalizer) {
+		return false
+	}
+	obj.SetFinalizers(append(obj.GetFinalizers(), finalizer))
+	return true
+}
+
+// RemoveFinalizer removes the specified finalizer from the specified Kubernetes resource in-place.
+// It returns a value indicating whether the Kubernetes resource was changed (i.e. whether it had the finalizer).
+func RemoveFinalizer(obj metav1.Object, finalizer string) bool {
+	res := make([]string, 0, len(obj.GetFinalizers()))
+	for _, f := range obj.GetFinalizers() {
+		if f != finalizer {
+			res = append(res, f)
+		}
+	}
+	if len(res) == len(obj.GetFinalizers()) {
+		return false
+	}
+	obj.SetFinalizers(res)
+	return true
+}
@@ -48,3 +48,50 @@ func TestKey(t *testing.T) {
 		assert.Equal(t, test.output, kubernetes.Key(test.input))
 	}
 }
+
+// TestFinalizers tests the "HasFinalizer", "AddFinalizer" and "RemoveFinalizer" functions.
+func TestFinalizers(t *testing.T) {
+	tests := []struct {
+		description         string
+		finalizers          []string
+		expectedHas         bool
+		expectedAfterAdd    []string
+		expectedAfterRemove []string
+	}{
+		{
+			description:         "resource without finalizers",
+			finalizers:          nil,
+			expectedHas:         false,
+			expectedAfterAdd:    []string{"foo/bar"},
+			expectedAfterRemove: []string{},
+		},
+		{
+			description:         "resource with a different finalizer",
+			finalizers:          []string{"foo/baz"},
+			expectedHas:         false,
+			expectedAfterAdd:    []string{"foo/baz", "foo/bar"},
+			expectedAfterRemove: []string{"foo/baz"},
+		},
+		{
+			description:         "resource with the finalizer",
+			finalizers:          []string{"foo/baz", "foo/bar"},
+			expectedHas:         true,
+			expectedAfterAdd:    []string{"foo/baz", "foo/bar"},
+			expectedAfterRemove: []string{"foo/baz"},
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		obj := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Finalizers: test.finalizers,
+			},
+		}
+		assert.Equal(t, test.expectedHas, kubernetes.HasFinalizer(obj, "foo/bar"))
+		assert.Equal(t, !test.expectedHas, kubernetes.AddFinalizer(obj, "foo/bar"))
+		assert.Equal(t, test.expectedAfterAdd, obj.Finalizers)
+		assert.True(t, kubernetes.RemoveFinalizer(obj, "foo/bar"))
+		assert.Equal(t, test.expectedAfterRemove, obj.Finalizers)
+		assert.False(t, kubernetes.RemoveFinalizer(obj, "foo/bar"))
+	}
+}
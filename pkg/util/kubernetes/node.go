@@ -0,0 +1,30 @@
+package kubernetes
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// IsReadyAndSchedulableNode returns a value indicating whether the specified Node resource is Ready and schedulable (i.e. it has not been cordoned or drained).
+func IsReadyAndSchedulableNode(node *corev1.Node) bool {
+	// Cordoned (and hence draining) nodes are marked as unschedulable.
+	if node.Spec.Unschedulable {
+		return false
+	}
+	// Look for the "Ready" condition and check whether its status is "True".
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// NodeInternalIP returns the internal IP of the specified Node resource, or the empty string in case it has none.
+func NodeInternalIP(node *corev1.Node) string {
+	for _, address := range node.Status.Addresses {
+		if address.Type == corev1.NodeInternalIP {
+			return address.Address
+		}
+	}
+	return ""
+}
@@ -0,0 +1,61 @@
+package kubernetes_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/mesosphere/dklb/pkg/util/kubernetes"
+)
+
+// TestIsReadyAndSchedulableNode tests the "IsReadyAndSchedulableNode" function.
+func TestIsReadyAndSchedulableNode(t *testing.T) {
+	tests := []struct {
+		description   string
+		unschedulable bool
+		conditions    []corev1.NodeCondition
+		expected      bool
+	}{
+		{
+			description: "ready and schedulable node",
+			conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionFalse},
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+			},
+			expected: true,
+		},
+		{
+			description:   "cordoned node",
+			unschedulable: true,
+			conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+			},
+			expected: false,
+		},
+		{
+			description: "node not ready",
+			conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionUnknown},
+			},
+			expected: false,
+		},
+		{
+			description: "node without a ready condition",
+			conditions:  nil,
+			expected:    false,
+		},
+	}
+	for _, test := range tests {
+		t.Logf("test case: %s", test.description)
+		node := &corev1.Node{
+			Spec: corev1.NodeSpec{
+				Unschedulable: test.unschedulable,
+			},
+			Status: corev1.NodeStatus{
+				Conditions: test.conditions,
+			},
+		}
+		assert.Equal(t, test.expected, kubernetes.IsReadyAndSchedulableNode(node))
+	}
+}
@@ -114,7 +114,7 @@ var _ = Describe("Ingress", func() {
 						description: "invalid edgelb pool port",
 						fn: func(ingress *extsv1beta1.Ingress) {
 							ingress.Annotations = map[string]string{
-								constants.EdgeLBPoolPortAnnotationKey: "-1",
+								constants.EdgeLBPoolHTTPPortAnnotationKey: "-1",
 							}
 						},
 						expectedErrorMessageRegex: "-1 is not a valid port number",
@@ -162,26 +162,19 @@ var _ = Describe("Ingress", func() {
 					fn                        func(*extsv1beta1.Ingress)
 					expecterErrorMessageRegex string
 				}{
-					{
-						description: "update the target edgelb pool's name",
-						fn: func(ingress *extsv1beta1.Ingress) {
-							ingress.Annotations[constants.EdgeLBPoolNameAnnotationKey] = "new-name"
-						},
-						expecterErrorMessageRegex: "the name of the target edgelb pool cannot be changed",
-					},
 					{
 						description: "update the target edgelb pool's role",
 						fn: func(ingress *extsv1beta1.Ingress) {
 							ingress.Annotations[constants.EdgeLBPoolRoleAnnotationKey] = "new-role"
 						},
-						expecterErrorMessageRegex: "the role of the target edgelb pool cannot be changed",
+						expecterErrorMessageRegex: "the role of the target edgelb pool can only be changed together with its name",
 					},
 					{
 						description: "update the target edgelb pool's virtual network",
 						fn: func(ingress *extsv1beta1.Ingress) {
 							ingress.Annotations[constants.EdgeLBPoolNetworkAnnotationKey] = "new-name"
 						},
-						expecterErrorMessageRegex: "the virtual network of the target edgelb pool cannot be changed",
+						expecterErrorMessageRegex: "the virtual network of the target edgelb pool can only be changed together with its name",
 					},
 				}
 				for _, test := range tests {
@@ -445,7 +438,7 @@ var _ = Describe("Ingress", func() {
 							// Request for the EdgeLB pool to be deployed to an agent with the "slave_public" role.
 							constants.EdgeLBPoolRoleAnnotationKey: constants.EdgeLBRolePublic,
 							// Request for the EdgeLB pool to use the "18080" frontend bind port.
-							constants.EdgeLBPoolPortAnnotationKey: "18080",
+							constants.EdgeLBPoolHTTPPortAnnotationKey: "18080",
 						}
 						ingress.Spec.Rules = []extsv1beta1.IngressRule{
 							{
@@ -487,7 +480,7 @@ var _ = Describe("Ingress", func() {
 						Expect(publicIP).NotTo(BeEmpty())
 						// Attempt to connect to the ingress using the reported IP.
 						log.Debugf("attempting to connect to %q at %q", kubernetes.Key(ingress1), publicIP)
-						url := fmt.Sprintf("http://%s:%s/foo", publicIP, ingress1.Annotations[constants.EdgeLBPoolPortAnnotationKey])
+						url := fmt.Sprintf("http://%s:%s/foo", publicIP, ingress1.Annotations[constants.EdgeLBPoolHTTPPortAnnotationKey])
 						r, err := f.HTTPClient.Get(url)
 						if err != nil {
 							log.Debugf("waiting for the ingress to be reachable at %s", url)
@@ -514,7 +507,7 @@ var _ = Describe("Ingress", func() {
 							// Request for the EdgeLB pool to be deployed to an agent with the "slave_public" role.
 							constants.EdgeLBPoolRoleAnnotationKey: constants.EdgeLBRolePublic,
 							// Request for the EdgeLB pool to use the "28080" frontend bind port.
-							constants.EdgeLBPoolPortAnnotationKey: "28080",
+							constants.EdgeLBPoolHTTPPortAnnotationKey: "28080",
 						}
 						ingress.Spec.Rules = []extsv1beta1.IngressRule{
 							{
@@ -547,7 +540,7 @@ var _ = Describe("Ingress", func() {
 						Expect(publicIP).NotTo(BeEmpty())
 						// Attempt to connect to the ingress using the reported IP.
 						log.Debugf("attempting to connect to %q at %q", kubernetes.Key(ingress2), publicIP)
-						url := fmt.Sprintf("http://%s:%s/foo", publicIP, ingress2.Annotations[constants.EdgeLBPoolPortAnnotationKey])
+						url := fmt.Sprintf("http://%s:%s/foo", publicIP, ingress2.Annotations[constants.EdgeLBPoolHTTPPortAnnotationKey])
 						r, err := f.HTTPClient.Get(url)
 						if err != nil {
 							log.Debugf("waiting for the ingress to be reachable at %s", url)
@@ -566,13 +559,13 @@ var _ = Describe("Ingress", func() {
 						expectedPod       string
 					}{
 						{
-							port:              ingress1.Annotations[constants.EdgeLBPoolPortAnnotationKey],
+							port:              ingress1.Annotations[constants.EdgeLBPoolHTTPPortAnnotationKey],
 							path:              "/foo",
 							expectedNamespace: echoPod1.Namespace,
 							expectedPod:       echoPod1.Name,
 						},
 						{
-							port:              ingress2.Annotations[constants.EdgeLBPoolPortAnnotationKey],
+							port:              ingress2.Annotations[constants.EdgeLBPoolHTTPPortAnnotationKey],
 							path:              "/foo",
 							expectedNamespace: echoPod2.Namespace,
 							expectedPod:       echoPod2.Name,
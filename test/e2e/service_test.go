@@ -185,26 +185,19 @@ var _ = Describe("Service", func() {
 					fn                        func(*corev1.Service)
 					expecterErrorMessageRegex string
 				}{
-					{
-						description: "update the target edgelb pool's name",
-						fn: func(service *corev1.Service) {
-							service.Annotations[constants.EdgeLBPoolNameAnnotationKey] = "new-name"
-						},
-						expecterErrorMessageRegex: "the name of the target edgelb pool cannot be changed",
-					},
 					{
 						description: "update the target edgelb pool's role",
 						fn: func(service *corev1.Service) {
 							service.Annotations[constants.EdgeLBPoolRoleAnnotationKey] = "new-role"
 						},
-						expecterErrorMessageRegex: "the role of the target edgelb pool cannot be changed",
+						expecterErrorMessageRegex: "the role of the target edgelb pool can only be changed together with its name",
 					},
 					{
 						description: "update the target edgelb pool's virtual network",
 						fn: func(service *corev1.Service) {
 							service.Annotations[constants.EdgeLBPoolNetworkAnnotationKey] = "new-name"
 						},
-						expecterErrorMessageRegex: "the virtual network of the target edgelb pool cannot be changed",
+						expecterErrorMessageRegex: "the virtual network of the target edgelb pool can only be changed together with its name",
 					},
 				}
 				for _, test := range tests {
@@ -25,13 +25,19 @@ func NewFakeSharedInformerFactory(res ...runtime.Object) kubeinformers.SharedInf
 	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(fakeClient, 30*time.Second)
 	// Start all the required informers.
 	configMapInformer := kubeInformerFactory.Core().V1().ConfigMaps()
+	endpointsInformer := kubeInformerFactory.Core().V1().Endpoints()
 	ingressInformer := kubeInformerFactory.Extensions().V1beta1().Ingresses()
+	nodeInformer := kubeInformerFactory.Core().V1().Nodes()
+	secretInformer := kubeInformerFactory.Core().V1().Secrets()
 	serviceInformer := kubeInformerFactory.Core().V1().Services()
 	go configMapInformer.Informer().Run(wait.NeverStop)
+	go endpointsInformer.Informer().Run(wait.NeverStop)
 	go ingressInformer.Informer().Run(wait.NeverStop)
+	go nodeInformer.Informer().Run(wait.NeverStop)
+	go secretInformer.Informer().Run(wait.NeverStop)
 	go serviceInformer.Informer().Run(wait.NeverStop)
 	// Wait for the caches to be synced.
-	if !kubecache.WaitForCacheSync(wait.NeverStop, configMapInformer.Informer().HasSynced, ingressInformer.Informer().HasSynced, serviceInformer.Informer().HasSynced) {
+	if !kubecache.WaitForCacheSync(wait.NeverStop, configMapInformer.Informer().HasSynced, endpointsInformer.Informer().HasSynced, ingressInformer.Informer().HasSynced, nodeInformer.Informer().HasSynced, secretInformer.Informer().HasSynced, serviceInformer.Informer().HasSynced) {
 		panic("failed to wait for caches to be synced")
 	}
 	// Return the shared informer factory.
@@ -0,0 +1,24 @@
+package secrets
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockSecretsManager is a mock implementation of the "SecretsManager" interface.
+type MockSecretsManager struct {
+	mock.Mock
+}
+
+// DeleteSecret deletes the DC/OS secret at the specified path.
+func (m *MockSecretsManager) DeleteSecret(ctx context.Context, path string) error {
+	args := m.Called(ctx, path)
+	return args.Error(0)
+}
+
+// PutSecret creates or updates the DC/OS secret at the specified path so that it holds the specified value.
+func (m *MockSecretsManager) PutSecret(ctx context.Context, path, value string) error {
+	args := m.Called(ctx, path, value)
+	return args.Error(0)
+}
@@ -37,3 +37,10 @@ func WithPorts(ports []corev1.ServicePort) ResourceCustomizer {
 		service.Spec.Ports = ports
 	}
 }
+
+// WithLoadBalancerSourceRanges returns a customizer that sets the specified source ranges on a Service resource.
+func WithLoadBalancerSourceRanges(ranges []string) ResourceCustomizer {
+	return func(service *corev1.Service) {
+		service.Spec.LoadBalancerSourceRanges = ranges
+	}
+}